@@ -1,24 +1,46 @@
 package main
 
 import (
+	"context"
+	"flag"
 	"fmt"
 	"log/slog"
 	"net/http"
 	"os"
 	"time"
+	// time zones of users are known without tzdata of the host
+	_ "time/tzdata"
 
 	authclient "ChadProgress/internal/auth_client/http"
+	localauth "ChadProgress/internal/auth_client/local"
 	"ChadProgress/internal/config"
+	accounthandler "ChadProgress/internal/http_server/handlers/url/account"
+	adminhandler "ChadProgress/internal/http_server/handlers/url/admin"
 	"ChadProgress/internal/http_server/handlers/url/authorization"
+	exporthandler "ChadProgress/internal/http_server/handlers/url/export"
+	reporthandler "ChadProgress/internal/http_server/handlers/url/report"
+	sessionhandler "ChadProgress/internal/http_server/handlers/url/session"
 	userhandler "ChadProgress/internal/http_server/handlers/url/user"
+	httprouter "ChadProgress/internal/http_server/router"
+	"ChadProgress/internal/lib/circuitbreaker"
 	"ChadProgress/internal/lib/logger/handlers/slogpretty"
+	"ChadProgress/internal/lib/logger/redact"
+	"ChadProgress/internal/lib/mailer"
+	"ChadProgress/internal/lib/ratelimit"
+	"ChadProgress/internal/lib/retry"
 	http2 "ChadProgress/internal/middleware/auth"
+	"ChadProgress/internal/middleware/throttle"
+	"ChadProgress/internal/models"
+	adminservice "ChadProgress/internal/services/admin"
 	userauthservice "ChadProgress/internal/services/authorization"
+	exportservice "ChadProgress/internal/services/export"
+	maintenanceservice "ChadProgress/internal/services/maintenance"
+	reportservice "ChadProgress/internal/services/report"
 	userservice "ChadProgress/internal/services/user"
 	"ChadProgress/storage/postgres"
 
 	"github.com/go-chi/chi/v5"
-	"github.com/go-chi/cors"
+	"github.com/go-chi/chi/v5/middleware"
 )
 
 const (
@@ -27,32 +49,128 @@ const (
 	envProd  = "prod"
 )
 
+// cmdReconcileUsers removes unfinished registrations and orphaned credentials given as arguments,
+// orphans only with providers managing credentials:
+// cp --config_path=... reconcile-users [email...]
+const cmdReconcileUsers = "reconcile-users"
+
+// cmdGrantAdmin gives admin role to registered user, admins can not be registered through API:
+// cp --config_path=... grant-admin email
+const cmdGrantAdmin = "grant-admin"
+
 func main() {
 	cfg := config.MustLoad()
-	log := setupLogger(cfg.Env)
-	dsn := fmt.Sprintf(
-		"postgres://%s:%s@%s:%s/%s?sslmode=%s",
-		cfg.DB.Username,
-		cfg.DB.DBPassword,
-		cfg.DB.Host, cfg.DB.Port,
-		cfg.DB.DBName,
-		cfg.DB.SSLMode,
-	)
-
-	storage, err := postgres.New(dsn)
+	log := setupLogger(cfg.Env, redact.ParsePolicy(cfg.Log.RedactPolicy))
+
+	storage, err := postgres.New(cfg.DB.DSN(), log, postgres.Options{
+		MaxOpenConns:       cfg.DB.MaxOpenConns,
+		MaxIdleConns:       cfg.DB.MaxIdleConns,
+		ConnMaxLifetime:    cfg.DB.ConnMaxLifetime,
+		ConnMaxIdleTime:    cfg.DB.ConnMaxIdleTime,
+		SlowQueryThreshold: cfg.DB.SlowQueryThreshold,
+		ReplicaDSNs:        cfg.DB.ReplicaDSNs(),
+	})
 	if err != nil {
 		log.Error("failed to init storage:", slog.String("errormsg", err.Error()))
 		return
 	}
 
-	authServiceClient := authclient.NewAuthClient(cfg.AuthClient.BaseURL, log, time.Second*10)
-	userAuthService := userauthservice.NewUserAuthService(storage, authServiceClient, log)
+	authServiceClient, err := setupAuthProvider(cfg.AuthClient, storage, log)
+	if err != nil {
+		log.Error("failed to init auth provider", slog.String("error", err.Error()))
+		return
+	}
+
+	limiter, lockout := setupRateLimiter(cfg.RateLimit, log)
+	notifier := mailer.NewNotifier(setupMailer(cfg.Mailer, log), cfg.Mailer.LinkBaseURL)
+	retention := models.RetentionPolicy{
+		Profile:       cfg.AccountDeletion.Profile,
+		Metrics:       cfg.AccountDeletion.Metrics,
+		SharedRecords: cfg.AccountDeletion.SharedRecords,
+	}
+	if err = retention.Validate(); err != nil {
+		log.Error("invalid account deletion policy", slog.String("error", err.Error()))
+		return
+	}
+	userAuthService := userauthservice.NewUserAuthService(authStorage{storage}, authServiceClient, lockout, notifier, retention, log)
 	userAuthHandler := authorization.NewUserAuthHandler(userAuthService, log)
+	adminService := adminservice.NewAdminService(storage, log)
+
+	switch flag.Arg(0) {
+	case "":
+	case cmdReconcileUsers:
+		if err = reconcileUsers(userAuthService, cfg.PendingUserTTL, flag.Args()[1:], log); err != nil {
+			os.Exit(1)
+		}
+		return
+	case cmdGrantAdmin:
+		if err = grantAdmin(adminService, flag.Arg(1), log); err != nil {
+			os.Exit(1)
+		}
+		return
+	default:
+		log.Error("unknown command", slog.String("command", flag.Arg(0)))
+		os.Exit(2)
+	}
 
-	userService := userservice.NewUserService(storage, log)
+	userService := userservice.NewUserService(userStorage{storage}, log)
 	userHandler := userhandler.NewUserHandler(log, userService)
 
-	router := chi.NewRouter()
+	sessionHandler := sessionhandler.NewSessionHandler(log, userAuthService, userService)
+	accountHandler := accounthandler.NewAccountHandler(log, userAuthService)
+
+	exportService := exportservice.NewExportService(storage, exportservice.Options{
+		Dir:             cfg.DataExport.Dir,
+		TTL:             cfg.DataExport.TTL,
+		SyncMaxRecords:  cfg.DataExport.SyncMaxRecords,
+		Workers:         cfg.DataExport.Workers,
+		QueueSize:       cfg.DataExport.QueueSize,
+		CleanupInterval: cfg.DataExport.CleanupInterval,
+	}, log)
+	go exportService.Run(context.Background())
+
+	maintenanceService := maintenanceservice.NewMaintenanceService(storage, maintenanceservice.Options{
+		Interval:    cfg.MetricPartitions.Interval,
+		MonthsAhead: cfg.MetricPartitions.MonthsAhead,
+	}, log)
+	go maintenanceService.Run(context.Background())
+	exportHandler := exporthandler.NewExportHandler(log, exportService, userService)
+	reportHandler := reporthandler.NewReportHandler(log, reportservice.NewReportService(storage, log), userService)
+	adminHandler := adminhandler.NewAdminHandler(log, adminService)
+
+	authMiddleware := http2.AuthMiddleware(authServiceClient, userAuthService, storage)
+	authLimits := cfg.RateLimit.Auth
+	userRouteLimits := make(map[string]ratelimit.Limit, len(cfg.RateLimit.User.Routes))
+	for route, limit := range cfg.RateLimit.User.Routes {
+		userRouteLimits[route] = toLimit(limit)
+	}
+	router := httprouter.New(httprouter.Handlers{
+		Auth:    userAuthHandler,
+		User:    userHandler,
+		Session: sessionHandler,
+		Account: accountHandler,
+		Export:  exportHandler,
+		Report:  reportHandler,
+		Admin:   adminHandler,
+	}, httprouter.Middlewares{
+		Proxy: proxyMiddlewares(cfg.HTTPServer),
+		Auth:  authMiddleware,
+		Admin: http2.RequireRole(storage, models.RoleAdmin),
+		Login: chi.Middlewares{
+			throttle.Limit(limiter, toLimit(authLimits.PerIP), "login_ip", throttle.ByIP, log),
+			throttle.Limit(limiter, toLimit(authLimits.PerEmail), "login_email", throttle.ByEmail, log),
+		},
+		Register: chi.Middlewares{
+			throttle.Limit(limiter, toLimit(authLimits.PerIP), "register_ip", throttle.ByIP, log),
+			throttle.Limit(limiter, toLimit(authLimits.PerEmail), "register_email", throttle.ByEmail, log),
+		},
+		Refresh: chi.Middlewares{
+			throttle.Limit(limiter, toLimit(authLimits.PerIP), "refresh_ip", throttle.ByIP, log),
+		},
+		User: chi.Middlewares{
+			throttle.PerRoute(limiter, toLimit(cfg.RateLimit.User.Default), userRouteLimits, "user", log),
+		},
+	})
 
 	serverAddr := cfg.HTTPServer.Host + ":" + cfg.HTTPServer.Port
 	server := &http.Server{
@@ -63,44 +181,6 @@ func main() {
 		IdleTimeout:  cfg.HTTPServer.IdleTimeout,
 	}
 
-	authMiddleware := http2.AuthMiddleware(authServiceClient)
-
-	router.Use(cors.Handler(cors.Options{
-		AllowedOrigins:   []string{"*"},
-		AllowedMethods:   []string{"GET", "POST", "PUT", "DELETE", "OPTIONS"},
-		AllowedHeaders:   []string{"Accept", "Authorization", "Content-Type", "X-CSRF-Token"},
-		AllowCredentials: true,
-	}))
-
-	// Open endpoints
-	router.Route("/authorization", func(r chi.Router) {
-		r.Post("/register", userAuthHandler.Register)
-		r.Post("/login", userAuthHandler.Login)
-	})
-
-	// Protected endpoints
-	router.Route("/user", func(r chi.Router) {
-		r.Use(authMiddleware)
-
-		// Trainer endpoints
-		r.Post("/trainers/profile", userHandler.CreateTrainer)
-		r.Get("/trainers/profile", userHandler.GetTrainerProfile)
-		r.Get("/trainers/clients", userHandler.GetTrainersClients)
-		r.Post("/training-plan", userHandler.CreatePlan)
-		r.Post("/progress-reports", userHandler.AddProgressReport)
-
-		// Client endpoints
-		r.Post("/clients/profile", userHandler.CreateClient)
-		r.Get("/clients/profile", userHandler.GetClientProfile)
-		r.Patch("/clients/select-trainers", userHandler.SelectTrainer)
-		r.Post("/clients/metrics", userHandler.AddMetrics)
-		r.Get("/clients/metrics", userHandler.GetMetrics)
-
-		// Common endpoints
-		r.Get("/progress-reports", userHandler.GetProgressReports)
-		r.Get("/training-plan", userHandler.GetPlan)
-	})
-
 	log.Info("server started", slog.String("servaddr", serverAddr))
 
 	if err = server.ListenAndServe(); err != nil {
@@ -110,29 +190,171 @@ func main() {
 	log.Error("server stopped")
 }
 
-func setupLogger(env string) *slog.Logger {
+// authProvider is implemented by both external auth service client and built-in provider
+type authProvider interface {
+	userauthservice.AuthServiceClient
+	http2.TokenValidator
+}
+
+func setupAuthProvider(cfg config.AuthServiceClient, storage *postgres.Storage, log *slog.Logger) (authProvider, error) {
+	switch cfg.Provider {
+	case "local":
+		log.Info("using built-in auth provider")
+		return localauth.New(storage, log, localauth.Options{
+			SigningKey: []byte(cfg.Local.SigningKey),
+			Issuer:     cfg.Local.Issuer,
+			AccessTTL:  cfg.Local.AccessTTL,
+			RefreshTTL: cfg.Local.RefreshTTL,
+			BcryptCost: cfg.Local.BcryptCost,
+		})
+	case "http", "":
+		return authclient.NewAuthClient(cfg.BaseURL, log, authclient.Options{
+			Timeout: cfg.Timeout,
+			Retry: retry.Policy{
+				Attempts:  cfg.Retries,
+				BaseDelay: cfg.BackoffBase,
+				MaxDelay:  cfg.BackoffMax,
+			},
+			Breaker: circuitbreaker.Settings{
+				FailureThreshold: cfg.BreakerFailures,
+				OpenTimeout:      cfg.BreakerOpenTimeout,
+			},
+		}), nil
+	default:
+		return nil, fmt.Errorf("unknown auth provider %q", cfg.Provider)
+	}
+}
+
+func reconcileUsers(service *userauthservice.UserAuthService, pendingTTL time.Duration, orphans []string, log *slog.Logger) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Minute)
+	defer cancel()
+
+	report, err := service.ReconcileUsers(ctx, time.Now().Add(-pendingTTL), orphans)
+	if err != nil {
+		log.Error("failed to reconcile users", slog.String("error", err.Error()))
+		return err
+	}
+
+	for _, email := range report.Removed {
+		fmt.Println("removed", email)
+	}
+	for _, email := range report.Failed {
+		fmt.Println("failed", email)
+	}
+	if len(report.Failed) > 0 {
+		return fmt.Errorf("%d users were not reconciled", len(report.Failed))
+	}
+
+	return nil
+}
+
+func grantAdmin(service *adminservice.AdminService, email string, log *slog.Logger) error {
+	if email == "" {
+		err := fmt.Errorf("email is required")
+		log.Error("failed to grant admin role", slog.String("error", err.Error()))
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+
+	if err := service.GrantAdmin(ctx, "cli", email); err != nil {
+		log.Error("failed to grant admin role", slog.String("error", err.Error()))
+		return err
+	}
+	fmt.Println("granted admin role to", email)
+
+	return nil
+}
+
+func setupMailer(cfg config.Mailer, log *slog.Logger) mailer.Sender {
+	switch cfg.Backend {
+	case "smtp":
+		log.Info("using smtp mailer", slog.String("host", cfg.SMTP.Host))
+		return mailer.NewSMTP(mailer.SMTPOptions{
+			Host:     cfg.SMTP.Host,
+			Port:     cfg.SMTP.Port,
+			Username: cfg.SMTP.Username,
+			Password: cfg.SMTP.Password,
+			From:     cfg.From,
+			Timeout:  cfg.SMTP.Timeout,
+		})
+	case "file":
+		log.Info("emails are written to files", slog.String("dir", cfg.Dir))
+		return mailer.NewFile(cfg.Dir, cfg.From)
+	default:
+		return mailer.NewLog(log)
+	}
+}
+
+// setupRateLimiter returns request limiter and login lockout kept in the same store,
+// so that with redis every instance sees the same limits and locks
+func setupRateLimiter(cfg config.RateLimit, log *slog.Logger) (ratelimit.Limiter, userauthservice.LoginGuard) {
+	policy := ratelimit.LockoutPolicy{
+		MaxFailures: cfg.Lockout.MaxFailures,
+		Window:      cfg.Lockout.Window,
+		Base:        cfg.Lockout.Base,
+		Max:         cfg.Lockout.Max,
+	}
+
+	switch cfg.Backend {
+	case "redis":
+		log.Info("using redis rate limiter", slog.String("addr", cfg.RedisAddr))
+		client := ratelimit.NewRESPClient(cfg.RedisAddr, cfg.RedisPassword, time.Second)
+		return ratelimit.NewRedis(client, "cp:ratelimit:"), ratelimit.NewRedisLockout(client, "cp:lockout:", policy, log)
+	default:
+		return ratelimit.NewMemory(), ratelimit.NewLockout(policy)
+	}
+}
+
+// proxyMiddlewares take client address from proxy headers when the proxy is trusted. Otherwise
+// RemoteAddr is kept, since anyone could set the headers.
+func proxyMiddlewares(cfg config.HTTPServer) chi.Middlewares {
+	if !cfg.TrustProxyHeaders {
+		return nil
+	}
+
+	return chi.Middlewares{middleware.RealIP}
+}
+
+func toLimit(l config.Limit) ratelimit.Limit {
+	return ratelimit.Limit{
+		Requests: l.Requests,
+		Per:      l.Per,
+		Burst:    l.Burst,
+	}
+}
+
+func setupLogger(env string, policy redact.Policy) *slog.Logger {
 	var log *slog.Logger
 
 	switch env {
 	case envLocal:
-		log = setupPrettySlog()
+		log = setupPrettySlog(policy)
 	case envDev:
 		log = slog.New(
-			slog.NewJSONHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelDebug}),
+			slog.NewJSONHandler(os.Stdout, &slog.HandlerOptions{
+				Level:       slog.LevelDebug,
+				ReplaceAttr: redact.ReplaceAttr(policy),
+			}),
 		)
 	case envProd:
 		log = slog.New(
-			slog.NewJSONHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelInfo}),
+			slog.NewJSONHandler(os.Stdout, &slog.HandlerOptions{
+				Level:       slog.LevelInfo,
+				ReplaceAttr: redact.ReplaceAttr(policy),
+			}),
 		)
 	}
 
 	return log
 }
 
-func setupPrettySlog() *slog.Logger {
+func setupPrettySlog(policy redact.Policy) *slog.Logger {
 	opts := slogpretty.PrettyHandlerOptions{
 		SlogOpts: &slog.HandlerOptions{
-			Level: slog.LevelDebug,
+			Level:       slog.LevelDebug,
+			ReplaceAttr: redact.ReplaceAttr(policy),
 		},
 	}
 	handler := opts.NewPrettyHandler(os.Stdout)
@@ -0,0 +1,31 @@
+package main
+
+import (
+	"context"
+
+	userauthservice "ChadProgress/internal/services/authorization"
+	userservice "ChadProgress/internal/services/user"
+	"ChadProgress/storage/postgres"
+)
+
+// userStorage passes storage bound to a transaction to user service as its own Storage interface
+type userStorage struct {
+	*postgres.Storage
+}
+
+func (s userStorage) WithTx(ctx context.Context, fn func(tx userservice.Storage) error) error {
+	return s.Storage.WithTx(ctx, func(tx *postgres.Storage) error {
+		return fn(userStorage{tx})
+	})
+}
+
+// authStorage passes storage bound to a transaction to auth service as its own Storage interface
+type authStorage struct {
+	*postgres.Storage
+}
+
+func (s authStorage) WithTx(ctx context.Context, fn func(tx userauthservice.Storage) error) error {
+	return s.Storage.WithTx(ctx, func(tx *postgres.Storage) error {
+		return fn(authStorage{tx})
+	})
+}
@@ -3,8 +3,18 @@ package auth_client
 import "errors"
 
 var (
-	ErrClientUnavailable = errors.New("auth client is not available")
-	ErrUserNotFound      = errors.New("user not found")
+	// ErrClientUnavailable means auth service can not be reached, answers with 5xx or circuit breaker is open
+	ErrClientUnavailable  = errors.New("auth client is not available")
+	ErrUserNotFound       = errors.New("user not found")
+	ErrUserAlreadyExists  = errors.New("user already exists")
+	ErrInvalidCredentials = errors.New("invalid credentials")
+	ErrInvalidToken       = errors.New("invalid token")
+	// ErrUnexpectedResponse means auth service answered with status or body client does not understand
+	ErrUnexpectedResponse = errors.New("unexpected response from auth service")
+	// ErrInvalidPassword means provider can not store the password, e.g. it is longer than bcrypt accepts
+	ErrInvalidPassword = errors.New("password does not meet requirements")
+	// ErrNotSupported means auth service has no documented endpoint for the operation
+	ErrNotSupported = errors.New("operation is not supported by auth service")
 )
 
 // UserAuthRequestInterface interface summarizes requests for authorization
@@ -21,9 +31,10 @@ type UserRegistrationRequest struct {
 
 // UserRegistrationResponse particular structure for registration responses to auth services
 type UserRegistrationResponse struct {
-	Status string `json:"status"`
-	Token  string `json:"token"`
-	Error  string `json:"error"`
+	Status       string `json:"status"`
+	Token        string `json:"token"`
+	RefreshToken string `json:"refresh_token,omitempty"`
+	Error        string `json:"error"`
 }
 
 // UserLoginRequest particular structure for login requests to auth services
@@ -34,8 +45,9 @@ type UserLoginRequest struct {
 
 // UserLoginResponse particular structure for login responses to auth services
 type UserLoginResponse struct {
-	Token string `json:"token"`
-	Error string `json:"error"`
+	Token        string `json:"token"`
+	RefreshToken string `json:"refresh_token,omitempty"`
+	Error        string `json:"error"`
 }
 
 type UserValidateTokenResponse struct {
@@ -12,28 +12,44 @@ import (
 	"time"
 
 	"ChadProgress/internal/auth_client"
+	"ChadProgress/internal/lib/circuitbreaker"
+	"ChadProgress/internal/lib/retry"
 )
 
+// maxResponseBody limits how much of auth service response is read
+const maxResponseBody = 1 << 20
+
+// Options tune resilience of the client. Retries are made only for idempotent calls.
+type Options struct {
+	Timeout time.Duration
+	Retry   retry.Policy
+	Breaker circuitbreaker.Settings
+}
+
 type AuthServiceClient struct {
 	baseUrl    string
 	log        *slog.Logger
 	httpClient *http.Client
+	retry      retry.Policy
+	breaker    *circuitbreaker.Breaker
 }
 
-func NewAuthClient(baseUrl string, log *slog.Logger, timeOut time.Duration) *AuthServiceClient {
+func NewAuthClient(baseUrl string, log *slog.Logger, opts Options) *AuthServiceClient {
 	return &AuthServiceClient{
 		baseUrl:    baseUrl,
 		log:        log,
-		httpClient: &http.Client{Timeout: timeOut},
+		httpClient: &http.Client{Timeout: opts.Timeout},
+		retry:      opts.Retry,
+		breaker:    circuitbreaker.New(opts.Breaker),
 	}
 }
 
 func (c *AuthServiceClient) RegisterUser(ctx context.Context, authReq auth_client.UserAuthRequestInterface) (*auth_client.UserRegistrationResponse, error) {
 	const op = "auth_client.http.auth_client.RegisterUser"
-
 	log := c.log.With(
 		slog.String("op", op),
 	)
+
 	regRequest := auth_client.UserRegistrationRequest{
 		Login:    authReq.GetLogin(),
 		Password: authReq.GetPassword(),
@@ -41,98 +57,87 @@ func (c *AuthServiceClient) RegisterUser(ctx context.Context, authReq auth_clien
 
 	jsonPayload, err := json.Marshal(regRequest)
 	if err != nil {
-		log.Error(
-			"error occurred: " + err.Error(),
-		)
-		return nil, err
-	}
-
-	req, err := http.NewRequestWithContext(
-		ctx,
-		http.MethodPost,
-		c.baseUrl+"/register",
-		bytes.NewBuffer(jsonPayload),
-	)
-	if err != nil {
-		log.Error("error occurred: " + err.Error())
 		return nil, fmt.Errorf("%s: %w", op, err)
 	}
 
-	req.Header.Set("Content-Type", "application/json")
+	// registration is not idempotent, so it is never retried
+	status, body, err := c.send(ctx, retry.Once, func(ctx context.Context) (*http.Request, error) {
+		req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.baseUrl+"/register", bytes.NewReader(jsonPayload))
+		if err != nil {
+			return nil, err
+		}
+		req.Header.Set("Content-Type", "application/json")
 
-	resp, err := c.httpClient.Do(req)
+		return req, nil
+	})
 	if err != nil {
-		log.Error("error occurred: " + err.Error())
+		log.Error("auth service request failed", slog.String("error", err.Error()))
 		return nil, fmt.Errorf("%s: %w", op, err)
 	}
 
-	defer resp.Body.Close()
-
-	contentLen := resp.Header.Get("Content-Length")
-	if resp.StatusCode != http.StatusOK && contentLen == "0" {
-		log.Error("auth client response status code: " + fmt.Sprintf("%v", resp.StatusCode))
-		body, _ := io.ReadAll(resp.Body)
-		return nil, errors.New("failed to register user: " + string(body))
+	if status != http.StatusOK {
+		log.Error("auth service rejected registration", slog.Int("status", status))
+		return nil, fmt.Errorf("%s: %w", op, statusError(status, auth_client.ErrUnexpectedResponse))
 	}
 
 	var regResp auth_client.UserRegistrationResponse
-	err = json.NewDecoder(resp.Body).Decode(&regResp)
-	if err != nil {
-		log.Error("error occurred: " + err.Error())
-		return nil, errors.New("failed to parse response from auth service")
+	if err = json.Unmarshal(body, &regResp); err != nil {
+		log.Error("failed to parse response", slog.String("error", err.Error()))
+		return nil, fmt.Errorf("%s: %w: %w", op, auth_client.ErrUnexpectedResponse, err)
+	}
+
+	if regResp.Error != "" {
+		log.Error("auth service did not register user", slog.String("error", regResp.Error))
+		return nil, fmt.Errorf("%s: %w: %s", op, auth_client.ErrUnexpectedResponse, regResp.Error)
 	}
 
 	return &regResp, nil
 }
 
 func (c *AuthServiceClient) LoginUser(ctx context.Context, authReq auth_client.UserAuthRequestInterface) (*auth_client.UserLoginResponse, error) {
-	const op = "auth_client.http.auth_client.RegisterUser"
+	const op = "auth_client.http.auth_client.LoginUser"
 	log := c.log.With(
 		slog.String("op", op),
 	)
-	loginReq := auth_client.UserRegistrationRequest{
+
+	loginReq := auth_client.UserLoginRequest{
 		Login:    authReq.GetLogin(),
 		Password: authReq.GetPassword(),
 	}
 
 	jsonPayload, err := json.Marshal(loginReq)
 	if err != nil {
-		log.Error(
-			"error occurred: " + err.Error(),
-		)
-		return nil, err
-	}
-
-	req, err := http.NewRequestWithContext(
-		ctx,
-		http.MethodGet,
-		c.baseUrl+"/auth",
-		bytes.NewBuffer(jsonPayload),
-	)
-	if err != nil {
-		log.Error("error occurred: " + err.Error())
 		return nil, fmt.Errorf("%s: %w", op, err)
 	}
 
-	req.Header.Set("Content-Type", "application/json")
+	status, body, err := c.send(ctx, c.retry, func(ctx context.Context) (*http.Request, error) {
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.baseUrl+"/auth", bytes.NewReader(jsonPayload))
+		if err != nil {
+			return nil, err
+		}
+		req.Header.Set("Content-Type", "application/json")
 
-	resp, err := c.httpClient.Do(req)
+		return req, nil
+	})
 	if err != nil {
-		log.Error("error occurred: " + err.Error())
+		log.Error("auth service request failed", slog.String("error", err.Error()))
 		return nil, fmt.Errorf("%s: %w", op, err)
 	}
-	defer resp.Body.Close()
 
 	var loginResp auth_client.UserLoginResponse
-	err = json.NewDecoder(resp.Body).Decode(&loginResp)
-	if err != nil {
-		log.Error("error occurred: " + err.Error())
-		return nil, fmt.Errorf("%s: %w", op, errors.New("failed to parse response from auth service"))
+	if status != http.StatusOK {
+		log.Info("auth service rejected login", slog.Int("status", status))
+		return nil, fmt.Errorf("%s: %w", op, statusError(status, auth_client.ErrInvalidCredentials))
+	}
+
+	if err = json.Unmarshal(body, &loginResp); err != nil {
+		log.Error("failed to parse response", slog.String("error", err.Error()))
+		return nil, fmt.Errorf("%s: %w: %w", op, auth_client.ErrUnexpectedResponse, err)
 	}
 
 	if loginResp.Error != "" {
-		log.Error("auth client did not find user")
-		return nil, fmt.Errorf("%s: %w", op, errors.New(loginResp.Error))
+		log.Info("auth service rejected credentials", slog.String("error", loginResp.Error))
+		return nil, fmt.Errorf("%s: %w", op, auth_client.ErrInvalidCredentials)
 	}
 
 	return &loginResp, nil
@@ -145,32 +150,133 @@ func (c *AuthServiceClient) ValidateToken(ctx context.Context, token string) (st
 		slog.String("op", op),
 	)
 
-	req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.baseUrl+"/validate", nil)
-	if err != nil {
-		return "", err
-	}
-	req.Header.Set("Authorization", "Bearer "+token)
+	status, body, err := c.send(ctx, c.retry, func(ctx context.Context) (*http.Request, error) {
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.baseUrl+"/validate", nil)
+		if err != nil {
+			return nil, err
+		}
+		req.Header.Set("Authorization", "Bearer "+token)
 
-	resp, err := c.httpClient.Do(req)
+		return req, nil
+	})
 	if err != nil {
-		return "", err
+		log.Error("auth service request failed", slog.String("error", err.Error()))
+		return "", fmt.Errorf("%s: %w", op, err)
 	}
-	defer resp.Body.Close()
 
-	if resp.StatusCode != http.StatusOK {
-		return "", errors.New("token validation failed")
+	if status != http.StatusOK {
+		return "", fmt.Errorf("%s: %w", op, statusError(status, auth_client.ErrInvalidToken))
 	}
 
 	var validateResp auth_client.UserValidateTokenResponse
-	err = json.NewDecoder(resp.Body).Decode(&validateResp)
-	if err != nil {
-		return "", errors.New("failed to parse response from auth service")
+	if err = json.Unmarshal(body, &validateResp); err != nil {
+		return "", fmt.Errorf("%s: %w: %w", op, auth_client.ErrUnexpectedResponse, err)
 	}
 
 	if validateResp.Status != "OK" {
-		return "", errors.New(validateResp.Error)
+		return "", fmt.Errorf("%s: %w: %s", op, auth_client.ErrInvalidToken, validateResp.Error)
 	}
-	log.Info("token successfully validated")
+	log.Debug("token successfully validated")
 
 	return validateResp.UserLogin, nil
 }
+
+// DeleteUser is not supported: the auth service contract has no endpoint to remove credentials
+func (c *AuthServiceClient) DeleteUser(ctx context.Context, login string) error {
+	const op = "auth_client.http.auth_client.DeleteUser"
+
+	return fmt.Errorf("%s: %w", op, auth_client.ErrNotSupported)
+}
+
+// ChangeLogin is not supported: the auth service contract has no endpoint to rename credentials
+func (c *AuthServiceClient) ChangeLogin(ctx context.Context, login, newLogin string) error {
+	const op = "auth_client.http.auth_client.ChangeLogin"
+
+	return fmt.Errorf("%s: %w", op, auth_client.ErrNotSupported)
+}
+
+// ManagesCredentials reports that credentials can be neither deleted nor renamed through the auth service
+func (c *AuthServiceClient) ManagesCredentials() bool {
+	return false
+}
+
+// send makes request through circuit breaker retrying unavailability according to policy.
+// Transport failures and 5xx/429 statuses are reported as auth_client.ErrClientUnavailable,
+// any other status is returned to caller together with response body. Calls canceled by caller
+// return error of ctx and are neither retried nor counted by the breaker.
+func (c *AuthServiceClient) send(
+	ctx context.Context,
+	policy retry.Policy,
+	newRequest func(ctx context.Context) (*http.Request, error),
+) (int, []byte, error) {
+	var (
+		status int
+		body   []byte
+	)
+
+	err := retry.Do(ctx, policy, retryable, func() error {
+		if err := c.breaker.Allow(); err != nil {
+			return fmt.Errorf("%w: %w", auth_client.ErrClientUnavailable, err)
+		}
+
+		req, err := newRequest(ctx)
+		if err != nil {
+			c.breaker.Success()
+			return err
+		}
+
+		resp, err := c.httpClient.Do(req)
+		if err != nil {
+			if ctx.Err() != nil {
+				c.breaker.Release()
+				return ctx.Err()
+			}
+			c.breaker.Failure()
+			return fmt.Errorf("%w: %w", auth_client.ErrClientUnavailable, err)
+		}
+		defer resp.Body.Close()
+
+		body, err = io.ReadAll(io.LimitReader(resp.Body, maxResponseBody))
+		if err != nil {
+			if ctx.Err() != nil {
+				c.breaker.Release()
+				return ctx.Err()
+			}
+			c.breaker.Failure()
+			return fmt.Errorf("%w: %w", auth_client.ErrClientUnavailable, err)
+		}
+
+		status = resp.StatusCode
+		if status >= http.StatusInternalServerError || status == http.StatusTooManyRequests {
+			c.breaker.Failure()
+			return fmt.Errorf("%w: status %d", auth_client.ErrClientUnavailable, status)
+		}
+		c.breaker.Success()
+
+		return nil
+	})
+	if err != nil {
+		return 0, nil, err
+	}
+
+	return status, body, nil
+}
+
+// retryable reports whether call may succeed if repeated. Calls rejected by open breaker are not repeated.
+func retryable(err error) bool {
+	return errors.Is(err, auth_client.ErrClientUnavailable) && !errors.Is(err, circuitbreaker.ErrOpen)
+}
+
+// statusError maps non-OK status of auth service to typed error, fallback is used for 400/401/403
+func statusError(status int, fallback error) error {
+	switch status {
+	case http.StatusNotFound:
+		return auth_client.ErrUserNotFound
+	case http.StatusConflict:
+		return auth_client.ErrUserAlreadyExists
+	case http.StatusBadRequest, http.StatusUnauthorized, http.StatusForbidden:
+		return fallback
+	default:
+		return fmt.Errorf("%w: status %d", auth_client.ErrUnexpectedResponse, status)
+	}
+}
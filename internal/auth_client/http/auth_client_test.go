@@ -0,0 +1,197 @@
+package authclient
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"ChadProgress/internal/auth_client"
+	"ChadProgress/internal/lib/circuitbreaker"
+	"ChadProgress/internal/lib/retry"
+	"ChadProgress/internal/models"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var testOptions = Options{
+	Timeout: time.Second,
+	Retry:   retry.Policy{Attempts: 3, BaseDelay: time.Millisecond, MaxDelay: time.Millisecond},
+	Breaker: circuitbreaker.Settings{FailureThreshold: 5, OpenTimeout: time.Minute},
+}
+
+func newTestClient(t *testing.T, opts Options, handler http.HandlerFunc) (*AuthServiceClient, *atomic.Int32) {
+	t.Helper()
+
+	var calls atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		handler(w, r)
+	}))
+	t.Cleanup(srv.Close)
+
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+
+	return NewAuthClient(srv.URL, logger, opts), &calls
+}
+
+func TestStatusesAreMappedToTypedErrors(t *testing.T) {
+	creds := models.UserAuth{Login: "user@example.com", Password: "secret"}
+
+	tests := []struct {
+		name        string
+		status      int
+		body        string
+		call        func(c *AuthServiceClient) error
+		expectedErr error
+	}{
+		{
+			name:   "Login wrong password",
+			status: http.StatusUnauthorized,
+			call: func(c *AuthServiceClient) error {
+				_, err := c.LoginUser(context.Background(), creds)
+				return err
+			},
+			expectedErr: auth_client.ErrInvalidCredentials,
+		},
+		{
+			name:   "Login error in body",
+			status: http.StatusOK,
+			body:   `{"error":"wrong password"}`,
+			call: func(c *AuthServiceClient) error {
+				_, err := c.LoginUser(context.Background(), creds)
+				return err
+			},
+			expectedErr: auth_client.ErrInvalidCredentials,
+		},
+		{
+			name:   "Login unknown user",
+			status: http.StatusNotFound,
+			call: func(c *AuthServiceClient) error {
+				_, err := c.LoginUser(context.Background(), creds)
+				return err
+			},
+			expectedErr: auth_client.ErrUserNotFound,
+		},
+		{
+			name:   "Register existing user",
+			status: http.StatusConflict,
+			call: func(c *AuthServiceClient) error {
+				_, err := c.RegisterUser(context.Background(), creds)
+				return err
+			},
+			expectedErr: auth_client.ErrUserAlreadyExists,
+		},
+		{
+			name:   "Validate bad token",
+			status: http.StatusUnauthorized,
+			call: func(c *AuthServiceClient) error {
+				_, err := c.ValidateToken(context.Background(), "token")
+				return err
+			},
+			expectedErr: auth_client.ErrInvalidToken,
+		},
+		{
+			name:   "Service is down",
+			status: http.StatusServiceUnavailable,
+			call: func(c *AuthServiceClient) error {
+				_, err := c.ValidateToken(context.Background(), "token")
+				return err
+			},
+			expectedErr: auth_client.ErrClientUnavailable,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c, _ := newTestClient(t, testOptions, func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(tt.status)
+				_, _ = w.Write([]byte(tt.body))
+			})
+
+			assert.ErrorIs(t, tt.call(c), tt.expectedErr)
+		})
+	}
+}
+
+func TestIdempotentCallsAreRetried(t *testing.T) {
+	var failures atomic.Int32
+	failures.Store(2)
+
+	c, calls := newTestClient(t, testOptions, func(w http.ResponseWriter, r *http.Request) {
+		if failures.Add(-1) >= 0 {
+			w.WriteHeader(http.StatusBadGateway)
+			return
+		}
+		_, _ = w.Write([]byte(`{"status":"OK","user-login":"user@example.com"}`))
+	})
+
+	login, err := c.ValidateToken(context.Background(), "token")
+	require.NoError(t, err)
+	assert.Equal(t, "user@example.com", login)
+	assert.Equal(t, int32(3), calls.Load())
+}
+
+func TestRegisterIsNotRetried(t *testing.T) {
+	c, calls := newTestClient(t, testOptions, func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	})
+
+	_, err := c.RegisterUser(context.Background(), models.UserAuth{Login: "user@example.com", Password: "secret"})
+	assert.ErrorIs(t, err, auth_client.ErrClientUnavailable)
+	assert.Equal(t, int32(1), calls.Load())
+}
+
+func TestBreakerFailsFast(t *testing.T) {
+	opts := testOptions
+	opts.Retry = retry.Once
+	opts.Breaker = circuitbreaker.Settings{FailureThreshold: 2, OpenTimeout: time.Minute}
+
+	c, calls := newTestClient(t, opts, func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	})
+
+	for i := 0; i < 5; i++ {
+		_, err := c.ValidateToken(context.Background(), "token")
+		assert.ErrorIs(t, err, auth_client.ErrClientUnavailable)
+	}
+
+	assert.Equal(t, int32(2), calls.Load())
+}
+
+func TestCanceledCallsDoNotOpenBreaker(t *testing.T) {
+	opts := testOptions
+	opts.Breaker = circuitbreaker.Settings{FailureThreshold: 1, OpenTimeout: time.Minute}
+
+	c, calls := newTestClient(t, opts, func(w http.ResponseWriter, r *http.Request) {
+		<-r.Context().Done()
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	_, err := c.ValidateToken(ctx, "token")
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+	assert.NotErrorIs(t, err, auth_client.ErrClientUnavailable)
+
+	ctx, cancel = context.WithCancel(context.Background())
+	cancel()
+	_, err = c.ValidateToken(ctx, "token")
+	assert.ErrorIs(t, err, context.Canceled)
+
+	assert.Equal(t, circuitbreaker.StateClosed, c.breaker.State())
+	assert.Equal(t, int32(1), calls.Load(), "canceled call is not retried")
+}
+
+func TestUndocumentedOperationsAreNotSent(t *testing.T) {
+	c, calls := newTestClient(t, testOptions, func(w http.ResponseWriter, r *http.Request) {})
+
+	assert.ErrorIs(t, c.DeleteUser(context.Background(), "user@example.com"), auth_client.ErrNotSupported)
+	assert.ErrorIs(t, c.ChangeLogin(context.Background(), "user@example.com", "new@example.com"), auth_client.ErrNotSupported)
+	assert.False(t, c.ManagesCredentials())
+	assert.Zero(t, calls.Load())
+}
@@ -0,0 +1,397 @@
+package localauth
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"ChadProgress/internal/auth_client"
+	"ChadProgress/internal/models"
+	"ChadProgress/storage"
+
+	"github.com/golang-jwt/jwt/v5"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// MinKeyLength is the shortest accepted HMAC signing key
+const MinKeyLength = 32
+
+var ErrWeakSigningKey = fmt.Errorf("signing key must be at least %d bytes long", MinKeyLength)
+
+// Storage keeps credentials, refresh tokens and revoked access tokens
+type Storage interface {
+	SaveCredential(cred *models.Credential) error
+	GetCredential(login string) (*models.Credential, error)
+	DeleteCredential(login string) error
+	UpdateCredential(login, passwordHash string) error
+	ChangeCredentialLogin(login, newLogin string) error
+	SaveRefreshToken(token *models.RefreshToken) error
+	GetRefreshToken(hash string) (*models.RefreshToken, error)
+	RevokeRefreshToken(hash string) error
+	RevokeRefreshTokens(login string) error
+	SaveRevokedToken(token *models.RevokedToken) error
+	IsTokenRevoked(tokenID string) (bool, error)
+}
+
+// Options of Provider. Zero TTLs and cost fall back to defaults.
+type Options struct {
+	SigningKey []byte
+	Issuer     string
+	AccessTTL  time.Duration
+	RefreshTTL time.Duration
+	BcryptCost int
+}
+
+// Provider is built-in identity provider. It implements the same contract as HTTP client
+// of external auth service, so it can be used instead of it in small deployments.
+type Provider struct {
+	storage Storage
+	log     *slog.Logger
+	opts    Options
+	now     func() time.Time
+	// dummyHash is compared against when login is unknown, so that response time does not reveal it
+	dummyHash []byte
+}
+
+func New(storage Storage, log *slog.Logger, opts Options) (*Provider, error) {
+	const op = "auth_client.local.New"
+
+	if len(opts.SigningKey) < MinKeyLength {
+		return nil, fmt.Errorf("%s: %w", op, ErrWeakSigningKey)
+	}
+	if opts.AccessTTL <= 0 {
+		opts.AccessTTL = 15 * time.Minute
+	}
+	if opts.RefreshTTL <= 0 {
+		opts.RefreshTTL = 30 * 24 * time.Hour
+	}
+	if opts.BcryptCost == 0 {
+		opts.BcryptCost = bcrypt.DefaultCost
+	}
+
+	dummyHash, err := bcrypt.GenerateFromPassword([]byte("dummy password"), opts.BcryptCost)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return &Provider{
+		storage:   storage,
+		log:       log,
+		opts:      opts,
+		now:       time.Now,
+		dummyHash: dummyHash,
+	}, nil
+}
+
+func (p *Provider) RegisterUser(ctx context.Context, authReq auth_client.UserAuthRequestInterface) (*auth_client.UserRegistrationResponse, error) {
+	const op = "auth_client.local.RegisterUser"
+
+	hash, err := p.hashPassword(authReq.GetPassword())
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	err = p.storage.SaveCredential(&models.Credential{
+		Login:        authReq.GetLogin(),
+		PasswordHash: hash,
+	})
+	if err != nil {
+		if errors.Is(err, storage.ErrDuplicateKey) {
+			return nil, fmt.Errorf("%s: %w", op, auth_client.ErrUserAlreadyExists)
+		}
+
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	access, refresh, err := p.issue(authReq.GetLogin())
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return &auth_client.UserRegistrationResponse{Status: "OK", Token: access, RefreshToken: refresh}, nil
+}
+
+func (p *Provider) LoginUser(ctx context.Context, authReq auth_client.UserAuthRequestInterface) (*auth_client.UserLoginResponse, error) {
+	const op = "auth_client.local.LoginUser"
+
+	cred, err := p.storage.GetCredential(authReq.GetLogin())
+	if err != nil {
+		if errors.Is(err, storage.ErrRecordNotFound) {
+			_ = bcrypt.CompareHashAndPassword(p.dummyHash, []byte(authReq.GetPassword()))
+			return nil, fmt.Errorf("%s: %w", op, auth_client.ErrInvalidCredentials)
+		}
+
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	if err = bcrypt.CompareHashAndPassword([]byte(cred.PasswordHash), []byte(authReq.GetPassword())); err != nil {
+		return nil, fmt.Errorf("%s: %w", op, auth_client.ErrInvalidCredentials)
+	}
+
+	access, refresh, err := p.issue(cred.Login)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return &auth_client.UserLoginResponse{Token: access, RefreshToken: refresh}, nil
+}
+
+// ValidateToken checks signature, expiration and revocation of access token and returns its login
+func (p *Provider) ValidateToken(ctx context.Context, token string) (string, error) {
+	const op = "auth_client.local.ValidateToken"
+
+	claims, err := p.parse(token)
+	if err != nil {
+		return "", fmt.Errorf("%s: %w: %w", op, auth_client.ErrInvalidToken, err)
+	}
+
+	revoked, err := p.storage.IsTokenRevoked(claims.ID)
+	if err != nil {
+		// the token may be revoked, it is neither accepted nor rejected as invalid
+		return "", fmt.Errorf("%s: %w: %w", op, auth_client.ErrClientUnavailable, err)
+	}
+	if revoked {
+		return "", fmt.Errorf("%s: %w: token is revoked", op, auth_client.ErrInvalidToken)
+	}
+
+	return claims.Subject, nil
+}
+
+// Refresh exchanges refresh token for new pair of tokens. Refresh tokens are rotated: each one
+// can be used once, and reuse of already rotated token revokes all sessions of the user.
+func (p *Provider) Refresh(ctx context.Context, refreshToken string) (*auth_client.UserLoginResponse, error) {
+	const op = "auth_client.local.Refresh"
+
+	hash := hashToken(refreshToken)
+	stored, err := p.storage.GetRefreshToken(hash)
+	if err != nil {
+		if errors.Is(err, storage.ErrRecordNotFound) {
+			return nil, fmt.Errorf("%s: %w", op, auth_client.ErrInvalidToken)
+		}
+
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	if stored.RevokedAt != nil {
+		p.log.Warn("revoked refresh token reused, revoking all sessions", slog.String("op", op), slog.String("email", stored.Login))
+		if err = p.storage.RevokeRefreshTokens(stored.Login); err != nil {
+			return nil, fmt.Errorf("%s: %w", op, err)
+		}
+
+		return nil, fmt.Errorf("%s: %w", op, auth_client.ErrInvalidToken)
+	}
+	if !p.now().Before(stored.ExpiresAt) {
+		return nil, fmt.Errorf("%s: %w: refresh token expired", op, auth_client.ErrInvalidToken)
+	}
+
+	if err = p.storage.RevokeRefreshToken(hash); err != nil {
+		if errors.Is(err, storage.ErrRecordNotFound) {
+			// token was rotated concurrently
+			return nil, fmt.Errorf("%s: %w", op, auth_client.ErrInvalidToken)
+		}
+
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	// access token issued with the rotated refresh token stops working before it expires
+	if stored.AccessTokenID != "" {
+		err = p.storage.SaveRevokedToken(&models.RevokedToken{
+			TokenID:   stored.AccessTokenID,
+			ExpiresAt: p.now().Add(p.opts.AccessTTL),
+		})
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", op, err)
+		}
+	}
+
+	access, refresh, err := p.issue(stored.Login)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return &auth_client.UserLoginResponse{Token: access, RefreshToken: refresh}, nil
+}
+
+// Logout revokes access token until it expires and refresh token issued with it, if given
+func (p *Provider) Logout(ctx context.Context, accessToken, refreshToken string) error {
+	const op = "auth_client.local.Logout"
+
+	claims, err := p.parse(accessToken)
+	if err != nil {
+		return fmt.Errorf("%s: %w: %w", op, auth_client.ErrInvalidToken, err)
+	}
+
+	err = p.storage.SaveRevokedToken(&models.RevokedToken{
+		TokenID:   claims.ID,
+		ExpiresAt: claims.ExpiresAt.Time,
+	})
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	if refreshToken != "" {
+		if err = p.storage.RevokeRefreshToken(hashToken(refreshToken)); err != nil && !errors.Is(err, storage.ErrRecordNotFound) {
+			return fmt.Errorf("%s: %w", op, err)
+		}
+	}
+
+	return nil
+}
+
+// hashPassword hashes password with bcrypt, passwords bcrypt does not accept are invalid
+func (p *Provider) hashPassword(password string) (string, error) {
+	hash, err := bcrypt.GenerateFromPassword([]byte(password), p.opts.BcryptCost)
+	if errors.Is(err, bcrypt.ErrPasswordTooLong) {
+		return "", fmt.Errorf("%w: %w", auth_client.ErrInvalidPassword, err)
+	}
+	if err != nil {
+		return "", err
+	}
+
+	return string(hash), nil
+}
+
+// SetPassword replaces password of login and revokes its refresh tokens
+func (p *Provider) SetPassword(ctx context.Context, login, password string) error {
+	const op = "auth_client.local.SetPassword"
+
+	hash, err := p.hashPassword(password)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	if err = p.storage.UpdateCredential(login, hash); err != nil {
+		if errors.Is(err, storage.ErrRecordNotFound) {
+			return fmt.Errorf("%s: %w", op, auth_client.ErrUserNotFound)
+		}
+
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	if err = p.storage.RevokeRefreshTokens(login); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	return nil
+}
+
+// ChangeLogin renames credentials and revokes refresh tokens issued for the old login
+func (p *Provider) ChangeLogin(ctx context.Context, login, newLogin string) error {
+	const op = "auth_client.local.ChangeLogin"
+
+	if err := p.storage.ChangeCredentialLogin(login, newLogin); err != nil {
+		if errors.Is(err, storage.ErrRecordNotFound) {
+			return fmt.Errorf("%s: %w", op, auth_client.ErrUserNotFound)
+		}
+		if errors.Is(err, storage.ErrDuplicateKey) {
+			return fmt.Errorf("%s: %w", op, auth_client.ErrUserAlreadyExists)
+		}
+
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	if err := p.storage.RevokeRefreshTokens(login); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	return nil
+}
+
+// DeleteUser removes credentials and revokes all refresh tokens of login
+func (p *Provider) DeleteUser(ctx context.Context, login string) error {
+	const op = "auth_client.local.DeleteUser"
+
+	if err := p.storage.DeleteCredential(login); err != nil {
+		if errors.Is(err, storage.ErrRecordNotFound) {
+			return fmt.Errorf("%s: %w", op, auth_client.ErrUserNotFound)
+		}
+
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	if err := p.storage.RevokeRefreshTokens(login); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	return nil
+}
+
+// issue creates signed access token and stores new refresh token
+func (p *Provider) issue(login string) (string, string, error) {
+	now := p.now()
+
+	tokenID, err := randomToken(16)
+	if err != nil {
+		return "", "", err
+	}
+
+	access, err := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.RegisteredClaims{
+		ID:        tokenID,
+		Issuer:    p.opts.Issuer,
+		Subject:   login,
+		IssuedAt:  jwt.NewNumericDate(now),
+		ExpiresAt: jwt.NewNumericDate(now.Add(p.opts.AccessTTL)),
+	}).SignedString(p.opts.SigningKey)
+	if err != nil {
+		return "", "", err
+	}
+
+	refresh, err := randomToken(32)
+	if err != nil {
+		return "", "", err
+	}
+
+	err = p.storage.SaveRefreshToken(&models.RefreshToken{
+		Login:         login,
+		TokenHash:     hashToken(refresh),
+		AccessTokenID: tokenID,
+		ExpiresAt:     now.Add(p.opts.RefreshTTL),
+	})
+	if err != nil {
+		return "", "", err
+	}
+
+	return access, refresh, nil
+}
+
+func (p *Provider) parse(token string) (*jwt.RegisteredClaims, error) {
+	var claims jwt.RegisteredClaims
+	_, err := jwt.ParseWithClaims(
+		token,
+		&claims,
+		func(*jwt.Token) (any, error) { return p.opts.SigningKey, nil },
+		jwt.WithValidMethods([]string{jwt.SigningMethodHS256.Alg()}),
+		jwt.WithIssuer(p.opts.Issuer),
+		jwt.WithExpirationRequired(),
+		jwt.WithTimeFunc(p.now),
+	)
+	if err != nil {
+		return nil, err
+	}
+	if claims.Subject == "" || claims.ID == "" {
+		return nil, errors.New("token has no subject or id")
+	}
+
+	return &claims, nil
+}
+
+func randomToken(n int) (string, error) {
+	b := make([]byte, n)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+func hashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+
+	return hex.EncodeToString(sum[:])
+}
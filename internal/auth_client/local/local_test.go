@@ -0,0 +1,324 @@
+package localauth
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"strings"
+	"testing"
+	"time"
+
+	"ChadProgress/internal/auth_client"
+	"ChadProgress/internal/models"
+	"ChadProgress/storage"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/bcrypt"
+)
+
+type memoryStorage struct {
+	creds   map[string]*models.Credential
+	refresh map[string]*models.RefreshToken
+	revoked map[string]bool
+	// err is returned by IsTokenRevoked
+	err error
+}
+
+func newMemoryStorage() *memoryStorage {
+	return &memoryStorage{
+		creds:   make(map[string]*models.Credential),
+		refresh: make(map[string]*models.RefreshToken),
+		revoked: make(map[string]bool),
+	}
+}
+
+func (m *memoryStorage) SaveCredential(cred *models.Credential) error {
+	if _, ok := m.creds[cred.Login]; ok {
+		return storage.ErrDuplicateKey
+	}
+	m.creds[cred.Login] = cred
+
+	return nil
+}
+
+func (m *memoryStorage) GetCredential(login string) (*models.Credential, error) {
+	cred, ok := m.creds[login]
+	if !ok {
+		return nil, storage.ErrRecordNotFound
+	}
+
+	return cred, nil
+}
+
+func (m *memoryStorage) DeleteCredential(login string) error {
+	if _, ok := m.creds[login]; !ok {
+		return storage.ErrRecordNotFound
+	}
+	delete(m.creds, login)
+
+	return nil
+}
+
+func (m *memoryStorage) UpdateCredential(login, passwordHash string) error {
+	cred, ok := m.creds[login]
+	if !ok {
+		return storage.ErrRecordNotFound
+	}
+	cred.PasswordHash = passwordHash
+
+	return nil
+}
+
+func (m *memoryStorage) ChangeCredentialLogin(login, newLogin string) error {
+	cred, ok := m.creds[login]
+	if !ok {
+		return storage.ErrRecordNotFound
+	}
+	if _, ok = m.creds[newLogin]; ok {
+		return storage.ErrDuplicateKey
+	}
+	delete(m.creds, login)
+	cred.Login = newLogin
+	m.creds[newLogin] = cred
+
+	return nil
+}
+
+func (m *memoryStorage) SaveRefreshToken(token *models.RefreshToken) error {
+	m.refresh[token.TokenHash] = token
+	return nil
+}
+
+func (m *memoryStorage) GetRefreshToken(hash string) (*models.RefreshToken, error) {
+	token, ok := m.refresh[hash]
+	if !ok {
+		return nil, storage.ErrRecordNotFound
+	}
+	copied := *token
+
+	return &copied, nil
+}
+
+func (m *memoryStorage) RevokeRefreshToken(hash string) error {
+	token, ok := m.refresh[hash]
+	if !ok || token.RevokedAt != nil {
+		return storage.ErrRecordNotFound
+	}
+	now := time.Now()
+	token.RevokedAt = &now
+
+	return nil
+}
+
+func (m *memoryStorage) RevokeRefreshTokens(login string) error {
+	now := time.Now()
+	for _, token := range m.refresh {
+		if token.Login == login && token.RevokedAt == nil {
+			token.RevokedAt = &now
+		}
+	}
+
+	return nil
+}
+
+func (m *memoryStorage) SaveRevokedToken(token *models.RevokedToken) error {
+	m.revoked[token.TokenID] = true
+	return nil
+}
+
+func (m *memoryStorage) IsTokenRevoked(tokenID string) (bool, error) {
+	if m.err != nil {
+		return false, m.err
+	}
+
+	return m.revoked[tokenID], nil
+}
+
+func newTestProvider(t *testing.T) (*Provider, *memoryStorage) {
+	t.Helper()
+
+	st := newMemoryStorage()
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	p, err := New(st, logger, Options{
+		SigningKey: []byte(strings.Repeat("k", MinKeyLength)),
+		Issuer:     "test",
+		BcryptCost: bcrypt.MinCost,
+	})
+	require.NoError(t, err)
+
+	return p, st
+}
+
+var creds = models.UserAuth{Login: "user@example.com", Password: "secret"}
+
+func TestNewRejectsWeakKey(t *testing.T) {
+	_, err := New(newMemoryStorage(), slog.Default(), Options{SigningKey: []byte("short")})
+	assert.ErrorIs(t, err, ErrWeakSigningKey)
+}
+
+func TestRegisterLoginValidate(t *testing.T) {
+	ctx := context.Background()
+	p, st := newTestProvider(t)
+
+	reg, err := p.RegisterUser(ctx, creds)
+	require.NoError(t, err)
+	assert.NotEqual(t, creds.Password, st.creds[creds.Login].PasswordHash)
+
+	login, err := p.ValidateToken(ctx, reg.Token)
+	require.NoError(t, err)
+	assert.Equal(t, creds.Login, login)
+
+	_, err = p.RegisterUser(ctx, creds)
+	assert.ErrorIs(t, err, auth_client.ErrUserAlreadyExists)
+
+	_, err = p.LoginUser(ctx, models.UserAuth{Login: creds.Login, Password: "wrong"})
+	assert.ErrorIs(t, err, auth_client.ErrInvalidCredentials)
+	_, err = p.LoginUser(ctx, models.UserAuth{Login: "unknown@example.com", Password: "secret"})
+	assert.ErrorIs(t, err, auth_client.ErrInvalidCredentials)
+
+	resp, err := p.LoginUser(ctx, creds)
+	require.NoError(t, err)
+	assert.NotEmpty(t, resp.RefreshToken)
+}
+
+func TestValidateTokenRejectsForgedAndExpired(t *testing.T) {
+	ctx := context.Background()
+	p, _ := newTestProvider(t)
+
+	resp, err := p.LoginUser(ctx, creds)
+	require.ErrorIs(t, err, auth_client.ErrInvalidCredentials)
+	assert.Nil(t, resp)
+
+	reg, err := p.RegisterUser(ctx, creds)
+	require.NoError(t, err)
+
+	other, _ := newTestProvider(t)
+	other.opts.SigningKey = []byte(strings.Repeat("x", MinKeyLength))
+	_, err = other.ValidateToken(ctx, reg.Token)
+	assert.ErrorIs(t, err, auth_client.ErrInvalidToken)
+
+	p.now = func() time.Time { return time.Now().Add(time.Hour) }
+	_, err = p.ValidateToken(ctx, reg.Token)
+	assert.ErrorIs(t, err, auth_client.ErrInvalidToken)
+}
+
+func TestValidateTokenStorageFailure(t *testing.T) {
+	ctx := context.Background()
+	p, st := newTestProvider(t)
+
+	reg, err := p.RegisterUser(ctx, creds)
+	require.NoError(t, err)
+
+	st.err = errors.New("connection refused")
+	_, err = p.ValidateToken(ctx, reg.Token)
+	assert.ErrorIs(t, err, auth_client.ErrClientUnavailable)
+	assert.NotErrorIs(t, err, auth_client.ErrInvalidToken)
+}
+
+func TestRefreshRotation(t *testing.T) {
+	ctx := context.Background()
+	p, st := newTestProvider(t)
+
+	reg, err := p.RegisterUser(ctx, creds)
+	require.NoError(t, err)
+
+	refreshed, err := p.Refresh(ctx, reg.RefreshToken)
+	require.NoError(t, err)
+	assert.NotEqual(t, reg.RefreshToken, refreshed.RefreshToken)
+	_, err = p.ValidateToken(ctx, reg.Token)
+	assert.ErrorIs(t, err, auth_client.ErrInvalidToken, "access token of rotated refresh token is revoked")
+	_, err = p.ValidateToken(ctx, refreshed.Token)
+	assert.NoError(t, err)
+
+	// reuse of rotated token revokes every session
+	_, err = p.Refresh(ctx, reg.RefreshToken)
+	assert.ErrorIs(t, err, auth_client.ErrInvalidToken)
+	_, err = p.Refresh(ctx, refreshed.RefreshToken)
+	assert.ErrorIs(t, err, auth_client.ErrInvalidToken)
+
+	for _, token := range st.refresh {
+		assert.NotNil(t, token.RevokedAt)
+	}
+}
+
+func TestLogout(t *testing.T) {
+	ctx := context.Background()
+	p, _ := newTestProvider(t)
+
+	reg, err := p.RegisterUser(ctx, creds)
+	require.NoError(t, err)
+
+	require.NoError(t, p.Logout(ctx, reg.Token, reg.RefreshToken))
+
+	_, err = p.ValidateToken(ctx, reg.Token)
+	assert.ErrorIs(t, err, auth_client.ErrInvalidToken)
+	_, err = p.Refresh(ctx, reg.RefreshToken)
+	assert.ErrorIs(t, err, auth_client.ErrInvalidToken)
+}
+
+func TestSetPassword(t *testing.T) {
+	ctx := context.Background()
+	p, _ := newTestProvider(t)
+
+	reg, err := p.RegisterUser(ctx, creds)
+	require.NoError(t, err)
+
+	require.NoError(t, p.SetPassword(ctx, creds.Login, "new-secret"))
+	assert.ErrorIs(t, p.SetPassword(ctx, "unknown@example.com", "new-secret"), auth_client.ErrUserNotFound)
+
+	_, err = p.LoginUser(ctx, creds)
+	assert.ErrorIs(t, err, auth_client.ErrInvalidCredentials)
+	_, err = p.LoginUser(ctx, models.UserAuth{Login: creds.Login, Password: "new-secret"})
+	assert.NoError(t, err)
+	_, err = p.Refresh(ctx, reg.RefreshToken)
+	assert.ErrorIs(t, err, auth_client.ErrInvalidToken)
+}
+
+func TestPasswordTooLongForBcrypt(t *testing.T) {
+	ctx := context.Background()
+	p, _ := newTestProvider(t)
+	// 42 two-byte runes pass validation of at most 72 characters, but are longer than 72 bytes bcrypt hashes
+	long := strings.Repeat("пароль", 7)
+
+	_, err := p.RegisterUser(ctx, models.UserAuth{Login: creds.Login, Password: long})
+	assert.ErrorIs(t, err, auth_client.ErrInvalidPassword)
+
+	_, err = p.RegisterUser(ctx, creds)
+	require.NoError(t, err)
+	assert.ErrorIs(t, p.SetPassword(ctx, creds.Login, long), auth_client.ErrInvalidPassword)
+}
+
+func TestChangeLogin(t *testing.T) {
+	ctx := context.Background()
+	p, _ := newTestProvider(t)
+
+	reg, err := p.RegisterUser(ctx, creds)
+	require.NoError(t, err)
+	_, err = p.RegisterUser(ctx, models.UserAuth{Login: "taken@example.com", Password: "secret"})
+	require.NoError(t, err)
+
+	assert.ErrorIs(t, p.ChangeLogin(ctx, creds.Login, "taken@example.com"), auth_client.ErrUserAlreadyExists)
+	require.NoError(t, p.ChangeLogin(ctx, creds.Login, "new@example.com"))
+	assert.ErrorIs(t, p.ChangeLogin(ctx, creds.Login, "other@example.com"), auth_client.ErrUserNotFound)
+
+	_, err = p.LoginUser(ctx, models.UserAuth{Login: "new@example.com", Password: creds.Password})
+	assert.NoError(t, err)
+	_, err = p.Refresh(ctx, reg.RefreshToken)
+	assert.ErrorIs(t, err, auth_client.ErrInvalidToken)
+}
+
+func TestDeleteUser(t *testing.T) {
+	ctx := context.Background()
+	p, _ := newTestProvider(t)
+
+	_, err := p.RegisterUser(ctx, creds)
+	require.NoError(t, err)
+
+	require.NoError(t, p.DeleteUser(ctx, creds.Login))
+	assert.ErrorIs(t, p.DeleteUser(ctx, creds.Login), auth_client.ErrUserNotFound)
+
+	_, err = p.LoginUser(ctx, creds)
+	assert.ErrorIs(t, err, auth_client.ErrInvalidCredentials)
+}
@@ -3,7 +3,10 @@ package config
 import (
 	"flag"
 	"fmt"
+	"net"
+	"net/url"
 	"os"
+	"strconv"
 	"time"
 
 	"github.com/ilyakaznacheev/cleanenv"
@@ -12,9 +15,24 @@ import (
 
 type Config struct {
 	Env        string            `yaml:"env" env-default:"local"`
+	Log        Log               `yaml:"log"`
 	HTTPServer HTTPServer        `yaml:"http_server"`
 	DB         DataBase          `yaml:"db"`
 	AuthClient AuthServiceClient `yaml:"auth_client"`
+	RateLimit  RateLimit         `yaml:"rate_limit"`
+	Mailer     Mailer            `yaml:"mailer"`
+	// AccountDeletion is retention policy applied to data of deleted accounts, see models.RetentionPolicy
+	AccountDeletion AccountDeletion `yaml:"account_deletion"`
+	DataExport      DataExport      `yaml:"data_export"`
+	// MetricPartitions configures the job creating monthly partitions of metrics in advance
+	MetricPartitions MetricPartitions `yaml:"metric_partitions"`
+	// PendingUserTTL is how long registration may stay unfinished before reconcile-users removes it
+	PendingUserTTL time.Duration `yaml:"pending_user_ttl" env-default:"15m"`
+}
+
+type Log struct {
+	// RedactPolicy is either "strict" (mask secrets and emails) or "relaxed" (mask secrets only)
+	RedactPolicy string `yaml:"redact_policy" env-default:"strict"`
 }
 
 type HTTPServer struct {
@@ -22,6 +40,9 @@ type HTTPServer struct {
 	Port        string        `yaml:"port" env-default:"8080"`
 	Timeout     time.Duration `yaml:"timeout" env-default:"4s"`
 	IdleTimeout time.Duration `yaml:"idle_timeout" env-default:"60s"`
+	// TrustProxyHeaders takes client address from True-Client-IP, X-Real-IP and X-Forwarded-For headers.
+	// Enable it only behind proxy which sets them, otherwise clients can pick any address.
+	TrustProxyHeaders bool `yaml:"trust_proxy_headers" env-default:"false"`
 }
 
 type DataBase struct {
@@ -31,10 +52,148 @@ type DataBase struct {
 	DBName     string `yaml:"dbname" env-default:"my-db"`
 	DBPassword string
 	SSLMode    string `yaml:"sslmode" env-default:"disable"`
+	// Replicas are "host:port" of read replicas serving list queries, they use credentials of primary
+	Replicas        []string      `yaml:"replicas"`
+	MaxOpenConns    int           `yaml:"max_open_conns" env-default:"20"`
+	MaxIdleConns    int           `yaml:"max_idle_conns" env-default:"10"`
+	ConnMaxLifetime time.Duration `yaml:"conn_max_lifetime" env-default:"30m"`
+	ConnMaxIdleTime time.Duration `yaml:"conn_max_idle_time" env-default:"5m"`
+	// StatementTimeout makes database cancel longer queries, zero disables it
+	StatementTimeout time.Duration `yaml:"statement_timeout" env-default:"10s"`
+	// SlowQueryThreshold is duration above which queries are logged, zero disables it
+	SlowQueryThreshold time.Duration `yaml:"slow_query_threshold" env-default:"200ms"`
+}
+
+// DSN returns connection string of primary database
+func (d DataBase) DSN() string {
+	return d.dsn(net.JoinHostPort(d.Host, d.Port))
+}
+
+// ReplicaDSNs returns connection strings of read replicas
+func (d DataBase) ReplicaDSNs() []string {
+	dsns := make([]string, 0, len(d.Replicas))
+	for _, replica := range d.Replicas {
+		dsns = append(dsns, d.dsn(replica))
+	}
+
+	return dsns
+}
+
+func (d DataBase) dsn(host string) string {
+	query := url.Values{}
+	query.Set("sslmode", d.SSLMode)
+	if d.StatementTimeout > 0 {
+		// unknown parameters are sent to postgres as session settings, value is in milliseconds
+		query.Set("statement_timeout", strconv.FormatInt(d.StatementTimeout.Milliseconds(), 10))
+	}
+
+	u := url.URL{
+		Scheme:   "postgres",
+		User:     url.UserPassword(d.Username, d.DBPassword),
+		Host:     host,
+		Path:     "/" + d.DBName,
+		RawQuery: query.Encode(),
+	}
+
+	return u.String()
 }
 
 type AuthServiceClient struct {
-	BaseURL string `yaml:"baseurl"`
+	// Provider is "http" for external auth service or "local" for built-in one
+	Provider string        `yaml:"provider" env-default:"http"`
+	BaseURL  string        `yaml:"baseurl"`
+	Timeout  time.Duration `yaml:"timeout" env-default:"5s"`
+	// Retries is the number of attempts for idempotent calls (login, token validation)
+	Retries     int           `yaml:"retries" env-default:"3"`
+	BackoffBase time.Duration `yaml:"backoff_base" env-default:"100ms"`
+	BackoffMax  time.Duration `yaml:"backoff_max" env-default:"1s"`
+	// Breaker opens after BreakerFailures consecutive failures and stays open for BreakerOpenTimeout
+	BreakerFailures    int           `yaml:"breaker_failures" env-default:"5"`
+	BreakerOpenTimeout time.Duration `yaml:"breaker_open_timeout" env-default:"30s"`
+	Local              LocalAuth     `yaml:"local"`
+}
+
+// LocalAuth configures built-in identity provider, SigningKey is read from AUTH_SIGNING_KEY
+type LocalAuth struct {
+	Issuer     string        `yaml:"issuer" env-default:"chadprogress"`
+	AccessTTL  time.Duration `yaml:"access_ttl" env-default:"15m"`
+	RefreshTTL time.Duration `yaml:"refresh_ttl" env-default:"720h"`
+	BcryptCost int           `yaml:"bcrypt_cost" env-default:"10"`
+	SigningKey string
+}
+
+// AccountDeletion values are "erase" or "anonymize", records are kept only with anonymized profile
+type AccountDeletion struct {
+	Profile       string `yaml:"profile" env-default:"anonymize"`
+	Metrics       string `yaml:"metrics" env-default:"erase"`
+	SharedRecords string `yaml:"shared_records" env-default:"anonymize"`
+}
+
+// DataExport configures personal data archives. Accounts with more than SyncMaxRecords rows are
+// exported in background into Dir and can be downloaded for TTL.
+type DataExport struct {
+	Dir             string        `yaml:"dir" env-default:"./exports"`
+	TTL             time.Duration `yaml:"ttl" env-default:"72h"`
+	SyncMaxRecords  int           `yaml:"sync_max_records" env-default:"2000"`
+	Workers         int           `yaml:"workers" env-default:"2"`
+	QueueSize       int           `yaml:"queue_size" env-default:"32"`
+	CleanupInterval time.Duration `yaml:"cleanup_interval" env-default:"1h"`
+}
+
+// MetricPartitions makes partitions of the current month and MonthsAhead next ones every Interval
+type MetricPartitions struct {
+	Interval    time.Duration `yaml:"interval" env-default:"24h"`
+	MonthsAhead int           `yaml:"months_ahead" env-default:"2"`
+}
+
+// Mailer configures delivery of account emails, SMTP password is read from SMTP_PASSWORD
+type Mailer struct {
+	// Backend is "smtp", "file" (writes .eml files to Dir) or "log"
+	Backend string `yaml:"backend" env-default:"log"`
+	From    string `yaml:"from" env-default:"noreply@chadprogress.local"`
+	Dir     string `yaml:"dir" env-default:"./mail"`
+	// LinkBaseURL is frontend address used in links of emails
+	LinkBaseURL string `yaml:"link_base_url" env-default:"http://localhost:3000"`
+	SMTP        struct {
+		Host     string        `yaml:"host"`
+		Port     string        `yaml:"port" env-default:"587"`
+		Username string        `yaml:"username"`
+		Timeout  time.Duration `yaml:"timeout" env-default:"10s"`
+		Password string
+	} `yaml:"smtp"`
+}
+
+type RateLimit struct {
+	// Backend is either "memory" or "redis"
+	Backend       string `yaml:"backend" env-default:"memory"`
+	RedisAddr     string `yaml:"redis_addr"`
+	RedisPassword string
+	// Auth limits /authorization/login and /authorization/register separately per client ip and per email
+	Auth struct {
+		PerIP    Limit `yaml:"per_ip"`
+		PerEmail Limit `yaml:"per_email"`
+	} `yaml:"auth"`
+	// User limits /user endpoints per user, Routes are keyed by "METHOD /pattern" relative to /user, such as "GET /reports/clients/{id}/summary"
+	User struct {
+		Default Limit            `yaml:"default"`
+		Routes  map[string]Limit `yaml:"routes"`
+	} `yaml:"user"`
+	Lockout Lockout `yaml:"lockout"`
+}
+
+// Limit allows Requests per Per with bursts up to Burst. Zero Requests disables limit.
+type Limit struct {
+	Requests int           `yaml:"requests"`
+	Per      time.Duration `yaml:"per"`
+	Burst    int           `yaml:"burst"`
+}
+
+// Lockout locks email after MaxFailures failed logins within Window for Base, doubling up to Max
+type Lockout struct {
+	MaxFailures int           `yaml:"max_failures" env-default:"5"`
+	Window      time.Duration `yaml:"window" env-default:"15m"`
+	Base        time.Duration `yaml:"base" env-default:"1m"`
+	Max         time.Duration `yaml:"max" env-default:"1h"`
 }
 
 func MustLoad() *Config {
@@ -56,6 +215,9 @@ func MustLoad() *Config {
 		panic("could not read env files: " + err.Error())
 	}
 	cfg.DB.DBPassword = os.Getenv("DB_PASSWORD")
+	cfg.RateLimit.RedisPassword = os.Getenv("REDIS_PASSWORD")
+	cfg.AuthClient.Local.SigningKey = os.Getenv("AUTH_SIGNING_KEY")
+	cfg.Mailer.SMTP.Password = os.Getenv("SMTP_PASSWORD")
 
 	return &cfg
 }
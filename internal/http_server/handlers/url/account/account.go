@@ -0,0 +1,143 @@
+package accounthandler
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+
+	"ChadProgress/internal/lib/api/request"
+	"ChadProgress/internal/lib/api/response"
+	"ChadProgress/internal/lib/apperr"
+	"ChadProgress/internal/lib/logger/redact"
+	"ChadProgress/internal/models"
+
+	"github.com/go-chi/render"
+)
+
+//go:generate mockgen -source=account.go -destination=./account_mock.go -package=accounthandler
+type AccountService interface {
+	ResendVerification(ctx context.Context, email string) error
+	RequestEmailChange(ctx context.Context, email, password, newEmail string) error
+	DeleteAccount(ctx context.Context, email, password string) (*models.DeletionReceipt, error)
+}
+
+type ChangeEmailRequest struct {
+	NewEmail string `json:"new_email" validate:"required,email"`
+	// Password is current password, passwords set before the length policy may be shorter than 8
+	Password string `json:"password" validate:"required,max=72"`
+}
+
+// LogValue implements slog.LogValuer so that password never reaches logs
+func (r ChangeEmailRequest) LogValue() slog.Value {
+	return slog.GroupValue(
+		slog.String("new_email", r.NewEmail),
+		slog.String("password", redact.Secret(r.Password)),
+	)
+}
+
+// DeleteAccountRequest confirms deletion with password
+type DeleteAccountRequest struct {
+	Password string `json:"password" validate:"required,max=72"`
+}
+
+// LogValue implements slog.LogValuer so that password never reaches logs
+func (r DeleteAccountRequest) LogValue() slog.Value {
+	return slog.GroupValue(slog.String("password", redact.Secret(r.Password)))
+}
+
+type DeleteAccountResponse struct {
+	Status  string                 `json:"status"`
+	Receipt models.DeletionReceipt `json:"receipt"`
+}
+
+type AccountHandler struct {
+	log            *slog.Logger
+	accountService AccountService
+}
+
+func NewAccountHandler(log *slog.Logger, accountService AccountService) *AccountHandler {
+	return &AccountHandler{log: log, accountService: accountService}
+}
+
+// ResendVerification sends new email verification link to current user
+func (a *AccountHandler) ResendVerification(w http.ResponseWriter, r *http.Request) {
+	const op = "handlers.url.account.ResendVerification"
+	log := a.log.With(
+		slog.String("op", op),
+	)
+	userEmail, _ := r.Context().Value(models.ContextUserKey).(string)
+	if userEmail == "" {
+		log.Error("empty email from context")
+		response.RenderError(w, r, apperr.ErrUnauthorized)
+
+		return
+	}
+
+	if err := a.accountService.ResendVerification(r.Context(), userEmail); err != nil {
+		log.Error("failed to resend verification email", slog.String("error", err.Error()))
+		response.RenderError(w, r, err)
+
+		return
+	}
+
+	render.JSON(w, r, response.OK())
+}
+
+// ChangeEmail sends confirmation link to the new email of current user
+func (a *AccountHandler) ChangeEmail(w http.ResponseWriter, r *http.Request) {
+	const op = "handlers.url.account.ChangeEmail"
+	log := a.log.With(
+		slog.String("op", op),
+	)
+	userEmail, _ := r.Context().Value(models.ContextUserKey).(string)
+	if userEmail == "" {
+		log.Error("empty email from context")
+		response.RenderError(w, r, apperr.ErrUnauthorized)
+
+		return
+	}
+
+	var req ChangeEmailRequest
+	if !request.Decode(w, r, log, &req) {
+		return
+	}
+
+	if err := a.accountService.RequestEmailChange(r.Context(), userEmail, req.Password, req.NewEmail); err != nil {
+		log.Error("failed to request email change", slog.String("error", err.Error()))
+		response.RenderError(w, r, err)
+
+		return
+	}
+
+	render.JSON(w, r, response.OK())
+}
+
+// DeleteAccount erases data of current user and returns deletion receipt
+func (a *AccountHandler) DeleteAccount(w http.ResponseWriter, r *http.Request) {
+	const op = "handlers.url.account.DeleteAccount"
+	log := a.log.With(
+		slog.String("op", op),
+	)
+	userEmail, _ := r.Context().Value(models.ContextUserKey).(string)
+	if userEmail == "" {
+		log.Error("empty email from context")
+		response.RenderError(w, r, apperr.ErrUnauthorized)
+
+		return
+	}
+
+	var req DeleteAccountRequest
+	if !request.Decode(w, r, log, &req) {
+		return
+	}
+
+	receipt, err := a.accountService.DeleteAccount(r.Context(), userEmail, req.Password)
+	if err != nil {
+		log.Error("failed to delete account", slog.String("error", err.Error()))
+		response.RenderError(w, r, err)
+
+		return
+	}
+
+	render.JSON(w, r, DeleteAccountResponse{Status: "OK", Receipt: *receipt})
+}
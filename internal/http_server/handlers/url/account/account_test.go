@@ -0,0 +1,126 @@
+package accounthandler
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"ChadProgress/internal/models"
+	service "ChadProgress/internal/services"
+
+	gomock "github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+)
+
+const email = "user@example.com"
+
+func newRequest(method, target, body string) *http.Request {
+	req := httptest.NewRequest(method, target, strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+
+	return req.WithContext(context.WithValue(req.Context(), models.ContextUserKey, email))
+}
+
+func TestResendVerification(t *testing.T) {
+	tests := []struct {
+		name         string
+		mockError    error
+		expectedCode int
+	}{
+		{
+			name:         "Success",
+			expectedCode: http.StatusOK,
+		},
+		{
+			name:         "Mailer failed",
+			mockError:    errors.New("smtp is down"),
+			expectedCode: http.StatusInternalServerError,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			mockService := NewMockAccountService(ctrl)
+			mockService.EXPECT().ResendVerification(gomock.Any(), email).Return(tt.mockError)
+
+			handler := NewAccountHandler(slog.New(slog.NewTextHandler(io.Discard, nil)), mockService)
+			rr := httptest.NewRecorder()
+			handler.ResendVerification(rr, newRequest(http.MethodPost, "/user/email/verification", ""))
+
+			assert.Equal(t, tt.expectedCode, rr.Code)
+		})
+	}
+}
+
+func TestChangeEmail(t *testing.T) {
+	tests := []struct {
+		name         string
+		requestBody  string
+		callService  bool
+		mockError    error
+		expectedCode int
+	}{
+		{
+			name:         "Success",
+			requestBody:  `{"new_email": "new@example.com", "password": "secret"}`,
+			callService:  true,
+			expectedCode: http.StatusOK,
+		},
+		{
+			name:         "Invalid email",
+			requestBody:  `{"new_email": "new", "password": "secret"}`,
+			expectedCode: http.StatusBadRequest,
+		},
+		{
+			name:         "Wrong password",
+			requestBody:  `{"new_email": "new@example.com", "password": "secret"}`,
+			callService:  true,
+			mockError:    service.ErrInvalidCredentials,
+			expectedCode: http.StatusUnauthorized,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			mockService := NewMockAccountService(ctrl)
+			if tt.callService {
+				mockService.EXPECT().RequestEmailChange(gomock.Any(), email, "secret", "new@example.com").Return(tt.mockError)
+			}
+
+			handler := NewAccountHandler(slog.New(slog.NewTextHandler(io.Discard, nil)), mockService)
+			rr := httptest.NewRecorder()
+			handler.ChangeEmail(rr, newRequest(http.MethodPost, "/user/email", tt.requestBody))
+
+			assert.Equal(t, tt.expectedCode, rr.Code)
+		})
+	}
+}
+
+func TestDeleteAccount(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	mockService := NewMockAccountService(ctrl)
+	mockService.EXPECT().DeleteAccount(gomock.Any(), email, "secret").Return(&models.DeletionReceipt{
+		ID:                 "receipt",
+		EmailHash:          "hash",
+		Erased:             map[string]int64{"metrics": 3},
+		Anonymized:         map[string]int64{"user": 1},
+		CredentialsRemoved: true,
+		DeletedAt:          time.Date(2026, time.October, 19, 12, 0, 0, 0, time.UTC),
+	}, nil)
+
+	handler := NewAccountHandler(slog.New(slog.NewTextHandler(io.Discard, nil)), mockService)
+	rr := httptest.NewRecorder()
+	handler.DeleteAccount(rr, newRequest(http.MethodDelete, "/user/account", `{"password": "secret"}`))
+
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.Contains(t, rr.Body.String(), `"id":"receipt","email_sha256":"hash"`)
+	assert.Contains(t, rr.Body.String(), `"erased":{"metrics":3},"anonymized":{"user":1},"credentials_removed":true`)
+}
@@ -0,0 +1,408 @@
+package adminhandler
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"time"
+
+	"ChadProgress/internal/lib/api/request"
+	"ChadProgress/internal/lib/api/response"
+	"ChadProgress/internal/lib/apperr"
+	"ChadProgress/internal/models"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/render"
+)
+
+//go:generate mockgen -source=admin.go -destination=./admin_mock.go -package=adminhandler
+type AdminService interface {
+	SearchUsers(ctx context.Context, filter models.UserFilter) ([]models.User, int64, error)
+	GetUser(ctx context.Context, id uint) (*models.UserDetails, error)
+	SetUserStatus(ctx context.Context, actor string, id uint, status string) error
+	SetTrainerStatus(ctx context.Context, actor string, trainerID uint, status string) error
+	ReassignClient(ctx context.Context, actor string, clientID, trainerID uint) error
+	AuditHistory(ctx context.Context, filter models.AuditFilter) ([]models.AuditEvent, error)
+	SaveMeasurementType(ctx context.Context, actor string, t models.MeasurementType) error
+}
+
+type SetUserStatusRequest struct {
+	Status string `json:"status" validate:"required,oneof=active disabled"`
+}
+
+type SetTrainerStatusRequest struct {
+	Status string `json:"status" validate:"required,oneof=ACTIVE BUSY ON_VACATION"`
+}
+
+type SaveMeasurementTypeRequest struct {
+	Name      string  `json:"name" validate:"required,max=255"`
+	Unit      string  `json:"unit" validate:"required,max=16"`
+	ValueType string  `json:"value_type" validate:"required,oneof=decimal integer"`
+	Min       float64 `json:"min"`
+	Max       float64 `json:"max"`
+}
+
+type ReassignClientRequest struct {
+	TrainerID uint `json:"trainer_id" validate:"required"`
+}
+
+type UserResponse struct {
+	ID              uint       `json:"id"`
+	Email           string     `json:"email"`
+	Name            string     `json:"name"`
+	Role            string     `json:"role"`
+	Status          string     `json:"status"`
+	RegisteredAt    time.Time  `json:"registered_at"`
+	EmailVerifiedAt *time.Time `json:"email_verified_at,omitempty"`
+}
+
+type TrainerResponse struct {
+	ID             uint   `json:"id"`
+	Qualifications string `json:"qualifications"`
+	Experience     string `json:"experience"`
+	Achievements   string `json:"achievements"`
+	Status         string `json:"status"`
+}
+
+type ClientResponse struct {
+	ID        uint    `json:"id"`
+	TrainerID uint    `json:"trainer_id"`
+	Height    float64 `json:"height"`
+	Weight    float64 `json:"weight"`
+	BodyFat   float64 `json:"body_fat"`
+}
+
+type SearchUsersResponse struct {
+	Status string         `json:"status"`
+	Users  []UserResponse `json:"users"`
+	Total  int64          `json:"total"`
+}
+
+type UserDetailsResponse struct {
+	Status  string           `json:"status"`
+	User    UserResponse     `json:"user"`
+	Trainer *TrainerResponse `json:"trainer,omitempty"`
+	Client  *ClientResponse  `json:"client,omitempty"`
+}
+
+type AuditHistoryResponse struct {
+	Status string              `json:"status"`
+	Events []models.AuditEvent `json:"events"`
+}
+
+type AdminHandler struct {
+	log          *slog.Logger
+	adminService AdminService
+}
+
+func NewAdminHandler(log *slog.Logger, adminService AdminService) *AdminHandler {
+	return &AdminHandler{log: log, adminService: adminService}
+}
+
+// SearchUsers lists users filtered by query (part of email or name), role and status
+func (a *AdminHandler) SearchUsers(w http.ResponseWriter, r *http.Request) {
+	const op = "handlers.url.admin.SearchUsers"
+	log := a.log.With(
+		slog.String("op", op),
+	)
+
+	q := r.URL.Query()
+	limit, offset, ok := pageParams(w, r)
+	if !ok {
+		return
+	}
+
+	users, total, err := a.adminService.SearchUsers(r.Context(), models.UserFilter{
+		Query:  q.Get("query"),
+		Role:   q.Get("role"),
+		Status: q.Get("status"),
+		Limit:  limit,
+		Offset: offset,
+	})
+	if err != nil {
+		log.Error("failed to search users", slog.String("error", err.Error()))
+		response.RenderError(w, r, err)
+
+		return
+	}
+
+	resp := SearchUsersResponse{Status: "OK", Users: make([]UserResponse, 0, len(users)), Total: total}
+	for _, user := range users {
+		resp.Users = append(resp.Users, userResponse(user))
+	}
+
+	render.JSON(w, r, resp)
+}
+
+// GetUser returns any user with profile of their role
+func (a *AdminHandler) GetUser(w http.ResponseWriter, r *http.Request) {
+	const op = "handlers.url.admin.GetUser"
+	log := a.log.With(
+		slog.String("op", op),
+	)
+
+	id, ok := pathID(w, r)
+	if !ok {
+		return
+	}
+
+	details, err := a.adminService.GetUser(r.Context(), id)
+	if err != nil {
+		log.Error("failed to get user", slog.String("error", err.Error()))
+		response.RenderError(w, r, err)
+
+		return
+	}
+
+	resp := UserDetailsResponse{Status: "OK", User: userResponse(details.User)}
+	if t := details.Trainer; t != nil {
+		resp.Trainer = &TrainerResponse{
+			ID:             t.ID,
+			Qualifications: t.Qualifications,
+			Experience:     t.Experience,
+			Achievements:   t.Achievements,
+			Status:         t.Status,
+		}
+	}
+	if c := details.Client; c != nil {
+		resp.Client = &ClientResponse{
+			ID:        c.ID,
+			TrainerID: c.TrainerID,
+			Height:    c.Height,
+			Weight:    c.Weight,
+			BodyFat:   c.BodyFat,
+		}
+	}
+
+	render.JSON(w, r, resp)
+}
+
+// SetUserStatus disables or enables account
+func (a *AdminHandler) SetUserStatus(w http.ResponseWriter, r *http.Request) {
+	const op = "handlers.url.admin.SetUserStatus"
+	log := a.log.With(
+		slog.String("op", op),
+	)
+	actor, ok := actorFromContext(w, r)
+	if !ok {
+		return
+	}
+
+	id, ok := pathID(w, r)
+	if !ok {
+		return
+	}
+	var req SetUserStatusRequest
+	if !request.Decode(w, r, log, &req) {
+		return
+	}
+
+	if err := a.adminService.SetUserStatus(r.Context(), actor, id, req.Status); err != nil {
+		log.Error("failed to set user status", slog.String("error", err.Error()))
+		response.RenderError(w, r, err)
+
+		return
+	}
+
+	render.JSON(w, r, response.OK())
+}
+
+// SetTrainerStatus changes availability of trainer
+func (a *AdminHandler) SetTrainerStatus(w http.ResponseWriter, r *http.Request) {
+	const op = "handlers.url.admin.SetTrainerStatus"
+	log := a.log.With(
+		slog.String("op", op),
+	)
+	actor, ok := actorFromContext(w, r)
+	if !ok {
+		return
+	}
+
+	id, ok := pathID(w, r)
+	if !ok {
+		return
+	}
+	var req SetTrainerStatusRequest
+	if !request.Decode(w, r, log, &req) {
+		return
+	}
+
+	if err := a.adminService.SetTrainerStatus(r.Context(), actor, id, req.Status); err != nil {
+		log.Error("failed to set trainer status", slog.String("error", err.Error()))
+		response.RenderError(w, r, err)
+
+		return
+	}
+
+	render.JSON(w, r, response.OK())
+}
+
+// ReassignClient binds client to another trainer
+func (a *AdminHandler) ReassignClient(w http.ResponseWriter, r *http.Request) {
+	const op = "handlers.url.admin.ReassignClient"
+	log := a.log.With(
+		slog.String("op", op),
+	)
+	actor, ok := actorFromContext(w, r)
+	if !ok {
+		return
+	}
+
+	id, ok := pathID(w, r)
+	if !ok {
+		return
+	}
+	var req ReassignClientRequest
+	if !request.Decode(w, r, log, &req) {
+		return
+	}
+
+	if err := a.adminService.ReassignClient(r.Context(), actor, id, req.TrainerID); err != nil {
+		log.Error("failed to reassign client", slog.String("error", err.Error()))
+		response.RenderError(w, r, err)
+
+		return
+	}
+
+	render.JSON(w, r, response.OK())
+}
+
+// SaveMeasurementType adds or changes type of measurement catalog named by "code" URL parameter
+func (a *AdminHandler) SaveMeasurementType(w http.ResponseWriter, r *http.Request) {
+	const op = "handlers.url.admin.SaveMeasurementType"
+	log := a.log.With(
+		slog.String("op", op),
+	)
+	actor, ok := actorFromContext(w, r)
+	if !ok {
+		return
+	}
+
+	var req SaveMeasurementTypeRequest
+	if !request.Decode(w, r, log, &req) {
+		return
+	}
+
+	err := a.adminService.SaveMeasurementType(r.Context(), actor, models.MeasurementType{
+		Code:      chi.URLParam(r, "code"),
+		Name:      req.Name,
+		Unit:      req.Unit,
+		ValueType: req.ValueType,
+		Min:       req.Min,
+		Max:       req.Max,
+	})
+	if err != nil {
+		log.Error("failed to save measurement type", slog.String("error", err.Error()))
+		response.RenderError(w, r, err)
+
+		return
+	}
+
+	render.JSON(w, r, response.OK())
+}
+
+// AuditHistory lists audit events filtered by actor, action, entity and request, newest first
+func (a *AdminHandler) AuditHistory(w http.ResponseWriter, r *http.Request) {
+	const op = "handlers.url.admin.AuditHistory"
+	log := a.log.With(
+		slog.String("op", op),
+	)
+
+	q := r.URL.Query()
+	limit, offset, ok := pageParams(w, r)
+	if !ok {
+		return
+	}
+	entityID, err := parseUint(q.Get("entity_id"))
+	if err != nil {
+		response.RenderError(w, r, apperr.ErrBadRequest.WithMessage("entity_id must be a positive number"))
+
+		return
+	}
+
+	events, err := a.adminService.AuditHistory(r.Context(), models.AuditFilter{
+		Actor:     q.Get("actor"),
+		Action:    q.Get("action"),
+		Entity:    q.Get("entity"),
+		EntityID:  entityID,
+		RequestID: q.Get("request_id"),
+		Limit:     limit,
+		Offset:    offset,
+	})
+	if err != nil {
+		log.Error("failed to get audit history", slog.String("error", err.Error()))
+		response.RenderError(w, r, err)
+
+		return
+	}
+	if events == nil {
+		events = []models.AuditEvent{}
+	}
+
+	render.JSON(w, r, AuditHistoryResponse{Status: "OK", Events: events})
+}
+
+func userResponse(user models.User) UserResponse {
+	return UserResponse{
+		ID:              user.ID,
+		Email:           user.Email,
+		Name:            user.Name,
+		Role:            user.Role,
+		Status:          user.Status,
+		RegisteredAt:    user.RegisteredAt,
+		EmailVerifiedAt: user.EmailVerifiedAt,
+	}
+}
+
+func actorFromContext(w http.ResponseWriter, r *http.Request) (string, bool) {
+	actor, _ := r.Context().Value(models.ContextUserKey).(string)
+	if actor == "" {
+		response.RenderError(w, r, apperr.ErrUnauthorized)
+
+		return "", false
+	}
+
+	return actor, true
+}
+
+// pathID parses "id" URL parameter, rendering error if it is not a positive number
+func pathID(w http.ResponseWriter, r *http.Request) (uint, bool) {
+	id, err := parseUint(chi.URLParam(r, "id"))
+	if err != nil || id == 0 {
+		response.RenderError(w, r, apperr.ErrBadRequest.WithMessage("id must be a positive number"))
+
+		return 0, false
+	}
+
+	return id, true
+}
+
+// pageParams parses optional limit and offset query parameters
+func pageParams(w http.ResponseWriter, r *http.Request) (int, int, bool) {
+	q := r.URL.Query()
+	limit, err := parseUint(q.Get("limit"))
+	if err != nil {
+		response.RenderError(w, r, apperr.ErrBadRequest.WithMessage("limit must be a positive number"))
+
+		return 0, 0, false
+	}
+	offset, err := parseUint(q.Get("offset"))
+	if err != nil {
+		response.RenderError(w, r, apperr.ErrBadRequest.WithMessage("offset must be a positive number"))
+
+		return 0, 0, false
+	}
+
+	return int(limit), int(offset), true
+}
+
+// parseUint parses optional number, empty string is zero
+func parseUint(s string) (uint, error) {
+	if s == "" {
+		return 0, nil
+	}
+	n, err := strconv.ParseUint(s, 10, 32)
+
+	return uint(n), err
+}
@@ -0,0 +1,138 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: admin.go
+
+// Package adminhandler is a generated GoMock package.
+package adminhandler
+
+import (
+	models "ChadProgress/internal/models"
+	context "context"
+	reflect "reflect"
+
+	gomock "github.com/golang/mock/gomock"
+)
+
+// MockAdminService is a mock of AdminService interface.
+type MockAdminService struct {
+	ctrl     *gomock.Controller
+	recorder *MockAdminServiceMockRecorder
+}
+
+// MockAdminServiceMockRecorder is the mock recorder for MockAdminService.
+type MockAdminServiceMockRecorder struct {
+	mock *MockAdminService
+}
+
+// NewMockAdminService creates a new mock instance.
+func NewMockAdminService(ctrl *gomock.Controller) *MockAdminService {
+	mock := &MockAdminService{ctrl: ctrl}
+	mock.recorder = &MockAdminServiceMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockAdminService) EXPECT() *MockAdminServiceMockRecorder {
+	return m.recorder
+}
+
+// AuditHistory mocks base method.
+func (m *MockAdminService) AuditHistory(ctx context.Context, filter models.AuditFilter) ([]models.AuditEvent, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AuditHistory", ctx, filter)
+	ret0, _ := ret[0].([]models.AuditEvent)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// AuditHistory indicates an expected call of AuditHistory.
+func (mr *MockAdminServiceMockRecorder) AuditHistory(ctx, filter interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AuditHistory", reflect.TypeOf((*MockAdminService)(nil).AuditHistory), ctx, filter)
+}
+
+// GetUser mocks base method.
+func (m *MockAdminService) GetUser(ctx context.Context, id uint) (*models.UserDetails, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetUser", ctx, id)
+	ret0, _ := ret[0].(*models.UserDetails)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetUser indicates an expected call of GetUser.
+func (mr *MockAdminServiceMockRecorder) GetUser(ctx, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUser", reflect.TypeOf((*MockAdminService)(nil).GetUser), ctx, id)
+}
+
+// ReassignClient mocks base method.
+func (m *MockAdminService) ReassignClient(ctx context.Context, actor string, clientID, trainerID uint) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ReassignClient", ctx, actor, clientID, trainerID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ReassignClient indicates an expected call of ReassignClient.
+func (mr *MockAdminServiceMockRecorder) ReassignClient(ctx, actor, clientID, trainerID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReassignClient", reflect.TypeOf((*MockAdminService)(nil).ReassignClient), ctx, actor, clientID, trainerID)
+}
+
+// SaveMeasurementType mocks base method.
+func (m *MockAdminService) SaveMeasurementType(ctx context.Context, actor string, t models.MeasurementType) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SaveMeasurementType", ctx, actor, t)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SaveMeasurementType indicates an expected call of SaveMeasurementType.
+func (mr *MockAdminServiceMockRecorder) SaveMeasurementType(ctx, actor, t interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SaveMeasurementType", reflect.TypeOf((*MockAdminService)(nil).SaveMeasurementType), ctx, actor, t)
+}
+
+// SearchUsers mocks base method.
+func (m *MockAdminService) SearchUsers(ctx context.Context, filter models.UserFilter) ([]models.User, int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SearchUsers", ctx, filter)
+	ret0, _ := ret[0].([]models.User)
+	ret1, _ := ret[1].(int64)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// SearchUsers indicates an expected call of SearchUsers.
+func (mr *MockAdminServiceMockRecorder) SearchUsers(ctx, filter interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SearchUsers", reflect.TypeOf((*MockAdminService)(nil).SearchUsers), ctx, filter)
+}
+
+// SetTrainerStatus mocks base method.
+func (m *MockAdminService) SetTrainerStatus(ctx context.Context, actor string, trainerID uint, status string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetTrainerStatus", ctx, actor, trainerID, status)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SetTrainerStatus indicates an expected call of SetTrainerStatus.
+func (mr *MockAdminServiceMockRecorder) SetTrainerStatus(ctx, actor, trainerID, status interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetTrainerStatus", reflect.TypeOf((*MockAdminService)(nil).SetTrainerStatus), ctx, actor, trainerID, status)
+}
+
+// SetUserStatus mocks base method.
+func (m *MockAdminService) SetUserStatus(ctx context.Context, actor string, id uint, status string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetUserStatus", ctx, actor, id, status)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SetUserStatus indicates an expected call of SetUserStatus.
+func (mr *MockAdminServiceMockRecorder) SetUserStatus(ctx, actor, id, status interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetUserStatus", reflect.TypeOf((*MockAdminService)(nil).SetUserStatus), ctx, actor, id, status)
+}
@@ -0,0 +1,177 @@
+package adminhandler
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"ChadProgress/internal/models"
+	service "ChadProgress/internal/services"
+
+	"github.com/go-chi/chi/v5"
+	gomock "github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+)
+
+const admin = "admin@example.com"
+
+func newRequest(method, target, body string) *http.Request {
+	req := httptest.NewRequest(method, target, strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+
+	return req.WithContext(context.WithValue(req.Context(), models.ContextUserKey, admin))
+}
+
+func newRouter(handler *AdminHandler) http.Handler {
+	router := chi.NewRouter()
+	router.Get("/admin/users", handler.SearchUsers)
+	router.Get("/admin/users/{id}", handler.GetUser)
+	router.Patch("/admin/users/{id}/status", handler.SetUserStatus)
+	router.Patch("/admin/clients/{id}/trainer", handler.ReassignClient)
+	router.Get("/admin/audit", handler.AuditHistory)
+
+	return router
+}
+
+func TestSearchUsers(t *testing.T) {
+	tests := []struct {
+		name         string
+		target       string
+		filter       *models.UserFilter
+		expectedCode int
+	}{
+		{
+			name:         "Success",
+			target:       "/admin/users?query=bob&role=trainer&limit=10&offset=20",
+			filter:       &models.UserFilter{Query: "bob", Role: models.RoleTrainer, Limit: 10, Offset: 20},
+			expectedCode: http.StatusOK,
+		},
+		{
+			name:         "Invalid limit",
+			target:       "/admin/users?limit=-1",
+			expectedCode: http.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			mockService := NewMockAdminService(ctrl)
+			if tt.filter != nil {
+				mockService.EXPECT().SearchUsers(gomock.Any(), *tt.filter).
+					Return([]models.User{{ID: 7, Email: "bob@example.com", Role: models.RoleTrainer}}, int64(21), nil)
+			}
+
+			handler := NewAdminHandler(slog.New(slog.NewTextHandler(io.Discard, nil)), mockService)
+			rr := httptest.NewRecorder()
+			newRouter(handler).ServeHTTP(rr, newRequest(http.MethodGet, tt.target, ""))
+
+			assert.Equal(t, tt.expectedCode, rr.Code)
+			if tt.filter != nil {
+				assert.Contains(t, rr.Body.String(), `"id":7,"email":"bob@example.com"`)
+				assert.Contains(t, rr.Body.String(), `"total":21`)
+			}
+		})
+	}
+}
+
+func TestGetUser(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	mockService := NewMockAdminService(ctrl)
+	mockService.EXPECT().GetUser(gomock.Any(), uint(7)).Return(&models.UserDetails{
+		User:   models.User{ID: 7, Role: models.RoleClient},
+		Client: &models.Client{ID: 3, TrainerID: 2},
+	}, nil)
+
+	handler := NewAdminHandler(slog.New(slog.NewTextHandler(io.Discard, nil)), mockService)
+	rr := httptest.NewRecorder()
+	newRouter(handler).ServeHTTP(rr, newRequest(http.MethodGet, "/admin/users/7", ""))
+
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.Contains(t, rr.Body.String(), `"client":{"id":3,"trainer_id":2`)
+	assert.NotContains(t, rr.Body.String(), `"trainer":`)
+}
+
+func TestSetUserStatus(t *testing.T) {
+	tests := []struct {
+		name         string
+		target       string
+		body         string
+		callService  bool
+		mockError    error
+		expectedCode int
+	}{
+		{
+			name:         "Success",
+			target:       "/admin/users/7/status",
+			body:         `{"status":"disabled"}`,
+			callService:  true,
+			expectedCode: http.StatusOK,
+		},
+		{
+			name:         "Unknown user",
+			target:       "/admin/users/7/status",
+			body:         `{"status":"disabled"}`,
+			callService:  true,
+			mockError:    service.ErrUserNotFound,
+			expectedCode: http.StatusNotFound,
+		},
+		{
+			name:         "Unknown status",
+			target:       "/admin/users/7/status",
+			body:         `{"status":"deleted"}`,
+			expectedCode: http.StatusBadRequest,
+		},
+		{
+			name:         "Invalid id",
+			target:       "/admin/users/abc/status",
+			body:         `{"status":"disabled"}`,
+			expectedCode: http.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			mockService := NewMockAdminService(ctrl)
+			if tt.callService {
+				mockService.EXPECT().SetUserStatus(gomock.Any(), admin, uint(7), models.UserStatusDisabled).Return(tt.mockError)
+			}
+
+			handler := NewAdminHandler(slog.New(slog.NewTextHandler(io.Discard, nil)), mockService)
+			rr := httptest.NewRecorder()
+			newRouter(handler).ServeHTTP(rr, newRequest(http.MethodPatch, tt.target, tt.body))
+
+			assert.Equal(t, tt.expectedCode, rr.Code)
+		})
+	}
+}
+
+func TestReassignClient(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	mockService := NewMockAdminService(ctrl)
+	mockService.EXPECT().ReassignClient(gomock.Any(), admin, uint(3), uint(2)).Return(nil)
+
+	handler := NewAdminHandler(slog.New(slog.NewTextHandler(io.Discard, nil)), mockService)
+	rr := httptest.NewRecorder()
+	newRouter(handler).ServeHTTP(rr, newRequest(http.MethodPatch, "/admin/clients/3/trainer", `{"trainer_id":2}`))
+
+	assert.Equal(t, http.StatusOK, rr.Code)
+}
+
+func TestAuditHistory(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	mockService := NewMockAdminService(ctrl)
+	mockService.EXPECT().AuditHistory(gomock.Any(), models.AuditFilter{Entity: "client", EntityID: 3}).Return(nil, nil)
+
+	handler := NewAdminHandler(slog.New(slog.NewTextHandler(io.Discard, nil)), mockService)
+	rr := httptest.NewRecorder()
+	newRouter(handler).ServeHTTP(rr, newRequest(http.MethodGet, "/admin/audit?entity=client&entity_id=3", ""))
+
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.Contains(t, rr.Body.String(), `"events":[]`)
+}
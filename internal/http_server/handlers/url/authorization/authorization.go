@@ -1,12 +1,15 @@
 package authorization
 
 import (
-	"errors"
+	"context"
 	"log/slog"
 	"net/http"
 
+	"ChadProgress/internal/lib/api/request"
 	"ChadProgress/internal/lib/api/response"
-	service "ChadProgress/internal/services"
+	"ChadProgress/internal/lib/apperr"
+	"ChadProgress/internal/lib/logger/redact"
+	"ChadProgress/internal/models"
 
 	"github.com/go-chi/render"
 	"github.com/go-playground/validator/v10"
@@ -14,20 +17,37 @@ import (
 
 //go:generate mockgen -source=authorization.go -destination=./authorization_mock.go -package=authorization
 type UserAuthService interface {
-	RegisterUser(email, password, name, role string) (string, error)
-	Login(email, password string) (string, error)
+	RegisterUser(ctx context.Context, email, password, name, role string, client models.ClientInfo) (models.TokenPair, error)
+	Login(ctx context.Context, email, password string, client models.ClientInfo) (models.TokenPair, error)
+	Refresh(ctx context.Context, refreshToken string, client models.ClientInfo) (models.TokenPair, error)
+	Logout(ctx context.Context, accessToken, refreshToken string) error
+	VerifyEmail(ctx context.Context, token string) error
+	RequestPasswordReset(ctx context.Context, email string) error
+	ResetPassword(ctx context.Context, token, password string) error
+	ConfirmEmailChange(ctx context.Context, token string) error
 }
 
 type RegisterRequest struct {
-	Email    string `json:"email" validate:"required"`
-	Password string `json:"password" validate:"required"`
+	Email    string `json:"email" validate:"required,email"`
+	Password string `json:"password" validate:"required,min=8,max=72"`
 	Name     string `json:"name" validate:"required"`
 	Role     string `json:"role" validate:"required,oneof=trainer client"`
 }
 
+// LogValue implements slog.LogValuer so that password never reaches logs
+func (r RegisterRequest) LogValue() slog.Value {
+	return slog.GroupValue(
+		slog.String("email", r.Email),
+		slog.String("password", redact.Secret(r.Password)),
+		slog.String("name", r.Name),
+		slog.String("role", r.Role),
+	)
+}
+
 type RegisterResponse struct {
-	Status   string `json:"status"`
-	JWTToken string `json:"token"`
+	Status       string `json:"status"`
+	JWTToken     string `json:"token"`
+	RefreshToken string `json:"refresh_token,omitempty"`
 }
 
 type LoginRequest struct {
@@ -35,9 +55,58 @@ type LoginRequest struct {
 	Password string `json:"password" validate:"required"`
 }
 
+// LogValue implements slog.LogValuer so that password never reaches logs
+func (r LoginRequest) LogValue() slog.Value {
+	return slog.GroupValue(
+		slog.String("email", r.Email),
+		slog.String("password", redact.Secret(r.Password)),
+	)
+}
+
 type LoginResponse struct {
-	Status   string `json:"status"`
-	JWTToken string `json:"token,omitempty"`
+	Status       string `json:"status"`
+	JWTToken     string `json:"token,omitempty"`
+	RefreshToken string `json:"refresh_token,omitempty"`
+}
+
+type RefreshRequest struct {
+	RefreshToken string `json:"refresh_token" validate:"required"`
+}
+
+// LogValue implements slog.LogValuer so that token never reaches logs
+func (r RefreshRequest) LogValue() slog.Value {
+	return slog.GroupValue(slog.String("refresh_token", redact.Secret(r.RefreshToken)))
+}
+
+// LogoutRequest may carry refresh token of the session so that it is revoked too
+type LogoutRequest struct {
+	RefreshToken string `json:"refresh_token"`
+}
+
+type VerifyEmailRequest struct {
+	Token string `json:"token" validate:"required"`
+}
+
+// LogValue implements slog.LogValuer so that token never reaches logs
+func (r VerifyEmailRequest) LogValue() slog.Value {
+	return slog.GroupValue(slog.String("token", redact.Secret(r.Token)))
+}
+
+type ForgotPasswordRequest struct {
+	Email string `json:"email" validate:"required,email"`
+}
+
+type ResetPasswordRequest struct {
+	Token    string `json:"token" validate:"required"`
+	Password string `json:"password" validate:"required,min=8,max=72"`
+}
+
+// LogValue implements slog.LogValuer so that token and password never reach logs
+func (r ResetPasswordRequest) LogValue() slog.Value {
+	return slog.GroupValue(
+		slog.String("token", redact.Secret(r.Token)),
+		slog.String("password", redact.Secret(r.Password)),
+	)
 }
 
 type UserAuthHandler struct {
@@ -58,8 +127,7 @@ func (u *UserAuthHandler) Register(w http.ResponseWriter, r *http.Request) {
 		slog.String("op", op),
 	)
 	if r.Body == nil || r.ContentLength == 0 {
-		w.WriteHeader(http.StatusBadRequest)
-		render.JSON(w, r, response.Error("empty request"))
+		response.RenderError(w, r, apperr.ErrBadRequest.WithMessage("empty request"))
 		return
 	}
 
@@ -67,46 +135,31 @@ func (u *UserAuthHandler) Register(w http.ResponseWriter, r *http.Request) {
 	err := render.DecodeJSON(r.Body, &req)
 	if err != nil {
 		log.Error("failed to decode request body", slog.String("error", err.Error()))
-		setHeaderRenderJSON(w, r, http.StatusBadRequest, response.Error("failed to decode request body"))
+		response.RenderError(w, r, apperr.ErrBadRequest.WithMessage("failed to decode request body"))
 
 		return
 	}
 
 	log.Info("register request body decoded", slog.Any("request", req))
 	if err = validator.New().Struct(req); err != nil {
-		w.WriteHeader(http.StatusBadRequest)
 		validationErr := err.(validator.ValidationErrors)
 
 		log.Error("invalid request", slog.String("error", validationErr.Error()))
-		setHeaderRenderJSON(w, r, http.StatusBadRequest, response.ValidationError(validationErr))
+		response.RenderError(w, r, response.ValidationError(validationErr))
 
 		return
 	}
 
-	jwtToken, err := u.userService.RegisterUser(req.Email, req.Password, req.Name, req.Role)
+	tokens, err := u.userService.RegisterUser(r.Context(), req.Email, req.Password, req.Name, req.Role, request.ClientInfo(r))
 	if err != nil {
-		if errors.Is(err, service.ErrUserAlreadyExists) {
-			log.Info("user already exists")
-			w.WriteHeader(http.StatusBadRequest)
-			render.JSON(w, r, response.Error("user already with such email"))
-
-			return
-		} else if errors.Is(err, service.ErrFieldIsTooLong) {
-			log.Info("field login or password is too long")
-			w.WriteHeader(http.StatusBadRequest)
-			render.JSON(w, r, response.Error("login and password must be no more than 100 symbols"))
-
-			return
-		}
-
 		log.Error("failed to save user", slog.String("error", err.Error()))
-		w.WriteHeader(http.StatusBadGateway)
-		render.JSON(w, r, response.Error("failed to save user"))
+		response.RenderError(w, r, err)
+
 		return
 	}
 
 	log.Info("successfully saved user", slog.String("email", req.Email))
-	render.JSON(w, r, regResponseOK(jwtToken))
+	render.JSON(w, r, regResponseOK(tokens))
 }
 
 func (u *UserAuthHandler) Login(w http.ResponseWriter, r *http.Request) {
@@ -116,8 +169,7 @@ func (u *UserAuthHandler) Login(w http.ResponseWriter, r *http.Request) {
 	)
 
 	if r.Body == nil || r.ContentLength == 0 {
-		w.WriteHeader(http.StatusBadRequest)
-		render.JSON(w, r, response.Error("empty request"))
+		response.RenderError(w, r, apperr.ErrBadRequest.WithMessage("empty request"))
 		return
 	}
 
@@ -126,7 +178,7 @@ func (u *UserAuthHandler) Login(w http.ResponseWriter, r *http.Request) {
 	err := render.DecodeJSON(r.Body, &req)
 	if err != nil {
 		log.Error("failed to decode request body", slog.String("error", err.Error()))
-		setHeaderRenderJSON(w, r, http.StatusBadRequest, response.Error("failed to decode request body"))
+		response.RenderError(w, r, apperr.ErrBadRequest.WithMessage("failed to decode request body"))
 
 		return
 	}
@@ -134,21 +186,16 @@ func (u *UserAuthHandler) Login(w http.ResponseWriter, r *http.Request) {
 	if err = validator.New().Struct(req); err != nil {
 		validationErr := err.(validator.ValidationErrors)
 		log.Error("invalid request", slog.String("error", validationErr.Error()))
-		setHeaderRenderJSON(w, r, http.StatusBadRequest, response.ValidationError(validationErr))
+		response.RenderError(w, r, response.ValidationError(validationErr))
 
 		return
 	}
 	log.Info("login request body decoded", slog.Any("request", req))
 
-	jwtToken, err := u.userService.Login(req.Email, req.Password)
+	tokens, err := u.userService.Login(r.Context(), req.Email, req.Password, request.ClientInfo(r))
 	if err != nil {
-		if errors.Is(err, service.ErrInvalidCredentials) {
-			log.Info("invalid credentials")
-			setHeaderRenderJSON(w, r, http.StatusUnauthorized, response.Error("invalid credentials"))
-			return
-		}
-		log.Error("failed to sign in")
-		setHeaderRenderJSON(w, r, http.StatusBadGateway, response.Error("failed to sign in"))
+		log.Error("failed to sign in", slog.String("error", err.Error()))
+		response.RenderError(w, r, err)
 
 		return
 	}
@@ -157,21 +204,78 @@ func (u *UserAuthHandler) Login(w http.ResponseWriter, r *http.Request) {
 	setHeaderRenderJSON(
 		w, r,
 		http.StatusOK,
-		loginResponseOK(jwtToken),
+		loginResponseOK(tokens),
 	)
 }
 
-func regResponseOK(token string) RegisterResponse {
+func (u *UserAuthHandler) Refresh(w http.ResponseWriter, r *http.Request) {
+	const op = "handlers.url.user.reg.Refresh"
+	log := u.log.With(
+		slog.String("op", op),
+	)
+
+	var req RefreshRequest
+	if !request.Decode(w, r, log, &req) {
+		return
+	}
+
+	tokens, err := u.userService.Refresh(r.Context(), req.RefreshToken, request.ClientInfo(r))
+	if err != nil {
+		log.Error("failed to refresh token", slog.String("error", err.Error()))
+		response.RenderError(w, r, err)
+
+		return
+	}
+
+	render.JSON(w, r, loginResponseOK(tokens))
+}
+
+func (u *UserAuthHandler) Logout(w http.ResponseWriter, r *http.Request) {
+	const op = "handlers.url.user.reg.Logout"
+	log := u.log.With(
+		slog.String("op", op),
+	)
+
+	token := request.BearerToken(r)
+	if token == "" {
+		response.RenderError(w, r, apperr.ErrUnauthorized)
+		return
+	}
+
+	// body is optional
+	var req LogoutRequest
+	if r.Body != nil && r.ContentLength != 0 {
+		if err := render.DecodeJSON(r.Body, &req); err != nil {
+			log.Error("failed to decode request body", slog.String("error", err.Error()))
+			response.RenderError(w, r, apperr.ErrBadRequest.WithMessage("failed to decode request body"))
+
+			return
+		}
+	}
+
+	if err := u.userService.Logout(r.Context(), token, req.RefreshToken); err != nil {
+		log.Error("failed to sign out", slog.String("error", err.Error()))
+		response.RenderError(w, r, err)
+
+		return
+	}
+
+	render.JSON(w, r, response.OK())
+}
+
+func regResponseOK(tokens models.TokenPair) RegisterResponse {
 	return RegisterResponse{
-		Status:   "OK",
-		JWTToken: token,
+		Status:       "OK",
+		JWTToken:     tokens.AccessToken,
+		RefreshToken: tokens.RefreshToken,
 	}
 }
 
-func loginResponseOK(token string) LoginResponse {
+func loginResponseOK(tokens models.TokenPair) LoginResponse {
 	return LoginResponse{
-		Status:   "OK",
-		JWTToken: token,
+		Status:       "OK",
+		JWTToken:     tokens.AccessToken,
+		RefreshToken: tokens.RefreshToken,
 	}
 }
 
@@ -179,3 +283,91 @@ func setHeaderRenderJSON(w http.ResponseWriter, r *http.Request, status int, v a
 	w.WriteHeader(status)
 	render.JSON(w, r, v)
 }
+
+// VerifyEmail consumes token from verification email
+func (u *UserAuthHandler) VerifyEmail(w http.ResponseWriter, r *http.Request) {
+	const op = "handlers.url.user.reg.VerifyEmail"
+	log := u.log.With(
+		slog.String("op", op),
+	)
+
+	var req VerifyEmailRequest
+	if !request.Decode(w, r, log, &req) {
+		return
+	}
+
+	if err := u.userService.VerifyEmail(r.Context(), req.Token); err != nil {
+		log.Error("failed to verify email", slog.String("error", err.Error()))
+		response.RenderError(w, r, err)
+
+		return
+	}
+
+	render.JSON(w, r, response.OK())
+}
+
+// ForgotPassword sends password reset link. It answers OK for unknown emails too.
+func (u *UserAuthHandler) ForgotPassword(w http.ResponseWriter, r *http.Request) {
+	const op = "handlers.url.user.reg.ForgotPassword"
+	log := u.log.With(
+		slog.String("op", op),
+	)
+
+	var req ForgotPasswordRequest
+	if !request.Decode(w, r, log, &req) {
+		return
+	}
+
+	if err := u.userService.RequestPasswordReset(r.Context(), req.Email); err != nil {
+		log.Error("failed to request password reset", slog.String("error", err.Error()))
+		response.RenderError(w, r, err)
+
+		return
+	}
+
+	render.JSON(w, r, response.OK())
+}
+
+// ResetPassword sets new password using token from reset email
+func (u *UserAuthHandler) ResetPassword(w http.ResponseWriter, r *http.Request) {
+	const op = "handlers.url.user.reg.ResetPassword"
+	log := u.log.With(
+		slog.String("op", op),
+	)
+
+	var req ResetPasswordRequest
+	if !request.Decode(w, r, log, &req) {
+		return
+	}
+
+	if err := u.userService.ResetPassword(r.Context(), req.Token, req.Password); err != nil {
+		log.Error("failed to reset password", slog.String("error", err.Error()))
+		response.RenderError(w, r, err)
+
+		return
+	}
+
+	render.JSON(w, r, response.OK())
+}
+
+// ConfirmEmailChange consumes token sent to the new email and changes email of the account
+func (u *UserAuthHandler) ConfirmEmailChange(w http.ResponseWriter, r *http.Request) {
+	const op = "handlers.url.user.reg.ConfirmEmailChange"
+	log := u.log.With(
+		slog.String("op", op),
+	)
+
+	var req VerifyEmailRequest
+	if !request.Decode(w, r, log, &req) {
+		return
+	}
+
+	if err := u.userService.ConfirmEmailChange(r.Context(), req.Token); err != nil {
+		log.Error("failed to change email", slog.String("error", err.Error()))
+		response.RenderError(w, r, err)
+
+		return
+	}
+
+	render.JSON(w, r, response.OK())
+}
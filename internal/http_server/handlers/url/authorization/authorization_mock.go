@@ -5,6 +5,8 @@
 package authorization
 
 import (
+	models "ChadProgress/internal/models"
+	context "context"
 	reflect "reflect"
 
 	gomock "github.com/golang/mock/gomock"
@@ -33,32 +35,117 @@ func (m *MockUserAuthService) EXPECT() *MockUserAuthServiceMockRecorder {
 	return m.recorder
 }
 
+// ConfirmEmailChange mocks base method.
+func (m *MockUserAuthService) ConfirmEmailChange(ctx context.Context, token string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ConfirmEmailChange", ctx, token)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ConfirmEmailChange indicates an expected call of ConfirmEmailChange.
+func (mr *MockUserAuthServiceMockRecorder) ConfirmEmailChange(ctx, token interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ConfirmEmailChange", reflect.TypeOf((*MockUserAuthService)(nil).ConfirmEmailChange), ctx, token)
+}
+
 // Login mocks base method.
-func (m *MockUserAuthService) Login(email, password string) (string, error) {
+func (m *MockUserAuthService) Login(ctx context.Context, email, password string, client models.ClientInfo) (models.TokenPair, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "Login", email, password)
-	ret0, _ := ret[0].(string)
+	ret := m.ctrl.Call(m, "Login", ctx, email, password, client)
+	ret0, _ := ret[0].(models.TokenPair)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // Login indicates an expected call of Login.
-func (mr *MockUserAuthServiceMockRecorder) Login(email, password interface{}) *gomock.Call {
+func (mr *MockUserAuthServiceMockRecorder) Login(ctx, email, password, client interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Login", reflect.TypeOf((*MockUserAuthService)(nil).Login), ctx, email, password, client)
+}
+
+// Logout mocks base method.
+func (m *MockUserAuthService) Logout(ctx context.Context, accessToken, refreshToken string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Logout", ctx, accessToken, refreshToken)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Logout indicates an expected call of Logout.
+func (mr *MockUserAuthServiceMockRecorder) Logout(ctx, accessToken, refreshToken interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Logout", reflect.TypeOf((*MockUserAuthService)(nil).Logout), ctx, accessToken, refreshToken)
+}
+
+// Refresh mocks base method.
+func (m *MockUserAuthService) Refresh(ctx context.Context, refreshToken string, client models.ClientInfo) (models.TokenPair, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Refresh", ctx, refreshToken, client)
+	ret0, _ := ret[0].(models.TokenPair)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Refresh indicates an expected call of Refresh.
+func (mr *MockUserAuthServiceMockRecorder) Refresh(ctx, refreshToken, client interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Login", reflect.TypeOf((*MockUserAuthService)(nil).Login), email, password)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Refresh", reflect.TypeOf((*MockUserAuthService)(nil).Refresh), ctx, refreshToken, client)
 }
 
 // RegisterUser mocks base method.
-func (m *MockUserAuthService) RegisterUser(email, password, name, role string) (string, error) {
+func (m *MockUserAuthService) RegisterUser(ctx context.Context, email, password, name, role string, client models.ClientInfo) (models.TokenPair, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "RegisterUser", email, password, name, role)
-	ret0, _ := ret[0].(string)
+	ret := m.ctrl.Call(m, "RegisterUser", ctx, email, password, name, role, client)
+	ret0, _ := ret[0].(models.TokenPair)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // RegisterUser indicates an expected call of RegisterUser.
-func (mr *MockUserAuthServiceMockRecorder) RegisterUser(email, password, name, role interface{}) *gomock.Call {
+func (mr *MockUserAuthServiceMockRecorder) RegisterUser(ctx, email, password, name, role, client interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RegisterUser", reflect.TypeOf((*MockUserAuthService)(nil).RegisterUser), ctx, email, password, name, role, client)
+}
+
+// RequestPasswordReset mocks base method.
+func (m *MockUserAuthService) RequestPasswordReset(ctx context.Context, email string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RequestPasswordReset", ctx, email)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RequestPasswordReset indicates an expected call of RequestPasswordReset.
+func (mr *MockUserAuthServiceMockRecorder) RequestPasswordReset(ctx, email interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RequestPasswordReset", reflect.TypeOf((*MockUserAuthService)(nil).RequestPasswordReset), ctx, email)
+}
+
+// ResetPassword mocks base method.
+func (m *MockUserAuthService) ResetPassword(ctx context.Context, token, password string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ResetPassword", ctx, token, password)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ResetPassword indicates an expected call of ResetPassword.
+func (mr *MockUserAuthServiceMockRecorder) ResetPassword(ctx, token, password interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ResetPassword", reflect.TypeOf((*MockUserAuthService)(nil).ResetPassword), ctx, token, password)
+}
+
+// VerifyEmail mocks base method.
+func (m *MockUserAuthService) VerifyEmail(ctx context.Context, token string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "VerifyEmail", ctx, token)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// VerifyEmail indicates an expected call of VerifyEmail.
+func (mr *MockUserAuthServiceMockRecorder) VerifyEmail(ctx, token interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RegisterUser", reflect.TypeOf((*MockUserAuthService)(nil).RegisterUser), email, password, name, role)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "VerifyEmail", reflect.TypeOf((*MockUserAuthService)(nil).VerifyEmail), ctx, token)
 }
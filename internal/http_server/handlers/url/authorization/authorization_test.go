@@ -1,6 +1,9 @@
 package authorization
 
 import (
+	"bytes"
+	"errors"
+	"fmt"
 	"io"
 	"log/slog"
 	"net/http"
@@ -8,6 +11,10 @@ import (
 	"strings"
 	"testing"
 
+	"ChadProgress/internal/lib/logger/redact"
+	"ChadProgress/internal/models"
+	service "ChadProgress/internal/services"
+
 	gomock "github.com/golang/mock/gomock"
 	"github.com/stretchr/testify/assert"
 )
@@ -35,7 +42,7 @@ func TestRegister(t *testing.T) {
 			mockReturn:   "",
 			mockError:    nil,
 			expectedCode: http.StatusBadRequest,
-			expectedResp: `{"status":"Error","error":"field Role is not valid"}`,
+			expectedResp: `{"status":"Error","error":"field Role is not valid","code":"validation_failed"`,
 		},
 		{
 			name:         "Empty request",
@@ -45,6 +52,26 @@ func TestRegister(t *testing.T) {
 			expectedCode: http.StatusBadRequest,
 			expectedResp: `"empty request"`,
 		},
+		{
+			name:         "Short password",
+			requestBody:  `{"email": "test@example.com", "password": "secret", "name": "Test", "role": "client"}`,
+			expectedCode: http.StatusBadRequest,
+			expectedResp: `"error":"field Password must be at least 8 characters long"`,
+		},
+		{
+			name:         "Password longer than bcrypt accepts",
+			requestBody:  `{"email": "test@example.com", "password": "` + strings.Repeat("a", 73) + `", "name": "Test", "role": "client"}`,
+			expectedCode: http.StatusBadRequest,
+			expectedResp: `"error":"field Password must be at most 72 characters long"`,
+		},
+		{
+			name:         "Invalid email",
+			requestBody:  `{"email": "not an email", "password": "password123", "name": "Test", "role": "client"}`,
+			mockReturn:   "",
+			mockError:    nil,
+			expectedCode: http.StatusBadRequest,
+			expectedResp: `"code":"validation_failed"`,
+		},
 	}
 
 	for _, tt := range tests {
@@ -57,8 +84,8 @@ func TestRegister(t *testing.T) {
 
 			if tt.mockError != nil || tt.mockReturn != "" {
 				mockAuthService.EXPECT().
-					RegisterUser(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).
-					Return(tt.mockReturn, tt.mockError)
+					RegisterUser(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).
+					Return(models.TokenPair{AccessToken: tt.mockReturn}, tt.mockError)
 			}
 
 			handler := NewUserAuthHandler(mockAuthService, logger)
@@ -97,7 +124,7 @@ func TestLogin(t *testing.T) {
             mockReturn:   "",
             mockError:    nil,
             expectedCode: http.StatusBadRequest,
-            expectedResp: `{"status":"Error","error":"field Email is a required field"}`,
+            expectedResp: `{"status":"Error","error":"field Email is a required field","code":"validation_failed"`,
         },
 		{
 			name:         "Empty request",
@@ -115,6 +142,20 @@ func TestLogin(t *testing.T) {
 			expectedCode: http.StatusBadRequest,
 			expectedResp: "failed to decode request bod",
 		},
+		{
+			name:         "Invalid credentials",
+			requestBody:  `{"email": "test@example.com", "password": "wrong"}`,
+			mockError:    fmt.Errorf("login: %w", service.ErrInvalidCredentials),
+			expectedCode: http.StatusUnauthorized,
+			expectedResp: `"code":"invalid_credentials"`,
+		},
+		{
+			name:         "Auth service unavailable",
+			requestBody:  `{"email": "test@example.com", "password": "valid"}`,
+			mockError:    fmt.Errorf("login: %w: %w", service.ErrAuthUnavailable, errors.New("connection refused")),
+			expectedCode: http.StatusServiceUnavailable,
+			expectedResp: `"code":"auth_service_unavailable"`,
+		},
     }
 
     for _, tt := range tests {
@@ -127,8 +168,8 @@ func TestLogin(t *testing.T) {
 
             if tt.mockError != nil || tt.mockReturn != "" {
                 mockAuthService.EXPECT().
-                    Login(gomock.Any(), gomock.Any()).
-                    Return(tt.mockReturn, tt.mockError)
+                    Login(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).
+                    Return(models.TokenPair{AccessToken: tt.mockReturn}, tt.mockError)
             }
 
             handler := NewUserAuthHandler(mockAuthService, logger)
@@ -143,3 +184,279 @@ func TestLogin(t *testing.T) {
         })
     }
 }
+
+func TestAuthRequestsAreNotLoggedInPlaintext(t *testing.T) {
+	tests := []struct {
+		name        string
+		path        string
+		requestBody string
+	}{
+		{
+			name:        "Register",
+			path:        "/register",
+			requestBody: `{"email": "test@example.com", "password": "password123", "name": "Test User", "role": "client"}`,
+		},
+		{
+			name:        "Login",
+			path:        "/login",
+			requestBody: `{"email": "test@example.com", "password": "password123"}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			mockAuthService := NewMockUserAuthService(ctrl)
+			mockAuthService.EXPECT().
+				RegisterUser(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).
+				Return(models.TokenPair{AccessToken: "fake-jwt-token"}, nil).AnyTimes()
+			mockAuthService.EXPECT().
+				Login(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).
+				Return(models.TokenPair{AccessToken: "fake-jwt-token"}, nil).AnyTimes()
+
+			var out bytes.Buffer
+			logger := slog.New(slog.NewJSONHandler(&out, &slog.HandlerOptions{
+				ReplaceAttr: redact.ReplaceAttr(redact.PolicyStrict),
+			}))
+
+			handler := NewUserAuthHandler(mockAuthService, logger)
+			req, _ := http.NewRequest("POST", tt.path, strings.NewReader(tt.requestBody))
+			req.Header.Set("Content-Type", "application/json")
+
+			rr := httptest.NewRecorder()
+			if tt.path == "/register" {
+				handler.Register(rr, req)
+			} else {
+				handler.Login(rr, req)
+			}
+
+			assert.Equal(t, http.StatusOK, rr.Code)
+			assert.NotEmpty(t, out.String())
+			assert.NotContains(t, out.String(), "password123")
+			assert.NotContains(t, out.String(), "test@example.com")
+		})
+	}
+}
+
+func TestRefresh(t *testing.T) {
+	tests := []struct {
+		name         string
+		requestBody  string
+		callService  bool
+		mockError    error
+		expectedCode int
+		expectedResp string
+	}{
+		{
+			name:         "Success",
+			requestBody:  `{"refresh_token": "old-refresh"}`,
+			callService:  true,
+			expectedCode: http.StatusOK,
+			expectedResp: `{"status":"OK","token":"new-access","refresh_token":"new-refresh"}`,
+		},
+		{
+			name:         "Missing token",
+			requestBody:  `{}`,
+			expectedCode: http.StatusBadRequest,
+			expectedResp: `"code":"validation_failed"`,
+		},
+		{
+			name:         "Revoked token",
+			requestBody:  `{"refresh_token": "old-refresh"}`,
+			callService:  true,
+			mockError:    service.ErrInvalidToken,
+			expectedCode: http.StatusUnauthorized,
+			expectedResp: `"code":"invalid_token"`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			mockAuthService := NewMockUserAuthService(ctrl)
+			logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+
+			if tt.callService {
+				mockAuthService.EXPECT().
+					Refresh(gomock.Any(), "old-refresh", gomock.Any()).
+					Return(models.TokenPair{AccessToken: "new-access", RefreshToken: "new-refresh"}, tt.mockError)
+			}
+
+			handler := NewUserAuthHandler(mockAuthService, logger)
+			req, _ := http.NewRequest("POST", "/refresh", strings.NewReader(tt.requestBody))
+			req.Header.Set("Content-Type", "application/json")
+
+			rr := httptest.NewRecorder()
+			handler.Refresh(rr, req)
+
+			assert.Equal(t, tt.expectedCode, rr.Code)
+			assert.Contains(t, rr.Body.String(), tt.expectedResp)
+		})
+	}
+}
+
+func TestLogout(t *testing.T) {
+	tests := []struct {
+		name            string
+		authHeader      string
+		requestBody     string
+		expectedRefresh string
+		expectedCode    int
+	}{
+		{
+			name:            "With refresh token",
+			authHeader:      "Bearer access",
+			requestBody:     `{"refresh_token": "refresh"}`,
+			expectedRefresh: "refresh",
+			expectedCode:    http.StatusOK,
+		},
+		{
+			name:         "Without body",
+			authHeader:   "Bearer access",
+			expectedCode: http.StatusOK,
+		},
+		{
+			name:         "Without token",
+			expectedCode: http.StatusUnauthorized,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			mockAuthService := NewMockUserAuthService(ctrl)
+			logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+
+			if tt.authHeader != "" {
+				mockAuthService.EXPECT().
+					Logout(gomock.Any(), "access", tt.expectedRefresh).
+					Return(nil)
+			}
+
+			handler := NewUserAuthHandler(mockAuthService, logger)
+			req, _ := http.NewRequest("POST", "/logout", strings.NewReader(tt.requestBody))
+			req.Header.Set("Content-Type", "application/json")
+			if tt.authHeader != "" {
+				req.Header.Set("Authorization", tt.authHeader)
+			}
+
+			rr := httptest.NewRecorder()
+			handler.Logout(rr, req)
+
+			assert.Equal(t, tt.expectedCode, rr.Code)
+		})
+	}
+}
+
+func TestEmailFlows(t *testing.T) {
+	tests := []struct {
+		name         string
+		requestBody  string
+		expect       func(m *MockUserAuthService)
+		call         func(h *UserAuthHandler) http.HandlerFunc
+		expectedCode int
+		expectedResp string
+	}{
+		{
+			name:        "Verify email",
+			requestBody: `{"token": "verify-token"}`,
+			expect: func(m *MockUserAuthService) {
+				m.EXPECT().VerifyEmail(gomock.Any(), "verify-token").Return(nil)
+			},
+			call:         func(h *UserAuthHandler) http.HandlerFunc { return h.VerifyEmail },
+			expectedCode: http.StatusOK,
+			expectedResp: `{"status":"OK"}`,
+		},
+		{
+			name:        "Verify email with used token",
+			requestBody: `{"token": "verify-token"}`,
+			expect: func(m *MockUserAuthService) {
+				m.EXPECT().VerifyEmail(gomock.Any(), "verify-token").Return(service.ErrInvalidEmailToken)
+			},
+			call:         func(h *UserAuthHandler) http.HandlerFunc { return h.VerifyEmail },
+			expectedCode: http.StatusBadRequest,
+			expectedResp: `"code":"invalid_email_token"`,
+		},
+		{
+			name:        "Forgot password",
+			requestBody: `{"email": "test@example.com"}`,
+			expect: func(m *MockUserAuthService) {
+				m.EXPECT().RequestPasswordReset(gomock.Any(), "test@example.com").Return(nil)
+			},
+			call:         func(h *UserAuthHandler) http.HandlerFunc { return h.ForgotPassword },
+			expectedCode: http.StatusOK,
+			expectedResp: `{"status":"OK"}`,
+		},
+		{
+			name:         "Forgot password with invalid email",
+			requestBody:  `{"email": "test"}`,
+			expect:       func(m *MockUserAuthService) {},
+			call:         func(h *UserAuthHandler) http.HandlerFunc { return h.ForgotPassword },
+			expectedCode: http.StatusBadRequest,
+			expectedResp: `"code":"validation_failed"`,
+		},
+		{
+			name:        "Reset password",
+			requestBody: `{"token": "reset-token", "password": "new-password"}`,
+			expect: func(m *MockUserAuthService) {
+				m.EXPECT().ResetPassword(gomock.Any(), "reset-token", "new-password").Return(nil)
+			},
+			call:         func(h *UserAuthHandler) http.HandlerFunc { return h.ResetPassword },
+			expectedCode: http.StatusOK,
+			expectedResp: `{"status":"OK"}`,
+		},
+		{
+			name:        "Confirm email change",
+			requestBody: `{"token": "change-token"}`,
+			expect: func(m *MockUserAuthService) {
+				m.EXPECT().ConfirmEmailChange(gomock.Any(), "change-token").Return(service.ErrUserAlreadyExists)
+			},
+			call:         func(h *UserAuthHandler) http.HandlerFunc { return h.ConfirmEmailChange },
+			expectedCode: http.StatusConflict,
+			expectedResp: `"code":"user_already_exists"`,
+		},
+		{
+			name:         "Reset password with short password",
+			requestBody:  `{"token": "reset-token", "password": "1"}`,
+			expect:       func(m *MockUserAuthService) {},
+			call:         func(h *UserAuthHandler) http.HandlerFunc { return h.ResetPassword },
+			expectedCode: http.StatusBadRequest,
+			expectedResp: `"code":"validation_failed"`,
+		},
+		{
+			name:         "Reset password without token",
+			requestBody:  `{"password": "new-password"}`,
+			expect:       func(m *MockUserAuthService) {},
+			call:         func(h *UserAuthHandler) http.HandlerFunc { return h.ResetPassword },
+			expectedCode: http.StatusBadRequest,
+			expectedResp: `"code":"validation_failed"`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			mockAuthService := NewMockUserAuthService(ctrl)
+			tt.expect(mockAuthService)
+			logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+
+			handler := NewUserAuthHandler(mockAuthService, logger)
+			req, _ := http.NewRequest("POST", "/", strings.NewReader(tt.requestBody))
+			req.Header.Set("Content-Type", "application/json")
+
+			rr := httptest.NewRecorder()
+			tt.call(handler)(rr, req)
+
+			assert.Equal(t, tt.expectedCode, rr.Code)
+			assert.Contains(t, rr.Body.String(), tt.expectedResp)
+		})
+	}
+}
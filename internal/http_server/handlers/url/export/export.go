@@ -0,0 +1,192 @@
+package exporthandler
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"ChadProgress/internal/lib/api/response"
+	"ChadProgress/internal/lib/apperr"
+	"ChadProgress/internal/models"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/render"
+)
+
+// downloadTimeout replaces server write timeout for archive downloads, which may be large
+const downloadTimeout = 10 * time.Minute
+
+//go:generate mockgen -source=export.go -destination=./export_mock.go -package=exporthandler
+type ExportService interface {
+	Export(ctx context.Context, email string) (*models.ExportResult, error)
+	Status(ctx context.Context, email, id string) (*models.DataExport, error)
+	Open(ctx context.Context, token string) (*models.DataExport, io.ReadCloser, error)
+}
+
+// SettingsService tells time zone export status is shown in
+type SettingsService interface {
+	Settings(userEmail string) (*models.UserSettings, error)
+}
+
+type ExportResponse struct {
+	Status string            `json:"status"`
+	Export models.DataExport `json:"export"`
+	// DownloadURL is returned only when export is started, archive can be downloaded by it without token once ready
+	DownloadURL string `json:"download_url,omitempty"`
+}
+
+type ExportHandler struct {
+	log             *slog.Logger
+	exportService   ExportService
+	settingsService SettingsService
+}
+
+func NewExportHandler(log *slog.Logger, exportService ExportService, settingsService SettingsService) *ExportHandler {
+	return &ExportHandler{log: log, exportService: exportService, settingsService: settingsService}
+}
+
+// Export responds with personal data archive of current user. When the account is too large,
+// archive is built in background: 202 is returned with download link and Location of export status.
+func (e *ExportHandler) Export(w http.ResponseWriter, r *http.Request) {
+	const op = "handlers.url.export.Export"
+	log := e.log.With(
+		slog.String("op", op),
+	)
+	userEmail, _ := r.Context().Value(models.ContextUserKey).(string)
+	if userEmail == "" {
+		log.Error("empty email from context")
+		response.RenderError(w, r, apperr.ErrUnauthorized)
+
+		return
+	}
+
+	res, err := e.exportService.Export(r.Context(), userEmail)
+	if err != nil {
+		log.Error("failed to export personal data", slog.String("error", err.Error()))
+		response.RenderError(w, r, err)
+
+		return
+	}
+
+	if res.Export == nil {
+		setArchiveHeaders(w, time.Now(), int64(len(res.Archive)))
+		if _, err = w.Write(res.Archive); err != nil {
+			log.Error("failed to write archive", slog.String("error", err.Error()))
+		}
+
+		return
+	}
+	export, ok := e.inUserZone(w, r, log, userEmail, res.Export)
+	if !ok {
+		return
+	}
+
+	// links are built relative to the API version the request came to
+	prefix := strings.TrimSuffix(r.URL.Path, "/user/export")
+	w.Header().Set("Location", r.URL.Path+"/"+res.Export.ID)
+	render.Status(r, http.StatusAccepted)
+	render.JSON(w, r, ExportResponse{
+		Status:      "OK",
+		Export:      export,
+		DownloadURL: prefix + "/exports/" + res.Token,
+	})
+}
+
+// GetExport returns status of background export of current user
+func (e *ExportHandler) GetExport(w http.ResponseWriter, r *http.Request) {
+	const op = "handlers.url.export.GetExport"
+	log := e.log.With(
+		slog.String("op", op),
+	)
+	userEmail, _ := r.Context().Value(models.ContextUserKey).(string)
+	if userEmail == "" {
+		log.Error("empty email from context")
+		response.RenderError(w, r, apperr.ErrUnauthorized)
+
+		return
+	}
+
+	exportID := chi.URLParam(r, "id")
+	if exportID == "" {
+		response.RenderError(w, r, apperr.ErrBadRequest.WithMessage("export id is required"))
+
+		return
+	}
+
+	export, err := e.exportService.Status(r.Context(), userEmail, exportID)
+	if err != nil {
+		log.Error("failed to get export", slog.String("error", err.Error()))
+		response.RenderError(w, r, err)
+
+		return
+	}
+	resp, ok := e.inUserZone(w, r, log, userEmail, export)
+	if !ok {
+		return
+	}
+
+	render.JSON(w, r, ExportResponse{Status: "OK", Export: resp})
+}
+
+// Download serves archive of background export. It is an open endpoint: the token in the link is the credential.
+func (e *ExportHandler) Download(w http.ResponseWriter, r *http.Request) {
+	const op = "handlers.url.export.Download"
+	log := e.log.With(
+		slog.String("op", op),
+	)
+
+	export, archive, err := e.exportService.Open(r.Context(), chi.URLParam(r, "token"))
+	if err != nil {
+		log.Info("failed to open export", slog.String("error", err.Error()))
+		response.RenderError(w, r, err)
+
+		return
+	}
+	defer archive.Close()
+
+	if err = http.NewResponseController(w).SetWriteDeadline(time.Now().Add(downloadTimeout)); err != nil {
+		log.Warn("failed to extend write deadline", slog.String("error", err.Error()))
+	}
+	setArchiveHeaders(w, export.CreatedAt, export.Size)
+	if _, err = io.Copy(w, archive); err != nil {
+		log.Error("failed to send archive", slog.String("id", export.ID), slog.String("error", err.Error()))
+	}
+}
+
+// inUserZone returns copy of export with times in time zone of the user. Error response is already
+// rendered when false is returned.
+func (e *ExportHandler) inUserZone(w http.ResponseWriter, r *http.Request, log *slog.Logger, userEmail string, export *models.DataExport) (models.DataExport, bool) {
+	settings, err := e.settingsService.Settings(userEmail)
+	if err != nil {
+		log.Error("failed to get settings", slog.String("error", err.Error()))
+		response.RenderError(w, r, err)
+
+		return models.DataExport{}, false
+	}
+	loc := models.LoadLocation(settings.Timezone)
+
+	res := *export
+	res.CreatedAt = res.CreatedAt.In(loc)
+	res.ExpiresAt = res.ExpiresAt.In(loc)
+	if res.ReadyAt != nil {
+		readyAt := res.ReadyAt.In(loc)
+		res.ReadyAt = &readyAt
+	}
+
+	return res, true
+}
+
+func setArchiveHeaders(w http.ResponseWriter, createdAt time.Time, size int64) {
+	w.Header().Set("Content-Type", "application/zip")
+	w.Header().Set("Content-Disposition",
+		fmt.Sprintf(`attachment; filename="chadprogress-export-%s.zip"`, createdAt.UTC().Format("2006-01-02")))
+	if size > 0 {
+		w.Header().Set("Content-Length", strconv.FormatInt(size, 10))
+	}
+	w.Header().Set("Cache-Control", "no-store")
+}
@@ -0,0 +1,121 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: export.go
+
+// Package exporthandler is a generated GoMock package.
+package exporthandler
+
+import (
+	models "ChadProgress/internal/models"
+	context "context"
+	io "io"
+	reflect "reflect"
+
+	gomock "github.com/golang/mock/gomock"
+)
+
+// MockExportService is a mock of ExportService interface.
+type MockExportService struct {
+	ctrl     *gomock.Controller
+	recorder *MockExportServiceMockRecorder
+}
+
+// MockExportServiceMockRecorder is the mock recorder for MockExportService.
+type MockExportServiceMockRecorder struct {
+	mock *MockExportService
+}
+
+// NewMockExportService creates a new mock instance.
+func NewMockExportService(ctrl *gomock.Controller) *MockExportService {
+	mock := &MockExportService{ctrl: ctrl}
+	mock.recorder = &MockExportServiceMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockExportService) EXPECT() *MockExportServiceMockRecorder {
+	return m.recorder
+}
+
+// Export mocks base method.
+func (m *MockExportService) Export(ctx context.Context, email string) (*models.ExportResult, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Export", ctx, email)
+	ret0, _ := ret[0].(*models.ExportResult)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Export indicates an expected call of Export.
+func (mr *MockExportServiceMockRecorder) Export(ctx, email interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Export", reflect.TypeOf((*MockExportService)(nil).Export), ctx, email)
+}
+
+// Open mocks base method.
+func (m *MockExportService) Open(ctx context.Context, token string) (*models.DataExport, io.ReadCloser, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Open", ctx, token)
+	ret0, _ := ret[0].(*models.DataExport)
+	ret1, _ := ret[1].(io.ReadCloser)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// Open indicates an expected call of Open.
+func (mr *MockExportServiceMockRecorder) Open(ctx, token interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Open", reflect.TypeOf((*MockExportService)(nil).Open), ctx, token)
+}
+
+// Status mocks base method.
+func (m *MockExportService) Status(ctx context.Context, email, id string) (*models.DataExport, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Status", ctx, email, id)
+	ret0, _ := ret[0].(*models.DataExport)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Status indicates an expected call of Status.
+func (mr *MockExportServiceMockRecorder) Status(ctx, email, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Status", reflect.TypeOf((*MockExportService)(nil).Status), ctx, email, id)
+}
+
+// MockSettingsService is a mock of SettingsService interface.
+type MockSettingsService struct {
+	ctrl     *gomock.Controller
+	recorder *MockSettingsServiceMockRecorder
+}
+
+// MockSettingsServiceMockRecorder is the mock recorder for MockSettingsService.
+type MockSettingsServiceMockRecorder struct {
+	mock *MockSettingsService
+}
+
+// NewMockSettingsService creates a new mock instance.
+func NewMockSettingsService(ctrl *gomock.Controller) *MockSettingsService {
+	mock := &MockSettingsService{ctrl: ctrl}
+	mock.recorder = &MockSettingsServiceMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockSettingsService) EXPECT() *MockSettingsServiceMockRecorder {
+	return m.recorder
+}
+
+// Settings mocks base method.
+func (m *MockSettingsService) Settings(userEmail string) (*models.UserSettings, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Settings", userEmail)
+	ret0, _ := ret[0].(*models.UserSettings)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Settings indicates an expected call of Settings.
+func (mr *MockSettingsServiceMockRecorder) Settings(userEmail interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Settings", reflect.TypeOf((*MockSettingsService)(nil).Settings), userEmail)
+}
@@ -0,0 +1,153 @@
+package exporthandler
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"ChadProgress/internal/models"
+	service "ChadProgress/internal/services"
+
+	"github.com/go-chi/chi/v5"
+	gomock "github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const email = "user@example.com"
+
+func newHandler(t *testing.T) (*ExportHandler, *MockExportService, *MockSettingsService) {
+	ctrl := gomock.NewController(t)
+	mockService := NewMockExportService(ctrl)
+	mockSettings := NewMockSettingsService(ctrl)
+
+	return NewExportHandler(slog.New(slog.NewTextHandler(io.Discard, nil)), mockService, mockSettings), mockService, mockSettings
+}
+
+func newRequest(method, target string) *http.Request {
+	req := httptest.NewRequest(method, target, nil)
+
+	return req.WithContext(context.WithValue(req.Context(), models.ContextUserKey, email))
+}
+
+func TestExport(t *testing.T) {
+	t.Run("Small account gets archive", func(t *testing.T) {
+		handler, mockService, _ := newHandler(t)
+		mockService.EXPECT().Export(gomock.Any(), email).Return(&models.ExportResult{Archive: []byte("PK")}, nil)
+
+		rr := httptest.NewRecorder()
+		handler.Export(rr, newRequest(http.MethodGet, "/api/v1/user/export"))
+
+		assert.Equal(t, http.StatusOK, rr.Code)
+		assert.Equal(t, "application/zip", rr.Header().Get("Content-Type"))
+		assert.Contains(t, rr.Header().Get("Content-Disposition"), "attachment")
+		assert.Equal(t, "PK", rr.Body.String())
+	})
+
+	t.Run("Large account is exported in background", func(t *testing.T) {
+		handler, mockService, mockSettings := newHandler(t)
+		mockService.EXPECT().Export(gomock.Any(), email).Return(&models.ExportResult{
+			Export: &models.DataExport{ID: "abc", Status: models.ExportStatusPending, ExpiresAt: time.Now().Add(time.Hour)},
+			Token:  "secret",
+		}, nil)
+		mockSettings.EXPECT().Settings(email).Return(&models.UserSettings{Units: "metric", Timezone: "UTC"}, nil)
+
+		rr := httptest.NewRecorder()
+		handler.Export(rr, newRequest(http.MethodGet, "/api/v1/user/export"))
+
+		require.Equal(t, http.StatusAccepted, rr.Code)
+		assert.Equal(t, "/api/v1/user/export/abc", rr.Header().Get("Location"))
+
+		var resp ExportResponse
+		require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &resp))
+		assert.Equal(t, "/api/v1/exports/secret", resp.DownloadURL)
+		assert.Equal(t, models.ExportStatusPending, resp.Export.Status)
+	})
+
+	t.Run("Export in progress", func(t *testing.T) {
+		handler, mockService, _ := newHandler(t)
+		mockService.EXPECT().Export(gomock.Any(), email).Return(nil, service.ErrExportInProgress)
+
+		rr := httptest.NewRecorder()
+		handler.Export(rr, newRequest(http.MethodGet, "/user/export"))
+
+		assert.Equal(t, http.StatusConflict, rr.Code)
+		assert.Contains(t, rr.Body.String(), "export_in_progress")
+	})
+}
+
+func TestGetExport(t *testing.T) {
+	handler, mockService, mockSettings := newHandler(t)
+	readyAt := time.Date(2026, time.October, 19, 9, 5, 0, 0, time.UTC)
+	mockService.EXPECT().Status(gomock.Any(), email, "abc").Return(&models.DataExport{
+		ID:        "abc",
+		Status:    models.ExportStatusReady,
+		CreatedAt: time.Date(2026, time.October, 19, 9, 0, 0, 0, time.UTC),
+		ReadyAt:   &readyAt,
+		ExpiresAt: time.Date(2026, time.October, 20, 9, 0, 0, 0, time.UTC),
+	}, nil)
+	mockSettings.EXPECT().Settings(email).Return(&models.UserSettings{Units: "metric", Timezone: "Europe/Moscow"}, nil)
+
+	router := chi.NewRouter()
+	router.Get("/user/export/{id}", handler.GetExport)
+	rr := httptest.NewRecorder()
+	router.ServeHTTP(rr, newRequest(http.MethodGet, "/user/export/abc"))
+
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.Contains(t, rr.Body.String(), `"status":"ready"`)
+	assert.Contains(t, rr.Body.String(), `"created_at":"2026-10-19T12:00:00+03:00","ready_at":"2026-10-19T12:05:00+03:00","expires_at":"2026-10-20T12:00:00+03:00"`)
+	assert.NotContains(t, rr.Body.String(), "download_url")
+}
+
+func TestDownload(t *testing.T) {
+	tests := []struct {
+		name         string
+		mockError    error
+		expectedCode int
+	}{
+		{
+			name:         "Success",
+			expectedCode: http.StatusOK,
+		},
+		{
+			name:         "Expired",
+			mockError:    service.ErrExportExpired,
+			expectedCode: http.StatusGone,
+		},
+		{
+			name:         "Unknown token",
+			mockError:    service.ErrExportNotFound,
+			expectedCode: http.StatusNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handler, mockService, _ := newHandler(t)
+			if tt.mockError != nil {
+				mockService.EXPECT().Open(gomock.Any(), "secret").Return(nil, nil, tt.mockError)
+			} else {
+				export := &models.DataExport{ID: "abc", Size: 7, CreatedAt: time.Date(2026, time.October, 19, 0, 0, 0, 0, time.UTC)}
+				mockService.EXPECT().Open(gomock.Any(), "secret").Return(export, io.NopCloser(strings.NewReader("archive")), nil)
+			}
+
+			router := chi.NewRouter()
+			router.Get("/exports/{token}", handler.Download)
+			rr := httptest.NewRecorder()
+			router.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/exports/secret", nil))
+
+			assert.Equal(t, tt.expectedCode, rr.Code)
+			if tt.mockError == nil {
+				assert.Equal(t, "archive", rr.Body.String())
+				assert.Equal(t, "7", rr.Header().Get("Content-Length"))
+				assert.Contains(t, rr.Header().Get("Content-Disposition"), "chadprogress-export-2026-10-19.zip")
+			}
+		})
+	}
+}
@@ -0,0 +1,206 @@
+package reporthandler
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"time"
+
+	"ChadProgress/internal/lib/api/request"
+	"ChadProgress/internal/lib/api/response"
+	"ChadProgress/internal/lib/apperr"
+	"ChadProgress/internal/lib/units"
+	"ChadProgress/internal/models"
+
+	"github.com/go-chi/chi/v5"
+)
+
+// defaultAverageDays is window of moving average drawn on charts when average parameter is missing
+const defaultAverageDays = 7
+
+//go:generate mockgen -source=report.go -destination=./report_mock.go -package=reporthandler
+type ReportService interface {
+	MetricsExport(ctx context.Context, viewerEmail string, clientID uint, format string, from, to time.Time, view models.ReportView) (*models.ReportFile, error)
+	Summary(ctx context.Context, viewerEmail string, clientID uint, view models.ReportView) (*models.ReportFile, error)
+	Chart(ctx context.Context, viewerEmail string, clientID uint, code string, opts models.ChartOptions, view models.ReportView) (*models.ReportFile, error)
+}
+
+// SettingsService tells units and time zone reports are shown in
+type SettingsService interface {
+	Settings(userEmail string) (*models.UserSettings, error)
+}
+
+type ReportHandler struct {
+	log             *slog.Logger
+	reportService   ReportService
+	settingsService SettingsService
+}
+
+func NewReportHandler(log *slog.Logger, reportService ReportService, settingsService SettingsService) *ReportHandler {
+	return &ReportHandler{log: log, reportService: reportService, settingsService: settingsService}
+}
+
+// MetricsExport responds with metrics of client as CSV or XLSX file. Clients export their own metrics,
+// trainers those of their clients.
+func (h *ReportHandler) MetricsExport(w http.ResponseWriter, r *http.Request) {
+	const op = "handlers.url.report.MetricsExport"
+	log := h.log.With(
+		slog.String("op", op),
+	)
+
+	userEmail, clientID, view, ok := h.params(w, r, log)
+	if !ok {
+		return
+	}
+	from, to, err := request.Range(r, view.Location)
+	if err != nil {
+		response.RenderError(w, r, apperr.ErrBadRequest.WithMessage(err.Error()))
+
+		return
+	}
+	format := r.URL.Query().Get("format")
+	if format == "" {
+		format = models.ReportCSV
+	}
+
+	file, err := h.reportService.MetricsExport(r.Context(), userEmail, clientID, format, from, to, view)
+	if err != nil {
+		log.Error("failed to export metrics", slog.String("error", err.Error()))
+		response.RenderError(w, r, err)
+
+		return
+	}
+
+	writeFile(w, log, file, "attachment")
+}
+
+// Summary responds with PDF report of client progress
+func (h *ReportHandler) Summary(w http.ResponseWriter, r *http.Request) {
+	const op = "handlers.url.report.Summary"
+	log := h.log.With(
+		slog.String("op", op),
+	)
+
+	userEmail, clientID, view, ok := h.params(w, r, log)
+	if !ok {
+		return
+	}
+
+	file, err := h.reportService.Summary(r.Context(), userEmail, clientID, view)
+	if err != nil {
+		log.Error("failed to make summary report", slog.String("error", err.Error()))
+		response.RenderError(w, r, err)
+
+		return
+	}
+
+	writeFile(w, log, file, "attachment")
+}
+
+// Chart responds with SVG line chart of measurement of client, shown inline so that it can be
+// embedded by image tags of web pages and emails
+func (h *ReportHandler) Chart(w http.ResponseWriter, r *http.Request) {
+	const op = "handlers.url.report.Chart"
+	log := h.log.With(
+		slog.String("op", op),
+	)
+
+	userEmail, clientID, view, ok := h.params(w, r, log)
+	if !ok {
+		return
+	}
+	from, to, err := request.Range(r, view.Location)
+	if err != nil {
+		response.RenderError(w, r, apperr.ErrBadRequest.WithMessage(err.Error()))
+
+		return
+	}
+
+	query := r.URL.Query()
+	opts := models.ChartOptions{From: from, To: to, Theme: query.Get("theme"), AverageDays: defaultAverageDays}
+	var fields []apperr.FieldError
+	for _, p := range []struct {
+		name string
+		dst  *int
+	}{
+		{name: "width", dst: &opts.Width},
+		{name: "height", dst: &opts.Height},
+		{name: "average", dst: &opts.AverageDays},
+	} {
+		if v := query.Get(p.name); v != "" {
+			n, err := strconv.Atoi(v)
+			if err != nil {
+				fields = append(fields, apperr.FieldError{Field: p.name, Message: "must be a whole number"})
+			}
+			*p.dst = n
+		}
+	}
+	if len(fields) > 0 {
+		response.RenderError(w, r, apperr.ErrValidation.WithFields(fields...))
+
+		return
+	}
+
+	file, err := h.reportService.Chart(r.Context(), userEmail, clientID, chi.URLParam(r, "code"), opts, view)
+	if err != nil {
+		log.Error("failed to render chart", slog.String("error", err.Error()))
+		response.RenderError(w, r, err)
+
+		return
+	}
+
+	writeFile(w, log, file, "inline")
+}
+
+// params returns current user, client id of the path and how the user sees reports: in unit system
+// requested or chosen by them and in their time zone. Error is rendered if false is returned.
+func (h *ReportHandler) params(w http.ResponseWriter, r *http.Request, log *slog.Logger) (string, uint, models.ReportView, bool) {
+	userEmail, _ := r.Context().Value(models.ContextUserKey).(string)
+	if userEmail == "" {
+		log.Error("empty email from context")
+		response.RenderError(w, r, apperr.ErrUnauthorized)
+
+		return "", 0, models.ReportView{}, false
+	}
+
+	clientID, err := strconv.ParseUint(chi.URLParam(r, "id"), 10, 32)
+	if err != nil || clientID == 0 {
+		response.RenderError(w, r, apperr.ErrBadRequest.WithMessage("id must be a positive number"))
+
+		return "", 0, models.ReportView{}, false
+	}
+
+	requested, err := units.FromRequest(r)
+	if err != nil {
+		response.RenderError(w, r, apperr.ErrValidation.WithFields(apperr.FieldError{Field: units.Param, Message: err.Error()}))
+
+		return "", 0, models.ReportView{}, false
+	}
+	settings, err := h.settingsService.Settings(userEmail)
+	if err != nil {
+		log.Error("failed to get settings", slog.String("error", err.Error()))
+		response.RenderError(w, r, err)
+
+		return "", 0, models.ReportView{}, false
+	}
+
+	view := models.ReportView{Units: string(requested), Location: models.LoadLocation(settings.Timezone)}
+	if view.Units == "" {
+		view.Units = settings.Units
+	}
+
+	return userEmail, uint(clientID), view, true
+}
+
+// writeFile writes report, disposition tells whether it is downloaded as attachment or shown inline
+func writeFile(w http.ResponseWriter, log *slog.Logger, file *models.ReportFile, disposition string) {
+	w.Header().Set("Content-Type", file.ContentType)
+	w.Header().Set("Content-Disposition", fmt.Sprintf(`%s; filename="%s"`, disposition, file.Name))
+	w.Header().Set("Content-Length", strconv.Itoa(len(file.Content)))
+	w.Header().Set("Cache-Control", "no-store")
+	if _, err := w.Write(file.Content); err != nil {
+		log.Error("failed to write report", slog.String("error", err.Error()))
+	}
+}
@@ -0,0 +1,120 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: report.go
+
+// Package reporthandler is a generated GoMock package.
+package reporthandler
+
+import (
+	models "ChadProgress/internal/models"
+	context "context"
+	reflect "reflect"
+	time "time"
+
+	gomock "github.com/golang/mock/gomock"
+)
+
+// MockReportService is a mock of ReportService interface.
+type MockReportService struct {
+	ctrl     *gomock.Controller
+	recorder *MockReportServiceMockRecorder
+}
+
+// MockReportServiceMockRecorder is the mock recorder for MockReportService.
+type MockReportServiceMockRecorder struct {
+	mock *MockReportService
+}
+
+// NewMockReportService creates a new mock instance.
+func NewMockReportService(ctrl *gomock.Controller) *MockReportService {
+	mock := &MockReportService{ctrl: ctrl}
+	mock.recorder = &MockReportServiceMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockReportService) EXPECT() *MockReportServiceMockRecorder {
+	return m.recorder
+}
+
+// Chart mocks base method.
+func (m *MockReportService) Chart(ctx context.Context, viewerEmail string, clientID uint, code string, opts models.ChartOptions, view models.ReportView) (*models.ReportFile, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Chart", ctx, viewerEmail, clientID, code, opts, view)
+	ret0, _ := ret[0].(*models.ReportFile)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Chart indicates an expected call of Chart.
+func (mr *MockReportServiceMockRecorder) Chart(ctx, viewerEmail, clientID, code, opts, view interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Chart", reflect.TypeOf((*MockReportService)(nil).Chart), ctx, viewerEmail, clientID, code, opts, view)
+}
+
+// MetricsExport mocks base method.
+func (m *MockReportService) MetricsExport(ctx context.Context, viewerEmail string, clientID uint, format string, from, to time.Time, view models.ReportView) (*models.ReportFile, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "MetricsExport", ctx, viewerEmail, clientID, format, from, to, view)
+	ret0, _ := ret[0].(*models.ReportFile)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// MetricsExport indicates an expected call of MetricsExport.
+func (mr *MockReportServiceMockRecorder) MetricsExport(ctx, viewerEmail, clientID, format, from, to, view interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "MetricsExport", reflect.TypeOf((*MockReportService)(nil).MetricsExport), ctx, viewerEmail, clientID, format, from, to, view)
+}
+
+// Summary mocks base method.
+func (m *MockReportService) Summary(ctx context.Context, viewerEmail string, clientID uint, view models.ReportView) (*models.ReportFile, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Summary", ctx, viewerEmail, clientID, view)
+	ret0, _ := ret[0].(*models.ReportFile)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Summary indicates an expected call of Summary.
+func (mr *MockReportServiceMockRecorder) Summary(ctx, viewerEmail, clientID, view interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Summary", reflect.TypeOf((*MockReportService)(nil).Summary), ctx, viewerEmail, clientID, view)
+}
+
+// MockSettingsService is a mock of SettingsService interface.
+type MockSettingsService struct {
+	ctrl     *gomock.Controller
+	recorder *MockSettingsServiceMockRecorder
+}
+
+// MockSettingsServiceMockRecorder is the mock recorder for MockSettingsService.
+type MockSettingsServiceMockRecorder struct {
+	mock *MockSettingsService
+}
+
+// NewMockSettingsService creates a new mock instance.
+func NewMockSettingsService(ctrl *gomock.Controller) *MockSettingsService {
+	mock := &MockSettingsService{ctrl: ctrl}
+	mock.recorder = &MockSettingsServiceMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockSettingsService) EXPECT() *MockSettingsServiceMockRecorder {
+	return m.recorder
+}
+
+// Settings mocks base method.
+func (m *MockSettingsService) Settings(userEmail string) (*models.UserSettings, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Settings", userEmail)
+	ret0, _ := ret[0].(*models.UserSettings)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Settings indicates an expected call of Settings.
+func (mr *MockSettingsServiceMockRecorder) Settings(userEmail interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Settings", reflect.TypeOf((*MockSettingsService)(nil).Settings), userEmail)
+}
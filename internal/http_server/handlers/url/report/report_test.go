@@ -0,0 +1,187 @@
+package reporthandler
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"ChadProgress/internal/lib/apperr"
+	"ChadProgress/internal/lib/units"
+	"ChadProgress/internal/models"
+
+	"github.com/go-chi/chi/v5"
+	gomock "github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+)
+
+const email = "trainer@example.com"
+
+func newRouter(t *testing.T) (http.Handler, *MockReportService, *MockSettingsService) {
+	ctrl := gomock.NewController(t)
+	reports := NewMockReportService(ctrl)
+	settings := NewMockSettingsService(ctrl)
+	handler := NewReportHandler(slog.New(slog.NewTextHandler(io.Discard, nil)), reports, settings)
+
+	router := chi.NewRouter()
+	router.Get("/user/reports/clients/{id}/metrics", handler.MetricsExport)
+	router.Get("/user/reports/clients/{id}/summary", handler.Summary)
+	router.Get("/user/reports/clients/{id}/charts/{code}", handler.Chart)
+
+	return router, reports, settings
+}
+
+func newRequest(target string) *http.Request {
+	req := httptest.NewRequest(http.MethodGet, target, nil)
+
+	return req.WithContext(context.WithValue(req.Context(), models.ContextUserKey, email))
+}
+
+func TestMetricsExport(t *testing.T) {
+	moscow := models.LoadLocation("Europe/Moscow")
+	file := &models.ReportFile{Name: "chadprogress-metrics-3-2026-10-19.xlsx", ContentType: models.ContentTypeXLSX, Content: []byte("PK")}
+
+	tests := []struct {
+		name         string
+		target       string
+		mock         func(reports *MockReportService, settings *MockSettingsService)
+		expectedCode int
+	}{
+		{
+			name:   "Dates are days of user",
+			target: "/user/reports/clients/3/metrics?format=xlsx&from=2026-10-01&to=2026-10-18&units=imperial",
+			mock: func(reports *MockReportService, settings *MockSettingsService) {
+				settings.EXPECT().Settings(email).Return(&models.UserSettings{Units: "metric", Timezone: "Europe/Moscow"}, nil)
+				reports.EXPECT().MetricsExport(gomock.Any(), email, uint(3), models.ReportXLSX,
+					time.Date(2026, time.October, 1, 0, 0, 0, 0, moscow), time.Date(2026, time.October, 19, 0, 0, 0, 0, moscow),
+					models.ReportView{Units: string(units.Imperial), Location: moscow}).Return(file, nil)
+			},
+			expectedCode: http.StatusOK,
+		},
+		{
+			name:   "Trainer of another client",
+			target: "/user/reports/clients/4/metrics",
+			mock: func(reports *MockReportService, settings *MockSettingsService) {
+				settings.EXPECT().Settings(email).Return(&models.UserSettings{Units: "metric", Timezone: "UTC"}, nil)
+				reports.EXPECT().MetricsExport(gomock.Any(), email, uint(4), models.ReportCSV, time.Time{}, time.Time{}, gomock.Any()).
+					Return(nil, apperr.ErrForbidden)
+			},
+			expectedCode: http.StatusForbidden,
+		},
+		{
+			name:         "Malformed id",
+			target:       "/user/reports/clients/me/metrics",
+			mock:         func(*MockReportService, *MockSettingsService) {},
+			expectedCode: http.StatusBadRequest,
+		},
+		{
+			name:   "Malformed from",
+			target: "/user/reports/clients/3/metrics?from=yesterday",
+			mock: func(_ *MockReportService, settings *MockSettingsService) {
+				settings.EXPECT().Settings(email).Return(&models.UserSettings{Units: "metric", Timezone: "UTC"}, nil)
+			},
+			expectedCode: http.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			router, reports, settings := newRouter(t)
+			tt.mock(reports, settings)
+
+			rr := httptest.NewRecorder()
+			router.ServeHTTP(rr, newRequest(tt.target))
+
+			assert.Equal(t, tt.expectedCode, rr.Code)
+			if tt.expectedCode == http.StatusOK {
+				assert.Equal(t, models.ContentTypeXLSX, rr.Header().Get("Content-Type"))
+				assert.Equal(t, `attachment; filename="chadprogress-metrics-3-2026-10-19.xlsx"`, rr.Header().Get("Content-Disposition"))
+				assert.Equal(t, "PK", rr.Body.String())
+			}
+		})
+	}
+}
+
+func TestSummary(t *testing.T) {
+	router, reports, settings := newRouter(t)
+	settings.EXPECT().Settings(email).Return(&models.UserSettings{Units: "imperial", Timezone: "UTC"}, nil)
+	reports.EXPECT().Summary(gomock.Any(), email, uint(3), models.ReportView{Units: "imperial", Location: time.UTC}).
+		Return(&models.ReportFile{Name: "summary.pdf", ContentType: models.ContentTypePDF, Content: []byte("%PDF-1.3")}, nil)
+
+	rr := httptest.NewRecorder()
+	router.ServeHTTP(rr, newRequest("/user/reports/clients/3/summary"))
+
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.Equal(t, models.ContentTypePDF, rr.Header().Get("Content-Type"))
+	assert.Equal(t, "8", rr.Header().Get("Content-Length"))
+	assert.Equal(t, "%PDF-1.3", rr.Body.String())
+}
+
+func TestChart(t *testing.T) {
+	svg := &models.ReportFile{Name: "chadprogress-weight-3-2026-10-19.svg", ContentType: models.ContentTypeSVG, Content: []byte("<svg/>")}
+
+	tests := []struct {
+		name         string
+		target       string
+		mock         func(reports *MockReportService, settings *MockSettingsService)
+		expectedCode int
+	}{
+		{
+			name:   "Options",
+			target: "/user/reports/clients/3/charts/weight?from=2026-07-01&width=800&height=400&theme=dark&average=14",
+			mock: func(reports *MockReportService, settings *MockSettingsService) {
+				settings.EXPECT().Settings(email).Return(&models.UserSettings{Units: "imperial", Timezone: "UTC"}, nil)
+				reports.EXPECT().Chart(gomock.Any(), email, uint(3), "weight", models.ChartOptions{
+					From: time.Date(2026, time.July, 1, 0, 0, 0, 0, time.UTC), Width: 800, Height: 400, Theme: "dark", AverageDays: 14,
+				}, models.ReportView{Units: "imperial", Location: time.UTC}).Return(svg, nil)
+			},
+			expectedCode: http.StatusOK,
+		},
+		{
+			name:   "Weekly average by default",
+			target: "/user/reports/clients/3/charts/bmi",
+			mock: func(reports *MockReportService, settings *MockSettingsService) {
+				settings.EXPECT().Settings(email).Return(&models.UserSettings{Units: "metric", Timezone: "UTC"}, nil)
+				reports.EXPECT().Chart(gomock.Any(), email, uint(3), "bmi", models.ChartOptions{AverageDays: 7}, gomock.Any()).Return(svg, nil)
+			},
+			expectedCode: http.StatusOK,
+		},
+		{
+			name:   "Malformed size",
+			target: "/user/reports/clients/3/charts/weight?width=wide",
+			mock: func(_ *MockReportService, settings *MockSettingsService) {
+				settings.EXPECT().Settings(email).Return(&models.UserSettings{Units: "metric", Timezone: "UTC"}, nil)
+			},
+			expectedCode: http.StatusBadRequest,
+		},
+		{
+			name:   "Client of another trainer",
+			target: "/user/reports/clients/4/charts/weight",
+			mock: func(reports *MockReportService, settings *MockSettingsService) {
+				settings.EXPECT().Settings(email).Return(&models.UserSettings{Units: "metric", Timezone: "UTC"}, nil)
+				reports.EXPECT().Chart(gomock.Any(), email, uint(4), "weight", gomock.Any(), gomock.Any()).Return(nil, apperr.ErrForbidden)
+			},
+			expectedCode: http.StatusForbidden,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			router, reports, settings := newRouter(t)
+			tt.mock(reports, settings)
+
+			rr := httptest.NewRecorder()
+			router.ServeHTTP(rr, newRequest(tt.target))
+
+			assert.Equal(t, tt.expectedCode, rr.Code)
+			if tt.expectedCode == http.StatusOK {
+				assert.Equal(t, models.ContentTypeSVG, rr.Header().Get("Content-Type"))
+				assert.Equal(t, `inline; filename="chadprogress-weight-3-2026-10-19.svg"`, rr.Header().Get("Content-Disposition"))
+				assert.Equal(t, "<svg/>", rr.Body.String())
+			}
+		})
+	}
+}
@@ -0,0 +1,162 @@
+package sessionhandler
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"ChadProgress/internal/lib/api/response"
+	"ChadProgress/internal/lib/apperr"
+	"ChadProgress/internal/models"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/render"
+)
+
+//go:generate mockgen -source=session.go -destination=./session_mock.go -package=sessionhandler
+type SessionService interface {
+	Sessions(ctx context.Context, email string) ([]models.Session, error)
+	RevokeSession(ctx context.Context, email, sessionID string) error
+	RevokeOtherSessions(ctx context.Context, email, currentID string) (int64, error)
+}
+
+// SettingsService tells time zone sessions are shown in
+type SettingsService interface {
+	Settings(userEmail string) (*models.UserSettings, error)
+}
+
+type SessionResponse struct {
+	ID         string    `json:"id"`
+	UserAgent  string    `json:"user_agent"`
+	IP         string    `json:"ip"`
+	CreatedAt  time.Time `json:"created_at"`
+	LastSeenAt time.Time `json:"last_seen_at"`
+	Current    bool      `json:"current"`
+}
+
+type GetSessionsResponse struct {
+	Status   string            `json:"status"`
+	Sessions []SessionResponse `json:"sessions"`
+}
+
+type RevokeSessionsResponse struct {
+	Status  string `json:"status"`
+	Revoked int64  `json:"revoked"`
+}
+
+type SessionHandler struct {
+	log             *slog.Logger
+	sessionService  SessionService
+	settingsService SettingsService
+}
+
+func NewSessionHandler(log *slog.Logger, sessionService SessionService, settingsService SettingsService) *SessionHandler {
+	return &SessionHandler{log: log, sessionService: sessionService, settingsService: settingsService}
+}
+
+// GetSessions lists devices the user is signed in from
+func (s *SessionHandler) GetSessions(w http.ResponseWriter, r *http.Request) {
+	const op = "handlers.url.session.GetSessions"
+	log := s.log.With(
+		slog.String("op", op),
+	)
+	userEmail, _ := r.Context().Value(models.ContextUserKey).(string)
+	if userEmail == "" {
+		log.Error("empty email from context")
+		response.RenderError(w, r, apperr.ErrUnauthorized)
+
+		return
+	}
+	currentID, _ := r.Context().Value(models.ContextSessionKey).(string)
+
+	sessions, err := s.sessionService.Sessions(r.Context(), userEmail)
+	if err != nil {
+		log.Error("failed to get sessions", slog.String("error", err.Error()))
+		response.RenderError(w, r, err)
+
+		return
+	}
+	settings, err := s.settingsService.Settings(userEmail)
+	if err != nil {
+		log.Error("failed to get settings", slog.String("error", err.Error()))
+		response.RenderError(w, r, err)
+
+		return
+	}
+	loc := models.LoadLocation(settings.Timezone)
+
+	resp := GetSessionsResponse{
+		Status:   "OK",
+		Sessions: make([]SessionResponse, 0, len(sessions)),
+	}
+	for _, session := range sessions {
+		resp.Sessions = append(resp.Sessions, SessionResponse{
+			ID:         session.ID,
+			UserAgent:  session.UserAgent,
+			IP:         session.IP,
+			CreatedAt:  session.CreatedAt.In(loc),
+			LastSeenAt: session.LastSeenAt.In(loc),
+			Current:    session.ID == currentID,
+		})
+	}
+
+	render.JSON(w, r, resp)
+}
+
+// RevokeSession signs the user out of one session, which may be the current one
+func (s *SessionHandler) RevokeSession(w http.ResponseWriter, r *http.Request) {
+	const op = "handlers.url.session.RevokeSession"
+	log := s.log.With(
+		slog.String("op", op),
+	)
+	userEmail, _ := r.Context().Value(models.ContextUserKey).(string)
+	if userEmail == "" {
+		log.Error("empty email from context")
+		response.RenderError(w, r, apperr.ErrUnauthorized)
+
+		return
+	}
+
+	sessionID := chi.URLParam(r, "id")
+	if sessionID == "" {
+		response.RenderError(w, r, apperr.ErrBadRequest.WithMessage("session id is required"))
+
+		return
+	}
+
+	if err := s.sessionService.RevokeSession(r.Context(), userEmail, sessionID); err != nil {
+		log.Error("failed to revoke session", slog.String("error", err.Error()))
+		response.RenderError(w, r, err)
+
+		return
+	}
+
+	render.JSON(w, r, response.OK())
+}
+
+// RevokeOtherSessions signs the user out everywhere except the current session
+func (s *SessionHandler) RevokeOtherSessions(w http.ResponseWriter, r *http.Request) {
+	const op = "handlers.url.session.RevokeOtherSessions"
+	log := s.log.With(
+		slog.String("op", op),
+	)
+	userEmail, _ := r.Context().Value(models.ContextUserKey).(string)
+	if userEmail == "" {
+		log.Error("empty email from context")
+		response.RenderError(w, r, apperr.ErrUnauthorized)
+
+		return
+	}
+	currentID, _ := r.Context().Value(models.ContextSessionKey).(string)
+
+	n, err := s.sessionService.RevokeOtherSessions(r.Context(), userEmail, currentID)
+	if err != nil {
+		log.Error("failed to revoke sessions", slog.String("error", err.Error()))
+		response.RenderError(w, r, err)
+
+		return
+	}
+
+	render.JSON(w, r, RevokeSessionsResponse{Status: "OK", Revoked: n})
+}
@@ -0,0 +1,118 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: session.go
+
+// Package sessionhandler is a generated GoMock package.
+package sessionhandler
+
+import (
+	models "ChadProgress/internal/models"
+	context "context"
+	reflect "reflect"
+
+	gomock "github.com/golang/mock/gomock"
+)
+
+// MockSessionService is a mock of SessionService interface.
+type MockSessionService struct {
+	ctrl     *gomock.Controller
+	recorder *MockSessionServiceMockRecorder
+}
+
+// MockSessionServiceMockRecorder is the mock recorder for MockSessionService.
+type MockSessionServiceMockRecorder struct {
+	mock *MockSessionService
+}
+
+// NewMockSessionService creates a new mock instance.
+func NewMockSessionService(ctrl *gomock.Controller) *MockSessionService {
+	mock := &MockSessionService{ctrl: ctrl}
+	mock.recorder = &MockSessionServiceMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockSessionService) EXPECT() *MockSessionServiceMockRecorder {
+	return m.recorder
+}
+
+// RevokeOtherSessions mocks base method.
+func (m *MockSessionService) RevokeOtherSessions(ctx context.Context, email, currentID string) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RevokeOtherSessions", ctx, email, currentID)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RevokeOtherSessions indicates an expected call of RevokeOtherSessions.
+func (mr *MockSessionServiceMockRecorder) RevokeOtherSessions(ctx, email, currentID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RevokeOtherSessions", reflect.TypeOf((*MockSessionService)(nil).RevokeOtherSessions), ctx, email, currentID)
+}
+
+// RevokeSession mocks base method.
+func (m *MockSessionService) RevokeSession(ctx context.Context, email, sessionID string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RevokeSession", ctx, email, sessionID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RevokeSession indicates an expected call of RevokeSession.
+func (mr *MockSessionServiceMockRecorder) RevokeSession(ctx, email, sessionID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RevokeSession", reflect.TypeOf((*MockSessionService)(nil).RevokeSession), ctx, email, sessionID)
+}
+
+// Sessions mocks base method.
+func (m *MockSessionService) Sessions(ctx context.Context, email string) ([]models.Session, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Sessions", ctx, email)
+	ret0, _ := ret[0].([]models.Session)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Sessions indicates an expected call of Sessions.
+func (mr *MockSessionServiceMockRecorder) Sessions(ctx, email interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Sessions", reflect.TypeOf((*MockSessionService)(nil).Sessions), ctx, email)
+}
+
+// MockSettingsService is a mock of SettingsService interface.
+type MockSettingsService struct {
+	ctrl     *gomock.Controller
+	recorder *MockSettingsServiceMockRecorder
+}
+
+// MockSettingsServiceMockRecorder is the mock recorder for MockSettingsService.
+type MockSettingsServiceMockRecorder struct {
+	mock *MockSettingsService
+}
+
+// NewMockSettingsService creates a new mock instance.
+func NewMockSettingsService(ctrl *gomock.Controller) *MockSettingsService {
+	mock := &MockSettingsService{ctrl: ctrl}
+	mock.recorder = &MockSettingsServiceMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockSettingsService) EXPECT() *MockSettingsServiceMockRecorder {
+	return m.recorder
+}
+
+// Settings mocks base method.
+func (m *MockSettingsService) Settings(userEmail string) (*models.UserSettings, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Settings", userEmail)
+	ret0, _ := ret[0].(*models.UserSettings)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Settings indicates an expected call of Settings.
+func (mr *MockSettingsServiceMockRecorder) Settings(userEmail interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Settings", reflect.TypeOf((*MockSettingsService)(nil).Settings), userEmail)
+}
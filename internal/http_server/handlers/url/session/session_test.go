@@ -0,0 +1,98 @@
+package sessionhandler
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"ChadProgress/internal/models"
+	service "ChadProgress/internal/services"
+
+	"github.com/go-chi/chi/v5"
+	gomock "github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+)
+
+const email = "user@example.com"
+
+func newRequest(method, target string) *http.Request {
+	req := httptest.NewRequest(method, target, nil)
+	ctx := context.WithValue(req.Context(), models.ContextUserKey, email)
+	ctx = context.WithValue(ctx, models.ContextSessionKey, "current")
+
+	return req.WithContext(ctx)
+}
+
+func TestGetSessions(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	mockService := NewMockSessionService(ctrl)
+	mockSettings := NewMockSettingsService(ctrl)
+	created := time.Date(2026, time.October, 18, 21, 30, 0, 0, time.UTC)
+	seen := time.Date(2026, time.October, 19, 12, 0, 0, 0, time.UTC)
+	mockService.EXPECT().Sessions(gomock.Any(), email).Return([]models.Session{
+		{ID: "current", UserAgent: "curl/8.0", IP: "10.0.0.1", CreatedAt: created, LastSeenAt: seen},
+		{ID: "other", UserAgent: "Firefox", IP: "10.0.0.2", CreatedAt: seen, LastSeenAt: seen},
+	}, nil)
+	mockSettings.EXPECT().Settings(email).Return(&models.UserSettings{Units: "metric", Timezone: "Europe/Moscow"}, nil)
+
+	handler := NewSessionHandler(slog.New(slog.NewTextHandler(io.Discard, nil)), mockService, mockSettings)
+	rr := httptest.NewRecorder()
+	handler.GetSessions(rr, newRequest(http.MethodGet, "/user/sessions"))
+
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.Contains(t, rr.Body.String(), `"id":"current","user_agent":"curl/8.0","ip":"10.0.0.1"`)
+	assert.Contains(t, rr.Body.String(), `"created_at":"2026-10-19T00:30:00+03:00","last_seen_at":"2026-10-19T15:00:00+03:00","current":true`)
+	assert.Contains(t, rr.Body.String(), `"id":"other"`)
+}
+
+func TestRevokeSession(t *testing.T) {
+	tests := []struct {
+		name         string
+		mockError    error
+		expectedCode int
+	}{
+		{
+			name:         "Success",
+			expectedCode: http.StatusOK,
+		},
+		{
+			name:         "Session of another user",
+			mockError:    service.ErrSessionNotFound,
+			expectedCode: http.StatusNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			mockService := NewMockSessionService(ctrl)
+			mockService.EXPECT().RevokeSession(gomock.Any(), email, "other").Return(tt.mockError)
+
+			handler := NewSessionHandler(slog.New(slog.NewTextHandler(io.Discard, nil)), mockService, NewMockSettingsService(ctrl))
+			router := chi.NewRouter()
+			router.Delete("/user/sessions/{id}", handler.RevokeSession)
+
+			rr := httptest.NewRecorder()
+			router.ServeHTTP(rr, newRequest(http.MethodDelete, "/user/sessions/other"))
+
+			assert.Equal(t, tt.expectedCode, rr.Code)
+		})
+	}
+}
+
+func TestRevokeOtherSessions(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	mockService := NewMockSessionService(ctrl)
+	mockService.EXPECT().RevokeOtherSessions(gomock.Any(), email, "current").Return(int64(2), nil)
+
+	handler := NewSessionHandler(slog.New(slog.NewTextHandler(io.Discard, nil)), mockService, NewMockSettingsService(ctrl))
+	rr := httptest.NewRecorder()
+	handler.RevokeOtherSessions(rr, newRequest(http.MethodDelete, "/user/sessions"))
+
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.JSONEq(t, `{"status":"OK","revoked":2}`, rr.Body.String())
+}
@@ -0,0 +1,186 @@
+package userhandler
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"ChadProgress/internal/lib/apperr"
+	"ChadProgress/internal/models"
+)
+
+const (
+	// maxImportSize limits size of imported file, ten years of daily weigh-ins take well under it
+	maxImportSize = 10 << 20
+	maxImportRows = 20000
+	// measuredAtColumn is the column of CSV file with time of measurement
+	measuredAtColumn = "measured-at"
+)
+
+// csvOptions tell how to read CSV file of metrics
+type csvOptions struct {
+	delimiter rune
+	// columns map lowercased headers to measurement codes or measured-at. Only mapped columns are read
+	// when it is set, otherwise headers are taken as codes. Columns named measured-at or date hold times
+	// unless they are mapped.
+	columns map[string]string
+}
+
+// csvOptionsFromQuery reads delimiter parameter and column parameters of form "Header:code"
+func csvOptionsFromQuery(q url.Values) (csvOptions, error) {
+	opts := csvOptions{delimiter: ','}
+	if d := q.Get("delimiter"); d != "" {
+		r, size := utf8.DecodeRuneInString(d)
+		if size != len(d) || r == '"' || r == '\r' || r == '\n' {
+			return csvOptions{}, fmt.Errorf("delimiter must be a single character")
+		}
+		opts.delimiter = r
+	}
+
+	for _, c := range q["column"] {
+		i := strings.LastIndex(c, ":")
+		if i <= 0 || i == len(c)-1 {
+			return csvOptions{}, fmt.Errorf("column %q must be Header:code", c)
+		}
+		if opts.columns == nil {
+			opts.columns = make(map[string]string)
+		}
+		opts.columns[strings.ToLower(strings.TrimSpace(c[:i]))] = strings.TrimSpace(c[i+1:])
+	}
+
+	return opts, nil
+}
+
+// readMetricsCSV reads metrics of CSV file with header. Times without time zone are taken in loc. Values
+// which can not be read are reported as errors of their rows, error is returned if the file is malformed.
+func readMetricsCSV(body io.Reader, opts csvOptions, loc *time.Location) ([]models.MetricImportRow, error) {
+	reader := csv.NewReader(body)
+	reader.Comma = opts.delimiter
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if errors.Is(err, io.EOF) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read header: %w", err)
+	}
+
+	// codes of columns by their position, empty for skipped ones
+	codes := make([]string, len(header))
+	timeColumn := -1
+	found := make(map[string]bool, len(header))
+	for i, h := range header {
+		// spreadsheets start UTF-8 files with byte order mark
+		name := strings.ToLower(strings.TrimSpace(strings.TrimPrefix(h, "\ufeff")))
+		found[name] = true
+		code := name
+		if name == "date" {
+			code = measuredAtColumn
+		}
+		if mapped, ok := opts.columns[name]; ok {
+			code = mapped
+		} else if opts.columns != nil && code != measuredAtColumn {
+			code = ""
+		}
+		if code == measuredAtColumn {
+			timeColumn = i
+			continue
+		}
+		codes[i] = code
+	}
+	for name := range opts.columns {
+		if !found[name] {
+			return nil, fmt.Errorf("column %q is not in the file", name)
+		}
+	}
+	if timeColumn < 0 {
+		return nil, fmt.Errorf("file has no %s column", measuredAtColumn)
+	}
+
+	var rows []models.MetricImportRow
+	for {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if len(rows) == maxImportRows {
+			return nil, fmt.Errorf("file has more than %d rows", maxImportRows)
+		}
+		var parseErr *csv.ParseError
+		if errors.As(err, &parseErr) && errors.Is(err, csv.ErrFieldCount) {
+			rows = append(rows, models.MetricImportRow{Row: parseErr.StartLine, Errors: []apperr.FieldError{
+				{Field: "row", Message: fmt.Sprintf("has %d fields, header has %d", len(record), len(header))},
+			}})
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		line, _ := reader.FieldPos(0)
+		rows = append(rows, csvRow(line, record, codes, timeColumn, loc))
+	}
+
+	return rows, nil
+}
+
+func csvRow(line int, record, codes []string, timeColumn int, loc *time.Location) models.MetricImportRow {
+	row := models.MetricImportRow{Row: line, Measurements: make(models.MeasurementValues, len(codes))}
+
+	measuredAt, err := models.ParseCustomTime(strings.TrimSpace(record[timeColumn]))
+	if err != nil {
+		row.Errors = append(row.Errors, apperr.FieldError{Field: measuredAtColumn, Message: "must be RFC 3339 time or date"})
+	}
+	row.MeasuredAt = measuredAt.In(loc)
+
+	for i, code := range codes {
+		value := strings.TrimSpace(record[i])
+		if code == "" || value == "" {
+			continue
+		}
+		// spreadsheets of some locales write decimal comma
+		v, err := strconv.ParseFloat(strings.Replace(value, ",", ".", 1), 64)
+		if err != nil {
+			row.Errors = append(row.Errors, apperr.FieldError{Field: "measurements." + code, Message: "must be a number"})
+			continue
+		}
+		row.Measurements[code] = v
+	}
+
+	return row
+}
+
+// readMetricsJSON reads JSON array of metrics in format of AddMetricsRequest. Times without time zone are
+// taken in loc. Entries which can not be read are reported as errors of their rows.
+func readMetricsJSON(body io.Reader, loc *time.Location) ([]models.MetricImportRow, error) {
+	var entries []json.RawMessage
+	if err := json.NewDecoder(body).Decode(&entries); err != nil {
+		return nil, fmt.Errorf("file must be JSON array of metrics: %w", err)
+	}
+	if len(entries) > maxImportRows {
+		return nil, fmt.Errorf("file has more than %d rows", maxImportRows)
+	}
+
+	rows := make([]models.MetricImportRow, 0, len(entries))
+	for i, entry := range entries {
+		row := models.MetricImportRow{Row: i + 1}
+		var req AddMetricsRequest
+		if err := json.Unmarshal(entry, &req); err != nil {
+			row.Errors = []apperr.FieldError{{Field: "row", Message: "must be a metric with numeric measurements and RFC 3339 measured-at"}}
+		} else {
+			row.Measurements = req.values()
+			row.MeasuredAt = req.MeasuredAt.In(loc)
+		}
+		rows = append(rows, row)
+	}
+
+	return rows, nil
+}
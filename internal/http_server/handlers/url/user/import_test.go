@@ -0,0 +1,115 @@
+package userhandler
+
+import (
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"ChadProgress/internal/lib/apperr"
+	"ChadProgress/internal/models"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReadMetricsCSV(t *testing.T) {
+	moscow := time.FixedZone("MSK", 3*60*60)
+	oct19 := time.Date(2026, time.October, 19, 0, 0, 0, 0, moscow)
+
+	tests := []struct {
+		name     string
+		query    string
+		file     string
+		expected []models.MetricImportRow
+		err      string
+	}{
+		{
+			name: "Headers are codes",
+			file: "\ufeffDate,Weight,waist\n2026-10-19,80.5,\n2026-10-20T08:00:00Z,80,84\n",
+			expected: []models.MetricImportRow{
+				{Row: 2, Measurements: models.MeasurementValues{"weight": 80.5}, MeasuredAt: oct19},
+				{Row: 3, Measurements: models.MeasurementValues{"weight": 80, "waist": 84}, MeasuredAt: time.Date(2026, time.October, 20, 8, 0, 0, 0, time.UTC)},
+			},
+		},
+		{
+			name:  "Mapped columns of spreadsheet",
+			query: "column=Day:measured-at&column=Body+weight+(kg):weight&delimiter=%3B",
+			file:  "Day;Body weight (kg);Notes\n2026-10-19;80,5;after holidays\n",
+			expected: []models.MetricImportRow{
+				{Row: 2, Measurements: models.MeasurementValues{"weight": 80.5}, MeasuredAt: oct19},
+			},
+		},
+		{
+			name: "Values that can not be read are errors of their rows",
+			file: "measured-at,weight\nyesterday,80\n2026-10-19,heavy\n2026-10-19\n",
+			expected: []models.MetricImportRow{
+				{Row: 2, Measurements: models.MeasurementValues{"weight": 80}, Errors: []apperr.FieldError{{Field: "measured-at", Message: "must be RFC 3339 time or date"}}},
+				{Row: 3, Measurements: models.MeasurementValues{}, MeasuredAt: oct19, Errors: []apperr.FieldError{{Field: "measurements.weight", Message: "must be a number"}}},
+				{Row: 4, Errors: []apperr.FieldError{{Field: "row", Message: "has 1 fields, header has 2"}}},
+			},
+		},
+		{
+			name: "No time column",
+			file: "weight\n80\n",
+			err:  "file has no measured-at column",
+		},
+		{
+			name:  "Mapped column is missing",
+			query: "column=Weight:weight",
+			file:  "date,mass\n2026-10-19,80\n",
+			err:   `column "weight" is not in the file`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			q, err := url.ParseQuery(tt.query)
+			require.NoError(t, err)
+			opts, err := csvOptionsFromQuery(q)
+			require.NoError(t, err)
+
+			rows, err := readMetricsCSV(strings.NewReader(tt.file), opts, moscow)
+			if tt.err != "" {
+				assert.EqualError(t, err, tt.err)
+				return
+			}
+			require.NoError(t, err)
+			require.Len(t, rows, len(tt.expected))
+			for i, row := range rows {
+				assert.True(t, tt.expected[i].MeasuredAt.Equal(row.MeasuredAt), "row %d measured at %s", row.Row, row.MeasuredAt)
+				row.MeasuredAt = tt.expected[i].MeasuredAt
+				assert.Equal(t, tt.expected[i], row)
+			}
+		})
+	}
+}
+
+func TestCSVOptionsFromQuery(t *testing.T) {
+	_, err := csvOptionsFromQuery(url.Values{"delimiter": {";;"}})
+	assert.EqualError(t, err, "delimiter must be a single character")
+
+	_, err = csvOptionsFromQuery(url.Values{"column": {"weight"}})
+	assert.EqualError(t, err, `column "weight" must be Header:code`)
+
+	opts, err := csvOptionsFromQuery(url.Values{"column": {"Time: 24h:measured-at"}, "delimiter": {"\t"}})
+	require.NoError(t, err)
+	assert.Equal(t, csvOptions{delimiter: '\t', columns: map[string]string{"time: 24h": "measured-at"}}, opts)
+}
+
+func TestReadMetricsJSON(t *testing.T) {
+	rows, err := readMetricsJSON(strings.NewReader(`[
+		{"measurements": {"weight": 80}, "measured-at": "2026-10-19 08:00:00"},
+		{"weight": 79.5, "measured-at": "2026-10-20T08:00:00+03:00"},
+		{"measurements": {"weight": "heavy"}}
+	]`), time.UTC)
+	require.NoError(t, err)
+	assert.Equal(t, []models.MetricImportRow{
+		{Row: 1, Measurements: models.MeasurementValues{"weight": 80}, MeasuredAt: time.Date(2026, time.October, 19, 8, 0, 0, 0, time.UTC)},
+		{Row: 2, Measurements: models.MeasurementValues{"weight": 79.5}, MeasuredAt: time.Date(2026, time.October, 20, 8, 0, 0, 0, time.FixedZone("", 3*60*60))},
+		{Row: 3, Errors: []apperr.FieldError{{Field: "row", Message: "must be a metric with numeric measurements and RFC 3339 measured-at"}}},
+	}, rows)
+
+	_, err = readMetricsJSON(strings.NewReader(`{"weight": 80}`), time.UTC)
+	assert.ErrorContains(t, err, "file must be JSON array of metrics")
+}
@@ -1,13 +1,19 @@
 package userhandler
 
 import (
+	"context"
 	"errors"
+	"fmt"
 	"log/slog"
+	"mime"
 	"net/http"
+	"time"
 
+	"ChadProgress/internal/lib/api/request"
 	"ChadProgress/internal/lib/api/response"
+	"ChadProgress/internal/lib/apperr"
+	"ChadProgress/internal/lib/units"
 	"ChadProgress/internal/models"
-	service "ChadProgress/internal/services"
 
 	"github.com/go-chi/render"
 	"github.com/go-playground/validator/v10"
@@ -15,18 +21,32 @@ import (
 
 //go:generate mockgen -source=user.go -destination=./user_mock.go -package=userhandler
 type UserService interface {
-	CreateTrainer(userEmail, qualification, experience, achievement string) error
-	CreateClient(userEmail string, height, weight, bodyFat float64) error
-	SelectTrainer(userEmail string, trainerID uint) error
+	CreateTrainer(ctx context.Context, userEmail, qualification, experience, achievement string) error
+	CreateClient(ctx context.Context, userEmail string, height, weight, bodyFat float64) error
+	SelectTrainer(ctx context.Context, userEmail string, trainerID uint) error
 	GetClientProfile(userEmail string) (*models.Client, error)
+	SetGoals(ctx context.Context, clientEmail string, goals models.MeasurementValues) error
 	GetTrainerProfile(userEmail string) (*models.Trainer, error)
 	GetTrainersClients(userEmail string) ([]models.Client, error)
-	CreatePlan(trainerEmail string, clientID uint, description, schedule string) error
-	AddMetrics(clientEmail string, height, weight, bodyFat, bmi float64, measuredAt models.CustomTime) error
-	GetMetrics(clientEmail string) ([]models.Metric, error)
-	AddProgressReport(trainerEmail, comments string, clientID uint) error
+	CreatePlan(ctx context.Context, trainerEmail string, clientID uint, description, schedule string) error
+	AddMetrics(ctx context.Context, clientEmail string, values models.MeasurementValues, measuredAt time.Time) error
+	ImportMetrics(ctx context.Context, clientEmail string, rows []models.MetricImportRow) (*models.MetricImportReport, error)
+	GetMetrics(clientEmail string, from, to time.Time) ([]models.Metric, error)
+	MetricSeries(clientEmail string, from, to time.Time) (*models.MetricSeries, error)
+	MeasurementTypes() ([]models.MeasurementType, error)
+	AddProgressReport(ctx context.Context, trainerEmail, comments string, clientID uint) error
 	GetProgressReport(userEmail string, trainerID, clientID uint) ([]models.ProgressReport, error)
 	GetPlan(userEmail string, trainerID, clientID uint) ([]models.TrainingPlan, error)
+	Settings(userEmail string) (*models.UserSettings, error)
+	UpdateSettings(ctx context.Context, userEmail string, settings models.UserSettings) error
+}
+
+// profileUnits are stored units of body measurements of client profile and v1 metrics by their keys
+var profileUnits = map[string]string{
+	"height":  units.Centimetre,
+	"weight":  units.Kilogram,
+	"bodyfat": units.Percent,
+	"bmi":     "kg/m2",
 }
 
 type CreateTrainerProfileRequest struct {
@@ -49,26 +69,88 @@ type GetClientProfileResponse struct {
 	Height  float64 `json:"height"`
 	Weight  float64 `json:"weight"`
 	BodyFat float64 `json:"bodyfat"`
+	// Goals are target values by code of measurement type
+	Goals map[string]float64 `json:"goals"`
+	// Units are labels of units of values by their keys and of goals by their codes
+	Units map[string]string `json:"units"`
+}
+
+// GoalsRequest replaces goals of client: target values by code of measurement type, empty goals clear them
+type GoalsRequest struct {
+	Goals map[string]float64 `json:"goals"`
 }
 
+// GetTrainerProfileResponse is v1 contract of trainer profile. Qualification is emitted under "height" key,
+// the shape is kept for v1 clients only, see TrainerProfileResponse
 type GetTrainerProfileResponse struct {
 	Qualification string `json:"height"`
 	Experience    string `json:"experience"`
 	Achievements  string `json:"achievements"`
 }
 
+// TrainerProfileResponse is v2 contract of trainer profile
+type TrainerProfileResponse struct {
+	Qualification string `json:"qualification"`
+	Experience    string `json:"experience"`
+	Achievements  string `json:"achievements"`
+}
+
 type CreatePlanRequest struct {
 	ClientID    uint   `json:"client-id" validate:"required"`
 	Description string `json:"description" validate:"required"`
 	Schedule    string `json:"schedule" validate:"required"`
 }
 
+// AddMetricsRequest takes measurements by code of measurement type. Height, weight, body fat and BMI
+// may also be given in their own fields as before the catalog was introduced, zero means not measured.
 type AddMetricsRequest struct {
-	Height     float64           `json:"height"`
-	Weight     float64           `json:"weight"`
-	BodyFat    float64           `json:"bodyfat"`
-	BMI        float64           `json:"bmi"`
-	MeasuredAt models.CustomTime `json:"measured-at"`
+	Height       float64            `json:"height"`
+	Weight       float64            `json:"weight"`
+	BodyFat      float64            `json:"bodyfat"`
+	BMI          float64            `json:"bmi"`
+	Measurements map[string]float64 `json:"measurements"`
+	MeasuredAt   models.CustomTime  `json:"measured-at"`
+}
+
+// values merges measurements given in legacy fields into measurements by code
+func (req AddMetricsRequest) values() models.MeasurementValues {
+	values := make(models.MeasurementValues, len(req.Measurements)+4)
+	for code, v := range map[string]float64{
+		models.MeasurementHeight:  req.Height,
+		models.MeasurementWeight:  req.Weight,
+		models.MeasurementBodyFat: req.BodyFat,
+		models.MeasurementBMI:     req.BMI,
+	} {
+		if v != 0 {
+			values[code] = v
+		}
+	}
+	for code, v := range req.Measurements {
+		values[code] = v
+	}
+
+	return values
+}
+
+type MeasurementTypeResponse struct {
+	Code      string  `json:"code"`
+	Name      string  `json:"name"`
+	Unit      string  `json:"unit"`
+	ValueType string  `json:"value-type"`
+	Min       float64 `json:"min"`
+	Max       float64 `json:"max"`
+}
+
+// SettingsRequest changes preferences of current user, omitted fields are left unchanged
+type SettingsRequest struct {
+	Units string `json:"units" validate:"omitempty,oneof=metric imperial"`
+	// Timezone is IANA time zone, e.g. Europe/Moscow
+	Timezone string `json:"timezone"`
+}
+
+type SettingsResponse struct {
+	Units    string `json:"units"`
+	Timezone string `json:"timezone"`
 }
 
 type AddProgressReportRequest struct {
@@ -106,7 +188,7 @@ func (u *UserHandler) CreateTrainer(w http.ResponseWriter, r *http.Request) {
 	userEmail := r.Context().Value(models.ContextUserKey).(string)
 	if userEmail == "" {
 		log.Error("empty email from context")
-		setHeaderRenderJSON(w, r, http.StatusBadGateway, response.Error("bad gateway"))
+		response.RenderError(w, r, apperr.ErrUnauthorized)
 
 		return
 	}
@@ -115,7 +197,7 @@ func (u *UserHandler) CreateTrainer(w http.ResponseWriter, r *http.Request) {
 	err := render.DecodeJSON(r.Body, &req)
 	if err != nil {
 		log.Error("failed to decode request body", slog.String("error", err.Error()))
-		setHeaderRenderJSON(w, r, http.StatusBadRequest, response.Error("could not decode request body"))
+		response.RenderError(w, r, apperr.ErrBadRequest.WithMessage("could not decode request body"))
 
 		return
 	}
@@ -124,32 +206,16 @@ func (u *UserHandler) CreateTrainer(w http.ResponseWriter, r *http.Request) {
 	if err = validator.New().Struct(req); err != nil {
 		validationErr := err.(validator.ValidationErrors)
 		log.Error("invalid request", slog.String("error", validationErr.Error()))
-		setHeaderRenderJSON(w, r, http.StatusBadRequest, response.ValidationError(validationErr))
+		response.RenderError(w, r, response.ValidationError(validationErr))
 
 		return
 	}
 
 	log.Info("user email extracted from context", slog.String("email", userEmail))
-	err = u.userService.CreateTrainer(userEmail, req.Qualification, req.Experience, req.Achievement)
+	err = u.userService.CreateTrainer(r.Context(), userEmail, req.Qualification, req.Experience, req.Achievement)
 	if err != nil {
-		if errors.Is(err, service.ErrDuplicateKey) {
-			log.Error("trainer already exists")
-			setHeaderRenderJSON(w, r, http.StatusBadRequest, response.Error("trainer already exists"))
-
-			return
-		} else if errors.Is(err, service.ErrFieldIsTooLong) {
-			log.Error("one of fields is too long")
-			setHeaderRenderJSON(w, r, http.StatusBadRequest, response.Error("too long field"))
-
-			return
-		} else if errors.Is(err, service.ErrInvalidRoleRequest) {
-			log.Error("invalid role request")
-			setHeaderRenderJSON(w, r, http.StatusBadRequest, response.Error("cannot create trainer profile while being client"))
-
-			return
-		}
-		log.Error("create trainer failed: " + err.Error())
-		setHeaderRenderJSON(w, r, http.StatusBadGateway, response.Error("create trainer failed"))
+		log.Error("create trainer failed", slog.String("error", err.Error()))
+		response.RenderError(w, r, err)
 
 		return
 	}
@@ -165,7 +231,7 @@ func (u *UserHandler) CreateClient(w http.ResponseWriter, r *http.Request) {
 	userEmail := r.Context().Value(models.ContextUserKey).(string)
 	if userEmail == "" {
 		log.Error("empty email from context")
-		setHeaderRenderJSON(w, r, http.StatusBadGateway, response.Error("bad gateway"))
+		response.RenderError(w, r, apperr.ErrUnauthorized)
 
 		return
 	}
@@ -174,38 +240,28 @@ func (u *UserHandler) CreateClient(w http.ResponseWriter, r *http.Request) {
 	err := render.DecodeJSON(r.Body, &req)
 	if err != nil {
 		log.Error("failed to decode request body", slog.String("error", err.Error()))
-		setHeaderRenderJSON(w, r, http.StatusBadRequest, response.Error("could not decode request body"))
+		response.RenderError(w, r, apperr.ErrBadRequest.WithMessage("could not decode request body"))
 
 		return
 	}
 	if req.Height < 0.0 || req.Weight < 0.0 || req.BodyFat < 0.0 {
 		log.Info("invalid request. negative parameter")
-		setHeaderRenderJSON(w, r, http.StatusBadRequest, response.Error("negative parameter"))
+		response.RenderError(w, r, apperr.ErrValidation.WithMessage("negative parameter"))
 		return
 	}
 
+	prefs, ok := u.preferences(w, r, userEmail)
+	if !ok {
+		return
+	}
+	conv := units.NewConverter(prefs.units, profileUnits)
+
 	log.Info("user email extracted from context", slog.String("email", userEmail))
-	err = u.userService.CreateClient(userEmail, req.Height, req.Weight, req.BodyFat)
+	err = u.userService.CreateClient(r.Context(), userEmail,
+		conv.ToStored("height", req.Height), conv.ToStored("weight", req.Weight), conv.ToStored("bodyfat", req.BodyFat))
 	if err != nil {
-		if errors.Is(err, service.ErrDuplicateKey) {
-			log.Error("client already exists")
-			setHeaderRenderJSON(w, r, http.StatusBadRequest, response.Error("trainer already exists"))
-
-			return
-		} else if errors.Is(err, service.ErrFieldIsTooLong) {
-			log.Error("one of fields is too long")
-			setHeaderRenderJSON(w, r, http.StatusBadRequest, response.Error("too long field"))
-
-			return
-		} else if errors.Is(err, service.ErrInvalidRoleRequest) {
-			log.Error("invalid role request")
-			setHeaderRenderJSON(w, r, http.StatusBadRequest, response.Error("cannot create client profile while being trainer"))
-
-			return
-		}
-
-		log.Error("failed to save client " + err.Error())
-		setHeaderRenderJSON(w, r, http.StatusBadGateway, response.Error("failed to save client"))
+		log.Error("failed to save client", slog.String("error", err.Error()))
+		response.RenderError(w, r, err)
 
 		return
 	}
@@ -222,7 +278,7 @@ func (u *UserHandler) SelectTrainer(w http.ResponseWriter, r *http.Request) {
 	userEmail := r.Context().Value(models.ContextUserKey).(string)
 	if userEmail == "" {
 		log.Error("empty email from context")
-		setHeaderRenderJSON(w, r, http.StatusBadGateway, response.Error("bad gateway"))
+		response.RenderError(w, r, apperr.ErrUnauthorized)
 
 		return
 	}
@@ -231,7 +287,7 @@ func (u *UserHandler) SelectTrainer(w http.ResponseWriter, r *http.Request) {
 	err := render.DecodeJSON(r.Body, &req)
 	if err != nil {
 		log.Error("failed to decode request body", slog.String("error", err.Error()))
-		setHeaderRenderJSON(w, r, http.StatusBadRequest, response.Error("could not decode request body"))
+		response.RenderError(w, r, apperr.ErrBadRequest.WithMessage("could not decode request body"))
 
 		return
 	}
@@ -240,39 +296,22 @@ func (u *UserHandler) SelectTrainer(w http.ResponseWriter, r *http.Request) {
 	if err = validator.New().Struct(req); err != nil {
 		validationErr := err.(validator.ValidationErrors)
 		log.Error("invalid request", slog.String("error", validationErr.Error()))
-		setHeaderRenderJSON(w, r, http.StatusBadRequest, response.ValidationError(validationErr))
+		response.RenderError(w, r, response.ValidationError(validationErr))
 
 		return
 	}
 
 	if req.TrainerID <= 0 {
 		log.Error("invalid trainer id to bind")
-		setHeaderRenderJSON(w, r, http.StatusBadRequest, response.Error("invalid trainer id to bind"))
+		response.RenderError(w, r, apperr.ErrValidation.WithMessage("invalid trainer id to bind"))
 
 		return
 	}
 
-	err = u.userService.SelectTrainer(userEmail, req.TrainerID)
+	err = u.userService.SelectTrainer(r.Context(), userEmail, req.TrainerID)
 	if err != nil {
-		if errors.Is(err, service.ErrClientNotFound) {
-			log.Error("client's profile does not exist")
-			setHeaderRenderJSON(w, r, http.StatusBadRequest, response.Error("client's profile does not exist"))
-
-			return
-		} else if errors.Is(err, service.ErrTrainerNotFound) {
-			log.Error("trainer's profile does not exist")
-			setHeaderRenderJSON(w, r, http.StatusBadRequest, response.Error("trainer's profile does not exist"))
-
-			return
-		} else if errors.Is(err, service.ErrNotActiveTrainer) {
-			log.Error("not active trainer")
-			setHeaderRenderJSON(w, r, http.StatusBadRequest, response.Error("trainer is busy or on vacation"))
-
-			return
-		}
-
-		log.Error("failed to bind client to trainer")
-		setHeaderRenderJSON(w, r, http.StatusBadGateway, response.Error("failed to bind client to trainer"))
+		log.Error("failed to bind client to trainer", slog.String("error", err.Error()))
+		response.RenderError(w, r, err)
 
 		return
 	}
@@ -289,40 +328,48 @@ func (u *UserHandler) GetClientProfile(w http.ResponseWriter, r *http.Request) {
 	userEmail := r.Context().Value(models.ContextUserKey).(string)
 	if userEmail == "" {
 		log.Error("empty email from context")
-		setHeaderRenderJSON(w, r, http.StatusBadGateway, response.Error("bad gateway"))
+		response.RenderError(w, r, apperr.ErrUnauthorized)
+
+		return
+	}
 
+	prefs, ok := u.preferences(w, r, userEmail)
+	if !ok {
 		return
 	}
 
 	client, err := u.userService.GetClientProfile(userEmail)
 	if err != nil {
-		if errors.Is(err, service.ErrInvalidRoleRequest) {
-			log.Info("invalid role request")
-			setHeaderRenderJSON(w, r, http.StatusBadRequest, response.Error("you cant get info about client as a trainer"))
-
-			return
-		} else if errors.Is(err, service.ErrClientNotFound) {
-			log.Info("client profile not found")
-			setHeaderRenderJSON(w, r, http.StatusBadRequest, response.Error("client profile not found"))
-
-			return
-		}
-		log.Error("failed to get client profile")
-		setHeaderRenderJSON(w, r, http.StatusBadGateway, response.Error("bad gateway"))
+		log.Error("failed to get client profile", slog.String("error", err.Error()))
+		response.RenderError(w, r, err)
 
 		return
 	}
 
+	conv := units.NewConverter(prefs.units, profileUnits)
 	clientResp := GetClientProfileResponse{
-		BodyFat: client.BodyFat,
-		Height:  client.Height,
-		Weight:  client.Weight,
+		BodyFat: conv.FromStored("bodyfat", client.BodyFat),
+		Height:  conv.FromStored("height", client.Height),
+		Weight:  conv.FromStored("weight", client.Weight),
+		Goals:   make(map[string]float64, len(client.Goals)),
+		Units:   conv.Units("height", "weight", "bodyfat"),
+	}
+	if len(client.Goals) > 0 {
+		goalConv, ok := u.catalogConverter(w, r, prefs.units)
+		if !ok {
+			return
+		}
+		for code, v := range client.Goals {
+			clientResp.Goals[code] = goalConv.FromStored(code, v)
+			clientResp.Units[code] = goalConv.Unit(code)
+		}
 	}
 	setHeaderRenderJSON(w, r, http.StatusOK, clientResp)
 }
 
-func (u *UserHandler) GetTrainerProfile(w http.ResponseWriter, r *http.Request) {
-	const op = "handlers.url.user.user.GetTrainerProfile"
+// SetGoals replaces goals of current client, targets are taken in unit system of user
+func (u *UserHandler) SetGoals(w http.ResponseWriter, r *http.Request) {
+	const op = "handlers.url.user.user.SetGoals"
 	log := u.log.With(
 		slog.String("op", op),
 	)
@@ -330,25 +377,54 @@ func (u *UserHandler) GetTrainerProfile(w http.ResponseWriter, r *http.Request)
 	userEmail := r.Context().Value(models.ContextUserKey).(string)
 	if userEmail == "" {
 		log.Error("empty email from context")
-		setHeaderRenderJSON(w, r, http.StatusBadGateway, response.Error("bad gateway"))
+		response.RenderError(w, r, apperr.ErrUnauthorized)
 
 		return
 	}
 
-	trainer, err := u.userService.GetTrainerProfile(userEmail)
+	var req GoalsRequest
+	err := render.DecodeJSON(r.Body, &req)
 	if err != nil {
-		if errors.Is(err, service.ErrTrainerNotFound) {
-			log.Info("trainer profile not found")
-			setHeaderRenderJSON(w, r, http.StatusBadRequest, response.Error("trainer profile not found"))
+		log.Error("failed to decode request body", slog.String("error", err.Error()))
+		response.RenderError(w, r, apperr.ErrBadRequest.WithMessage("could not decode request body"))
+
+		return
+	}
 
+	prefs, ok := u.preferences(w, r, userEmail)
+	if !ok {
+		return
+	}
+	goals := make(models.MeasurementValues, len(req.Goals))
+	for code, v := range req.Goals {
+		goals[code] = v
+	}
+	if prefs.units != units.Metric && len(goals) > 0 {
+		conv, ok := u.catalogConverter(w, r, prefs.units)
+		if !ok {
 			return
 		}
-		log.Error("failed to get trainer profile")
-		setHeaderRenderJSON(w, r, http.StatusBadGateway, response.Error("bad gateway"))
+		for code, v := range goals {
+			goals[code] = conv.ToStored(code, v)
+		}
+	}
+
+	if err = u.userService.SetGoals(r.Context(), userEmail, goals); err != nil {
+		log.Error("failed to set goals", slog.String("error", err.Error()))
+		response.RenderError(w, r, err)
 
 		return
 	}
 
+	setHeaderRenderJSON(w, r, http.StatusOK, response.OK())
+}
+
+func (u *UserHandler) GetTrainerProfile(w http.ResponseWriter, r *http.Request) {
+	trainer, ok := u.trainerProfile(w, r, "handlers.url.user.user.GetTrainerProfile")
+	if !ok {
+		return
+	}
+
 	clientResp := GetTrainerProfileResponse{
 		Qualification: trainer.Qualifications,
 		Experience:    trainer.Experience,
@@ -357,6 +433,45 @@ func (u *UserHandler) GetTrainerProfile(w http.ResponseWriter, r *http.Request)
 	setHeaderRenderJSON(w, r, http.StatusOK, clientResp)
 }
 
+// GetTrainerProfileV2 serves v2 contract of trainer profile
+func (u *UserHandler) GetTrainerProfileV2(w http.ResponseWriter, r *http.Request) {
+	trainer, ok := u.trainerProfile(w, r, "handlers.url.user.user.GetTrainerProfileV2")
+	if !ok {
+		return
+	}
+
+	setHeaderRenderJSON(w, r, http.StatusOK, TrainerProfileResponse{
+		Qualification: trainer.Qualifications,
+		Experience:    trainer.Experience,
+		Achievements:  trainer.Achievements,
+	})
+}
+
+// trainerProfile fetches trainer profile of current user. Error response is already rendered when false is returned
+func (u *UserHandler) trainerProfile(w http.ResponseWriter, r *http.Request, op string) (*models.Trainer, bool) {
+	log := u.log.With(
+		slog.String("op", op),
+	)
+
+	userEmail := r.Context().Value(models.ContextUserKey).(string)
+	if userEmail == "" {
+		log.Error("empty email from context")
+		response.RenderError(w, r, apperr.ErrUnauthorized)
+
+		return nil, false
+	}
+
+	trainer, err := u.userService.GetTrainerProfile(userEmail)
+	if err != nil {
+		log.Error("failed to get trainer profile", slog.String("error", err.Error()))
+		response.RenderError(w, r, err)
+
+		return nil, false
+	}
+
+	return trainer, true
+}
+
 func (u *UserHandler) GetTrainersClients(w http.ResponseWriter, r *http.Request) {
 	const op = "handlers.url.user.user.GetTrainersClients"
 	log := u.log.With(
@@ -366,26 +481,25 @@ func (u *UserHandler) GetTrainersClients(w http.ResponseWriter, r *http.Request)
 	userEmail := r.Context().Value(models.ContextUserKey).(string)
 	if userEmail == "" {
 		log.Error("empty email from context")
-		setHeaderRenderJSON(w, r, http.StatusBadGateway, response.Error("bad gateway"))
+		response.RenderError(w, r, apperr.ErrUnauthorized)
+
+		return
+	}
 
+	prefs, ok := u.preferences(w, r, userEmail)
+	if !ok {
 		return
 	}
 
 	clients, err := u.userService.GetTrainersClients(userEmail)
 	if err != nil {
-		if errors.Is(err, service.ErrTrainerNotFound) {
-			log.Info("trainer profile not found")
-			setHeaderRenderJSON(w, r, http.StatusBadRequest, response.Error("trainer profile not found"))
-
-			return
-		}
-		log.Error("failed to get trainer profile")
-		setHeaderRenderJSON(w, r, http.StatusBadGateway, response.Error("bad gateway"))
+		log.Error("failed to get trainer's clients", slog.String("error", err.Error()))
+		response.RenderError(w, r, err)
 
 		return
 	}
 
-	setHeaderRenderJSON(w, r, http.StatusOK, mapClientToClientResponse(clients))
+	setHeaderRenderJSON(w, r, http.StatusOK, mapClientToClientResponse(clients, units.NewConverter(prefs.units, profileUnits)))
 }
 
 func (u *UserHandler) CreatePlan(w http.ResponseWriter, r *http.Request) {
@@ -397,7 +511,7 @@ func (u *UserHandler) CreatePlan(w http.ResponseWriter, r *http.Request) {
 	userEmail := r.Context().Value(models.ContextUserKey).(string)
 	if userEmail == "" {
 		log.Error("empty email from context")
-		setHeaderRenderJSON(w, r, http.StatusBadGateway, response.Error("bad gateway"))
+		response.RenderError(w, r, apperr.ErrUnauthorized)
 
 		return
 	}
@@ -406,7 +520,7 @@ func (u *UserHandler) CreatePlan(w http.ResponseWriter, r *http.Request) {
 	err := render.DecodeJSON(r.Body, &req)
 	if err != nil {
 		log.Error("failed to decode request body", slog.String("error", err.Error()))
-		setHeaderRenderJSON(w, r, http.StatusBadRequest, response.Error("could not decode request body"))
+		response.RenderError(w, r, apperr.ErrBadRequest.WithMessage("could not decode request body"))
 
 		return
 	}
@@ -415,21 +529,15 @@ func (u *UserHandler) CreatePlan(w http.ResponseWriter, r *http.Request) {
 	if err = validator.New().Struct(req); err != nil {
 		validationErr := err.(validator.ValidationErrors)
 		log.Error("invalid request", slog.String("error", validationErr.Error()))
-		setHeaderRenderJSON(w, r, http.StatusBadRequest, response.ValidationError(validationErr))
+		response.RenderError(w, r, response.ValidationError(validationErr))
 
 		return
 	}
 
-	err = u.userService.CreatePlan(userEmail, req.ClientID, req.Description, req.Schedule)
+	err = u.userService.CreatePlan(r.Context(), userEmail, req.ClientID, req.Description, req.Schedule)
 	if err != nil {
-		if errors.Is(err, service.ErrTrainerNotFound) {
-			log.Info("trainer profile not found")
-			setHeaderRenderJSON(w, r, http.StatusBadRequest, response.Error("trainer profile not found"))
-
-			return
-		}
-		log.Error("failed to create plan")
-		setHeaderRenderJSON(w, r, http.StatusBadGateway, response.Error("bad gateway"))
+		log.Error("failed to create plan", slog.String("error", err.Error()))
+		response.RenderError(w, r, err)
 
 		return
 	}
@@ -446,7 +554,7 @@ func (u *UserHandler) AddMetrics(w http.ResponseWriter, r *http.Request) {
 	userEmail := r.Context().Value(models.ContextUserKey).(string)
 	if userEmail == "" {
 		log.Error("empty email from context")
-		setHeaderRenderJSON(w, r, http.StatusBadGateway, response.Error("bad gateway"))
+		response.RenderError(w, r, apperr.ErrUnauthorized)
 
 		return
 	}
@@ -455,7 +563,7 @@ func (u *UserHandler) AddMetrics(w http.ResponseWriter, r *http.Request) {
 	err := render.DecodeJSON(r.Body, &req)
 	if err != nil {
 		log.Error("failed to decode request body", slog.String("error", err.Error()))
-		setHeaderRenderJSON(w, r, http.StatusBadRequest, response.Error("could not decode request body"))
+		response.RenderError(w, r, apperr.ErrBadRequest.WithMessage("could not decode request body"))
 
 		return
 	}
@@ -464,21 +572,30 @@ func (u *UserHandler) AddMetrics(w http.ResponseWriter, r *http.Request) {
 	if err = validator.New().Struct(req); err != nil {
 		validationErr := err.(validator.ValidationErrors)
 		log.Error("invalid request", slog.String("error", validationErr.Error()))
-		setHeaderRenderJSON(w, r, http.StatusBadRequest, response.ValidationError(validationErr))
+		response.RenderError(w, r, response.ValidationError(validationErr))
 
 		return
 	}
 
-	err = u.userService.AddMetrics(userEmail, req.Height, req.Weight, req.BodyFat, req.BMI, req.MeasuredAt)
-	if err != nil {
-		if errors.Is(err, service.ErrClientNotFound) {
-			log.Info("client profile not found")
-			setHeaderRenderJSON(w, r, http.StatusBadRequest, response.Error("client profile not found"))
-
+	prefs, ok := u.preferences(w, r, userEmail)
+	if !ok {
+		return
+	}
+	values := req.values()
+	if prefs.units != units.Metric {
+		conv, ok := u.catalogConverter(w, r, prefs.units)
+		if !ok {
 			return
 		}
-		log.Error("failed to add metrics")
-		setHeaderRenderJSON(w, r, http.StatusBadGateway, response.Error("bad gateway"))
+		for code, v := range values {
+			values[code] = conv.ToStored(code, v)
+		}
+	}
+
+	err = u.userService.AddMetrics(r.Context(), userEmail, values, req.MeasuredAt.In(prefs.loc))
+	if err != nil {
+		log.Error("failed to add metrics", slog.String("error", err.Error()))
+		response.RenderError(w, r, err)
 
 		return
 	}
@@ -486,8 +603,10 @@ func (u *UserHandler) AddMetrics(w http.ResponseWriter, r *http.Request) {
 	setHeaderRenderJSON(w, r, http.StatusOK, response.OK())
 }
 
-func (u *UserHandler) GetMetrics(w http.ResponseWriter, r *http.Request) {
-	const op = "handlers.url.user.user.GetMetrics"
+// ImportMetrics adds metrics of JSON array or CSV file of request body, chosen by Content-Type. Rows
+// which can not be added are reported, they do not stop the import.
+func (u *UserHandler) ImportMetrics(w http.ResponseWriter, r *http.Request) {
+	const op = "handlers.url.user.user.ImportMetrics"
 	log := u.log.With(
 		slog.String("op", op),
 	)
@@ -495,26 +614,209 @@ func (u *UserHandler) GetMetrics(w http.ResponseWriter, r *http.Request) {
 	userEmail := r.Context().Value(models.ContextUserKey).(string)
 	if userEmail == "" {
 		log.Error("empty email from context")
-		setHeaderRenderJSON(w, r, http.StatusBadGateway, response.Error("bad gateway"))
+		response.RenderError(w, r, apperr.ErrUnauthorized)
+
+		return
+	}
 
+	prefs, ok := u.preferences(w, r, userEmail)
+	if !ok {
 		return
 	}
 
-	metrics, err := u.userService.GetMetrics(userEmail)
+	body := http.MaxBytesReader(w, r.Body, maxImportSize)
+	var rows []models.MetricImportRow
+	var err error
+	mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	switch mediaType {
+	case "text/csv":
+		var opts csvOptions
+		if opts, err = csvOptionsFromQuery(r.URL.Query()); err != nil {
+			response.RenderError(w, r, apperr.ErrBadRequest.WithMessage(err.Error()))
+
+			return
+		}
+		rows, err = readMetricsCSV(body, opts, prefs.loc)
+	case "application/json", "":
+		rows, err = readMetricsJSON(body, prefs.loc)
+	default:
+		response.RenderError(w, r, apperr.ErrBadRequest.WithMessage("file must be application/json or text/csv"))
+
+		return
+	}
 	if err != nil {
-		if errors.Is(err, service.ErrClientNotFound) {
-			log.Info("client profile not found")
-			setHeaderRenderJSON(w, r, http.StatusBadRequest, response.Error("client profile not found"))
+		log.Error("failed to read imported file", slog.String("error", err.Error()))
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			err = fmt.Errorf("file is larger than %d MB", maxImportSize>>20)
+		}
+		response.RenderError(w, r, apperr.ErrBadRequest.WithMessage(err.Error()))
 
+		return
+	}
+
+	if prefs.units != units.Metric {
+		conv, ok := u.catalogConverter(w, r, prefs.units)
+		if !ok {
 			return
 		}
-		log.Error("failed to get metrics")
-		setHeaderRenderJSON(w, r, http.StatusBadGateway, response.Error("bad gateway"))
+		for _, row := range rows {
+			for code, v := range row.Measurements {
+				row.Measurements[code] = conv.ToStored(code, v)
+			}
+		}
+	}
+
+	report, err := u.userService.ImportMetrics(r.Context(), userEmail, rows)
+	if err != nil {
+		log.Error("failed to import metrics", slog.String("error", err.Error()))
+		response.RenderError(w, r, err)
+
+		return
+	}
+
+	setHeaderRenderJSON(w, r, http.StatusOK, report)
+}
+
+func (u *UserHandler) GetMetrics(w http.ResponseWriter, r *http.Request) {
+	metrics, prefs, ok := u.metrics(w, r, "handlers.url.user.user.GetMetrics")
+	if !ok {
+		return
+	}
+
+	setHeaderRenderJSON(w, r, http.StatusOK, mapMetricToMetricsResponse(metrics, units.NewConverter(prefs.units, profileUnits), prefs.loc))
+}
+
+// GetMetricsV2 serves v2 contract of metrics with every measurement of the catalog
+func (u *UserHandler) GetMetricsV2(w http.ResponseWriter, r *http.Request) {
+	metrics, prefs, ok := u.metrics(w, r, "handlers.url.user.user.GetMetricsV2")
+	if !ok {
+		return
+	}
+	conv, ok := u.catalogConverter(w, r, prefs.units)
+	if !ok {
+		return
+	}
+
+	setHeaderRenderJSON(w, r, http.StatusOK, mapMetricToEntryResponse(metrics, conv, prefs.loc))
+}
+
+// metrics returns metrics of current client within range of query parameters and preferences they are
+// shown with, rendering error if it fails
+func (u *UserHandler) metrics(w http.ResponseWriter, r *http.Request, op string) ([]models.Metric, preferences, bool) {
+	log := u.log.With(
+		slog.String("op", op),
+	)
+
+	userEmail := r.Context().Value(models.ContextUserKey).(string)
+	if userEmail == "" {
+		log.Error("empty email from context")
+		response.RenderError(w, r, apperr.ErrUnauthorized)
+
+		return nil, preferences{}, false
+	}
+
+	prefs, ok := u.preferences(w, r, userEmail)
+	if !ok {
+		return nil, preferences{}, false
+	}
+	from, to, ok := rangeParams(w, r, prefs.loc)
+	if !ok {
+		return nil, preferences{}, false
+	}
+
+	metrics, err := u.userService.GetMetrics(userEmail, from, to)
+	if err != nil {
+		log.Error("failed to get metrics", slog.String("error", err.Error()))
+		response.RenderError(w, r, err)
+
+		return nil, preferences{}, false
+	}
+
+	return metrics, prefs, true
+}
+
+// GetMeasurementTypes lists measurement catalog: types metrics may have, their units and valid ranges
+func (u *UserHandler) GetMeasurementTypes(w http.ResponseWriter, r *http.Request) {
+	const op = "handlers.url.user.user.GetMeasurementTypes"
+	log := u.log.With(
+		slog.String("op", op),
+	)
+
+	userEmail := r.Context().Value(models.ContextUserKey).(string)
+	if userEmail == "" {
+		log.Error("empty email from context")
+		response.RenderError(w, r, apperr.ErrUnauthorized)
+
+		return
+	}
+
+	prefs, ok := u.preferences(w, r, userEmail)
+	if !ok {
+		return
+	}
+
+	types, err := u.userService.MeasurementTypes()
+	if err != nil {
+		log.Error("failed to get measurement types", slog.String("error", err.Error()))
+		response.RenderError(w, r, err)
 
 		return
 	}
 
-	setHeaderRenderJSON(w, r, http.StatusOK, mapMetricToMetricsResponse(metrics))
+	conv := units.NewConverter(prefs.units, storedUnits(types))
+	res := make([]MeasurementTypeResponse, 0, len(types))
+	for _, t := range types {
+		res = append(res, MeasurementTypeResponse{
+			Code:      t.Code,
+			Name:      t.Name,
+			Unit:      conv.Unit(t.Code),
+			ValueType: t.ValueType,
+			Min:       conv.FromStored(t.Code, t.Min),
+			Max:       conv.FromStored(t.Code, t.Max),
+		})
+	}
+	setHeaderRenderJSON(w, r, http.StatusOK, res)
+}
+
+// MetricSeries returns metrics within optional from and to query parameters for charts: measurements
+// of short ranges, daily or weekly averages of longer ones
+func (u *UserHandler) MetricSeries(w http.ResponseWriter, r *http.Request) {
+	const op = "handlers.url.user.user.MetricSeries"
+	log := u.log.With(
+		slog.String("op", op),
+	)
+
+	userEmail := r.Context().Value(models.ContextUserKey).(string)
+	if userEmail == "" {
+		log.Error("empty email from context")
+		response.RenderError(w, r, apperr.ErrUnauthorized)
+
+		return
+	}
+
+	prefs, ok := u.preferences(w, r, userEmail)
+	if !ok {
+		return
+	}
+	from, to, ok := rangeParams(w, r, prefs.loc)
+	if !ok {
+		return
+	}
+
+	series, err := u.userService.MetricSeries(userEmail, from, to)
+	if err != nil {
+		log.Error("failed to get metric series", slog.String("error", err.Error()))
+		response.RenderError(w, r, err)
+
+		return
+	}
+	conv, ok := u.catalogConverter(w, r, prefs.units)
+	if !ok {
+		return
+	}
+
+	setHeaderRenderJSON(w, r, http.StatusOK, mapMetricSeriesToResponse(series, conv))
 }
 
 func (u *UserHandler) AddProgressReport(w http.ResponseWriter, r *http.Request) {
@@ -526,7 +828,7 @@ func (u *UserHandler) AddProgressReport(w http.ResponseWriter, r *http.Request)
 	userEmail := r.Context().Value(models.ContextUserKey).(string)
 	if userEmail == "" {
 		log.Error("empty email from context")
-		setHeaderRenderJSON(w, r, http.StatusBadGateway, response.Error("bad gateway"))
+		response.RenderError(w, r, apperr.ErrUnauthorized)
 
 		return
 	}
@@ -536,7 +838,7 @@ func (u *UserHandler) AddProgressReport(w http.ResponseWriter, r *http.Request)
 	err := render.DecodeJSON(r.Body, &req)
 	if err != nil {
 		log.Error("failed to decode request body", slog.String("error", err.Error()))
-		setHeaderRenderJSON(w, r, http.StatusBadRequest, response.Error("could not decode request body"))
+		response.RenderError(w, r, apperr.ErrBadRequest.WithMessage("could not decode request body"))
 
 		return
 	}
@@ -545,33 +847,15 @@ func (u *UserHandler) AddProgressReport(w http.ResponseWriter, r *http.Request)
 	if err = validator.New().Struct(req); err != nil {
 		validationErr := err.(validator.ValidationErrors)
 		log.Error("invalid request", slog.String("error", validationErr.Error()))
-		setHeaderRenderJSON(w, r, http.StatusBadRequest, response.ValidationError(validationErr))
+		response.RenderError(w, r, response.ValidationError(validationErr))
 
 		return
 	}
 
-	err = u.userService.AddProgressReport(userEmail, req.Comments, req.ClientID)
+	err = u.userService.AddProgressReport(r.Context(), userEmail, req.Comments, req.ClientID)
 	if err != nil {
-		if errors.Is(err, service.ErrClientNotFound) {
-			log.Info("client profile not found")
-			setHeaderRenderJSON(w, r, http.StatusBadRequest, response.Error("client profile not found"))
-
-			return
-		}
-		if errors.Is(err, service.ErrTrainerNotFound) {
-			log.Info("trainer profile not found")
-			setHeaderRenderJSON(w, r, http.StatusBadRequest, response.Error("client profile not found"))
-
-			return
-		}
-		if errors.Is(err, service.ErrUserNotFound) {
-			log.Info("user not found")
-			setHeaderRenderJSON(w, r, http.StatusBadRequest, response.Error("client profile not found"))
-
-			return
-		}
-		log.Error("failed to add progress report")
-		setHeaderRenderJSON(w, r, http.StatusBadGateway, response.Error("bad gateway"))
+		log.Error("failed to add progress report", slog.String("error", err.Error()))
+		response.RenderError(w, r, err)
 
 		return
 	}
@@ -588,7 +872,7 @@ func (u *UserHandler) GetProgressReports(w http.ResponseWriter, r *http.Request)
 	userEmail := r.Context().Value(models.ContextUserKey).(string)
 	if userEmail == "" {
 		log.Error("empty email from context")
-		setHeaderRenderJSON(w, r, http.StatusBadGateway, response.Error("bad gateway"))
+		response.RenderError(w, r, apperr.ErrUnauthorized)
 
 		return
 	}
@@ -598,7 +882,7 @@ func (u *UserHandler) GetProgressReports(w http.ResponseWriter, r *http.Request)
 	err := render.DecodeJSON(r.Body, &req)
 	if err != nil {
 		log.Error("failed to decode request body", slog.String("error", err.Error()))
-		setHeaderRenderJSON(w, r, http.StatusBadRequest, response.Error("could not decode request body"))
+		response.RenderError(w, r, apperr.ErrBadRequest.WithMessage("could not decode request body"))
 
 		return
 	}
@@ -607,17 +891,26 @@ func (u *UserHandler) GetProgressReports(w http.ResponseWriter, r *http.Request)
 	if err = validator.New().Struct(req); err != nil {
 		validationErr := err.(validator.ValidationErrors)
 		log.Error("invalid request", slog.String("error", validationErr.Error()))
-		setHeaderRenderJSON(w, r, http.StatusBadRequest, response.ValidationError(validationErr))
+		response.RenderError(w, r, response.ValidationError(validationErr))
+
+		return
+	}
 
+	prefs, ok := u.preferences(w, r, userEmail)
+	if !ok {
 		return
 	}
 
 	reports, err := u.userService.GetProgressReport(userEmail, req.TrainerID, req.ClientID)
 	if err != nil {
-		log.Error("failed to get progress report")
-		setHeaderRenderJSON(w, r, http.StatusBadGateway, response.Error("bad gateway"))
+		log.Error("failed to get progress report", slog.String("error", err.Error()))
+		response.RenderError(w, r, err)
+
 		return
 	}
+	for i := range reports {
+		reports[i].CreatedAt = reports[i].CreatedAt.In(prefs.loc)
+	}
 
 	setHeaderRenderJSON(w, r, http.StatusOK, reports)
 }
@@ -631,7 +924,7 @@ func (u *UserHandler) GetPlan(w http.ResponseWriter, r *http.Request) {
 	userEmail := r.Context().Value(models.ContextUserKey).(string)
 	if userEmail == "" {
 		log.Error("empty email from context")
-		setHeaderRenderJSON(w, r, http.StatusBadGateway, response.Error("bad gateway"))
+		response.RenderError(w, r, apperr.ErrUnauthorized)
 
 		return
 	}
@@ -641,7 +934,7 @@ func (u *UserHandler) GetPlan(w http.ResponseWriter, r *http.Request) {
 	err := render.DecodeJSON(r.Body, &req)
 	if err != nil {
 		log.Error("failed to decode request body", slog.String("error", err.Error()))
-		setHeaderRenderJSON(w, r, http.StatusBadRequest, response.Error("could not decode request body"))
+		response.RenderError(w, r, apperr.ErrBadRequest.WithMessage("could not decode request body"))
 
 		return
 	}
@@ -650,19 +943,153 @@ func (u *UserHandler) GetPlan(w http.ResponseWriter, r *http.Request) {
 	if err = validator.New().Struct(req); err != nil {
 		validationErr := err.(validator.ValidationErrors)
 		log.Error("invalid request", slog.String("error", validationErr.Error()))
-		setHeaderRenderJSON(w, r, http.StatusBadRequest, response.ValidationError(validationErr))
+		response.RenderError(w, r, response.ValidationError(validationErr))
+
+		return
+	}
 
+	prefs, ok := u.preferences(w, r, userEmail)
+	if !ok {
 		return
 	}
 
 	plans, err := u.userService.GetPlan(userEmail, req.TrainerID, req.ClientID)
 	if err != nil {
-		log.Error("failed to get plan")
-		setHeaderRenderJSON(w, r, http.StatusBadGateway, response.Error("bad gateway"))
+		log.Error("failed to get plan", slog.String("error", err.Error()))
+		response.RenderError(w, r, err)
+
 		return
 	}
 
-	setHeaderRenderJSON(w, r, http.StatusOK, mapPlanToPlanResponse(plans))
+	setHeaderRenderJSON(w, r, http.StatusOK, mapPlanToPlanResponse(plans, prefs.loc))
+}
+
+// GetSettings returns preferences of current user
+func (u *UserHandler) GetSettings(w http.ResponseWriter, r *http.Request) {
+	const op = "handlers.url.user.user.GetSettings"
+	log := u.log.With(
+		slog.String("op", op),
+	)
+
+	userEmail := r.Context().Value(models.ContextUserKey).(string)
+	if userEmail == "" {
+		log.Error("empty email from context")
+		response.RenderError(w, r, apperr.ErrUnauthorized)
+
+		return
+	}
+
+	settings, err := u.userService.Settings(userEmail)
+	if err != nil {
+		log.Error("failed to get settings", slog.String("error", err.Error()))
+		response.RenderError(w, r, err)
+
+		return
+	}
+
+	setHeaderRenderJSON(w, r, http.StatusOK, SettingsResponse{Units: settings.Units, Timezone: settings.Timezone})
+}
+
+// UpdateSettings changes preferences of current user
+func (u *UserHandler) UpdateSettings(w http.ResponseWriter, r *http.Request) {
+	const op = "handlers.url.user.user.UpdateSettings"
+	log := u.log.With(
+		slog.String("op", op),
+	)
+
+	userEmail := r.Context().Value(models.ContextUserKey).(string)
+	if userEmail == "" {
+		log.Error("empty email from context")
+		response.RenderError(w, r, apperr.ErrUnauthorized)
+
+		return
+	}
+
+	var req SettingsRequest
+	err := render.DecodeJSON(r.Body, &req)
+	if err != nil {
+		log.Error("failed to decode request body", slog.String("error", err.Error()))
+		response.RenderError(w, r, apperr.ErrBadRequest.WithMessage("could not decode request body"))
+
+		return
+	}
+
+	log.Info("request body decoded", slog.Any("request", req))
+	if err = validator.New().Struct(req); err != nil {
+		validationErr := err.(validator.ValidationErrors)
+		log.Error("invalid request", slog.String("error", validationErr.Error()))
+		response.RenderError(w, r, response.ValidationError(validationErr))
+
+		return
+	}
+
+	err = u.userService.UpdateSettings(r.Context(), userEmail, models.UserSettings{Units: req.Units, Timezone: req.Timezone})
+	if err != nil {
+		log.Error("failed to update settings", slog.String("error", err.Error()))
+		response.RenderError(w, r, err)
+
+		return
+	}
+
+	setHeaderRenderJSON(w, r, http.StatusOK, response.OK())
+}
+
+// preferences are unit system and time zone values of a request are entered and shown in
+type preferences struct {
+	units units.System
+	loc   *time.Location
+}
+
+// preferences returns preferences of current user, unit system requested with units.Header or
+// units.Param takes precedence. Error response is already rendered when false is returned.
+func (u *UserHandler) preferences(w http.ResponseWriter, r *http.Request, userEmail string) (preferences, bool) {
+	requested, err := units.FromRequest(r)
+	if err != nil {
+		response.RenderError(w, r, apperr.ErrValidation.WithFields(apperr.FieldError{Field: units.Param, Message: err.Error()}))
+
+		return preferences{}, false
+	}
+
+	settings, err := u.userService.Settings(userEmail)
+	if err != nil {
+		u.log.Error("failed to get settings", slog.String("error", err.Error()))
+		response.RenderError(w, r, err)
+
+		return preferences{}, false
+	}
+
+	prefs := preferences{units: requested, loc: models.LoadLocation(settings.Timezone)}
+	if prefs.units == "" {
+		if prefs.units, err = units.Parse(settings.Units); err != nil {
+			prefs.units = units.Metric
+		}
+	}
+
+	return prefs, true
+}
+
+// catalogConverter returns converter of measurements by code of their type. Error response is already
+// rendered when false is returned.
+func (u *UserHandler) catalogConverter(w http.ResponseWriter, r *http.Request, system units.System) (units.Converter, bool) {
+	types, err := u.userService.MeasurementTypes()
+	if err != nil {
+		u.log.Error("failed to get measurement types", slog.String("error", err.Error()))
+		response.RenderError(w, r, err)
+
+		return units.Converter{}, false
+	}
+
+	return units.NewConverter(system, storedUnits(types)), true
+}
+
+// storedUnits maps codes of measurement types to units their values are stored in
+func storedUnits(types []models.MeasurementType) map[string]string {
+	res := make(map[string]string, len(types))
+	for _, t := range types {
+		res[t.Code] = t.Unit
+	}
+
+	return res
 }
 
 func setHeaderRenderJSON(w http.ResponseWriter, r *http.Request, status int, v any) {
@@ -670,7 +1097,7 @@ func setHeaderRenderJSON(w http.ResponseWriter, r *http.Request, status int, v a
 	render.JSON(w, r, v)
 }
 
-func mapClientToClientResponse(m []models.Client) []models.ClientResponse {
+func mapClientToClientResponse(m []models.Client, conv units.Converter) []models.ClientResponse {
 	res := make([]models.ClientResponse, 0, len(m))
 
 	for _, o := range m {
@@ -679,16 +1106,17 @@ func mapClientToClientResponse(m []models.Client) []models.ClientResponse {
 				ID:        o.ID,
 				UserID:    o.UserID,
 				TrainerID: o.TrainerID,
-				Height:    o.Height,
-				Weight:    o.Weight,
-				BodyFat:   o.BodyFat,
+				Height:    conv.FromStored("height", o.Height),
+				Weight:    conv.FromStored("weight", o.Weight),
+				BodyFat:   conv.FromStored("bodyfat", o.BodyFat),
+				Units:     conv.Units("height", "weight", "bodyfat"),
 			})
 	}
 
 	return res
 }
 
-func mapMetricToMetricsResponse(m []models.Metric) []models.MetricResponse {
+func mapMetricToMetricsResponse(m []models.Metric, conv units.Converter, loc *time.Location) []models.MetricResponse {
 	res := make([]models.MetricResponse, 0, len(m))
 
 	for _, o := range m {
@@ -696,18 +1124,86 @@ func mapMetricToMetricsResponse(m []models.Metric) []models.MetricResponse {
 			models.MetricResponse{
 				ID:         o.ID,
 				ClientID:   o.ClientID,
-				Height:     o.Height,
-				Weight:     o.Weight,
-				BodyFat:    o.BodyFat,
-				BMI:        o.BMI,
-				MeasuredAt: o.MeasuredAt.String(),
+				Height:     conv.FromStored("height", o.Measurements[models.MeasurementHeight]),
+				Weight:     conv.FromStored("weight", o.Measurements[models.MeasurementWeight]),
+				BodyFat:    conv.FromStored("bodyfat", o.Measurements[models.MeasurementBodyFat]),
+				BMI:        conv.FromStored("bmi", o.Measurements[models.MeasurementBMI]),
+				MeasuredAt: o.MeasuredAt.In(loc).Format(time.RFC3339),
+				Units:      conv.Units("height", "weight", "bodyfat", "bmi"),
+			})
+	}
+
+	return res
+}
+
+func mapMetricToEntryResponse(m []models.Metric, conv units.Converter, loc *time.Location) []models.MetricEntryResponse {
+	res := make([]models.MetricEntryResponse, 0, len(m))
+
+	for _, o := range m {
+		measurements := make(map[string]float64, len(o.Measurements))
+		codes := make([]string, 0, len(o.Measurements))
+		for code, v := range o.Measurements {
+			measurements[code] = conv.FromStored(code, v)
+			codes = append(codes, code)
+		}
+		res = append(res,
+			models.MetricEntryResponse{
+				ID:           o.ID,
+				ClientID:     o.ClientID,
+				Measurements: measurements,
+				MeasuredAt:   o.MeasuredAt.In(loc).Format(time.RFC3339),
+				Units:        conv.Units(codes...),
 			})
 	}
 
 	return res
 }
 
-func mapPlanToPlanResponse(m []models.TrainingPlan) []models.TrainingPlanResponse {
+func mapMetricSeriesToResponse(s *models.MetricSeries, conv units.Converter) models.MetricSeriesResponse {
+	res := models.MetricSeriesResponse{
+		From:       s.From.Format(time.RFC3339),
+		To:         s.To.Format(time.RFC3339),
+		Resolution: s.Resolution,
+		Points:     make([]models.MetricPointResponse, 0, len(s.Points)),
+		Units:      map[string]string{},
+	}
+
+	for _, p := range s.Points {
+		point := models.MetricPointResponse{
+			Start:  p.Start.Format(time.RFC3339),
+			Values: make(map[string]models.MetricStatResponse, len(p.Values)),
+		}
+		for code, v := range p.Values {
+			point.Values[code] = models.MetricStatResponse{
+				Count: v.Count,
+				Avg:   conv.FromStored(code, v.Avg),
+				Min:   conv.FromStored(code, v.Min),
+				Max:   conv.FromStored(code, v.Max),
+			}
+			if unit := conv.Unit(code); unit != "" {
+				res.Units[code] = unit
+			}
+		}
+		res.Points = append(res.Points, point)
+	}
+
+	return res
+}
+
+// rangeParams parses optional from and to query parameters, see request.Range. Error is rendered if
+// they are malformed.
+func rangeParams(w http.ResponseWriter, r *http.Request, loc *time.Location) (from, to time.Time, ok bool) {
+	from, to, err := request.Range(r, loc)
+	if err != nil {
+		response.RenderError(w, r, apperr.ErrBadRequest.WithMessage(err.Error()))
+
+		return time.Time{}, time.Time{}, false
+	}
+
+	return from, to, true
+}
+
+func mapPlanToPlanResponse(m []models.TrainingPlan, loc *time.Location) []models.TrainingPlanResponse {
 	res := make([]models.TrainingPlanResponse, 0, len(m))
 
 	for _, o := range m {
@@ -718,7 +1214,7 @@ func mapPlanToPlanResponse(m []models.TrainingPlan) []models.TrainingPlanRespons
 				ClientID:    o.ClientID,
 				Description: o.Description,
 				Schedule:    o.Schedule,
-				CreatedAt:   o.CreatedAt.Format("2009-01-02 15:04:05"),
+				CreatedAt:   o.CreatedAt.In(loc).Format(time.RFC3339),
 			})
 	}
 
@@ -6,7 +6,9 @@ package userhandler
 
 import (
 	models "ChadProgress/internal/models"
+	context "context"
 	reflect "reflect"
+	time "time"
 
 	gomock "github.com/golang/mock/gomock"
 )
@@ -35,73 +37,73 @@ func (m *MockUserService) EXPECT() *MockUserServiceMockRecorder {
 }
 
 // AddMetrics mocks base method.
-func (m *MockUserService) AddMetrics(clientEmail string, height, weight, bodyFat, bmi float64, measuredAt models.CustomTime) error {
+func (m *MockUserService) AddMetrics(ctx context.Context, clientEmail string, values models.MeasurementValues, measuredAt time.Time) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "AddMetrics", clientEmail, height, weight, bodyFat, bmi, measuredAt)
+	ret := m.ctrl.Call(m, "AddMetrics", ctx, clientEmail, values, measuredAt)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
 // AddMetrics indicates an expected call of AddMetrics.
-func (mr *MockUserServiceMockRecorder) AddMetrics(clientEmail, height, weight, bodyFat, bmi, measuredAt interface{}) *gomock.Call {
+func (mr *MockUserServiceMockRecorder) AddMetrics(ctx, clientEmail, values, measuredAt interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddMetrics", reflect.TypeOf((*MockUserService)(nil).AddMetrics), clientEmail, height, weight, bodyFat, bmi, measuredAt)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddMetrics", reflect.TypeOf((*MockUserService)(nil).AddMetrics), ctx, clientEmail, values, measuredAt)
 }
 
 // AddProgressReport mocks base method.
-func (m *MockUserService) AddProgressReport(trainerEmail, comments string, clientID uint) error {
+func (m *MockUserService) AddProgressReport(ctx context.Context, trainerEmail, comments string, clientID uint) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "AddProgressReport", trainerEmail, comments, clientID)
+	ret := m.ctrl.Call(m, "AddProgressReport", ctx, trainerEmail, comments, clientID)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
 // AddProgressReport indicates an expected call of AddProgressReport.
-func (mr *MockUserServiceMockRecorder) AddProgressReport(trainerEmail, comments, clientID interface{}) *gomock.Call {
+func (mr *MockUserServiceMockRecorder) AddProgressReport(ctx, trainerEmail, comments, clientID interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddProgressReport", reflect.TypeOf((*MockUserService)(nil).AddProgressReport), trainerEmail, comments, clientID)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddProgressReport", reflect.TypeOf((*MockUserService)(nil).AddProgressReport), ctx, trainerEmail, comments, clientID)
 }
 
 // CreateClient mocks base method.
-func (m *MockUserService) CreateClient(userEmail string, height, weight, bodyFat float64) error {
+func (m *MockUserService) CreateClient(ctx context.Context, userEmail string, height, weight, bodyFat float64) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "CreateClient", userEmail, height, weight, bodyFat)
+	ret := m.ctrl.Call(m, "CreateClient", ctx, userEmail, height, weight, bodyFat)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
 // CreateClient indicates an expected call of CreateClient.
-func (mr *MockUserServiceMockRecorder) CreateClient(userEmail, height, weight, bodyFat interface{}) *gomock.Call {
+func (mr *MockUserServiceMockRecorder) CreateClient(ctx, userEmail, height, weight, bodyFat interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateClient", reflect.TypeOf((*MockUserService)(nil).CreateClient), userEmail, height, weight, bodyFat)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateClient", reflect.TypeOf((*MockUserService)(nil).CreateClient), ctx, userEmail, height, weight, bodyFat)
 }
 
 // CreatePlan mocks base method.
-func (m *MockUserService) CreatePlan(trainerEmail string, clientID uint, description, schedule string) error {
+func (m *MockUserService) CreatePlan(ctx context.Context, trainerEmail string, clientID uint, description, schedule string) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "CreatePlan", trainerEmail, clientID, description, schedule)
+	ret := m.ctrl.Call(m, "CreatePlan", ctx, trainerEmail, clientID, description, schedule)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
 // CreatePlan indicates an expected call of CreatePlan.
-func (mr *MockUserServiceMockRecorder) CreatePlan(trainerEmail, clientID, description, schedule interface{}) *gomock.Call {
+func (mr *MockUserServiceMockRecorder) CreatePlan(ctx, trainerEmail, clientID, description, schedule interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreatePlan", reflect.TypeOf((*MockUserService)(nil).CreatePlan), trainerEmail, clientID, description, schedule)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreatePlan", reflect.TypeOf((*MockUserService)(nil).CreatePlan), ctx, trainerEmail, clientID, description, schedule)
 }
 
 // CreateTrainer mocks base method.
-func (m *MockUserService) CreateTrainer(userEmail, qualification, experience, achievement string) error {
+func (m *MockUserService) CreateTrainer(ctx context.Context, userEmail, qualification, experience, achievement string) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "CreateTrainer", userEmail, qualification, experience, achievement)
+	ret := m.ctrl.Call(m, "CreateTrainer", ctx, userEmail, qualification, experience, achievement)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
 // CreateTrainer indicates an expected call of CreateTrainer.
-func (mr *MockUserServiceMockRecorder) CreateTrainer(userEmail, qualification, experience, achievement interface{}) *gomock.Call {
+func (mr *MockUserServiceMockRecorder) CreateTrainer(ctx, userEmail, qualification, experience, achievement interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateTrainer", reflect.TypeOf((*MockUserService)(nil).CreateTrainer), userEmail, qualification, experience, achievement)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateTrainer", reflect.TypeOf((*MockUserService)(nil).CreateTrainer), ctx, userEmail, qualification, experience, achievement)
 }
 
 // GetClientProfile mocks base method.
@@ -120,18 +122,18 @@ func (mr *MockUserServiceMockRecorder) GetClientProfile(userEmail interface{}) *
 }
 
 // GetMetrics mocks base method.
-func (m *MockUserService) GetMetrics(clientEmail string) ([]models.Metric, error) {
+func (m *MockUserService) GetMetrics(clientEmail string, from, to time.Time) ([]models.Metric, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GetMetrics", clientEmail)
+	ret := m.ctrl.Call(m, "GetMetrics", clientEmail, from, to)
 	ret0, _ := ret[0].([]models.Metric)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // GetMetrics indicates an expected call of GetMetrics.
-func (mr *MockUserServiceMockRecorder) GetMetrics(clientEmail interface{}) *gomock.Call {
+func (mr *MockUserServiceMockRecorder) GetMetrics(clientEmail, from, to interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetMetrics", reflect.TypeOf((*MockUserService)(nil).GetMetrics), clientEmail)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetMetrics", reflect.TypeOf((*MockUserService)(nil).GetMetrics), clientEmail, from, to)
 }
 
 // GetPlan mocks base method.
@@ -194,16 +196,104 @@ func (mr *MockUserServiceMockRecorder) GetTrainersClients(userEmail interface{})
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetTrainersClients", reflect.TypeOf((*MockUserService)(nil).GetTrainersClients), userEmail)
 }
 
+// ImportMetrics mocks base method.
+func (m *MockUserService) ImportMetrics(ctx context.Context, clientEmail string, rows []models.MetricImportRow) (*models.MetricImportReport, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ImportMetrics", ctx, clientEmail, rows)
+	ret0, _ := ret[0].(*models.MetricImportReport)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ImportMetrics indicates an expected call of ImportMetrics.
+func (mr *MockUserServiceMockRecorder) ImportMetrics(ctx, clientEmail, rows interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ImportMetrics", reflect.TypeOf((*MockUserService)(nil).ImportMetrics), ctx, clientEmail, rows)
+}
+
+// MeasurementTypes mocks base method.
+func (m *MockUserService) MeasurementTypes() ([]models.MeasurementType, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "MeasurementTypes")
+	ret0, _ := ret[0].([]models.MeasurementType)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// MeasurementTypes indicates an expected call of MeasurementTypes.
+func (mr *MockUserServiceMockRecorder) MeasurementTypes() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "MeasurementTypes", reflect.TypeOf((*MockUserService)(nil).MeasurementTypes))
+}
+
+// MetricSeries mocks base method.
+func (m *MockUserService) MetricSeries(clientEmail string, from, to time.Time) (*models.MetricSeries, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "MetricSeries", clientEmail, from, to)
+	ret0, _ := ret[0].(*models.MetricSeries)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// MetricSeries indicates an expected call of MetricSeries.
+func (mr *MockUserServiceMockRecorder) MetricSeries(clientEmail, from, to interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "MetricSeries", reflect.TypeOf((*MockUserService)(nil).MetricSeries), clientEmail, from, to)
+}
+
 // SelectTrainer mocks base method.
-func (m *MockUserService) SelectTrainer(userEmail string, trainerID uint) error {
+func (m *MockUserService) SelectTrainer(ctx context.Context, userEmail string, trainerID uint) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "SelectTrainer", userEmail, trainerID)
+	ret := m.ctrl.Call(m, "SelectTrainer", ctx, userEmail, trainerID)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
 // SelectTrainer indicates an expected call of SelectTrainer.
-func (mr *MockUserServiceMockRecorder) SelectTrainer(userEmail, trainerID interface{}) *gomock.Call {
+func (mr *MockUserServiceMockRecorder) SelectTrainer(ctx, userEmail, trainerID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SelectTrainer", reflect.TypeOf((*MockUserService)(nil).SelectTrainer), ctx, userEmail, trainerID)
+}
+
+// SetGoals mocks base method.
+func (m *MockUserService) SetGoals(ctx context.Context, clientEmail string, goals models.MeasurementValues) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetGoals", ctx, clientEmail, goals)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SetGoals indicates an expected call of SetGoals.
+func (mr *MockUserServiceMockRecorder) SetGoals(ctx, clientEmail, goals interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetGoals", reflect.TypeOf((*MockUserService)(nil).SetGoals), ctx, clientEmail, goals)
+}
+
+// Settings mocks base method.
+func (m *MockUserService) Settings(userEmail string) (*models.UserSettings, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Settings", userEmail)
+	ret0, _ := ret[0].(*models.UserSettings)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Settings indicates an expected call of Settings.
+func (mr *MockUserServiceMockRecorder) Settings(userEmail interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Settings", reflect.TypeOf((*MockUserService)(nil).Settings), userEmail)
+}
+
+// UpdateSettings mocks base method.
+func (m *MockUserService) UpdateSettings(ctx context.Context, userEmail string, settings models.UserSettings) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateSettings", ctx, userEmail, settings)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateSettings indicates an expected call of UpdateSettings.
+func (mr *MockUserServiceMockRecorder) UpdateSettings(ctx, userEmail, settings interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SelectTrainer", reflect.TypeOf((*MockUserService)(nil).SelectTrainer), userEmail, trainerID)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateSettings", reflect.TypeOf((*MockUserService)(nil).UpdateSettings), ctx, userEmail, settings)
 }
@@ -8,12 +8,14 @@ import (
 	"net/http/httptest"
 	"strings"
 	"testing"
+	"time"
 
 	"ChadProgress/internal/models"
 	service "ChadProgress/internal/services"
 
 	gomock "github.com/golang/mock/gomock"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestCreateTrainer(t *testing.T) {
@@ -37,9 +39,9 @@ func TestCreateTrainer(t *testing.T) {
 			name:         "Invalid role (client exists)",
 			email:        "client@example.com",
 			requestBody:  `{"qualification":"Certified","experience":"5 years","achievement":"Champion"}`,
-			mockError:    service.ErrInvalidRoleRequest,
-			expectedCode: http.StatusBadRequest,
-			expectedResp: `"cannot create trainer profile while being client"`,
+			mockError:    service.ErrInvalidRoleRequest.WithMessage("cannot create trainer profile while being client"),
+			expectedCode: http.StatusForbidden,
+			expectedResp: `"error":"cannot create trainer profile while being client","code":"invalid_role"`,
 		},
 		{
 			name:         "Validation error",
@@ -47,7 +49,7 @@ func TestCreateTrainer(t *testing.T) {
 			requestBody:  `{"experience":"5 years"}`,
 			mockError:    nil,
 			expectedCode: http.StatusBadRequest,
-			expectedResp: `{"status":"Error","error":"field Qualification is a required field, field Achievement is a required field"}`,
+			expectedResp: `{"status":"Error","error":"field Qualification is a required field, field Achievement is a required field","code":"validation_failed"`,
 		},
 	}
 
@@ -65,7 +67,7 @@ func TestCreateTrainer(t *testing.T) {
 
 			if tt.mockError != nil || tt.expectedCode == http.StatusOK {
 				mockService.EXPECT().
-					CreateTrainer(tt.email, gomock.Any(), gomock.Any(), gomock.Any()).
+					CreateTrainer(gomock.Any(), tt.email, gomock.Any(), gomock.Any(), gomock.Any()).
 					Return(tt.mockError)
 			}
 
@@ -80,3 +82,369 @@ func TestCreateTrainer(t *testing.T) {
 		})
 	}
 }
+
+func TestImportMetrics(t *testing.T) {
+	email := "client@example.com"
+	report := &models.MetricImportReport{Accepted: 1, Rows: []models.MetricImportResult{{Row: 2, Status: models.ImportAccepted}}}
+
+	tests := []struct {
+		name         string
+		contentType  string
+		query        string
+		file         string
+		rows         []models.MetricImportRow
+		expectedCode int
+		expectedResp string
+	}{
+		{
+			name:        "CSV in imperial units",
+			contentType: "text/csv; charset=utf-8",
+			query:       "?units=imperial&column=Date:measured-at&column=Weight:weight",
+			file:        "Date,Weight\n2026-10-19T08:00:00Z,180\n",
+			rows: []models.MetricImportRow{
+				{Row: 2, Measurements: models.MeasurementValues{"weight": 180 * 0.45359237}, MeasuredAt: time.Date(2026, time.October, 19, 8, 0, 0, 0, time.UTC)},
+			},
+			expectedCode: http.StatusOK,
+			expectedResp: `{"accepted":1,"rejected":0,"duplicates":0,"rows":[{"row":2,"status":"accepted"}]}`,
+		},
+		{
+			name:        "JSON",
+			contentType: "application/json",
+			file:        `[{"measurements":{"weight":80},"measured-at":"2026-10-19T08:00:00Z"}]`,
+			rows: []models.MetricImportRow{
+				{Row: 1, Measurements: models.MeasurementValues{"weight": 80}, MeasuredAt: time.Date(2026, time.October, 19, 8, 0, 0, 0, time.UTC)},
+			},
+			expectedCode: http.StatusOK,
+			expectedResp: `"accepted":1`,
+		},
+		{
+			name:         "Malformed CSV",
+			contentType:  "text/csv",
+			file:         "weight\n80\n",
+			expectedCode: http.StatusBadRequest,
+			expectedResp: `"error":"file has no measured-at column"`,
+		},
+		{
+			name:         "Unsupported file",
+			contentType:  "application/vnd.ms-excel",
+			expectedCode: http.StatusBadRequest,
+			expectedResp: `"error":"file must be application/json or text/csv"`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			mockService := NewMockUserService(ctrl)
+			logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+
+			ctx := context.WithValue(context.Background(), models.ContextUserKey, email)
+			req, _ := http.NewRequestWithContext(ctx, "POST", "/clients/metrics/import"+tt.query, strings.NewReader(tt.file))
+			req.Header.Set("Content-Type", tt.contentType)
+
+			mockService.EXPECT().Settings(email).Return(&models.UserSettings{Units: "metric", Timezone: "UTC"}, nil)
+			if strings.Contains(tt.query, "imperial") {
+				mockService.EXPECT().MeasurementTypes().Return(models.DefaultMeasurementTypes, nil)
+			}
+			if tt.rows != nil {
+				mockService.EXPECT().ImportMetrics(gomock.Any(), email, gomock.Any()).
+					DoAndReturn(func(_ context.Context, _ string, rows []models.MetricImportRow) (*models.MetricImportReport, error) {
+						require.Len(t, rows, len(tt.rows))
+						for i := range rows {
+							assert.True(t, tt.rows[i].MeasuredAt.Equal(rows[i].MeasuredAt))
+							assert.Equal(t, tt.rows[i].Row, rows[i].Row)
+							assert.InDeltaMapValues(t, tt.rows[i].Measurements, rows[i].Measurements, 1e-9)
+						}
+						return report, nil
+					})
+			}
+
+			handler := NewUserHandler(logger, mockService)
+			rr := httptest.NewRecorder()
+			handler.ImportMetrics(rr, req)
+
+			assert.Equal(t, tt.expectedCode, rr.Code)
+			assert.Contains(t, rr.Body.String(), tt.expectedResp)
+		})
+	}
+}
+
+func TestMetricSeries(t *testing.T) {
+	email := "client@example.com"
+	day := time.Date(2026, time.October, 1, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name         string
+		query        string
+		units        string
+		timezone     string
+		from, to     time.Time
+		expectedCode int
+		expectedResp string
+	}{
+		{
+			name:         "Dates include the last day",
+			query:        "?from=2026-10-01&to=2026-10-19",
+			from:         day,
+			to:           time.Date(2026, time.October, 20, 0, 0, 0, 0, time.UTC),
+			expectedCode: http.StatusOK,
+			expectedResp: `"resolution":"raw"`,
+		},
+		{
+			name:         "RFC 3339 times",
+			query:        "?from=2026-10-01T00:00:00Z&to=2026-10-19T10:30:00%2B03:00",
+			from:         day,
+			to:           time.Date(2026, time.October, 19, 7, 30, 0, 0, time.UTC),
+			expectedCode: http.StatusOK,
+			expectedResp: `"resolution":"raw"`,
+		},
+		{
+			name:         "Dates are in time zone of user",
+			query:        "?from=2026-10-01&to=2026-10-19",
+			timezone:     "Europe/Moscow",
+			from:         day.Add(-3 * time.Hour),
+			to:           time.Date(2026, time.October, 19, 21, 0, 0, 0, time.UTC),
+			expectedCode: http.StatusOK,
+			expectedResp: `"from":"2026-10-01T00:00:00+03:00"`,
+		},
+		{
+			name:         "Open range",
+			expectedCode: http.StatusOK,
+			expectedResp: `"points":[`,
+		},
+		{
+			name:         "Imperial units of user",
+			query:        "?from=2026-10-01&to=2026-10-19",
+			units:        "imperial",
+			from:         day,
+			to:           time.Date(2026, time.October, 20, 0, 0, 0, 0, time.UTC),
+			expectedCode: http.StatusOK,
+			expectedResp: `"values":{"weight":{"count":1,"avg":176.37,"min":176.37,"max":176.37}}}],"units":{"weight":"lb"}`,
+		},
+		{
+			name:         "Unknown unit system",
+			query:        "?units=stones",
+			expectedCode: http.StatusBadRequest,
+			expectedResp: `"field":"units"`,
+		},
+		{
+			name:         "Malformed from",
+			query:        "?from=yesterday",
+			expectedCode: http.StatusBadRequest,
+			expectedResp: `"error":"from must be a date or RFC 3339 time"`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			mockService := NewMockUserService(ctrl)
+			logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+
+			ctx := context.WithValue(context.Background(), models.ContextUserKey, email)
+			req, _ := http.NewRequestWithContext(ctx, "GET", "/clients/metrics/series"+tt.query, nil)
+
+			// settings are read unless requested units are unknown
+			if !strings.Contains(tt.query, "units=") {
+				system := tt.units
+				if system == "" {
+					system = "metric"
+				}
+				mockService.EXPECT().Settings(email).Return(&models.UserSettings{Units: system, Timezone: tt.timezone}, nil)
+			}
+			if tt.expectedCode == http.StatusOK {
+				mockService.EXPECT().MeasurementTypes().Return(models.DefaultMeasurementTypes, nil)
+				mockService.EXPECT().
+					MetricSeries(email, gomock.Any(), gomock.Any()).
+					DoAndReturn(func(_ string, from, to time.Time) (*models.MetricSeries, error) {
+						assert.True(t, tt.from.Equal(from), "from %s", from)
+						assert.True(t, tt.to.Equal(to), "to %s", to)
+						return &models.MetricSeries{From: from, To: to, Resolution: models.ResolutionRaw, Points: []models.MetricPoint{
+							{Start: from, Values: map[string]models.MetricStat{"weight": {Count: 1, Avg: 80, Min: 80, Max: 80}}},
+						}}, nil
+					})
+			}
+
+			handler := NewUserHandler(logger, mockService)
+			rr := httptest.NewRecorder()
+			handler.MetricSeries(rr, req)
+
+			assert.Equal(t, tt.expectedCode, rr.Code)
+			assert.Contains(t, rr.Body.String(), tt.expectedResp)
+		})
+	}
+}
+
+func TestAddMetrics(t *testing.T) {
+	email := "client@example.com"
+
+	tests := []struct {
+		name        string
+		requestBody string
+		units       string
+		timezone    string
+		values      models.MeasurementValues
+		measuredAt  time.Time
+	}{
+		{
+			name:        "Legacy fields",
+			requestBody: `{"height":180,"weight":80.5,"measured-at":"2026-10-19 08:00:00"}`,
+			values:      models.MeasurementValues{"height": 180, "weight": 80.5},
+			measuredAt:  time.Date(2026, time.October, 19, 8, 0, 0, 0, time.UTC),
+		},
+		{
+			name:        "Legacy time is taken in time zone of user",
+			requestBody: `{"weight":80.5,"measured-at":"2026-10-19 08:00:00"}`,
+			timezone:    "Europe/Moscow",
+			values:      models.MeasurementValues{"weight": 80.5},
+			measuredAt:  time.Date(2026, time.October, 19, 5, 0, 0, 0, time.UTC),
+		},
+		{
+			name:        "RFC 3339 time",
+			requestBody: `{"weight":80.5,"measured-at":"2026-10-19T08:00:00-05:00"}`,
+			timezone:    "Europe/Moscow",
+			values:      models.MeasurementValues{"weight": 80.5},
+			measuredAt:  time.Date(2026, time.October, 19, 13, 0, 0, 0, time.UTC),
+		},
+		{
+			name:        "Measurements by code override legacy fields",
+			requestBody: `{"weight":80,"measurements":{"weight":79.5,"waist":84}}`,
+			values:      models.MeasurementValues{"weight": 79.5, "waist": 84},
+		},
+		{
+			name:        "Imperial values are stored in SI units",
+			requestBody: `{"measurements":{"height":70,"weight":180,"resting_heart_rate":58}}`,
+			units:       "imperial",
+			values:      models.MeasurementValues{"height": 70 * 2.54, "weight": 180 * 0.45359237, "resting_heart_rate": 58},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			mockService := NewMockUserService(ctrl)
+			logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+
+			ctx := context.WithValue(context.Background(), models.ContextUserKey, email)
+			req, _ := http.NewRequestWithContext(ctx, "POST", "/clients/metrics", strings.NewReader(tt.requestBody))
+			req.Header.Set("Content-Type", "application/json")
+			if tt.units != "" {
+				req.Header.Set("Accept-Units", tt.units)
+				mockService.EXPECT().MeasurementTypes().Return(models.DefaultMeasurementTypes, nil)
+			}
+			mockService.EXPECT().Settings(email).Return(&models.UserSettings{Units: "metric", Timezone: tt.timezone}, nil)
+			mockService.EXPECT().AddMetrics(gomock.Any(), email, tt.values, gomock.Any()).
+				DoAndReturn(func(_ context.Context, _ string, _ models.MeasurementValues, measuredAt time.Time) error {
+					if !tt.measuredAt.IsZero() {
+						assert.True(t, tt.measuredAt.Equal(measuredAt), "measured at %s", measuredAt)
+					}
+					return nil
+				})
+
+			handler := NewUserHandler(logger, mockService)
+			rr := httptest.NewRecorder()
+			handler.AddMetrics(rr, req)
+
+			assert.Equal(t, http.StatusOK, rr.Code, rr.Body.String())
+		})
+	}
+}
+
+func TestSetGoals(t *testing.T) {
+	email := "client@example.com"
+
+	tests := []struct {
+		name        string
+		requestBody string
+		units       string
+		goals       models.MeasurementValues
+	}{
+		{
+			name:        "Metric goals",
+			requestBody: `{"goals":{"weight":75,"body_fat":15}}`,
+			goals:       models.MeasurementValues{"weight": 75, "body_fat": 15},
+		},
+		{
+			name:        "Imperial goals are stored in SI units",
+			requestBody: `{"goals":{"weight":165,"waist":32}}`,
+			units:       "imperial",
+			goals:       models.MeasurementValues{"weight": 165 * 0.45359237, "waist": 32 * 2.54},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			mockService := NewMockUserService(ctrl)
+			logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+
+			ctx := context.WithValue(context.Background(), models.ContextUserKey, email)
+			req, _ := http.NewRequestWithContext(ctx, "PUT", "/clients/goals", strings.NewReader(tt.requestBody))
+			req.Header.Set("Content-Type", "application/json")
+			if tt.units != "" {
+				req.Header.Set("Accept-Units", tt.units)
+				mockService.EXPECT().MeasurementTypes().Return(models.DefaultMeasurementTypes, nil)
+			}
+			mockService.EXPECT().Settings(email).Return(&models.UserSettings{Units: "metric"}, nil)
+			mockService.EXPECT().SetGoals(gomock.Any(), email, tt.goals).Return(nil)
+
+			handler := NewUserHandler(logger, mockService)
+			rr := httptest.NewRecorder()
+			handler.SetGoals(rr, req)
+
+			assert.Equal(t, http.StatusOK, rr.Code, rr.Body.String())
+		})
+	}
+}
+
+func TestTimesInZoneOfUser(t *testing.T) {
+	email := "client@example.com"
+	created := time.Date(2026, time.October, 19, 8, 0, 0, 0, time.UTC)
+	body := `{"trainer-id":1,"client-id":2}`
+
+	tests := []struct {
+		name         string
+		mock         func(mockService *MockUserService)
+		handle       func(handler *UserHandler) http.HandlerFunc
+		expectedResp string
+	}{
+		{
+			name: "Training plan",
+			mock: func(mockService *MockUserService) {
+				mockService.EXPECT().GetPlan(email, uint(1), uint(2)).
+					Return([]models.TrainingPlan{{ID: 3, TrainerID: 1, ClientID: 2, CreatedAt: created}}, nil)
+			},
+			handle:       func(handler *UserHandler) http.HandlerFunc { return handler.GetPlan },
+			expectedResp: `"created-at":"2026-10-19T11:00:00+03:00"`,
+		},
+		{
+			name: "Progress report",
+			mock: func(mockService *MockUserService) {
+				mockService.EXPECT().GetProgressReport(email, uint(1), uint(2)).
+					Return([]models.ProgressReport{{ID: 3, TrainerID: 1, ClientID: 2, CreatedAt: created}}, nil)
+			},
+			handle:       func(handler *UserHandler) http.HandlerFunc { return handler.GetProgressReports },
+			expectedResp: `"CreatedAt":"2026-10-19T11:00:00+03:00"`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			mockService := NewMockUserService(ctrl)
+			logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+
+			ctx := context.WithValue(context.Background(), models.ContextUserKey, email)
+			req, _ := http.NewRequestWithContext(ctx, "GET", "/", strings.NewReader(body))
+			req.Header.Set("Content-Type", "application/json")
+			mockService.EXPECT().Settings(email).Return(&models.UserSettings{Units: "metric", Timezone: "Europe/Moscow"}, nil)
+			tt.mock(mockService)
+
+			rr := httptest.NewRecorder()
+			tt.handle(NewUserHandler(logger, mockService))(rr, req)
+
+			assert.Equal(t, http.StatusOK, rr.Code, rr.Body.String())
+			assert.Contains(t, rr.Body.String(), tt.expectedResp)
+		})
+	}
+}
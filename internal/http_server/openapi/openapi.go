@@ -0,0 +1,223 @@
+package openapi
+
+import (
+	"net/http"
+	"strings"
+
+	"ChadProgress/internal/lib/api/response"
+
+	"github.com/go-chi/render"
+)
+
+const (
+	Version = "3.0.3"
+
+	jsonContentType   = "application/json"
+	bearerAuthScheme  = "bearerAuth"
+	errorSchemaName   = "Error"
+	problemSchemaName = "Problem"
+)
+
+// Document is the subset of OpenAPI 3 document used to describe ChadProgress API
+type Document struct {
+	OpenAPI    string              `json:"openapi"`
+	Info       Info                `json:"info"`
+	Paths      map[string]PathItem `json:"paths"`
+	Components Components          `json:"components"`
+}
+
+type Info struct {
+	Title       string `json:"title"`
+	Description string `json:"description,omitempty"`
+	Version     string `json:"version"`
+}
+
+// PathItem maps lowercase HTTP method to operation
+type PathItem map[string]*Operation
+
+type Operation struct {
+	OperationID string                `json:"operationId"`
+	Summary     string                `json:"summary,omitempty"`
+	Tags        []string              `json:"tags,omitempty"`
+	Parameters  []Parameter           `json:"parameters,omitempty"`
+	RequestBody *RequestBody          `json:"requestBody,omitempty"`
+	Responses   map[string]Response   `json:"responses"`
+	Security    []map[string][]string `json:"security,omitempty"`
+	Deprecated  bool                  `json:"deprecated,omitempty"`
+}
+
+type Parameter struct {
+	Name        string  `json:"name"`
+	In          string  `json:"in"`
+	Description string  `json:"description,omitempty"`
+	Required    bool    `json:"required,omitempty"`
+	Schema      *Schema `json:"schema"`
+}
+
+type RequestBody struct {
+	Required bool                 `json:"required"`
+	Content  map[string]MediaType `json:"content"`
+}
+
+type Response struct {
+	Description string               `json:"description"`
+	Headers     map[string]Header    `json:"headers,omitempty"`
+	Content     map[string]MediaType `json:"content,omitempty"`
+}
+
+type Header struct {
+	Description string  `json:"description,omitempty"`
+	Schema      *Schema `json:"schema"`
+}
+
+type MediaType struct {
+	Schema *Schema `json:"schema"`
+}
+
+type Components struct {
+	Schemas         map[string]*Schema        `json:"schemas"`
+	SecuritySchemes map[string]SecurityScheme `json:"securitySchemes"`
+}
+
+type SecurityScheme struct {
+	Type         string `json:"type"`
+	Scheme       string `json:"scheme"`
+	BearerFormat string `json:"bearerFormat,omitempty"`
+}
+
+// Spec builds OpenAPI document for all routes from Routes
+func Spec() *Document {
+	doc := &Document{
+		OpenAPI: Version,
+		Info: Info{
+			Title:       "ChadProgress API",
+			Description: "Trainers and clients progress tracking. Error codes are described in docs/errors.md",
+			Version:     "1.0.0",
+		},
+		Paths: make(map[string]PathItem),
+		Components: Components{
+			Schemas: make(map[string]*Schema),
+			SecuritySchemes: map[string]SecurityScheme{
+				bearerAuthScheme: {Type: "http", Scheme: "bearer", BearerFormat: "JWT"},
+			},
+		},
+	}
+
+	g := newGenerator(doc.Components.Schemas)
+	g.schemaRef(response.Response{}, errorSchemaName)
+	g.schemaRef(response.Problem{}, problemSchemaName)
+
+	for _, route := range Routes {
+		g.addOperation(doc, PrefixV1, route)
+		g.addOperation(doc, PrefixV2, routeV2(route))
+
+		legacy := route
+		legacy.Deprecated = true
+		g.addOperation(doc, "", legacy)
+	}
+
+	return doc
+}
+
+func (g *generator) addOperation(doc *Document, prefix string, route Route) {
+	path := prefix + route.Path
+	item, ok := doc.Paths[path]
+	if !ok {
+		item = make(PathItem)
+		doc.Paths[path] = item
+	}
+
+	op := g.operation(route)
+	op.OperationID = operationID(prefix, route.OperationID)
+	item[strings.ToLower(route.Method)] = op
+}
+
+func (g *generator) operation(route Route) *Operation {
+	op := &Operation{
+		Summary:    route.Summary,
+		Tags:       []string{route.Tag},
+		Parameters: route.Parameters,
+		Responses:  make(map[string]Response),
+		Deprecated: route.Deprecated,
+	}
+
+	if route.Request != nil {
+		op.RequestBody = &RequestBody{
+			Required: true,
+			Content:  map[string]MediaType{jsonContentType: {Schema: g.schemaRef(route.Request, "")}},
+		}
+		for _, t := range route.RequestFiles {
+			op.RequestBody.Content[t] = MediaType{Schema: &Schema{Type: "string", Format: "binary"}}
+		}
+	}
+
+	success := Response{Description: "OK"}
+	if len(route.ContentTypes) > 0 {
+		success.Content = make(map[string]MediaType, len(route.ContentTypes))
+		for _, t := range route.ContentTypes {
+			success.Content[t] = MediaType{Schema: &Schema{Type: "string", Format: "binary"}}
+		}
+	} else if route.Response != nil {
+		success.Content = map[string]MediaType{jsonContentType: {Schema: g.schemaRef(route.Response, "")}}
+	}
+	if route.Deprecated {
+		success.Headers = deprecationHeaders
+	}
+	op.Responses["200"] = success
+	if route.Accepted != nil {
+		op.Responses["202"] = Response{
+			Description: "Accepted, operation continues in background",
+			Content:     map[string]MediaType{jsonContentType: {Schema: g.schemaRef(route.Accepted, "")}},
+		}
+	}
+
+	errResp := Response{
+		Description: "Error, see docs/errors.md for codes",
+		Content: map[string]MediaType{
+			jsonContentType:             {Schema: &Schema{Ref: componentRef(errorSchemaName)}},
+			response.ProblemContentType: {Schema: &Schema{Ref: componentRef(problemSchemaName)}},
+		},
+	}
+	op.Responses["default"] = errResp
+	op.Responses["500"] = errResp
+	op.Responses["429"] = Response{
+		Description: "Rate limit exceeded or account temporarily locked",
+		Headers:     map[string]Header{"Retry-After": {Description: "Seconds to wait before retrying", Schema: &Schema{Type: "integer"}}},
+		Content:     errResp.Content,
+	}
+	if route.Request != nil || len(route.Parameters) > 0 {
+		op.Responses["400"] = errResp
+	}
+
+	if !route.Public {
+		op.Security = []map[string][]string{{bearerAuthScheme: {}}}
+		op.Responses["401"] = errResp
+	}
+
+	return op
+}
+
+var deprecationHeaders = map[string]Header{
+	"Deprecation": {Description: "Moment the endpoint became deprecated (RFC 9745)", Schema: &Schema{Type: "string"}},
+	"Sunset":      {Description: "Moment the endpoint stops working (RFC 8594)", Schema: &Schema{Type: "string"}},
+	"Link":        {Description: "Successor version of the endpoint", Schema: &Schema{Type: "string"}},
+}
+
+// operationID makes operation ids unique across API versions
+func operationID(prefix, id string) string {
+	switch prefix {
+	case PrefixV1:
+		return string(V1) + "_" + id
+	case PrefixV2:
+		return string(V2) + "_" + id
+	default:
+		return "legacy_" + id
+	}
+}
+
+// Handler serves OpenAPI document as JSON
+func Handler(spec *Document) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		render.JSON(w, r, spec)
+	}
+}
@@ -0,0 +1,396 @@
+package openapi
+
+import (
+	"net/http"
+
+	accounthandler "ChadProgress/internal/http_server/handlers/url/account"
+	adminhandler "ChadProgress/internal/http_server/handlers/url/admin"
+	"ChadProgress/internal/http_server/handlers/url/authorization"
+	exporthandler "ChadProgress/internal/http_server/handlers/url/export"
+	sessionhandler "ChadProgress/internal/http_server/handlers/url/session"
+	userhandler "ChadProgress/internal/http_server/handlers/url/user"
+	"ChadProgress/internal/lib/api/response"
+	"ChadProgress/internal/lib/chart"
+	"ChadProgress/internal/lib/units"
+	"ChadProgress/internal/models"
+)
+
+// Route describes a single API operation. Request and Response hold zero values of body types,
+// schemas are derived from them, so handler structs remain the single source of truth.
+type Route struct {
+	Method      string
+	Path        string
+	OperationID string
+	Summary     string
+	Tag         string
+	Parameters  []Parameter
+	Request     any
+	// RequestFiles lists media types of files which may be sent instead of JSON Request
+	RequestFiles []string
+	Response     any
+	// ContentTypes replace JSON Response with a file of one of the types
+	ContentTypes []string
+	// Accepted is the body of 202 response for operations that may complete in background
+	Accepted   any
+	Public     bool
+	Deprecated bool
+}
+
+type APIVersion string
+
+const (
+	V1 APIVersion = "v1"
+	V2 APIVersion = "v2"
+
+	PrefixV1 = "/api/v1"
+	PrefixV2 = "/api/v2"
+)
+
+const (
+	tagAuthorization = "authorization"
+	tagTrainer       = "trainer"
+	tagClient        = "client"
+	tagCommon        = "common"
+	tagSession       = "session"
+	tagAccount       = "account"
+	tagExport        = "export"
+	tagReport        = "report"
+	tagAdmin         = "admin"
+)
+
+// Routes lists v1 routes with paths relative to version prefix. The same routes are registered
+// unversioned (deprecated) and under v2 with V2Overrides applied. Keep it in sync with router: router tests fail otherwise.
+var Routes = []Route{
+	{
+		Method: http.MethodPost, Path: "/authorization/register", OperationID: "register", Tag: tagAuthorization,
+		Summary: "Register new user and get token",
+		Request: authorization.RegisterRequest{}, Response: authorization.RegisterResponse{}, Public: true,
+	},
+	{
+		Method: http.MethodPost, Path: "/authorization/login", OperationID: "login", Tag: tagAuthorization,
+		Summary: "Sign in and get token",
+		Request: authorization.LoginRequest{}, Response: authorization.LoginResponse{}, Public: true,
+	},
+	{
+		Method: http.MethodPost, Path: "/authorization/refresh", OperationID: "refreshToken", Tag: tagAuthorization,
+		Summary: "Exchange refresh token for new pair of tokens",
+		Request: authorization.RefreshRequest{}, Response: authorization.LoginResponse{}, Public: true,
+	},
+	{
+		Method: http.MethodPost, Path: "/authorization/logout", OperationID: "logout", Tag: tagAuthorization,
+		Summary: "Sign out of current session",
+		Request: authorization.LogoutRequest{}, Response: response.Response{},
+	},
+	{
+		Method: http.MethodPost, Path: "/authorization/verify-email", OperationID: "verifyEmail", Tag: tagAuthorization,
+		Summary: "Confirm email with token from verification email",
+		Request: authorization.VerifyEmailRequest{}, Response: response.Response{}, Public: true,
+	},
+	{
+		Method: http.MethodPost, Path: "/authorization/password/forgot", OperationID: "forgotPassword", Tag: tagAuthorization,
+		Summary: "Send password reset link to email",
+		Request: authorization.ForgotPasswordRequest{}, Response: response.Response{}, Public: true,
+	},
+	{
+		Method: http.MethodPost, Path: "/authorization/password/reset", OperationID: "resetPassword", Tag: tagAuthorization,
+		Summary: "Set new password with token from reset email",
+		Request: authorization.ResetPasswordRequest{}, Response: response.Response{}, Public: true,
+	},
+	{
+		Method: http.MethodPost, Path: "/authorization/email/confirm", OperationID: "confirmEmailChange", Tag: tagAuthorization,
+		Summary: "Change email with token sent to the new address",
+		Request: authorization.VerifyEmailRequest{}, Response: response.Response{}, Public: true,
+	},
+	{
+		Method: http.MethodPost, Path: "/user/trainers/profile", OperationID: "createTrainerProfile", Tag: tagTrainer,
+		Summary: "Create trainer profile for current user",
+		Request: userhandler.CreateTrainerProfileRequest{}, Response: response.Response{},
+	},
+	{
+		Method: http.MethodGet, Path: "/user/trainers/profile", OperationID: "getTrainerProfile", Tag: tagTrainer,
+		Summary:  "Get trainer profile of current user",
+		Response: userhandler.GetTrainerProfileResponse{}, Deprecated: true,
+	},
+	{
+		Method: http.MethodGet, Path: "/user/trainers/clients", OperationID: "getTrainersClients", Tag: tagTrainer,
+		Summary:    "List clients of current trainer",
+		Parameters: unitsParameters,
+		Response:   []models.ClientResponse{},
+	},
+	{
+		Method: http.MethodPost, Path: "/user/training-plan", OperationID: "createTrainingPlan", Tag: tagTrainer,
+		Summary: "Create training plan for a client",
+		Request: userhandler.CreatePlanRequest{}, Response: response.Response{},
+	},
+	{
+		Method: http.MethodPost, Path: "/user/progress-reports", OperationID: "addProgressReport", Tag: tagTrainer,
+		Summary: "Add progress report for a client",
+		Request: userhandler.AddProgressReportRequest{}, Response: response.Response{},
+	},
+	{
+		Method: http.MethodPost, Path: "/user/clients/profile", OperationID: "createClientProfile", Tag: tagClient,
+		Summary:    "Create client profile for current user",
+		Parameters: unitsParameters,
+		Request:    userhandler.CreateClientRequest{}, Response: response.Response{},
+	},
+	{
+		Method: http.MethodGet, Path: "/user/clients/profile", OperationID: "getClientProfile", Tag: tagClient,
+		Summary:    "Get client profile of current user",
+		Parameters: unitsParameters,
+		Response:   userhandler.GetClientProfileResponse{},
+	},
+	{
+		Method: http.MethodPatch, Path: "/user/clients/select-trainers", OperationID: "selectTrainer", Tag: tagClient,
+		Summary: "Bind current client to a trainer",
+		Request: userhandler.SelectTrainerRequest{}, Response: response.Response{},
+	},
+	{
+		Method: http.MethodPost, Path: "/user/clients/metrics", OperationID: "addMetrics", Tag: tagClient,
+		Summary:    "Add measurements of current client, types are listed by /user/measurement-types",
+		Parameters: unitsParameters,
+		Request:    userhandler.AddMetricsRequest{}, Response: response.Response{},
+	},
+	{
+		Method: http.MethodPut, Path: "/user/clients/goals", OperationID: "setGoals", Tag: tagClient,
+		Summary:    "Replace goals of current client, target values by code of measurement type",
+		Parameters: unitsParameters,
+		Request:    userhandler.GoalsRequest{}, Response: response.Response{},
+	},
+	{
+		Method: http.MethodPost, Path: "/user/clients/metrics/import", OperationID: "importMetrics", Tag: tagClient,
+		Summary:    "Import metrics of current client from JSON array or CSV file, reporting what became of every row",
+		Parameters: append(importParameters, unitsParameters...),
+		Request:    []userhandler.AddMetricsRequest{}, RequestFiles: []string{"text/csv"},
+		Response: models.MetricImportReport{},
+	},
+	{
+		Method: http.MethodGet, Path: "/user/clients/metrics", OperationID: "getMetrics", Tag: tagClient,
+		Summary:    "List body metrics of current client",
+		Parameters: append(rangeParameters, unitsParameters...),
+		Response:   []models.MetricResponse{},
+	},
+	{
+		Method: http.MethodGet, Path: "/user/clients/metrics/series", OperationID: "getMetricSeries", Tag: tagClient,
+		Summary:    "Metrics of current client for charts, averaged by day or week over long ranges",
+		Parameters: append(rangeParameters, unitsParameters...),
+		Response:   models.MetricSeriesResponse{},
+	},
+	{
+		Method: http.MethodGet, Path: "/user/progress-reports", OperationID: "getProgressReports", Tag: tagCommon,
+		Summary: "List progress reports of trainer and client pair",
+		Request: userhandler.GetProgressReportRequest{}, Response: []models.ProgressReport{},
+	},
+	{
+		Method: http.MethodGet, Path: "/user/training-plan", OperationID: "getTrainingPlans", Tag: tagCommon,
+		Summary: "List training plans of trainer and client pair",
+		Request: userhandler.GetPlanRequest{}, Response: []models.TrainingPlanResponse{},
+	},
+	{
+		Method: http.MethodGet, Path: "/user/measurement-types", OperationID: "getMeasurementTypes", Tag: tagCommon,
+		Summary:    "List measurement catalog: types of measurements, their units and valid ranges",
+		Parameters: unitsParameters,
+		Response:   []userhandler.MeasurementTypeResponse{},
+	},
+	{
+		Method: http.MethodGet, Path: "/user/reports/clients/{id}/metrics", OperationID: "exportClientMetrics", Tag: tagReport,
+		Summary:      "Download metrics of client as CSV or XLSX, clients export their own metrics and trainers those of their clients",
+		Parameters:   append(append([]Parameter{reportClientParameter, reportFormatParameter}, rangeParameters...), unitsParameters...),
+		ContentTypes: []string{models.ContentTypeCSV, models.ContentTypeXLSX},
+	},
+	{
+		Method: http.MethodGet, Path: "/user/reports/clients/{id}/summary", OperationID: "getClientSummary", Tag: tagReport,
+		Summary:      "Download PDF report of client progress with goals, charts, training plan and trainer comments",
+		Parameters:   append([]Parameter{reportClientParameter}, unitsParameters...),
+		ContentTypes: []string{models.ContentTypePDF},
+	},
+	{
+		Method: http.MethodGet, Path: "/user/reports/clients/{id}/charts/{code}", OperationID: "getClientChart", Tag: tagReport,
+		Summary:      "Render SVG line chart of measurement of client with goal line and moving average, the last 90 days by default",
+		Parameters:   append(append(append([]Parameter{reportClientParameter}, chartParameters...), rangeParameters...), unitsParameters...),
+		ContentTypes: []string{models.ContentTypeSVG},
+	},
+	{
+		Method: http.MethodGet, Path: "/user/settings", OperationID: "getSettings", Tag: tagAccount,
+		Summary:  "Get preferences of current user",
+		Response: userhandler.SettingsResponse{},
+	},
+	{
+		Method: http.MethodPatch, Path: "/user/settings", OperationID: "updateSettings", Tag: tagAccount,
+		Summary: "Change preferences of current user, omitted fields are left unchanged",
+		Request: userhandler.SettingsRequest{}, Response: response.Response{},
+	},
+	{
+		Method: http.MethodPost, Path: "/user/email/verification", OperationID: "resendVerification", Tag: tagAccount,
+		Summary:  "Send new email verification link to current user",
+		Response: response.Response{},
+	},
+	{
+		Method: http.MethodPost, Path: "/user/email", OperationID: "changeEmail", Tag: tagAccount,
+		Summary: "Send confirmation link to the new email of current user",
+		Request: accounthandler.ChangeEmailRequest{}, Response: response.Response{},
+	},
+	{
+		Method: http.MethodDelete, Path: "/user/account", OperationID: "deleteAccount", Tag: tagAccount,
+		Summary: "Delete account of current user according to retention policy",
+		Request: accounthandler.DeleteAccountRequest{}, Response: accounthandler.DeleteAccountResponse{},
+	},
+	{
+		Method: http.MethodGet, Path: "/user/export", OperationID: "exportPersonalData", Tag: tagExport,
+		Summary:      "Download ZIP archive with personal data of current user, large accounts are exported in background",
+		ContentTypes: []string{"application/zip"}, Accepted: exporthandler.ExportResponse{},
+	},
+	{
+		Method: http.MethodGet, Path: "/user/export/{id}", OperationID: "getExport", Tag: tagExport,
+		Summary: "Get status of background export",
+		Parameters: []Parameter{
+			{Name: "id", In: "path", Description: "Export id", Required: true, Schema: &Schema{Type: "string"}},
+		},
+		Response: exporthandler.ExportResponse{},
+	},
+	{
+		Method: http.MethodGet, Path: "/exports/{token}", OperationID: "downloadExport", Tag: tagExport,
+		Summary: "Download archive of background export by link",
+		Parameters: []Parameter{
+			{Name: "token", In: "path", Description: "Token from download_url", Required: true, Schema: &Schema{Type: "string"}},
+		},
+		ContentTypes: []string{"application/zip"}, Public: true,
+	},
+	{
+		Method: http.MethodGet, Path: "/user/sessions", OperationID: "getSessions", Tag: tagSession,
+		Summary:  "List active sessions of current user",
+		Response: sessionhandler.GetSessionsResponse{},
+	},
+	{
+		Method: http.MethodDelete, Path: "/user/sessions", OperationID: "revokeOtherSessions", Tag: tagSession,
+		Summary:  "Sign out of every session except current one",
+		Response: sessionhandler.RevokeSessionsResponse{},
+	},
+	{
+		Method: http.MethodDelete, Path: "/user/sessions/{id}", OperationID: "revokeSession", Tag: tagSession,
+		Summary: "Sign out of session",
+		Parameters: []Parameter{
+			{Name: "id", In: "path", Description: "Session id", Required: true, Schema: &Schema{Type: "string"}},
+		},
+		Response: response.Response{},
+	},
+	{
+		Method: http.MethodGet, Path: "/admin/users", OperationID: "searchUsers", Tag: tagAdmin,
+		Summary: "Search users by part of email or name",
+		Parameters: append([]Parameter{
+			{Name: "query", In: "query", Description: "Part of email or name", Schema: &Schema{Type: "string"}},
+			{Name: "role", In: "query", Description: "Role of users", Schema: &Schema{Type: "string", Enum: []string{models.RoleTrainer, models.RoleClient, models.RoleAdmin}}},
+			{Name: "status", In: "query", Description: "Status of users", Schema: &Schema{Type: "string"}},
+		}, pageParameters...),
+		Response: adminhandler.SearchUsersResponse{},
+	},
+	{
+		Method: http.MethodGet, Path: "/admin/users/{id}", OperationID: "getUser", Tag: tagAdmin,
+		Summary:    "Get user with profile of their role",
+		Parameters: []Parameter{adminIDParameter("User id")},
+		Response:   adminhandler.UserDetailsResponse{},
+	},
+	{
+		Method: http.MethodPatch, Path: "/admin/users/{id}/status", OperationID: "setUserStatus", Tag: tagAdmin,
+		Summary:    "Disable or enable account, disabled user is signed out",
+		Parameters: []Parameter{adminIDParameter("User id")},
+		Request:    adminhandler.SetUserStatusRequest{}, Response: response.Response{},
+	},
+	{
+		Method: http.MethodPatch, Path: "/admin/trainers/{id}/status", OperationID: "setTrainerStatus", Tag: tagAdmin,
+		Summary:    "Change availability of trainer",
+		Parameters: []Parameter{adminIDParameter("Trainer id")},
+		Request:    adminhandler.SetTrainerStatusRequest{}, Response: response.Response{},
+	},
+	{
+		Method: http.MethodPatch, Path: "/admin/clients/{id}/trainer", OperationID: "reassignClient", Tag: tagAdmin,
+		Summary:    "Bind client to another trainer",
+		Parameters: []Parameter{adminIDParameter("Client id")},
+		Request:    adminhandler.ReassignClientRequest{}, Response: response.Response{},
+	},
+	{
+		Method: http.MethodGet, Path: "/admin/audit", OperationID: "getAuditHistory", Tag: tagAdmin,
+		Summary: "List audit events, newest first",
+		Parameters: append([]Parameter{
+			{Name: "actor", In: "query", Description: "Email of user who made changes, system for background jobs. Changes of deleted accounts match no email", Schema: &Schema{Type: "string"}},
+			{Name: "action", In: "query", Description: "Kind of change", Schema: &Schema{Type: "string", Enum: []string{models.AuditCreate, models.AuditUpdate, models.AuditDelete}}},
+			{Name: "entity", In: "query", Description: "Changed table, e.g. clients", Schema: &Schema{Type: "string"}},
+			{Name: "entity_id", In: "query", Description: "Id of changed row", Schema: &Schema{Type: "integer"}},
+			{Name: "request_id", In: "query", Description: "X-Request-ID of request which made changes", Schema: &Schema{Type: "string"}},
+		}, pageParameters...),
+		Response: adminhandler.AuditHistoryResponse{},
+	},
+	{
+		Method: http.MethodPut, Path: "/admin/measurement-types/{code}", OperationID: "saveMeasurementType", Tag: tagAdmin,
+		Summary: "Add type to measurement catalog or change unit, name and range of existing one",
+		Parameters: []Parameter{
+			{Name: "code", In: "path", Description: "Code of measurement type, key of metric measurements", Required: true, Schema: &Schema{Type: "string"}},
+		},
+		Request: adminhandler.SaveMeasurementTypeRequest{}, Response: response.Response{},
+	},
+}
+
+// pageParameters are query parameters of paginated admin lists
+var pageParameters = []Parameter{
+	{Name: "limit", In: "query", Description: "Page size, 50 by default, at most 200", Schema: &Schema{Type: "integer"}},
+	{Name: "offset", In: "query", Description: "Number of skipped items", Schema: &Schema{Type: "integer"}},
+}
+
+var rangeParameters = []Parameter{
+	{Name: "from", In: "query", Description: "Start of range, RFC 3339 time or date", Schema: &Schema{Type: "string"}},
+	{Name: "to", In: "query", Description: "End of range exclusive, RFC 3339 time or date including the day", Schema: &Schema{Type: "string"}},
+}
+
+// importParameters tell how to read CSV file of metrics
+var importParameters = []Parameter{
+	{Name: "column", In: "query", Description: "Mapping of CSV column to measurement code or measured-at, Header:code, may be repeated. Only mapped columns are read", Schema: &Schema{Type: "string"}},
+	{Name: "delimiter", In: "query", Description: "Delimiter of CSV fields, comma by default", Schema: &Schema{Type: "string"}},
+}
+
+var (
+	reportClientParameter = Parameter{Name: "id", In: "path", Description: "Client id", Required: true, Schema: &Schema{Type: "integer"}}
+	reportFormatParameter = Parameter{Name: "format", In: "query", Description: "Format of file, csv by default", Schema: &Schema{Type: "string", Enum: []string{models.ReportCSV, models.ReportXLSX}}}
+)
+
+// chartParameters tell what chart shows and how it looks
+var chartParameters = []Parameter{
+	{Name: "code", In: "path", Description: "Code of measurement type, BMI is derived from weight and height when not measured", Required: true, Schema: &Schema{Type: "string"}},
+	{Name: "width", In: "query", Description: "Width in pixels, 640 by default, 200 to 2000", Schema: &Schema{Type: "integer"}},
+	{Name: "height", In: "query", Description: "Height in pixels, 320 by default, 120 to 1200", Schema: &Schema{Type: "integer"}},
+	{Name: "theme", In: "query", Description: "Color theme, light by default", Schema: &Schema{Type: "string", Enum: []string{chart.ThemeLight, chart.ThemeDark}}},
+	{Name: "average", In: "query", Description: "Window of moving average in days, 7 by default, 0 draws no average, at most 90", Schema: &Schema{Type: "integer"}},
+}
+
+// unitsParameters override unit system of user for endpoints taking or returning measurements
+var unitsParameters = []Parameter{
+	{Name: units.Param, In: "query", Description: "Unit system of values, metric or imperial", Schema: &Schema{Type: "string", Enum: []string{string(units.Metric), string(units.Imperial)}}},
+	{Name: units.Header, In: "header", Description: "Unit system of values, the query parameter takes precedence", Schema: &Schema{Type: "string", Enum: []string{string(units.Metric), string(units.Imperial)}}},
+}
+
+func adminIDParameter(description string) Parameter {
+	return Parameter{Name: "id", In: "path", Description: description, Required: true, Schema: &Schema{Type: "integer"}}
+}
+
+// V2Overrides lists v2 routes whose contracts differ from v1
+var V2Overrides = []Route{
+	{
+		Method: http.MethodGet, Path: "/user/trainers/profile", OperationID: "getTrainerProfile", Tag: tagTrainer,
+		Summary:  "Get trainer profile of current user",
+		Response: userhandler.TrainerProfileResponse{},
+	},
+	{
+		Method: http.MethodGet, Path: "/user/clients/metrics", OperationID: "getMetrics", Tag: tagClient,
+		Summary:    "List metrics of current client with every measurement",
+		Parameters: append(rangeParameters, unitsParameters...),
+		Response:   []models.MetricEntryResponse{},
+	},
+}
+
+func routeV2(route Route) Route {
+	for _, o := range V2Overrides {
+		if o.Method == route.Method && o.Path == route.Path {
+			return o
+		}
+	}
+
+	return route
+}
@@ -0,0 +1,140 @@
+package openapi
+
+import (
+	"reflect"
+	"strings"
+	"time"
+
+	"ChadProgress/internal/models"
+)
+
+// Schema is the subset of OpenAPI schema object
+type Schema struct {
+	Ref                  string             `json:"$ref,omitempty"`
+	Type                 string             `json:"type,omitempty"`
+	Format               string             `json:"format,omitempty"`
+	Description          string             `json:"description,omitempty"`
+	Example              any                `json:"example,omitempty"`
+	Enum                 []string           `json:"enum,omitempty"`
+	Items                *Schema            `json:"items,omitempty"`
+	Properties           map[string]*Schema `json:"properties,omitempty"`
+	AdditionalProperties *Schema            `json:"additionalProperties,omitempty"`
+	Required             []string           `json:"required,omitempty"`
+	Nullable             bool               `json:"nullable,omitempty"`
+}
+
+var (
+	timeType       = reflect.TypeOf(time.Time{})
+	customTimeType = reflect.TypeOf(models.CustomTime{})
+)
+
+// generator derives schemas from Go types using json and validate struct tags
+type generator struct {
+	schemas map[string]*Schema
+}
+
+func newGenerator(schemas map[string]*Schema) *generator {
+	return &generator{schemas: schemas}
+}
+
+// schemaRef registers named struct types as components and returns reference to them.
+// Empty name means the name of the Go type is used.
+func (g *generator) schemaRef(v any, name string) *Schema {
+	return g.schemaFor(reflect.TypeOf(v), name)
+}
+
+func (g *generator) schemaFor(t reflect.Type, name string) *Schema {
+	if t.Kind() == reflect.Pointer {
+		s := g.schemaFor(t.Elem(), name)
+		s.Nullable = true
+
+		return s
+	}
+
+	switch t {
+	case timeType:
+		return &Schema{Type: "string", Format: "date-time"}
+	case customTimeType:
+		return &Schema{Type: "string", Format: "date-time", Example: "2026-10-19T08:00:00+03:00"}
+	}
+
+	switch t.Kind() {
+	case reflect.String:
+		return &Schema{Type: "string"}
+	case reflect.Bool:
+		return &Schema{Type: "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return &Schema{Type: "integer"}
+	case reflect.Float32, reflect.Float64:
+		return &Schema{Type: "number", Format: "double"}
+	case reflect.Slice, reflect.Array:
+		return &Schema{Type: "array", Items: g.schemaFor(t.Elem(), "")}
+	case reflect.Map:
+		return &Schema{Type: "object", AdditionalProperties: g.schemaFor(t.Elem(), "")}
+	case reflect.Struct:
+		if name == "" {
+			name = t.Name()
+		}
+		if _, ok := g.schemas[name]; !ok {
+			// placeholder protects from infinite recursion on self referencing types
+			g.schemas[name] = &Schema{}
+			*g.schemas[name] = *g.structSchema(t)
+		}
+
+		return &Schema{Ref: componentRef(name)}
+	default:
+		return &Schema{}
+	}
+}
+
+func (g *generator) structSchema(t reflect.Type) *Schema {
+	s := &Schema{Type: "object", Properties: make(map[string]*Schema)}
+
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if !f.IsExported() {
+			continue
+		}
+
+		name, omit := jsonName(f)
+		if omit {
+			continue
+		}
+
+		prop := g.schemaFor(f.Type, "")
+		rules := strings.Split(f.Tag.Get("validate"), ",")
+		for _, rule := range rules {
+			switch {
+			case rule == "required":
+				s.Required = append(s.Required, name)
+			case rule == "email":
+				prop.Format = "email"
+			case strings.HasPrefix(rule, "oneof="):
+				prop.Enum = strings.Fields(strings.TrimPrefix(rule, "oneof="))
+			}
+		}
+		s.Properties[name] = prop
+	}
+
+	return s
+}
+
+// jsonName returns the name field is encoded with by encoding/json
+func jsonName(f reflect.StructField) (string, bool) {
+	tag := f.Tag.Get("json")
+	if tag == "-" {
+		return "", true
+	}
+
+	name, _, _ := strings.Cut(tag, ",")
+	if name == "" {
+		name = f.Name
+	}
+
+	return name, false
+}
+
+func componentRef(name string) string {
+	return "#/components/schemas/" + name
+}
@@ -0,0 +1,36 @@
+package openapi
+
+import (
+	"fmt"
+	"net/http"
+
+	swaggerFiles "github.com/swaggo/files/v2"
+)
+
+const swaggerInitializer = `window.onload = function() {
+  window.ui = SwaggerUIBundle({
+    url: %q,
+    dom_id: '#swagger-ui',
+    deepLinking: true,
+    presets: [SwaggerUIBundle.presets.apis, SwaggerUIStandalonePreset],
+    plugins: [SwaggerUIBundle.plugins.DownloadUrl],
+    layout: "StandaloneLayout"
+  });
+};
+`
+
+// SwaggerUI serves embedded Swagger UI under prefix. UI loads document from specURL.
+func SwaggerUI(prefix, specURL string) http.Handler {
+	files := http.StripPrefix(prefix, http.FileServer(http.FS(swaggerFiles.FS)))
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == prefix+"swagger-initializer.js" {
+			w.Header().Set("Content-Type", "application/javascript")
+			_, _ = fmt.Fprintf(w, swaggerInitializer, specURL)
+
+			return
+		}
+
+		files.ServeHTTP(w, r)
+	})
+}
@@ -0,0 +1,186 @@
+package router
+
+import (
+	"net/http"
+	"time"
+
+	accounthandler "ChadProgress/internal/http_server/handlers/url/account"
+	adminhandler "ChadProgress/internal/http_server/handlers/url/admin"
+	"ChadProgress/internal/http_server/handlers/url/authorization"
+	exporthandler "ChadProgress/internal/http_server/handlers/url/export"
+	reporthandler "ChadProgress/internal/http_server/handlers/url/report"
+	sessionhandler "ChadProgress/internal/http_server/handlers/url/session"
+	userhandler "ChadProgress/internal/http_server/handlers/url/user"
+	"ChadProgress/internal/http_server/openapi"
+	"ChadProgress/internal/lib/units"
+	"ChadProgress/internal/middleware/deprecation"
+	"ChadProgress/internal/middleware/requestid"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/cors"
+)
+
+const (
+	specPath    = "/openapi.json"
+	swaggerPath = "/swagger/"
+)
+
+var (
+	// legacyPolicy applies to unversioned routes which are kept as aliases of v1
+	legacyPolicy = deprecation.Policy{
+		Since:  time.Date(2026, time.October, 19, 0, 0, 0, 0, time.UTC),
+		Sunset: time.Date(2027, time.April, 1, 0, 0, 0, 0, time.UTC),
+		From:   "",
+		To:     openapi.PrefixV1,
+	}
+	// v1ReplacedPolicy applies to v1 routes whose contracts are corrected in v2
+	v1ReplacedPolicy = deprecation.Policy{
+		Since:  time.Date(2026, time.October, 19, 0, 0, 0, 0, time.UTC),
+		Sunset: time.Date(2027, time.October, 1, 0, 0, 0, 0, time.UTC),
+		From:   openapi.PrefixV1,
+		To:     openapi.PrefixV2,
+	}
+)
+
+type Handlers struct {
+	Auth    *authorization.UserAuthHandler
+	User    *userhandler.UserHandler
+	Session *sessionhandler.SessionHandler
+	Account *accounthandler.AccountHandler
+	Export  *exporthandler.ExportHandler
+	Report  *reporthandler.ReportHandler
+	Admin   *adminhandler.AdminHandler
+}
+
+// Middlewares are applied to route groups. Auth and Admin are required, limits may be left empty.
+type Middlewares struct {
+	// Proxy runs before any other middleware, it is set when the API is behind trusted proxy
+	Proxy chi.Middlewares
+	Auth  func(http.Handler) http.Handler
+	// Admin lets through admins only, it runs after Auth
+	Admin func(http.Handler) http.Handler
+	// Login and Register throttle open authorization endpoints
+	Login    chi.Middlewares
+	Register chi.Middlewares
+	Refresh  chi.Middlewares
+	// User throttles protected endpoints, it runs after Auth so user is known
+	User chi.Middlewares
+}
+
+// New registers all API routes. Every route must be described in openapi.Routes.
+func New(h Handlers, mw Middlewares) *chi.Mux {
+	router := chi.NewRouter()
+
+	router.Use(mw.Proxy...)
+	router.Use(requestid.RequestID)
+	router.Use(cors.Handler(cors.Options{
+		AllowedOrigins:   []string{"*"},
+		AllowedMethods:   []string{"GET", "POST", "PUT", "PATCH", "DELETE", "OPTIONS"},
+		AllowedHeaders:   []string{"Accept", "Authorization", "Content-Type", "X-CSRF-Token", requestid.Header, units.Header},
+		ExposedHeaders:   []string{"Deprecation", "Sunset", "Link", "Retry-After", requestid.Header},
+		AllowCredentials: true,
+	}))
+
+	// Documentation
+	router.Get(specPath, openapi.Handler(openapi.Spec()))
+	router.Handle(swaggerPath+"*", openapi.SwaggerUI(swaggerPath, specPath))
+
+	router.Route(openapi.PrefixV1, func(r chi.Router) {
+		mountRoutes(r, h, mw, openapi.V1)
+	})
+	router.Route(openapi.PrefixV2, func(r chi.Router) {
+		mountRoutes(r, h, mw, openapi.V2)
+	})
+
+	// Unversioned routes, kept for clients that have not moved to /api/v1 yet
+	router.Group(func(r chi.Router) {
+		r.Use(deprecation.Deprecated(legacyPolicy))
+		mountRoutes(r, h, mw, openapi.V1)
+	})
+
+	return router
+}
+
+func mountRoutes(router chi.Router, h Handlers, mw Middlewares, v openapi.APIVersion) {
+	// Open endpoints
+	router.Route("/authorization", func(r chi.Router) {
+		r.With(mw.Register...).Post("/register", h.Auth.Register)
+		r.With(mw.Login...).Post("/login", h.Auth.Login)
+		r.With(mw.Refresh...).Post("/refresh", h.Auth.Refresh)
+		r.Post("/logout", h.Auth.Logout)
+		r.Post("/verify-email", h.Auth.VerifyEmail)
+		r.With(mw.Register...).Post("/password/forgot", h.Auth.ForgotPassword)
+		r.Post("/password/reset", h.Auth.ResetPassword)
+		r.Post("/email/confirm", h.Auth.ConfirmEmailChange)
+	})
+	// the token in download link is the credential
+	router.Get("/exports/{token}", h.Export.Download)
+
+	// Protected endpoints
+	router.Route("/user", func(r chi.Router) {
+		r.Use(mw.Auth)
+		r.Use(mw.User...)
+
+		// Trainer endpoints
+		r.Post("/trainers/profile", h.User.CreateTrainer)
+		if v == openapi.V2 {
+			r.Get("/trainers/profile", h.User.GetTrainerProfileV2)
+		} else {
+			r.With(deprecation.Deprecated(v1ReplacedPolicy)).Get("/trainers/profile", h.User.GetTrainerProfile)
+		}
+		r.Get("/trainers/clients", h.User.GetTrainersClients)
+		r.Post("/training-plan", h.User.CreatePlan)
+		r.Post("/progress-reports", h.User.AddProgressReport)
+
+		// Client endpoints
+		r.Post("/clients/profile", h.User.CreateClient)
+		r.Get("/clients/profile", h.User.GetClientProfile)
+		r.Put("/clients/goals", h.User.SetGoals)
+		r.Patch("/clients/select-trainers", h.User.SelectTrainer)
+		r.Post("/clients/metrics", h.User.AddMetrics)
+		r.Post("/clients/metrics/import", h.User.ImportMetrics)
+		if v == openapi.V2 {
+			r.Get("/clients/metrics", h.User.GetMetricsV2)
+		} else {
+			r.With(deprecation.Deprecated(v1ReplacedPolicy)).Get("/clients/metrics", h.User.GetMetrics)
+		}
+		r.Get("/clients/metrics/series", h.User.MetricSeries)
+
+		// Common endpoints
+		r.Get("/progress-reports", h.User.GetProgressReports)
+		r.Get("/training-plan", h.User.GetPlan)
+		r.Get("/measurement-types", h.User.GetMeasurementTypes)
+		r.Get("/reports/clients/{id}/metrics", h.Report.MetricsExport)
+		r.Get("/reports/clients/{id}/summary", h.Report.Summary)
+		r.Get("/reports/clients/{id}/charts/{code}", h.Report.Chart)
+
+		// Account
+		r.Get("/settings", h.User.GetSettings)
+		r.Patch("/settings", h.User.UpdateSettings)
+		r.Post("/email/verification", h.Account.ResendVerification)
+		r.Post("/email", h.Account.ChangeEmail)
+		r.Delete("/account", h.Account.DeleteAccount)
+		r.Get("/export", h.Export.Export)
+		r.Get("/export/{id}", h.Export.GetExport)
+
+		// Sessions
+		r.Get("/sessions", h.Session.GetSessions)
+		r.Delete("/sessions", h.Session.RevokeOtherSessions)
+		r.Delete("/sessions/{id}", h.Session.RevokeSession)
+	})
+
+	// Admin endpoints
+	router.Route("/admin", func(r chi.Router) {
+		r.Use(mw.Auth)
+		r.Use(mw.Admin)
+		r.Use(mw.User...)
+
+		r.Get("/users", h.Admin.SearchUsers)
+		r.Get("/users/{id}", h.Admin.GetUser)
+		r.Patch("/users/{id}/status", h.Admin.SetUserStatus)
+		r.Patch("/trainers/{id}/status", h.Admin.SetTrainerStatus)
+		r.Patch("/clients/{id}/trainer", h.Admin.ReassignClient)
+		r.Get("/audit", h.Admin.AuditHistory)
+		r.Put("/measurement-types/{code}", h.Admin.SaveMeasurementType)
+	})
+}
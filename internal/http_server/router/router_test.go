@@ -0,0 +1,189 @@
+package router
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	userhandler "ChadProgress/internal/http_server/handlers/url/user"
+	"ChadProgress/internal/http_server/openapi"
+	"ChadProgress/internal/middleware/throttle"
+	"ChadProgress/internal/models"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/chi/v5/middleware"
+	gomock "github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func passThrough(next http.Handler) http.Handler {
+	return next
+}
+
+func TestAllRoutesAreDocumented(t *testing.T) {
+	router := New(Handlers{}, Middlewares{Auth: passThrough, Admin: passThrough})
+	spec := openapi.Spec()
+
+	registered := make(map[string]bool)
+	err := chi.Walk(router, func(method, route string, _ http.Handler, _ ...func(http.Handler) http.Handler) error {
+		if route == specPath || strings.HasPrefix(route, swaggerPath) {
+			return nil
+		}
+
+		key := method + " " + route
+		registered[key] = true
+
+		_, ok := spec.Paths[route][strings.ToLower(method)]
+		assert.True(t, ok, "route %s is missing from OpenAPI spec", key)
+
+		return nil
+	})
+	require.NoError(t, err)
+
+	for path, item := range spec.Paths {
+		for method := range item {
+			key := strings.ToUpper(method) + " " + path
+			assert.True(t, registered[key], "OpenAPI spec describes unregistered route %s", key)
+		}
+	}
+}
+
+func TestDocumentationIsServed(t *testing.T) {
+	router := New(Handlers{}, Middlewares{Auth: passThrough, Admin: passThrough})
+
+	rr := httptest.NewRecorder()
+	router.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, specPath, nil))
+	require.Equal(t, http.StatusOK, rr.Code)
+
+	var doc openapi.Document
+	require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &doc))
+	assert.Equal(t, openapi.Version, doc.OpenAPI)
+	assert.Contains(t, doc.Components.Schemas, "AddMetricsRequest")
+	assert.Contains(t, doc.Components.Schemas["AddMetricsRequest"].Properties, "measured-at")
+
+	rr = httptest.NewRecorder()
+	router.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, swaggerPath+"swagger-initializer.js", nil))
+	require.Equal(t, http.StatusOK, rr.Code)
+	assert.Contains(t, rr.Body.String(), `"`+specPath+`"`)
+
+	rr = httptest.NewRecorder()
+	router.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, swaggerPath, nil))
+	assert.Equal(t, http.StatusOK, rr.Code)
+}
+
+func TestVersioning(t *testing.T) {
+	tests := []struct {
+		name            string
+		path            string
+		expectedResp    string
+		expectedSunset  string
+		expectedLinks   []string
+		expectDeprecate bool
+	}{
+		{
+			name:         "v2 contract",
+			path:         "/api/v2/user/trainers/profile",
+			expectedResp: `"qualification":"Certified"`,
+		},
+		{
+			name:            "v1 contract is deprecated in favour of v2",
+			path:            "/api/v1/user/trainers/profile",
+			expectedResp:    `"height":"Certified"`,
+			expectedSunset:  "Fri, 01 Oct 2027 00:00:00 GMT",
+			expectedLinks:   []string{`</api/v2/user/trainers/profile>; rel="successor-version"`},
+			expectDeprecate: true,
+		},
+		{
+			name:           "Unversioned alias",
+			path:           "/user/trainers/profile",
+			expectedResp:   `"height":"Certified"`,
+			expectedSunset: "Thu, 01 Apr 2027 00:00:00 GMT",
+			expectedLinks: []string{
+				`</api/v1/user/trainers/profile>; rel="successor-version"`,
+				`</api/v2/user/trainers/profile>; rel="successor-version"`,
+			},
+			expectDeprecate: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			mockService := userhandler.NewMockUserService(ctrl)
+			mockService.EXPECT().
+				GetTrainerProfile("trainer@example.com").
+				Return(&models.Trainer{Qualifications: "Certified"}, nil)
+
+			logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+			withUser := func(next http.Handler) http.Handler {
+				return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+					ctx := context.WithValue(r.Context(), models.ContextUserKey, "trainer@example.com")
+					next.ServeHTTP(w, r.WithContext(ctx))
+				})
+			}
+			router := New(Handlers{User: userhandler.NewUserHandler(logger, mockService)}, Middlewares{Auth: withUser, Admin: passThrough})
+
+			rr := httptest.NewRecorder()
+			router.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, tt.path, nil))
+
+			assert.Equal(t, http.StatusOK, rr.Code)
+			assert.Contains(t, rr.Body.String(), tt.expectedResp)
+			assert.Equal(t, tt.expectDeprecate, rr.Header().Get("Deprecation") != "")
+			assert.Equal(t, tt.expectedSunset, rr.Header().Get("Sunset"))
+			assert.ElementsMatch(t, tt.expectedLinks, rr.Header().Values("Link"))
+		})
+	}
+}
+
+func TestClientAddressBehindProxy(t *testing.T) {
+	tests := []struct {
+		name       string
+		proxy      chi.Middlewares
+		expectedIP string
+	}{
+		{
+			name:       "Proxy headers are ignored by default",
+			expectedIP: "10.0.0.1",
+		},
+		{
+			name:       "Trusted proxy",
+			proxy:      chi.Middlewares{middleware.RealIP},
+			expectedIP: "203.0.113.7",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var gotIP string
+			captureIP := func(http.Handler) http.Handler {
+				return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+					gotIP = throttle.ByIP(r)
+					w.WriteHeader(http.StatusNoContent)
+				})
+			}
+			router := New(Handlers{}, Middlewares{
+				Proxy: tt.proxy,
+				Auth:  passThrough,
+				Admin: passThrough,
+				Login: chi.Middlewares{captureIP},
+			})
+
+			req := httptest.NewRequest(http.MethodPost, openapi.PrefixV1+"/authorization/login", nil)
+			req.RemoteAddr = "10.0.0.1:52000"
+			req.Header.Set("X-Forwarded-For", "203.0.113.7, 10.0.0.1")
+			rr := httptest.NewRecorder()
+			router.ServeHTTP(rr, req)
+
+			assert.Equal(t, http.StatusNoContent, rr.Code)
+			assert.Equal(t, tt.expectedIP, gotIP)
+		})
+	}
+}
@@ -0,0 +1,99 @@
+package request
+
+import (
+	"errors"
+	"log/slog"
+	"net"
+	"net/http"
+	"strings"
+	"time"
+
+	"ChadProgress/internal/lib/api/response"
+	"ChadProgress/internal/lib/apperr"
+	"ChadProgress/internal/models"
+
+	"github.com/go-chi/render"
+	"github.com/go-playground/validator/v10"
+)
+
+// Decode decodes and validates JSON body into req, rendering error if it fails
+func Decode(w http.ResponseWriter, r *http.Request, log *slog.Logger, req any) bool {
+	if err := render.DecodeJSON(r.Body, req); err != nil {
+		log.Error("failed to decode request body", slog.String("error", err.Error()))
+		response.RenderError(w, r, apperr.ErrBadRequest.WithMessage("failed to decode request body"))
+
+		return false
+	}
+
+	if err := validator.New().Struct(req); err != nil {
+		validationErr := err.(validator.ValidationErrors)
+		log.Error("invalid request", slog.String("error", validationErr.Error()))
+		response.RenderError(w, r, response.ValidationError(validationErr))
+
+		return false
+	}
+
+	return true
+}
+
+// ClientInfo describes device which made the request
+func ClientInfo(r *http.Request) models.ClientInfo {
+	return models.ClientInfo{
+		UserAgent: r.UserAgent(),
+		IP:        IP(r),
+	}
+}
+
+// IP returns address of the client without port
+func IP(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+
+	return host
+}
+
+// BearerToken extracts token from Authorization header
+func BearerToken(r *http.Request) string {
+	parts := strings.Split(r.Header.Get("Authorization"), " ")
+	if len(parts) != 2 || parts[0] != "Bearer" {
+		return ""
+	}
+
+	return parts[1]
+}
+
+// Range parses optional from and to query parameters, RFC 3339 times or dates in loc. Date in to
+// includes the whole day. Zero times are returned for missing parameters.
+func Range(r *http.Request, loc *time.Location) (from, to time.Time, err error) {
+	q := r.URL.Query()
+	if from, err = rangeTime(q.Get("from"), false, loc); err != nil {
+		return time.Time{}, time.Time{}, errors.New("from must be a date or RFC 3339 time")
+	}
+	if to, err = rangeTime(q.Get("to"), true, loc); err != nil {
+		return time.Time{}, time.Time{}, errors.New("to must be a date or RFC 3339 time")
+	}
+
+	return from, to, nil
+}
+
+// rangeTime parses optional time, empty string is zero time. Date is midnight in loc,
+// or the next midnight when it ends the range.
+func rangeTime(s string, end bool, loc *time.Location) (time.Time, error) {
+	if s == "" {
+		return time.Time{}, nil
+	}
+	if t, err := time.Parse(time.RFC3339, s); err == nil {
+		return t, nil
+	}
+	t, err := time.ParseInLocation(time.DateOnly, s, loc)
+	if err != nil {
+		return time.Time{}, err
+	}
+	if end {
+		t = t.AddDate(0, 0, 1)
+	}
+
+	return t, nil
+}
@@ -1,50 +1,123 @@
 package response
 
 import (
+	"encoding/json"
 	"fmt"
+	"math"
+	"net/http"
+	"strconv"
 	"strings"
 
+	"ChadProgress/internal/lib/apperr"
+
+	"github.com/go-chi/render"
 	"github.com/go-playground/validator/v10"
 )
 
 type Response struct {
-	Status string `json:"status"`
-	Error  string `json:"error,omitempty"`
+	Status  string              `json:"status"`
+	Error   string              `json:"error,omitempty"`
+	Code    apperr.Code         `json:"code,omitempty"`
+	Fields  []apperr.FieldError `json:"fields,omitempty"`
+	Details map[string]any      `json:"details,omitempty"`
+}
+
+// Problem is RFC 7807 representation of an error. It is rendered when client accepts application/problem+json
+type Problem struct {
+	Type     string              `json:"type"`
+	Title    string              `json:"title"`
+	Status   int                 `json:"status"`
+	Detail   string              `json:"detail,omitempty"`
+	Instance string              `json:"instance,omitempty"`
+	Code     apperr.Code         `json:"code"`
+	Fields   []apperr.FieldError `json:"fields,omitempty"`
+	Details  map[string]any      `json:"details,omitempty"`
 }
 
 const (
 	StatusOK    = "OK"
 	StatusError = "Error"
+
+	ProblemContentType = "application/problem+json"
+	problemTypePrefix  = "urn:chadprogress:error:"
 )
 
 func OK() Response {
 	return Response{Status: StatusOK}
 }
 
-func Error(msg string) Response {
-	return Response{Status: StatusError, Error: msg}
+// RenderError is the single place where errors are mapped to HTTP responses.
+// Application errors keep their status and code, any other error is rendered as internal error.
+func RenderError(w http.ResponseWriter, r *http.Request, err error) {
+	appErr := apperr.From(err)
+	if appErr.RetryAfter > 0 {
+		w.Header().Set("Retry-After", strconv.Itoa(int(math.Ceil(appErr.RetryAfter.Seconds()))))
+	}
+
+	if acceptsProblem(r) {
+		b, _ := json.Marshal(problem(r, appErr))
+		w.Header().Set("Content-Type", ProblemContentType)
+		w.WriteHeader(appErr.Status)
+		_, _ = w.Write(b)
+
+		return
+	}
+
+	render.Status(r, appErr.Status)
+	render.JSON(w, r, Response{
+		Status:  StatusError,
+		Error:   appErr.Message,
+		Code:    appErr.Code,
+		Fields:  appErr.Fields,
+		Details: appErr.Details,
+	})
 }
 
-func ValidationError(errs validator.ValidationErrors) Response {
+// ValidationError converts validator errors to application error with field level messages
+func ValidationError(errs validator.ValidationErrors) *apperr.Error {
 	var errMsgs []string
+	fields := make([]apperr.FieldError, 0, len(errs))
 
 	for _, err := range errs {
+		var msg string
 		switch err.ActualTag() {
 		case "required":
-			errMsgs = append(errMsgs, fmt.Sprintf("field %s is a required field", err.Field()))
+			msg = fmt.Sprintf("field %s is a required field", err.Field())
 		case "email":
-			errMsgs = append(errMsgs, fmt.Sprintf("field %s is not a valid login", err.Field()))
+			msg = fmt.Sprintf("field %s is not a valid email", err.Field())
 		case "password":
-			errMsgs = append(errMsgs, fmt.Sprintf("field %s is not a valid email", err.Field()))
+			msg = fmt.Sprintf("field %s does not meet password requirements", err.Field())
+		case "min":
+			msg = fmt.Sprintf("field %s must be at least %s characters long", err.Field(), err.Param())
+		case "max":
+			msg = fmt.Sprintf("field %s must be at most %s characters long", err.Field(), err.Param())
 		case "role":
-			errMsgs = append(errMsgs, fmt.Sprintf("field %s is not a valid role", err.Field()))
+			msg = fmt.Sprintf("field %s is not a valid role", err.Field())
 		default:
-			errMsgs = append(errMsgs, fmt.Sprintf("field %s is not valid", err.Field()))
+			msg = fmt.Sprintf("field %s is not valid", err.Field())
 		}
+		errMsgs = append(errMsgs, msg)
+		fields = append(fields, apperr.FieldError{Field: err.Field(), Message: msg})
 	}
 
-	return Response{
-		Status: StatusError,
-		Error:  strings.Join(errMsgs, ", "),
+	return apperr.ErrValidation.
+		WithMessage(strings.Join(errMsgs, ", ")).
+		WithFields(fields...)
+}
+
+func problem(r *http.Request, e *apperr.Error) Problem {
+	return Problem{
+		Type:     problemTypePrefix + string(e.Code),
+		Title:    http.StatusText(e.Status),
+		Status:   e.Status,
+		Detail:   e.Message,
+		Instance: r.URL.Path,
+		Code:     e.Code,
+		Fields:   e.Fields,
+		Details:  e.Details,
 	}
 }
+
+func acceptsProblem(r *http.Request) bool {
+	return strings.Contains(r.Header.Get("Accept"), ProblemContentType)
+}
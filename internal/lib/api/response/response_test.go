@@ -0,0 +1,117 @@
+package response
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"ChadProgress/internal/lib/apperr"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRenderError(t *testing.T) {
+	notFound := apperr.New(apperr.CodeUserNotFound, http.StatusNotFound, "user not found")
+
+	tests := []struct {
+		name                string
+		err                 error
+		accept              string
+		expectedCode        int
+		expectedContentType string
+		expectedResp        string
+	}{
+		{
+			name:                "Application error",
+			err:                 fmt.Errorf("op: %w", notFound),
+			expectedCode:        http.StatusNotFound,
+			expectedContentType: "application/json",
+			expectedResp:        `{"status":"Error","error":"user not found","code":"user_not_found"}`,
+		},
+		{
+			name:                "Problem details",
+			err:                 notFound.WithDetail("email", "j***@mail.ru"),
+			accept:              "application/problem+json",
+			expectedCode:        http.StatusNotFound,
+			expectedContentType: ProblemContentType,
+			expectedResp:        `{"type":"urn:chadprogress:error:user_not_found","title":"Not Found","status":404,"detail":"user not found","instance":"/user/clients/profile","code":"user_not_found","details":{"email":"j***@mail.ru"}}`,
+		},
+		{
+			name:                "Unknown error is not leaked",
+			err:                 errors.New("pq: connection refused"),
+			expectedCode:        http.StatusInternalServerError,
+			expectedContentType: "application/json",
+			expectedResp:        `{"status":"Error","error":"internal error","code":"internal_error"}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/user/clients/profile", nil)
+			if tt.accept != "" {
+				req.Header.Set("Accept", tt.accept)
+			}
+			rr := httptest.NewRecorder()
+
+			RenderError(rr, req, tt.err)
+
+			assert.Equal(t, tt.expectedCode, rr.Code)
+			assert.Contains(t, rr.Header().Get("Content-Type"), tt.expectedContentType)
+			assert.JSONEq(t, tt.expectedResp, rr.Body.String())
+		})
+	}
+}
+
+func TestApplicationErrorIs(t *testing.T) {
+	base := apperr.New(apperr.CodeInvalidRole, http.StatusForbidden, "invalid request due to role")
+	wrapped := fmt.Errorf("op: %w", base.WithMessage("cannot create trainer profile while being client"))
+
+	assert.True(t, errors.Is(wrapped, base))
+	assert.False(t, errors.Is(wrapped, apperr.ErrInternal))
+	assert.Equal(t, "invalid request due to role", base.Message)
+}
+
+func TestValidationError(t *testing.T) {
+	validate := validator.New()
+	require.NoError(t, validate.RegisterValidation("password", func(fl validator.FieldLevel) bool {
+		return len(fl.Field().String()) >= 8
+	}))
+
+	tests := []struct {
+		name     string
+		req      any
+		expected apperr.FieldError
+	}{
+		{
+			name: "Email",
+			req: struct {
+				Email string `validate:"email"`
+			}{Email: "not-an-email"},
+			expected: apperr.FieldError{Field: "Email", Message: "field Email is not a valid email"},
+		},
+		{
+			name: "Password",
+			req: struct {
+				Password string `validate:"password"`
+			}{Password: "short"},
+			expected: apperr.FieldError{Field: "Password", Message: "field Password does not meet password requirements"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var errs validator.ValidationErrors
+			require.ErrorAs(t, validate.Struct(tt.req), &errs)
+
+			err := ValidationError(errs)
+
+			assert.ErrorIs(t, err, apperr.ErrValidation)
+			assert.Equal(t, tt.expected.Message, err.Message)
+			assert.Equal(t, []apperr.FieldError{tt.expected}, err.Fields)
+		})
+	}
+}
@@ -0,0 +1,107 @@
+package apperr
+
+import (
+	"errors"
+	"net/http"
+	"time"
+)
+
+// Error is an application error that carries everything needed to build an API response:
+// stable machine-readable code, HTTP status, human-readable message and optional details.
+type Error struct {
+	Code    Code
+	Status  int
+	Message string
+	Fields  []FieldError
+	Details map[string]any
+	// RetryAfter tells client when request may be repeated, rendered as Retry-After header
+	RetryAfter time.Duration
+}
+
+// FieldError describes a problem with a particular request field
+type FieldError struct {
+	Field   string `json:"field"`
+	Message string `json:"message"`
+}
+
+func New(code Code, status int, msg string) *Error {
+	return &Error{Code: code, Status: status, Message: msg}
+}
+
+func (e *Error) Error() string {
+	return e.Message
+}
+
+// Is reports errors with the same code as equal, so copies made by With* methods
+// still match their sentinel in errors.Is checks.
+func (e *Error) Is(target error) bool {
+	t, ok := target.(*Error)
+	if !ok {
+		return false
+	}
+
+	return t.Code == e.Code
+}
+
+// WithMessage returns a copy of the error with another message
+func (e *Error) WithMessage(msg string) *Error {
+	c := e.clone()
+	c.Message = msg
+
+	return c
+}
+
+// WithDetail returns a copy of the error with additional detail
+func (e *Error) WithDetail(key string, value any) *Error {
+	c := e.clone()
+	c.Details[key] = value
+
+	return c
+}
+
+// WithFields returns a copy of the error with field level errors appended
+func (e *Error) WithFields(fields ...FieldError) *Error {
+	c := e.clone()
+	c.Fields = append(c.Fields, fields...)
+
+	return c
+}
+
+// WithRetryAfter returns a copy of the error telling client to retry after d
+func (e *Error) WithRetryAfter(d time.Duration) *Error {
+	c := e.clone()
+	c.RetryAfter = d
+
+	return c
+}
+
+func (e *Error) clone() *Error {
+	c := *e
+	c.Fields = append([]FieldError(nil), e.Fields...)
+	c.Details = make(map[string]any, len(e.Details))
+	for k, v := range e.Details {
+		c.Details[k] = v
+	}
+
+	return &c
+}
+
+// From extracts application error from the chain. Errors that are not application errors
+// are reported as ErrInternal so their text never leaks to API clients.
+func From(err error) *Error {
+	var appErr *Error
+	if errors.As(err, &appErr) {
+		return appErr
+	}
+
+	return ErrInternal
+}
+
+var (
+	ErrBadRequest      = New(CodeBadRequest, http.StatusBadRequest, "bad request")
+	ErrValidation      = New(CodeValidationFailed, http.StatusBadRequest, "request validation failed")
+	ErrUnauthorized    = New(CodeUnauthorized, http.StatusUnauthorized, "missing or invalid token")
+	ErrForbidden       = New(CodeForbidden, http.StatusForbidden, "not allowed")
+	ErrInternal        = New(CodeInternal, http.StatusInternalServerError, "internal error")
+	ErrTooManyRequests = New(CodeTooManyRequests, http.StatusTooManyRequests, "too many requests")
+)
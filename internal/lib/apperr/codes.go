@@ -0,0 +1,51 @@
+package apperr
+
+// Code is a stable machine-readable error identifier. Codes are part of the public API
+// contract (see docs/errors.md): never rename or reuse them, only add new ones.
+type Code string
+
+// Generic codes
+const (
+	CodeBadRequest       Code = "bad_request"
+	CodeValidationFailed Code = "validation_failed"
+	CodeUnauthorized     Code = "unauthorized"
+	CodeForbidden        Code = "forbidden"
+	CodeTooManyRequests  Code = "too_many_requests"
+	CodeInternal         Code = "internal_error"
+)
+
+// Authorization codes
+const (
+	CodeUserAlreadyExists  Code = "user_already_exists"
+	CodeInvalidCredentials Code = "invalid_credentials"
+	CodeAccountLocked      Code = "account_locked"
+	CodeAuthServiceFailed  Code = "auth_service_failed"
+	CodeAuthUnavailable    Code = "auth_service_unavailable"
+	CodeInvalidToken       Code = "invalid_token"
+	CodeSessionNotFound    Code = "session_not_found"
+	CodeNotSupported       Code = "not_supported"
+	CodeInvalidEmailToken  Code = "invalid_email_token"
+	CodeEmailNotVerified   Code = "email_not_verified"
+	CodeAccountDisabled    Code = "account_disabled"
+)
+
+// Domain codes
+const (
+	CodeFieldTooLong     Code = "field_too_long"
+	CodeAlreadyExists    Code = "already_exists"
+	CodeInvalidReference Code = "invalid_reference"
+	CodeInvalidRole      Code = "invalid_role"
+	CodeUserNotFound     Code = "user_not_found"
+	CodeClientNotFound   Code = "client_not_found"
+	CodeTrainerNotFound  Code = "trainer_not_found"
+	CodeTrainerNotActive Code = "trainer_not_active"
+)
+
+// Data export codes
+const (
+	CodeExportNotFound   Code = "export_not_found"
+	CodeExportNotReady   Code = "export_not_ready"
+	CodeExportInProgress Code = "export_in_progress"
+	CodeExportExpired    Code = "export_expired"
+	CodeExportBusy       Code = "export_busy"
+)
@@ -0,0 +1,205 @@
+// Package chart lays out line charts of measurements over time. Renderers draw the layout in their
+// own format, so reports and web views show the same charts.
+package chart
+
+import (
+	"math"
+	"strconv"
+	"time"
+)
+
+// Point is a value measured at a time
+type Point struct {
+	Time  time.Time
+	Value float64
+}
+
+// Series is a line of the chart, points are ordered by time
+type Series struct {
+	Name   string
+	Points []Point
+	// Trend marks series derived from measurements, such as moving average
+	Trend bool
+}
+
+// Chart is a line chart of series sharing the value axis
+type Chart struct {
+	Title  string
+	Unit   string
+	Series []Series
+	// Goals are drawn as horizontal lines
+	Goals []float64
+	// From and To are the range of time axis, it spans the points when they are zero
+	From, To time.Time
+}
+
+// Rect is an area of the canvas, Y grows downwards as in both PDF and SVG renderers
+type Rect struct {
+	X, Y, W, H float64
+}
+
+// Layout places chart into the plot area: ranges of axes, their ticks and projection of points
+type Layout struct {
+	Plot     Rect
+	From, To time.Time
+	Min, Max float64
+	// ValueTicks are round values within [Min, Max]
+	ValueTicks []float64
+	// TimeTicks are starts of days, months or years within [From, To], labelled by TimeFormat
+	TimeTicks  []time.Time
+	TimeFormat string
+}
+
+const valueTicks = 5
+
+// NewLayout lays chart out in plot area, false is returned when the chart has no points
+func NewLayout(c Chart, plot Rect) (Layout, bool) {
+	l := Layout{Plot: plot, Min: math.Inf(1), Max: math.Inf(-1)}
+	for _, s := range c.Series {
+		for _, p := range s.Points {
+			if l.From.IsZero() || p.Time.Before(l.From) {
+				l.From = p.Time
+			}
+			if p.Time.After(l.To) {
+				l.To = p.Time
+			}
+			l.Min = math.Min(l.Min, p.Value)
+			l.Max = math.Max(l.Max, p.Value)
+		}
+	}
+	if l.From.IsZero() {
+		return Layout{}, false
+	}
+	if !c.From.IsZero() && !c.To.IsZero() {
+		l.From, l.To = c.From, c.To
+	}
+	for _, g := range c.Goals {
+		l.Min = math.Min(l.Min, g)
+		l.Max = math.Max(l.Max, g)
+	}
+
+	if !l.To.After(l.From) {
+		l.From = l.From.Add(-12 * time.Hour)
+		l.To = l.To.Add(12 * time.Hour)
+	}
+	if l.Max-l.Min < 1e-9 {
+		l.Min--
+		l.Max++
+	}
+	l.ValueTicks = niceTicks(l.Min, l.Max, valueTicks)
+	l.Min = math.Min(l.Min, l.ValueTicks[0])
+	l.Max = math.Max(l.Max, l.ValueTicks[len(l.ValueTicks)-1])
+	l.TimeTicks, l.TimeFormat = timeTicks(l.From, l.To)
+
+	return l, true
+}
+
+// X returns horizontal position of time
+func (l Layout) X(t time.Time) float64 {
+	return l.Plot.X + l.Plot.W*float64(t.Sub(l.From))/float64(l.To.Sub(l.From))
+}
+
+// Y returns vertical position of value
+func (l Layout) Y(v float64) float64 {
+	return l.Plot.Y + l.Plot.H*(l.Max-v)/(l.Max-l.Min)
+}
+
+// ValueLabel formats value with as many decimals as the step between value ticks needs
+func (l Layout) ValueLabel(v float64) string {
+	decimals := 0
+	if len(l.ValueTicks) > 1 {
+		step := l.ValueTicks[1] - l.ValueTicks[0]
+		decimals = max(0, int(math.Ceil(-math.Log10(step)-1e-9)))
+	}
+
+	return strconv.FormatFloat(v, 'f', decimals, 64)
+}
+
+// TimeLabel formats time tick
+func (l Layout) TimeLabel(t time.Time) string {
+	return t.Format(l.TimeFormat)
+}
+
+// MovingAverage returns trailing average of points: every point is replaced by the mean of points
+// measured within window up to it, so irregular measurements are averaged over the same time
+func MovingAverage(points []Point, window time.Duration) []Point {
+	res := make([]Point, 0, len(points))
+	if window <= 0 {
+		return append(res, points...)
+	}
+	start, sum := 0, 0.0
+	for _, p := range points {
+		sum += p.Value
+		for !points[start].Time.After(p.Time.Add(-window)) {
+			sum -= points[start].Value
+			start++
+		}
+		res = append(res, Point{Time: p.Time, Value: sum / float64(len(res)+1-start)})
+	}
+
+	return res
+}
+
+// niceTicks returns about n round values covering [min, max]: multiples of 1, 2 or 5 times a power of ten
+func niceTicks(min, max float64, n int) []float64 {
+	raw := (max - min) / float64(n-1)
+	magnitude := math.Pow(10, math.Floor(math.Log10(raw)))
+	step := magnitude * 10
+	for _, m := range []float64{1, 2, 5} {
+		if raw <= m*magnitude {
+			step = m * magnitude
+			break
+		}
+	}
+
+	ticks := make([]float64, 0, n+1)
+	for i := math.Floor(min / step); ; i++ {
+		// multiplying instead of summing steps keeps values round
+		v := i * step
+		ticks = append(ticks, v)
+		if v >= max-step*1e-9 {
+			break
+		}
+	}
+
+	return ticks
+}
+
+// timeTicks returns starts of periods within [from, to] and layout of their labels, periods are chosen
+// so that there are a few ticks
+func timeTicks(from, to time.Time) ([]time.Time, string) {
+	days := to.Sub(from).Hours() / 24
+	next := func(t time.Time) time.Time { return t.AddDate(0, 0, 1) }
+	start := time.Date(from.Year(), from.Month(), from.Day(), 0, 0, 0, 0, from.Location())
+	format := "Jan 2"
+
+	switch {
+	case days <= 10:
+	case days <= 70:
+		next = func(t time.Time) time.Time { return t.AddDate(0, 0, 7) }
+		// weeks start on Monday
+		start = start.AddDate(0, 0, -(int(start.Weekday())+6)%7)
+	case days <= 400:
+		next = func(t time.Time) time.Time { return t.AddDate(0, 1, 0) }
+		start = time.Date(from.Year(), from.Month(), 1, 0, 0, 0, 0, from.Location())
+		format = "Jan 2006"
+	case days <= 1200:
+		next = func(t time.Time) time.Time { return t.AddDate(0, 3, 0) }
+		start = time.Date(from.Year(), from.Month()-(from.Month()-1)%3, 1, 0, 0, 0, 0, from.Location())
+		format = "Jan 2006"
+	default:
+		step := int(math.Ceil(days / 365 / 6))
+		next = func(t time.Time) time.Time { return t.AddDate(step, 0, 0) }
+		start = time.Date(from.Year(), time.January, 1, 0, 0, 0, 0, from.Location())
+		format = "2006"
+	}
+
+	var ticks []time.Time
+	for t := start; !t.After(to); t = next(t) {
+		if !t.Before(from) {
+			ticks = append(ticks, t)
+		}
+	}
+
+	return ticks, format
+}
@@ -0,0 +1,114 @@
+package chart
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNiceTicks(t *testing.T) {
+	tests := []struct {
+		min, max float64
+		expected []float64
+	}{
+		{min: 78.3, max: 82.9, expected: []float64{78, 80, 82, 84}},
+		{min: 0, max: 100, expected: []float64{0, 50, 100}},
+		{min: 14.2, max: 22.5, expected: []float64{10, 15, 20, 25}},
+		{min: 0.11, max: 0.19, expected: []float64{0.1, 0.12, 0.14, 0.16, 0.18, 0.2}},
+	}
+
+	for _, tt := range tests {
+		ticks := niceTicks(tt.min, tt.max, valueTicks)
+		assert.InDeltaSlice(t, tt.expected, ticks, 1e-9, "%v..%v", tt.min, tt.max)
+	}
+
+	l := Layout{ValueTicks: niceTicks(0.11, 0.19, valueTicks)}
+	assert.Equal(t, "0.12", l.ValueLabel(l.ValueTicks[1]), "decimals of step")
+	l = Layout{ValueTicks: niceTicks(0, 1, valueTicks)}
+	assert.Equal(t, "0.5", l.ValueLabel(0.5))
+}
+
+func TestLayout(t *testing.T) {
+	day := time.Date(2026, time.October, 1, 0, 0, 0, 0, time.UTC)
+	c := Chart{
+		Series: []Series{{Name: "weight", Points: []Point{
+			{Time: day, Value: 82},
+			{Time: day.AddDate(0, 0, 20), Value: 79},
+		}}},
+		Goals: []float64{75},
+	}
+
+	l, ok := NewLayout(c, Rect{X: 10, Y: 20, W: 200, H: 100})
+	require.True(t, ok)
+	assert.Equal(t, []float64{74, 76, 78, 80, 82}, l.ValueTicks, "goal is within the range")
+	assert.Equal(t, 10.0, l.X(day))
+	assert.Equal(t, 210.0, l.X(day.AddDate(0, 0, 20)))
+	assert.Equal(t, 20.0, l.Y(82))
+	assert.Equal(t, 120.0, l.Y(74))
+	assert.Equal(t, "Jan 2", l.TimeFormat)
+	assert.Equal(t, "76", l.ValueLabel(76))
+	assert.Equal(t, "Oct 5", l.TimeLabel(l.TimeTicks[0]))
+	assert.Equal(t, []time.Time{day.AddDate(0, 0, 4), day.AddDate(0, 0, 11), day.AddDate(0, 0, 18)}, l.TimeTicks, "Mondays")
+
+	_, ok = NewLayout(Chart{Series: []Series{{Name: "weight"}}}, Rect{W: 1, H: 1})
+	assert.False(t, ok)
+
+	single, ok := NewLayout(Chart{Series: []Series{{Points: []Point{{Time: day, Value: 80}}}}}, Rect{W: 100, H: 100})
+	require.True(t, ok)
+	assert.Equal(t, 50.0, single.X(day), "single point is in the middle")
+	assert.Equal(t, 50.0, single.Y(80))
+}
+
+func TestTimeTicks(t *testing.T) {
+	from := time.Date(2025, time.March, 14, 0, 0, 0, 0, time.UTC)
+
+	ticks, format := timeTicks(from, from.AddDate(1, 0, 0))
+	assert.Equal(t, "Jan 2006", format)
+	assert.Equal(t, time.Date(2025, time.April, 1, 0, 0, 0, 0, time.UTC), ticks[0])
+	assert.Len(t, ticks, 12)
+
+	ticks, format = timeTicks(from, from.AddDate(10, 0, 0))
+	assert.Equal(t, "2006", format)
+	assert.Equal(t, time.Date(2027, time.January, 1, 0, 0, 0, 0, time.UTC), ticks[0])
+}
+
+func TestMovingAverage(t *testing.T) {
+	day := time.Date(2026, time.October, 1, 8, 0, 0, 0, time.UTC)
+	points := []Point{
+		{Time: day, Value: 80},
+		{Time: day.AddDate(0, 0, 1), Value: 82},
+		{Time: day.AddDate(0, 0, 2), Value: 84},
+		{Time: day.AddDate(0, 0, 10), Value: 78},
+	}
+
+	avg := MovingAverage(points, 3*24*time.Hour)
+	require.Len(t, avg, 4)
+	assert.Equal(t, []float64{80, 81, 82, 78}, []float64{avg[0].Value, avg[1].Value, avg[2].Value, avg[3].Value},
+		"gap longer than window starts over")
+	assert.Equal(t, points[3].Time, avg[3].Time)
+
+	avg = MovingAverage(points, 2*24*time.Hour)
+	assert.Equal(t, 83.0, avg[2].Value, "point exactly window before is left out")
+
+	assert.Equal(t, points, MovingAverage(points, 0))
+	assert.Empty(t, MovingAverage(nil, time.Hour))
+}
+
+func TestLayoutRange(t *testing.T) {
+	day := time.Date(2026, time.October, 10, 0, 0, 0, 0, time.UTC)
+	c := Chart{
+		Series: []Series{{Points: []Point{{Time: day, Value: 80}}}},
+		From:   day.AddDate(0, 0, -9),
+		To:     day.AddDate(0, 0, 1),
+	}
+
+	l, ok := NewLayout(c, Rect{W: 100, H: 100})
+	require.True(t, ok)
+	assert.Equal(t, 90.0, l.X(day), "explicit range")
+
+	c.Series = nil
+	_, ok = NewLayout(c, Rect{W: 100, H: 100})
+	assert.False(t, ok, "range without points")
+}
@@ -0,0 +1,181 @@
+package chart
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"math"
+	"strconv"
+)
+
+// Names of themes
+const (
+	ThemeLight = "light"
+	ThemeDark  = "dark"
+)
+
+// Theme is colors of SVG chart, Series are colors of series in turn
+type Theme struct {
+	Background string
+	Text       string
+	Muted      string
+	Grid       string
+	Goal       string
+	Series     []string
+}
+
+// Themes are color themes by name
+var Themes = map[string]Theme{
+	ThemeLight: {
+		Background: "#ffffff", Text: "#1f2328", Muted: "#656d76", Grid: "#d8dee4", Goal: "#1a7f37",
+		Series: []string{"#0969da", "#bf3989", "#9a6700"},
+	},
+	ThemeDark: {
+		Background: "#0d1117", Text: "#e6edf3", Muted: "#8d96a0", Grid: "#30363d", Goal: "#3fb950",
+		Series: []string{"#58a6ff", "#db61a2", "#d29922"},
+	},
+}
+
+// SVGOptions are size of SVG chart in pixels and its colors
+type SVGOptions struct {
+	Width, Height float64
+	Theme         Theme
+}
+
+const (
+	// padding leaves room for title and legend above the plot, value labels on the left and time labels below
+	padTop    = 36.0
+	padRight  = 16.0
+	padBottom = 28.0
+	padLeft   = 48.0
+	fontSize  = 12.0
+	// charWidth is average width of a character of the font, used to keep labels apart
+	charWidth = fontSize * 0.6
+	// maxPointMarks is the number of points up to which every point is marked
+	maxPointMarks = 60
+)
+
+// WriteSVG renders chart as standalone SVG document. It uses no scripts, external fonts or styles, so
+// it can be shown by image tags and email clients.
+func WriteSVG(w io.Writer, c Chart, opts SVGOptions) error {
+	t := opts.Theme
+	var b bytes.Buffer
+	title := c.Title
+	if c.Unit != "" {
+		title += ", " + c.Unit
+	}
+
+	fmt.Fprintf(&b, `<svg xmlns="http://www.w3.org/2000/svg" width="%s" height="%s" viewBox="0 0 %[1]s %[2]s" `+
+		`font-family="-apple-system, 'Segoe UI', Roboto, Helvetica, Arial, sans-serif" font-size="%s" role="img">`,
+		num(opts.Width), num(opts.Height), num(fontSize))
+	b.WriteString("<title>")
+	escape(&b, title)
+	b.WriteString("</title>")
+	fmt.Fprintf(&b, `<rect width="100%%" height="100%%" fill="%s"/>`, t.Background)
+	fmt.Fprintf(&b, `<text x="%s" y="22" fill="%s" font-weight="600">`, num(padLeft), t.Text)
+	escape(&b, title)
+	b.WriteString("</text>")
+
+	plot := Rect{X: padLeft, Y: padTop, W: opts.Width - padLeft - padRight, H: opts.Height - padTop - padBottom}
+	l, ok := NewLayout(c, plot)
+	if !ok {
+		fmt.Fprintf(&b, `<text x="%s" y="%s" fill="%s" text-anchor="middle">No measurements</text>`,
+			num(opts.Width/2), num(opts.Height/2), t.Muted)
+		b.WriteString("</svg>")
+		_, err := w.Write(b.Bytes())
+
+		return err
+	}
+
+	writeLegend(&b, c, opts)
+
+	for _, v := range l.ValueTicks {
+		y := num(l.Y(v))
+		fmt.Fprintf(&b, `<line x1="%s" y1="%s" x2="%s" y2="%[2]s" stroke="%s"/>`, num(plot.X), y, num(plot.X+plot.W), t.Grid)
+		fmt.Fprintf(&b, `<text x="%s" y="%s" fill="%s" text-anchor="end" dy="4">%s</text>`, num(plot.X-6), y, t.Muted, l.ValueLabel(v))
+	}
+	bottom := plot.Y + plot.H
+	fmt.Fprintf(&b, `<line x1="%s" y1="%s" x2="%s" y2="%[2]s" stroke="%s"/>`, num(plot.X), num(bottom), num(plot.X+plot.W), t.Muted)
+	// labels are skipped in turn when they would overlap
+	labelWidth := float64(len(l.TimeFormat))*charWidth + 8
+	every := 1
+	if n := len(l.TimeTicks); n > 1 {
+		every = max(1, int(math.Ceil(labelWidth*float64(n)/plot.W)))
+	}
+	for i, tick := range l.TimeTicks {
+		if i%every != 0 {
+			continue
+		}
+		x := num(l.X(tick))
+		fmt.Fprintf(&b, `<line x1="%s" y1="%s" x2="%[1]s" y2="%s" stroke="%s"/>`, x, num(bottom), num(bottom+4), t.Muted)
+		fmt.Fprintf(&b, `<text x="%s" y="%s" fill="%s" text-anchor="middle">%s</text>`, x, num(bottom+18), t.Muted, l.TimeLabel(tick))
+	}
+
+	for _, g := range c.Goals {
+		y := l.Y(g)
+		fmt.Fprintf(&b, `<line x1="%s" y1="%s" x2="%s" y2="%[2]s" stroke="%s" stroke-width="1.5" stroke-dasharray="6 4"/>`,
+			num(plot.X), num(y), num(plot.X+plot.W), t.Goal)
+		fmt.Fprintf(&b, `<text x="%s" y="%s" fill="%s" text-anchor="end">Goal %s</text>`, num(plot.X+plot.W-4), num(y-5), t.Goal, l.ValueLabel(g))
+	}
+
+	for i, s := range c.Series {
+		if len(s.Points) == 0 {
+			continue
+		}
+		color := t.Series[i%len(t.Series)]
+		width := "1.5"
+		if s.Trend {
+			width = "2.5"
+		}
+		b.WriteString(`<path d="`)
+		for j, p := range s.Points {
+			cmd := "L"
+			if j == 0 {
+				cmd = "M"
+			}
+			fmt.Fprintf(&b, "%s%s %s", cmd, num(l.X(p.Time)), num(l.Y(p.Value)))
+		}
+		fmt.Fprintf(&b, `" fill="none" stroke="%s" stroke-width="%s" stroke-linejoin="round" stroke-linecap="round"/>`, color, width)
+		if s.Trend || len(s.Points) > maxPointMarks {
+			continue
+		}
+		for _, p := range s.Points {
+			fmt.Fprintf(&b, `<circle cx="%s" cy="%s" r="2.5" fill="%s"/>`, num(l.X(p.Time)), num(l.Y(p.Value)), color)
+		}
+	}
+
+	b.WriteString("</svg>")
+	_, err := w.Write(b.Bytes())
+
+	return err
+}
+
+// writeLegend names series in the top right corner when there are several of them
+func writeLegend(b *bytes.Buffer, c Chart, opts SVGOptions) {
+	if len(c.Series) < 2 {
+		return
+	}
+
+	x := opts.Width - padRight
+	for i := len(c.Series) - 1; i >= 0; i-- {
+		s := c.Series[i]
+		x -= float64(len([]rune(s.Name))) * charWidth
+		fmt.Fprintf(b, `<text x="%s" y="22" fill="%s">`, num(x), opts.Theme.Muted)
+		escape(b, s.Name)
+		b.WriteString("</text>")
+		x -= 18
+		fmt.Fprintf(b, `<rect x="%s" y="14" width="12" height="3" fill="%s"/>`, num(x), opts.Theme.Series[i%len(opts.Theme.Series)])
+		x -= 12
+	}
+}
+
+func escape(b *bytes.Buffer, s string) {
+	// writing to bytes.Buffer does not fail
+	_ = xml.EscapeText(b, []byte(s))
+}
+
+// num formats coordinate with precision of a tenth of pixel
+func num(v float64) string {
+	return strconv.FormatFloat(math.Round(v*10)/10, 'f', -1, 64)
+}
@@ -0,0 +1,82 @@
+package chart
+
+import (
+	"bytes"
+	"encoding/xml"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// elements returns count of SVG elements by name, failing when document is not well-formed
+func elements(t *testing.T, doc []byte) map[string]int {
+	t.Helper()
+
+	res := map[string]int{}
+	d := xml.NewDecoder(bytes.NewReader(doc))
+	for {
+		tok, err := d.Token()
+		if err == io.EOF {
+			return res
+		}
+		require.NoError(t, err)
+		if el, ok := tok.(xml.StartElement); ok {
+			res[el.Name.Local]++
+		}
+	}
+}
+
+func TestWriteSVG(t *testing.T) {
+	day := time.Date(2026, time.October, 1, 0, 0, 0, 0, time.UTC)
+	points := []Point{{Time: day, Value: 82}, {Time: day.AddDate(0, 0, 7), Value: 80.5}, {Time: day.AddDate(0, 0, 14), Value: 79}}
+	c := Chart{
+		Title: "Weight <raw>",
+		Unit:  "kg",
+		Series: []Series{
+			{Name: "Weight", Points: points},
+			{Name: "7-day average", Points: MovingAverage(points, 7*24*time.Hour), Trend: true},
+		},
+		Goals: []float64{75},
+	}
+
+	t.Run("Chart", func(t *testing.T) {
+		var b bytes.Buffer
+		require.NoError(t, WriteSVG(&b, c, SVGOptions{Width: 640, Height: 320, Theme: Themes[ThemeDark]}))
+
+		doc := b.String()
+		count := elements(t, b.Bytes())
+		assert.True(t, strings.HasPrefix(doc, `<svg xmlns="http://www.w3.org/2000/svg" width="640" height="320"`))
+		assert.Contains(t, doc, "<title>Weight &lt;raw&gt;, kg</title>")
+		assert.Contains(t, doc, `fill="`+Themes[ThemeDark].Background+`"`)
+		assert.Contains(t, doc, ">Goal 75</text>")
+		assert.Contains(t, doc, ">7-day average</text>")
+		assert.Contains(t, doc, `stroke-dasharray="6 4"`)
+		assert.Equal(t, 2, count["path"])
+		assert.Equal(t, 3, count["circle"], "only measurements are marked")
+	})
+
+	t.Run("No measurements", func(t *testing.T) {
+		var b bytes.Buffer
+		require.NoError(t, WriteSVG(&b, Chart{Title: "Weight"}, SVGOptions{Width: 300, Height: 150, Theme: Themes[ThemeLight]}))
+
+		assert.Contains(t, b.String(), ">No measurements</text>")
+		assert.Zero(t, elements(t, b.Bytes())["path"])
+	})
+}
+
+func TestTimeLabelsDoNotOverlap(t *testing.T) {
+	from := time.Date(2024, time.January, 1, 0, 0, 0, 0, time.UTC)
+	c := Chart{
+		Series: []Series{{Points: []Point{{Time: from, Value: 80}, {Time: from.AddDate(1, 0, 0), Value: 78}}}},
+	}
+
+	var b bytes.Buffer
+	require.NoError(t, WriteSVG(&b, c, SVGOptions{Width: 300, Height: 150, Theme: Themes[ThemeLight]}))
+
+	// a year of monthly labels does not fit in 236px wide plot, every fourth is shown
+	assert.Equal(t, 3, strings.Count(b.String(), " 2024</text>"))
+}
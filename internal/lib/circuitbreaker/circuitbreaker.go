@@ -0,0 +1,125 @@
+package circuitbreaker
+
+import (
+	"errors"
+	"sync"
+	"time"
+)
+
+var ErrOpen = errors.New("circuit breaker is open")
+
+type State int
+
+const (
+	// StateClosed lets all calls through and counts consecutive failures
+	StateClosed State = iota
+	// StateOpen rejects all calls until OpenTimeout passes
+	StateOpen
+	// StateHalfOpen lets single probe call through, its result closes or reopens the breaker
+	StateHalfOpen
+)
+
+func (s State) String() string {
+	switch s {
+	case StateClosed:
+		return "closed"
+	case StateOpen:
+		return "open"
+	default:
+		return "half-open"
+	}
+}
+
+// Settings of breaker. Zero FailureThreshold disables the breaker.
+type Settings struct {
+	FailureThreshold int
+	OpenTimeout      time.Duration
+}
+
+// Breaker stops calls to a dependency after FailureThreshold consecutive failures
+// so that callers fail fast instead of waiting for timeouts.
+type Breaker struct {
+	settings Settings
+	now      func() time.Time
+
+	mu       sync.Mutex
+	state    State
+	failures int
+	openedAt time.Time
+	probing  bool
+}
+
+func New(settings Settings) *Breaker {
+	return &Breaker{settings: settings, now: time.Now}
+}
+
+// Allow reports whether call may be made. Every allowed call must be followed by Success, Failure or Release.
+func (b *Breaker) Allow() error {
+	if b.settings.FailureThreshold <= 0 {
+		return nil
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	switch b.state {
+	case StateOpen:
+		if b.now().Sub(b.openedAt) < b.settings.OpenTimeout {
+			return ErrOpen
+		}
+		b.state = StateHalfOpen
+		b.probing = true
+
+		return nil
+	case StateHalfOpen:
+		if b.probing {
+			return ErrOpen
+		}
+		b.probing = true
+
+		return nil
+	default:
+		return nil
+	}
+}
+
+func (b *Breaker) Success() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.state = StateClosed
+	b.failures = 0
+	b.probing = false
+}
+
+func (b *Breaker) Failure() {
+	if b.settings.FailureThreshold <= 0 {
+		return
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.failures++
+	if b.state == StateHalfOpen || b.failures >= b.settings.FailureThreshold {
+		b.state = StateOpen
+		b.openedAt = b.now()
+		b.probing = false
+	}
+}
+
+// Release ends allowed call that tells nothing about the dependency, e.g. one canceled by caller.
+// A released probe lets the next call probe.
+func (b *Breaker) Release() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.probing = false
+}
+
+func (b *Breaker) State() State {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	return b.state
+}
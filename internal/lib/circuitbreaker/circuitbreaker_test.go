@@ -0,0 +1,56 @@
+package circuitbreaker
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBreaker(t *testing.T) {
+	now := time.Date(2026, time.October, 19, 12, 0, 0, 0, time.UTC)
+	b := New(Settings{FailureThreshold: 2, OpenTimeout: time.Minute})
+	b.now = func() time.Time { return now }
+
+	assert.NoError(t, b.Allow())
+	b.Failure()
+	assert.Equal(t, StateClosed, b.State())
+
+	// success resets consecutive failures
+	b.Success()
+	b.Failure()
+	assert.Equal(t, StateClosed, b.State())
+	b.Failure()
+	assert.Equal(t, StateOpen, b.State())
+	assert.ErrorIs(t, b.Allow(), ErrOpen)
+
+	// after timeout single probe is let through
+	now = now.Add(time.Minute)
+	assert.NoError(t, b.Allow())
+	assert.Equal(t, StateHalfOpen, b.State())
+	assert.ErrorIs(t, b.Allow(), ErrOpen)
+
+	// failed probe opens breaker again
+	b.Failure()
+	assert.Equal(t, StateOpen, b.State())
+	assert.ErrorIs(t, b.Allow(), ErrOpen)
+
+	// released probe neither closes nor reopens breaker
+	now = now.Add(time.Minute)
+	assert.NoError(t, b.Allow())
+	b.Release()
+	assert.Equal(t, StateHalfOpen, b.State())
+	assert.NoError(t, b.Allow())
+	b.Success()
+	assert.Equal(t, StateClosed, b.State())
+	assert.NoError(t, b.Allow())
+}
+
+func TestDisabledBreaker(t *testing.T) {
+	b := New(Settings{})
+	for i := 0; i < 10; i++ {
+		b.Failure()
+	}
+
+	assert.NoError(t, b.Allow())
+}
@@ -25,6 +25,7 @@ func (opts PrettyHandlerOptions) NewPrettyHandler(
 	out io.Writer,
 ) *PrettyHandler {
 	h := &PrettyHandler{
+		opts:    opts,
 		Handler: slog.NewJSONHandler(out, opts.SlogOpts),
 		l:       stdLog.New(out, "", 0),
 	}
@@ -49,13 +50,13 @@ func (h *PrettyHandler) Handle(_ context.Context, r slog.Record) error {
 	fields := make(map[string]interface{}, r.NumAttrs())
 
 	r.Attrs(func(a slog.Attr) bool {
-		fields[a.Key] = a.Value.Any()
+		h.setField(fields, nil, a)
 
 		return true
 	})
 
 	for _, a := range h.attrs {
-		fields[a.Key] = a.Value.Any()
+		h.setField(fields, nil, a)
 	}
 
 	var b []byte
@@ -81,17 +82,44 @@ func (h *PrettyHandler) Handle(_ context.Context, r slog.Record) error {
 	return nil
 }
 
+// setField resolves attribute, passes it through ReplaceAttr hook and puts it into fields.
+// Groups are expanded into nested maps.
+func (h *PrettyHandler) setField(fields map[string]interface{}, groups []string, a slog.Attr) {
+	a.Value = a.Value.Resolve()
+
+	if a.Value.Kind() == slog.KindGroup {
+		nested := make(map[string]interface{})
+		for _, ga := range a.Value.Group() {
+			h.setField(nested, append(groups, a.Key), ga)
+		}
+		fields[a.Key] = nested
+
+		return
+	}
+
+	if h.opts.SlogOpts != nil && h.opts.SlogOpts.ReplaceAttr != nil {
+		a = h.opts.SlogOpts.ReplaceAttr(groups, a)
+	}
+	if a.Key == "" {
+		return
+	}
+
+	fields[a.Key] = a.Value.Any()
+}
+
 func (h *PrettyHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
 	return &PrettyHandler{
+		opts:    h.opts,
 		Handler: h.Handler,
 		l:       h.l,
-		attrs:   attrs,
+		attrs:   append(append([]slog.Attr{}, h.attrs...), attrs...),
 	}
 }
 
 func (h *PrettyHandler) WithGroup(name string) slog.Handler {
 	// TODO: implement
 	return &PrettyHandler{
+		opts:    h.opts,
 		Handler: h.Handler.WithGroup(name),
 		l:       h.l,
 	}
@@ -0,0 +1,82 @@
+package redact
+
+import (
+	"log/slog"
+	"strings"
+)
+
+// Policy describes which classes of sensitive values are masked before a log record is written.
+// Secrets (passwords, tokens) are masked under every policy.
+type Policy string
+
+const (
+	// PolicyStrict masks secrets and emails.
+	PolicyStrict Policy = "strict"
+	// PolicyRelaxed masks secrets only. Meant for local runs where emails help debugging.
+	PolicyRelaxed Policy = "relaxed"
+)
+
+const Mask = "[REDACTED]"
+
+var (
+	secretKeys = []string{"password", "token", "secret", "authorization"}
+	emailKeys  = []string{"email", "login"}
+)
+
+// ParsePolicy converts config value to Policy. Unknown values fall back to PolicyStrict.
+func ParsePolicy(s string) Policy {
+	if Policy(s) == PolicyRelaxed {
+		return PolicyRelaxed
+	}
+
+	return PolicyStrict
+}
+
+// Secret masks any non-empty secret value.
+func Secret(s string) string {
+	if s == "" {
+		return ""
+	}
+
+	return Mask
+}
+
+// Email masks local part of an email leaving only its first symbol and the domain, e.g. j***@mail.ru
+func Email(s string) string {
+	at := strings.LastIndex(s, "@")
+	if at <= 0 {
+		return Secret(s)
+	}
+
+	return s[:1] + "***" + s[at:]
+}
+
+// ReplaceAttr returns slog.HandlerOptions.ReplaceAttr hook that masks attributes
+// according to the policy. Attributes are classified by their keys.
+func ReplaceAttr(p Policy) func(groups []string, a slog.Attr) slog.Attr {
+	return func(_ []string, a slog.Attr) slog.Attr {
+		if a.Value.Kind() != slog.KindString {
+			return a
+		}
+
+		key := strings.ToLower(a.Key)
+		switch {
+		case containsAny(key, secretKeys):
+			return slog.String(a.Key, Secret(a.Value.String()))
+		case p != PolicyRelaxed && containsAny(key, emailKeys):
+			return slog.String(a.Key, Email(a.Value.String()))
+		}
+
+		return a
+	}
+}
+
+func containsAny(key string, parts []string) bool {
+	for _, p := range parts {
+		if strings.Contains(key, p) {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,93 @@
+package redact
+
+import (
+	"bytes"
+	"log/slog"
+	"testing"
+
+	"ChadProgress/internal/lib/logger/handlers/slogpretty"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type credentials struct {
+	Email    string
+	Password string
+}
+
+func (c credentials) LogValue() slog.Value {
+	return slog.GroupValue(
+		slog.String("email", c.Email),
+		slog.String("password", c.Password),
+	)
+}
+
+func TestEmail(t *testing.T) {
+	tests := []struct {
+		name     string
+		email    string
+		expected string
+	}{
+		{name: "Regular email", email: "john@example.com", expected: "j***@example.com"},
+		{name: "Not an email", email: "john", expected: Mask},
+		{name: "Empty", email: "", expected: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, Email(tt.email))
+		})
+	}
+}
+
+func TestReplaceAttr(t *testing.T) {
+	tests := []struct {
+		name        string
+		policy      Policy
+		mustContain []string
+		mustNotHave []string
+	}{
+		{
+			name:        "Strict",
+			policy:      PolicyStrict,
+			mustContain: []string{"j***@example.com", Mask},
+			mustNotHave: []string{"john@example.com", "qwerty123", "secret-jwt"},
+		},
+		{
+			name:        "Relaxed",
+			policy:      PolicyRelaxed,
+			mustContain: []string{"john@example.com", Mask},
+			mustNotHave: []string{"qwerty123", "secret-jwt"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			opts := &slog.HandlerOptions{ReplaceAttr: ReplaceAttr(tt.policy)}
+			prettyOpts := slogpretty.PrettyHandlerOptions{SlogOpts: opts}
+
+			var jsonOut, prettyOut bytes.Buffer
+			loggers := []*slog.Logger{
+				slog.New(slog.NewJSONHandler(&jsonOut, opts)),
+				slog.New(prettyOpts.NewPrettyHandler(&prettyOut)),
+			}
+
+			for _, log := range loggers {
+				log.With(slog.String("user_email", "john@example.com")).Info(
+					"request body decoded",
+					slog.Any("request", credentials{Email: "john@example.com", Password: "qwerty123"}),
+					slog.String("token", "secret-jwt"),
+				)
+			}
+
+			for _, out := range []string{jsonOut.String(), prettyOut.String()} {
+				for _, s := range tt.mustContain {
+					assert.Contains(t, out, s)
+				}
+				for _, s := range tt.mustNotHave {
+					assert.NotContains(t, out, s)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,64 @@
+package mailer
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// File writes every message to a separate .eml file in directory, so that
+// links from emails can be opened in local runs
+type File struct {
+	dir  string
+	from string
+}
+
+func NewFile(dir, from string) *File {
+	return &File{dir: dir, from: from}
+}
+
+func (f *File) Send(ctx context.Context, msg Message) error {
+	const op = "mailer.File.Send"
+
+	if err := os.MkdirAll(f.dir, 0o750); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	suffix := make([]byte, 4)
+	if _, err := rand.Read(suffix); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	now := time.Now()
+	name := fmt.Sprintf("%s-%s.eml", now.UTC().Format("20060102T150405"), hex.EncodeToString(suffix))
+	if err := os.WriteFile(filepath.Join(f.dir, name), format(f.from, msg, now), 0o640); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	return nil
+}
+
+// Log writes messages to log instead of sending them. Bodies contain secret links,
+// so it must never be used in production.
+type Log struct {
+	log *slog.Logger
+}
+
+func NewLog(log *slog.Logger) *Log {
+	return &Log{log: log}
+}
+
+func (l *Log) Send(ctx context.Context, msg Message) error {
+	l.log.Info("email is not sent, mailer backend is log",
+		slog.String("op", "mailer.Log.Send"),
+		slog.String("subject", msg.Subject),
+		slog.String("body", msg.Body),
+	)
+
+	return nil
+}
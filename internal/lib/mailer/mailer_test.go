@@ -0,0 +1,61 @@
+package mailer
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type recorder struct {
+	sent []Message
+}
+
+func (r *recorder) Send(_ context.Context, msg Message) error {
+	r.sent = append(r.sent, msg)
+	return nil
+}
+
+func TestNotifierLinks(t *testing.T) {
+	rec := &recorder{}
+	n := NewNotifier(rec, "https://app.example.com/")
+
+	require.NoError(t, n.SendVerification(context.Background(), "user@example.com", "a+b/c"))
+	require.NoError(t, n.SendPasswordReset(context.Background(), "user@example.com", "token"))
+	require.NoError(t, n.SendEmailChange(context.Background(), "new@example.com", "token"))
+
+	require.Len(t, rec.sent, 3)
+	assert.Equal(t, "user@example.com", rec.sent[0].To)
+	assert.Contains(t, rec.sent[0].Body, "https://app.example.com/verify-email?token=a%2Bb%2Fc")
+	assert.Contains(t, rec.sent[1].Body, "https://app.example.com/reset-password?token=token")
+	assert.Equal(t, "new@example.com", rec.sent[2].To)
+	assert.Contains(t, rec.sent[2].Body, "https://app.example.com/confirm-email?token=token")
+}
+
+func TestFileSender(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "mail")
+	f := NewFile(dir, "noreply@example.com")
+
+	require.NoError(t, f.Send(context.Background(), Message{To: "user@example.com", Subject: "Hi", Body: "line 1\nline 2"}))
+
+	files, err := os.ReadDir(dir)
+	require.NoError(t, err)
+	require.Len(t, files, 1)
+	assert.True(t, strings.HasSuffix(files[0].Name(), ".eml"))
+
+	content, err := os.ReadFile(filepath.Join(dir, files[0].Name()))
+	require.NoError(t, err)
+	assert.Contains(t, string(content), "From: noreply@example.com\r\nTo: user@example.com\r\nSubject: Hi\r\n")
+	assert.True(t, strings.HasSuffix(string(content), "\r\n\r\nline 1\r\nline 2"))
+}
+
+func TestSMTPRejectsHeaderInjection(t *testing.T) {
+	s := NewSMTP(SMTPOptions{Host: "127.0.0.1", Port: "1"})
+
+	err := s.Send(context.Background(), Message{To: "user@example.com\r\nBcc: victim@example.com", Subject: "Hi"})
+	assert.ErrorIs(t, err, ErrInvalidHeader)
+}
@@ -0,0 +1,76 @@
+package mailer
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+// Notifier composes account emails. Links point to the frontend at baseURL,
+// which passes the token to the API.
+type Notifier struct {
+	sender  Sender
+	baseURL string
+}
+
+func NewNotifier(sender Sender, baseURL string) *Notifier {
+	return &Notifier{sender: sender, baseURL: strings.TrimRight(baseURL, "/")}
+}
+
+func (n *Notifier) SendVerification(ctx context.Context, email, token string) error {
+	const op = "mailer.Notifier.SendVerification"
+
+	err := n.sender.Send(ctx, Message{
+		To:      email,
+		Subject: "Confirm your email",
+		Body: "Welcome to ChadProgress!\n\n" +
+			"Please confirm your email by following the link:\n" +
+			n.link("/verify-email", token) + "\n",
+	})
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	return nil
+}
+
+func (n *Notifier) SendPasswordReset(ctx context.Context, email, token string) error {
+	const op = "mailer.Notifier.SendPasswordReset"
+
+	err := n.sender.Send(ctx, Message{
+		To:      email,
+		Subject: "Reset your password",
+		Body: "Somebody requested password reset for your ChadProgress account.\n\n" +
+			"Follow the link to choose a new password:\n" +
+			n.link("/reset-password", token) + "\n\n" +
+			"If it was not you, ignore this email.\n",
+	})
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	return nil
+}
+
+func (n *Notifier) SendEmailChange(ctx context.Context, newEmail, token string) error {
+	const op = "mailer.Notifier.SendEmailChange"
+
+	err := n.sender.Send(ctx, Message{
+		To:      newEmail,
+		Subject: "Confirm your new email",
+		Body: "Somebody asked to move a ChadProgress account to this email.\n\n" +
+			"Follow the link to confirm the change:\n" +
+			n.link("/confirm-email", token) + "\n\n" +
+			"If it was not you, ignore this email.\n",
+	})
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	return nil
+}
+
+func (n *Notifier) link(path, token string) string {
+	return n.baseURL + path + "?token=" + url.QueryEscape(token)
+}
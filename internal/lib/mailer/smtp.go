@@ -0,0 +1,101 @@
+package mailer
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"net"
+	"net/smtp"
+	"time"
+)
+
+var ErrInvalidHeader = errors.New("header contains line break")
+
+type SMTPOptions struct {
+	Host     string
+	Port     string
+	Username string
+	Password string
+	From     string
+	Timeout  time.Duration
+}
+
+// SMTP sends messages through SMTP server, STARTTLS is used when server supports it
+type SMTP struct {
+	opts SMTPOptions
+}
+
+func NewSMTP(opts SMTPOptions) *SMTP {
+	if opts.Timeout <= 0 {
+		opts.Timeout = 10 * time.Second
+	}
+
+	return &SMTP{opts: opts}
+}
+
+func (s *SMTP) Send(ctx context.Context, msg Message) error {
+	const op = "mailer.SMTP.Send"
+
+	if !validHeader(msg.To) || !validHeader(msg.Subject) {
+		return fmt.Errorf("%s: %w", op, ErrInvalidHeader)
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, s.opts.Timeout)
+	defer cancel()
+
+	addr := net.JoinHostPort(s.opts.Host, s.opts.Port)
+	conn, err := (&net.Dialer{}).DialContext(ctx, "tcp", addr)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	if deadline, ok := ctx.Deadline(); ok {
+		_ = conn.SetDeadline(deadline)
+	}
+
+	client, err := smtp.NewClient(conn, s.opts.Host)
+	if err != nil {
+		_ = conn.Close()
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	defer client.Close()
+
+	if err = s.send(client, msg); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	return nil
+}
+
+func (s *SMTP) send(client *smtp.Client, msg Message) error {
+	if ok, _ := client.Extension("STARTTLS"); ok {
+		if err := client.StartTLS(&tls.Config{ServerName: s.opts.Host}); err != nil {
+			return err
+		}
+	}
+	if s.opts.Username != "" {
+		if err := client.Auth(smtp.PlainAuth("", s.opts.Username, s.opts.Password, s.opts.Host)); err != nil {
+			return err
+		}
+	}
+
+	if err := client.Mail(s.opts.From); err != nil {
+		return err
+	}
+	if err := client.Rcpt(msg.To); err != nil {
+		return err
+	}
+
+	w, err := client.Data()
+	if err != nil {
+		return err
+	}
+	if _, err = w.Write(format(s.opts.From, msg, time.Now())); err != nil {
+		return err
+	}
+	if err = w.Close(); err != nil {
+		return err
+	}
+
+	return client.Quit()
+}
@@ -0,0 +1,123 @@
+package ratelimit
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// LockoutPolicy locks key after MaxFailures failures made within Window. Every next lock
+// of the same key lasts twice longer than previous one, starting from Base up to Max.
+type LockoutPolicy struct {
+	MaxFailures int
+	Window      time.Duration
+	Base        time.Duration
+	Max         time.Duration
+}
+
+type lockoutEntry struct {
+	failures    int
+	firstFail   time.Time
+	locks       int
+	lockedUntil time.Time
+}
+
+// Lockout is in-memory progressive lockout of keys (e.g. emails) after repeated failures
+type Lockout struct {
+	policy    LockoutPolicy
+	mu        sync.Mutex
+	entries   map[string]*lockoutEntry
+	now       func() time.Time
+	lastSweep time.Time
+}
+
+func NewLockout(policy LockoutPolicy) *Lockout {
+	return &Lockout{
+		policy:  policy,
+		entries: make(map[string]*lockoutEntry),
+		now:     time.Now,
+	}
+}
+
+// Locked returns how long key stays locked, zero if it is not locked
+func (l *Lockout) Locked(_ context.Context, key string) time.Duration {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	e, ok := l.entries[key]
+	if !ok {
+		return 0
+	}
+
+	return l.remaining(e)
+}
+
+// Fail registers failure and returns lock duration if key became locked
+func (l *Lockout) Fail(_ context.Context, key string) time.Duration {
+	if l.policy.MaxFailures <= 0 {
+		return 0
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := l.now()
+	l.sweep(now)
+
+	e, ok := l.entries[key]
+	if !ok {
+		e = &lockoutEntry{}
+		l.entries[key] = e
+	}
+
+	if e.failures == 0 || now.Sub(e.firstFail) > l.policy.Window {
+		e.failures = 0
+		e.firstFail = now
+	}
+	e.failures++
+
+	if e.failures < l.policy.MaxFailures {
+		return 0
+	}
+
+	lock := l.policy.Base << e.locks
+	if lock > l.policy.Max || lock <= 0 {
+		lock = l.policy.Max
+	}
+	e.locks++
+	e.failures = 0
+	e.lockedUntil = now.Add(lock)
+
+	return lock
+}
+
+// Reset forgets all failures and locks of key
+func (l *Lockout) Reset(_ context.Context, key string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	delete(l.entries, key)
+}
+
+func (l *Lockout) remaining(e *lockoutEntry) time.Duration {
+	left := e.lockedUntil.Sub(l.now())
+	if left < 0 {
+		return 0
+	}
+
+	return left
+}
+
+// sweep forgets keys which are quiet long enough: lock history is kept for Max after the last lock ends
+func (l *Lockout) sweep(now time.Time) {
+	if now.Sub(l.lastSweep) < sweepInterval {
+		return
+	}
+	l.lastSweep = now
+
+	for key, e := range l.entries {
+		if now.Sub(e.firstFail) > l.policy.Window && now.Sub(e.lockedUntil) > l.policy.Max {
+			delete(l.entries, key)
+		}
+	}
+}
@@ -0,0 +1,80 @@
+package ratelimit
+
+import (
+	"context"
+	"math"
+	"sync"
+	"time"
+)
+
+const sweepInterval = time.Minute
+
+type bucket struct {
+	tokens  float64
+	last    time.Time
+	expires time.Time
+}
+
+// Memory is in-process token bucket limiter. State is not shared between instances.
+type Memory struct {
+	mu        sync.Mutex
+	buckets   map[string]*bucket
+	now       func() time.Time
+	lastSweep time.Time
+}
+
+func NewMemory() *Memory {
+	return &Memory{
+		buckets: make(map[string]*bucket),
+		now:     time.Now,
+	}
+}
+
+func (m *Memory) Allow(_ context.Context, key string, limit Limit) (Result, error) {
+	if limit.Unlimited() {
+		return Result{Allowed: true}, nil
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	now := m.now()
+	m.sweep(now)
+
+	rate := limit.ratePerSecond()
+	burst := float64(limit.burst())
+
+	b, ok := m.buckets[key]
+	if !ok {
+		b = &bucket{tokens: burst, last: now}
+		m.buckets[key] = b
+	}
+
+	b.tokens = math.Min(burst, b.tokens+now.Sub(b.last).Seconds()*rate)
+	b.last = now
+	// bucket is full again after this moment, so it can be forgotten
+	b.expires = now.Add(time.Duration(burst / rate * float64(time.Second)))
+
+	if b.tokens < 1 {
+		wait := (1 - b.tokens) / rate
+
+		return Result{RetryAfter: time.Duration(wait * float64(time.Second))}, nil
+	}
+
+	b.tokens--
+
+	return Result{Allowed: true, Remaining: int(b.tokens)}, nil
+}
+
+func (m *Memory) sweep(now time.Time) {
+	if now.Sub(m.lastSweep) < sweepInterval {
+		return
+	}
+	m.lastSweep = now
+
+	for key, b := range m.buckets {
+		if now.After(b.expires) {
+			delete(m.buckets, key)
+		}
+	}
+}
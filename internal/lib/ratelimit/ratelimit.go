@@ -0,0 +1,44 @@
+package ratelimit
+
+import (
+	"context"
+	"time"
+)
+
+// Limit allows Requests per Per period with bursts up to Burst requests.
+// Zero Requests means no limit.
+type Limit struct {
+	Requests int
+	Per      time.Duration
+	Burst    int
+}
+
+// Result is an outcome of a single Allow call
+type Result struct {
+	Allowed    bool
+	Remaining  int
+	RetryAfter time.Duration
+}
+
+// Limiter is a token bucket limiter keyed by arbitrary strings (ip, email, route...)
+type Limiter interface {
+	Allow(ctx context.Context, key string, limit Limit) (Result, error)
+}
+
+// Unlimited reports whether limit does not restrict anything
+func (l Limit) Unlimited() bool {
+	return l.Requests <= 0 || l.Per <= 0
+}
+
+// ratePerSecond is the speed tokens are refilled with
+func (l Limit) ratePerSecond() float64 {
+	return float64(l.Requests) / l.Per.Seconds()
+}
+
+func (l Limit) burst() int {
+	if l.Burst <= 0 {
+		return l.Requests
+	}
+
+	return l.Burst
+}
@@ -0,0 +1,174 @@
+package ratelimit
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type clock struct {
+	t time.Time
+}
+
+func (c *clock) now() time.Time {
+	return c.t
+}
+
+func (c *clock) advance(d time.Duration) {
+	c.t = c.t.Add(d)
+}
+
+func TestMemoryAllow(t *testing.T) {
+	c := &clock{t: time.Date(2026, time.October, 19, 12, 0, 0, 0, time.UTC)}
+	m := NewMemory()
+	m.now = c.now
+
+	ctx := context.Background()
+	limit := Limit{Requests: 2, Per: time.Second, Burst: 3}
+
+	for i := 0; i < 3; i++ {
+		res, err := m.Allow(ctx, "ip", limit)
+		require.NoError(t, err)
+		assert.True(t, res.Allowed, "request %d within burst", i)
+	}
+
+	res, err := m.Allow(ctx, "ip", limit)
+	require.NoError(t, err)
+	assert.False(t, res.Allowed)
+	assert.Equal(t, 500*time.Millisecond, res.RetryAfter)
+
+	// other keys have own buckets
+	res, _ = m.Allow(ctx, "other", limit)
+	assert.True(t, res.Allowed)
+
+	c.advance(500 * time.Millisecond)
+	res, _ = m.Allow(ctx, "ip", limit)
+	assert.True(t, res.Allowed)
+
+	res, _ = m.Allow(ctx, "ip", Limit{})
+	assert.True(t, res.Allowed, "zero limit is unlimited")
+}
+
+func TestLockout(t *testing.T) {
+	c := &clock{t: time.Date(2026, time.October, 19, 12, 0, 0, 0, time.UTC)}
+	l := NewLockout(LockoutPolicy{MaxFailures: 3, Window: time.Minute, Base: time.Minute, Max: 3 * time.Minute})
+	l.now = c.now
+
+	ctx := context.Background()
+	const key = "user@example.com"
+
+	assert.Zero(t, l.Fail(ctx, key))
+	assert.Zero(t, l.Fail(ctx, key))
+	assert.Equal(t, time.Minute, l.Fail(ctx, key))
+	assert.Equal(t, time.Minute, l.Locked(ctx, key))
+
+	c.advance(time.Minute)
+	assert.Zero(t, l.Locked(ctx, key))
+
+	// next lock is twice longer, then capped by Max
+	l.Fail(ctx, key)
+	l.Fail(ctx, key)
+	assert.Equal(t, 2*time.Minute, l.Fail(ctx, key))
+	c.advance(2 * time.Minute)
+	l.Fail(ctx, key)
+	l.Fail(ctx, key)
+	assert.Equal(t, 3*time.Minute, l.Fail(ctx, key))
+
+	l.Reset(ctx, key)
+	assert.Zero(t, l.Locked(ctx, key))
+
+	// failures outside of window are not counted together
+	l.Fail(ctx, key)
+	l.Fail(ctx, key)
+	c.advance(2 * time.Minute)
+	assert.Zero(t, l.Fail(ctx, key))
+}
+
+type evalerFunc func(ctx context.Context, script string, keys []string, args ...string) (any, error)
+
+func (f evalerFunc) Eval(ctx context.Context, script string, keys []string, args ...string) (any, error) {
+	return f(ctx, script, keys, args...)
+}
+
+func TestRedisAllow(t *testing.T) {
+	var gotKeys, gotArgs []string
+	r := NewRedis(evalerFunc(func(_ context.Context, _ string, keys []string, args ...string) (any, error) {
+		gotKeys, gotArgs = keys, args
+		return []any{int64(0), int64(1500), int64(0)}, nil
+	}), "cp:")
+
+	res, err := r.Allow(context.Background(), "login_ip:10.0.0.1", Limit{Requests: 10, Per: 10 * time.Second})
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{"cp:login_ip:10.0.0.1"}, gotKeys)
+	assert.Equal(t, []string{"0.001", "10"}, gotArgs)
+	assert.Equal(t, Result{Allowed: false, RetryAfter: 1500 * time.Millisecond}, res)
+}
+
+func TestRedisLockout(t *testing.T) {
+	ctx := context.Background()
+	policy := LockoutPolicy{MaxFailures: 3, Window: time.Minute, Base: time.Minute, Max: time.Hour}
+
+	var gotKeys, gotArgs []string
+	l := NewRedisLockout(evalerFunc(func(_ context.Context, _ string, keys []string, args ...string) (any, error) {
+		gotKeys, gotArgs = keys, args
+		return int64(60000), nil
+	}), "cp:lockout:", policy, slog.New(slog.NewTextHandler(io.Discard, nil)))
+
+	assert.Equal(t, time.Minute, l.Fail(ctx, "user@example.com"))
+	assert.Equal(t, []string{"cp:lockout:user@example.com"}, gotKeys)
+	assert.Equal(t, []string{"3", "60000", "60000", "3600000"}, gotArgs)
+	assert.Equal(t, time.Minute, l.Locked(ctx, "user@example.com"))
+
+	// broken backend does not lock anyone out
+	broken := NewRedisLockout(evalerFunc(func(context.Context, string, []string, ...string) (any, error) {
+		return nil, errors.New("connection refused")
+	}), "cp:lockout:", policy, slog.New(slog.NewTextHandler(io.Discard, nil)))
+	assert.Zero(t, broken.Fail(ctx, "user@example.com"))
+	assert.Zero(t, broken.Locked(ctx, "user@example.com"))
+	broken.Reset(ctx, "user@example.com")
+}
+
+// TestRESPClientEval runs client against local stand-in speaking RESP
+func TestRESPClientEval(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer ln.Close()
+
+	commands := make(chan []any, 2)
+	go func() {
+		conn, err := ln.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+
+		rd := bufio.NewReader(conn)
+		for _, reply := range []string{"+OK\r\n", "*3\r\n:1\r\n:0\r\n$1\r\n7\r\n"} {
+			cmd, err := readReply(rd)
+			if err != nil {
+				return
+			}
+			commands <- cmd.([]any)
+			_, _ = conn.Write([]byte(reply))
+		}
+	}()
+
+	c := NewRESPClient(ln.Addr().String(), "secret", time.Second)
+	defer c.Close()
+
+	reply, err := c.Eval(context.Background(), "return 1", []string{"k"}, "a")
+	require.NoError(t, err)
+
+	assert.Equal(t, []any{"AUTH", "secret"}, <-commands)
+	assert.Equal(t, []any{"EVAL", "return 1", "1", "k", "a"}, <-commands)
+	assert.Equal(t, []any{int64(1), int64(0), "7"}, reply)
+}
@@ -0,0 +1,87 @@
+package ratelimit
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+)
+
+// Evaler runs Lua scripts on a Redis compatible server. RESPClient implements it,
+// any other client (or local stand-in) can be plugged instead.
+type Evaler interface {
+	Eval(ctx context.Context, script string, keys []string, args ...string) (any, error)
+}
+
+// tokenBucketScript keeps bucket as hash {tokens, ts}. Server time is used so that
+// every application instance shares the same clock. Returns {allowed, retry after ms, remaining}.
+const tokenBucketScript = `
+local rate = tonumber(ARGV[1])
+local burst = tonumber(ARGV[2])
+local t = redis.call('TIME')
+local now = tonumber(t[1]) * 1000 + math.floor(tonumber(t[2]) / 1000)
+local data = redis.call('HMGET', KEYS[1], 'tokens', 'ts')
+local tokens = tonumber(data[1]) or burst
+local ts = tonumber(data[2]) or now
+tokens = math.min(burst, tokens + math.max(0, now - ts) * rate)
+local allowed = 0
+local wait = 0
+if tokens >= 1 then
+  tokens = tokens - 1
+  allowed = 1
+else
+  wait = math.ceil((1 - tokens) / rate)
+end
+redis.call('HSET', KEYS[1], 'tokens', tostring(tokens), 'ts', tostring(now))
+redis.call('PEXPIRE', KEYS[1], math.ceil(burst / rate))
+return {allowed, wait, math.floor(tokens)}
+`
+
+// Redis is token bucket limiter sharing state between instances through Redis compatible server
+type Redis struct {
+	client Evaler
+	prefix string
+}
+
+func NewRedis(client Evaler, prefix string) *Redis {
+	return &Redis{client: client, prefix: prefix}
+}
+
+func (r *Redis) Allow(ctx context.Context, key string, limit Limit) (Result, error) {
+	const op = "ratelimit.Redis.Allow"
+
+	if limit.Unlimited() {
+		return Result{Allowed: true}, nil
+	}
+
+	ratePerMs := limit.ratePerSecond() / 1000
+	reply, err := r.client.Eval(
+		ctx,
+		tokenBucketScript,
+		[]string{r.prefix + key},
+		strconv.FormatFloat(ratePerMs, 'f', -1, 64),
+		strconv.Itoa(limit.burst()),
+	)
+	if err != nil {
+		return Result{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	values, ok := reply.([]any)
+	if !ok || len(values) != 3 {
+		return Result{}, fmt.Errorf("%s: unexpected reply %v", op, reply)
+	}
+	nums := make([]int64, len(values))
+	for i, v := range values {
+		n, ok := v.(int64)
+		if !ok {
+			return Result{}, fmt.Errorf("%s: unexpected reply %v", op, reply)
+		}
+		nums[i] = n
+	}
+
+	return Result{
+		Allowed:    nums[0] == 1,
+		RetryAfter: time.Duration(nums[1]) * time.Millisecond,
+		Remaining:  int(nums[2]),
+	}, nil
+}
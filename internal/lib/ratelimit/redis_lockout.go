@@ -0,0 +1,125 @@
+package ratelimit
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strconv"
+	"time"
+)
+
+// lockoutFailScript keeps lockout state as hash {failures, first, locks, until}, times are server ms.
+// It counts failure the same way Lockout.Fail does and returns lock duration in ms, zero if key is not locked.
+// State is forgotten once key is quiet for Window and Max after the last lock ends.
+const lockoutFailScript = `
+local maxFailures = tonumber(ARGV[1])
+local window = tonumber(ARGV[2])
+local base = tonumber(ARGV[3])
+local max = tonumber(ARGV[4])
+local t = redis.call('TIME')
+local now = tonumber(t[1]) * 1000 + math.floor(tonumber(t[2]) / 1000)
+local data = redis.call('HMGET', KEYS[1], 'failures', 'first', 'locks', 'until')
+local failures = tonumber(data[1]) or 0
+local first = tonumber(data[2]) or now
+local locks = tonumber(data[3]) or 0
+local lockedUntil = tonumber(data[4]) or 0
+if failures == 0 or now - first > window then
+  failures = 0
+  first = now
+end
+failures = failures + 1
+local lock = 0
+if failures >= maxFailures then
+  lock = math.min(max, base * 2 ^ locks)
+  locks = locks + 1
+  failures = 0
+  lockedUntil = now + lock
+end
+redis.call('HSET', KEYS[1], 'failures', failures, 'first', first, 'locks', locks, 'until', lockedUntil)
+redis.call('PEXPIRE', KEYS[1], math.max(window, lockedUntil - now + max))
+return lock
+`
+
+// lockoutLockedScript returns how long key stays locked in ms
+const lockoutLockedScript = `
+local t = redis.call('TIME')
+local now = tonumber(t[1]) * 1000 + math.floor(tonumber(t[2]) / 1000)
+local lockedUntil = tonumber(redis.call('HGET', KEYS[1], 'until')) or 0
+return math.max(0, lockedUntil - now)
+`
+
+const lockoutResetScript = `return redis.call('DEL', KEYS[1])`
+
+// RedisLockout is progressive lockout sharing state between instances through Redis compatible server.
+// Server failures are logged and keys are treated as not locked, so broken backend does not block logins.
+type RedisLockout struct {
+	client Evaler
+	prefix string
+	policy LockoutPolicy
+	log    *slog.Logger
+}
+
+func NewRedisLockout(client Evaler, prefix string, policy LockoutPolicy, log *slog.Logger) *RedisLockout {
+	return &RedisLockout{client: client, prefix: prefix, policy: policy, log: log}
+}
+
+// Locked returns how long key stays locked, zero if it is not locked
+func (l *RedisLockout) Locked(ctx context.Context, key string) time.Duration {
+	const op = "ratelimit.RedisLockout.Locked"
+
+	lock, err := l.eval(ctx, lockoutLockedScript, key)
+	if err != nil {
+		l.log.Error("lockout backend failed", slog.String("op", op), slog.String("error", err.Error()))
+
+		return 0
+	}
+
+	return lock
+}
+
+// Fail registers failure and returns lock duration if key became locked
+func (l *RedisLockout) Fail(ctx context.Context, key string) time.Duration {
+	const op = "ratelimit.RedisLockout.Fail"
+
+	if l.policy.MaxFailures <= 0 {
+		return 0
+	}
+
+	lock, err := l.eval(ctx, lockoutFailScript, key,
+		strconv.Itoa(l.policy.MaxFailures),
+		strconv.FormatInt(l.policy.Window.Milliseconds(), 10),
+		strconv.FormatInt(l.policy.Base.Milliseconds(), 10),
+		strconv.FormatInt(l.policy.Max.Milliseconds(), 10),
+	)
+	if err != nil {
+		l.log.Error("lockout backend failed", slog.String("op", op), slog.String("error", err.Error()))
+
+		return 0
+	}
+
+	return lock
+}
+
+// Reset forgets all failures and locks of key
+func (l *RedisLockout) Reset(ctx context.Context, key string) {
+	const op = "ratelimit.RedisLockout.Reset"
+
+	if _, err := l.eval(ctx, lockoutResetScript, key); err != nil {
+		l.log.Error("lockout backend failed", slog.String("op", op), slog.String("error", err.Error()))
+	}
+}
+
+// eval runs script on key and returns its integer reply as ms duration
+func (l *RedisLockout) eval(ctx context.Context, script, key string, args ...string) (time.Duration, error) {
+	reply, err := l.client.Eval(ctx, script, []string{l.prefix + key}, args...)
+	if err != nil {
+		return 0, err
+	}
+
+	ms, ok := reply.(int64)
+	if !ok {
+		return 0, fmt.Errorf("unexpected reply %v", reply)
+	}
+
+	return time.Duration(ms) * time.Millisecond, nil
+}
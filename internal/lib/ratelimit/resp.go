@@ -0,0 +1,177 @@
+package ratelimit
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// RESPClient is minimal client of Redis serialization protocol supporting EVAL only.
+// It keeps single connection and reconnects after any failure.
+type RESPClient struct {
+	addr     string
+	password string
+	timeout  time.Duration
+
+	mu   sync.Mutex
+	conn net.Conn
+	rd   *bufio.Reader
+}
+
+func NewRESPClient(addr, password string, timeout time.Duration) *RESPClient {
+	return &RESPClient{addr: addr, password: password, timeout: timeout}
+}
+
+func (c *RESPClient) Eval(ctx context.Context, script string, keys []string, args ...string) (any, error) {
+	cmd := make([]string, 0, 3+len(keys)+len(args))
+	cmd = append(cmd, "EVAL", script, strconv.Itoa(len(keys)))
+	cmd = append(cmd, keys...)
+	cmd = append(cmd, args...)
+
+	return c.do(ctx, cmd...)
+}
+
+func (c *RESPClient) Close() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.conn == nil {
+		return nil
+	}
+	err := c.conn.Close()
+	c.conn = nil
+
+	return err
+}
+
+func (c *RESPClient) do(ctx context.Context, cmd ...string) (any, error) {
+	const op = "ratelimit.RESPClient.do"
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if err := c.connect(ctx); err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	reply, err := c.roundTrip(ctx, cmd)
+	if err != nil {
+		var redisErr redisError
+		if !errors.As(err, &redisErr) {
+			// connection state is unknown after i/o failure
+			_ = c.conn.Close()
+			c.conn = nil
+		}
+
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return reply, nil
+}
+
+func (c *RESPClient) connect(ctx context.Context) error {
+	if c.conn != nil {
+		return nil
+	}
+
+	d := net.Dialer{Timeout: c.timeout}
+	conn, err := d.DialContext(ctx, "tcp", c.addr)
+	if err != nil {
+		return err
+	}
+	c.conn = conn
+	c.rd = bufio.NewReader(conn)
+
+	if c.password != "" {
+		if _, err = c.roundTrip(ctx, []string{"AUTH", c.password}); err != nil {
+			_ = conn.Close()
+			c.conn = nil
+
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (c *RESPClient) roundTrip(ctx context.Context, cmd []string) (any, error) {
+	deadline := time.Now().Add(c.timeout)
+	if d, ok := ctx.Deadline(); ok && d.Before(deadline) {
+		deadline = d
+	}
+	if err := c.conn.SetDeadline(deadline); err != nil {
+		return nil, err
+	}
+
+	w := bufio.NewWriter(c.conn)
+	_, _ = fmt.Fprintf(w, "*%d\r\n", len(cmd))
+	for _, arg := range cmd {
+		_, _ = fmt.Fprintf(w, "$%d\r\n%s\r\n", len(arg), arg)
+	}
+	if err := w.Flush(); err != nil {
+		return nil, err
+	}
+
+	return readReply(c.rd)
+}
+
+type redisError string
+
+func (e redisError) Error() string {
+	return "redis: " + string(e)
+}
+
+// readReply parses single RESP2 value. Integers are returned as int64, bulk strings as string, arrays as []any.
+func readReply(rd *bufio.Reader) (any, error) {
+	line, err := rd.ReadString('\n')
+	if err != nil {
+		return nil, err
+	}
+	if len(line) < 3 || line[len(line)-2] != '\r' {
+		return nil, fmt.Errorf("malformed reply %q", line)
+	}
+	payload := line[1 : len(line)-2]
+
+	switch line[0] {
+	case '+':
+		return payload, nil
+	case '-':
+		return nil, redisError(payload)
+	case ':':
+		return strconv.ParseInt(payload, 10, 64)
+	case '$':
+		n, err := strconv.Atoi(payload)
+		if err != nil || n < 0 {
+			return nil, err
+		}
+		buf := make([]byte, n+2)
+		if _, err = io.ReadFull(rd, buf); err != nil {
+			return nil, err
+		}
+
+		return string(buf[:n]), nil
+	case '*':
+		n, err := strconv.Atoi(payload)
+		if err != nil || n < 0 {
+			return nil, err
+		}
+		values := make([]any, 0, n)
+		for i := 0; i < n; i++ {
+			v, err := readReply(rd)
+			if err != nil {
+				return nil, err
+			}
+			values = append(values, v)
+		}
+
+		return values, nil
+	default:
+		return nil, fmt.Errorf("unknown reply type %q", line[0])
+	}
+}
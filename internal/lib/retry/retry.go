@@ -0,0 +1,61 @@
+package retry
+
+import (
+	"context"
+	"math/rand/v2"
+	"time"
+)
+
+// Policy describes how many times an operation is attempted and how long to wait between attempts.
+// Delays grow exponentially from BaseDelay up to MaxDelay, the actual wait is picked randomly
+// between zero and that value (full jitter), so that clients do not retry in lockstep.
+type Policy struct {
+	Attempts  int
+	BaseDelay time.Duration
+	MaxDelay  time.Duration
+}
+
+// Once is a policy without retries
+var Once = Policy{Attempts: 1}
+
+// Do calls fn until it succeeds, returns error which is not retryable, attempts are over or ctx is done.
+// The last error of fn is returned.
+func Do(ctx context.Context, p Policy, retryable func(error) bool, fn func() error) error {
+	attempts := p.Attempts
+	if attempts < 1 {
+		attempts = 1
+	}
+
+	var err error
+	for i := 0; i < attempts; i++ {
+		if i > 0 {
+			timer := time.NewTimer(p.backoff(i))
+			select {
+			case <-ctx.Done():
+				timer.Stop()
+				return err
+			case <-timer.C:
+			}
+		}
+
+		if err = fn(); err == nil || !retryable(err) {
+			return err
+		}
+	}
+
+	return err
+}
+
+// backoff returns jittered delay before retry number n (starting from 1)
+func (p Policy) backoff(n int) time.Duration {
+	if p.BaseDelay <= 0 {
+		return 0
+	}
+
+	d := p.BaseDelay << (n - 1)
+	if d <= 0 || (p.MaxDelay > 0 && d > p.MaxDelay) {
+		d = p.MaxDelay
+	}
+
+	return time.Duration(rand.Int64N(int64(d) + 1))
+}
@@ -0,0 +1,69 @@
+package retry
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+var errTemporary = errors.New("temporary")
+
+func isTemporary(err error) bool {
+	return errors.Is(err, errTemporary)
+}
+
+func TestDo(t *testing.T) {
+	p := Policy{Attempts: 3, BaseDelay: time.Millisecond, MaxDelay: 2 * time.Millisecond}
+
+	tests := []struct {
+		name          string
+		errs          []error
+		expectedCalls int
+		expectedErr   error
+	}{
+		{name: "Success", errs: []error{nil}, expectedCalls: 1},
+		{name: "Success after retry", errs: []error{errTemporary, nil}, expectedCalls: 2},
+		{name: "Attempts are over", errs: []error{errTemporary, errTemporary, errTemporary}, expectedCalls: 3, expectedErr: errTemporary},
+		{name: "Permanent error", errs: []error{errors.ErrUnsupported}, expectedCalls: 1, expectedErr: errors.ErrUnsupported},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			calls := 0
+			err := Do(context.Background(), p, isTemporary, func() error {
+				calls++
+				return tt.errs[calls-1]
+			})
+
+			assert.Equal(t, tt.expectedCalls, calls)
+			assert.Equal(t, tt.expectedErr, err)
+		})
+	}
+}
+
+func TestDoStopsOnContextCancel(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	calls := 0
+	err := Do(ctx, Policy{Attempts: 5, BaseDelay: time.Hour}, isTemporary, func() error {
+		calls++
+		return errTemporary
+	})
+
+	assert.Equal(t, 1, calls)
+	assert.ErrorIs(t, err, errTemporary)
+}
+
+func TestBackoffIsCapped(t *testing.T) {
+	p := Policy{BaseDelay: 10 * time.Millisecond, MaxDelay: 50 * time.Millisecond}
+
+	for n := 1; n < 70; n++ {
+		d := p.backoff(n)
+		assert.GreaterOrEqual(t, d, time.Duration(0))
+		assert.LessOrEqual(t, d, p.MaxDelay)
+	}
+}
@@ -0,0 +1,138 @@
+package units
+
+import (
+	"errors"
+	"math"
+	"net/http"
+	"strings"
+)
+
+// System is a unit system values are entered and shown in. Values are always stored in SI units
+// (centimetres and kilograms), conversion happens at the API boundary.
+type System string
+
+const (
+	Metric   System = "metric"
+	Imperial System = "imperial"
+)
+
+const (
+	// Header overrides unit system of user for a single request, so does Param query parameter
+	Header = "Accept-Units"
+	Param  = "units"
+)
+
+// Stored units of values which have an imperial counterpart
+const (
+	Centimetre = "cm"
+	Kilogram   = "kg"
+	Percent    = "%"
+)
+
+var ErrUnknownSystem = errors.New("must be metric or imperial")
+
+// imperial units by stored units and factors converting them to stored units
+var imperial = map[string]struct {
+	unit   string
+	factor float64
+}{
+	Centimetre: {unit: "in", factor: 2.54},
+	Kilogram:   {unit: "lb", factor: 0.45359237},
+}
+
+// Parse returns unit system by name, empty name is Metric
+func Parse(s string) (System, error) {
+	switch System(strings.ToLower(strings.TrimSpace(s))) {
+	case "", Metric:
+		return Metric, nil
+	case Imperial:
+		return Imperial, nil
+	default:
+		return "", ErrUnknownSystem
+	}
+}
+
+// FromRequest returns unit system requested with Param query parameter or Header, query parameter
+// takes precedence. Empty system is returned when request does not override preference of user.
+func FromRequest(r *http.Request) (System, error) {
+	s := r.URL.Query().Get(Param)
+	if s == "" {
+		s = r.Header.Get(Header)
+	}
+	if s == "" {
+		return "", nil
+	}
+
+	return Parse(s)
+}
+
+// Converter converts named values between units they are stored in and a unit system.
+// Values with unknown names or units without counterpart in the system are left as they are.
+type Converter struct {
+	system System
+	stored map[string]string
+}
+
+// NewConverter returns converter of values whose stored units are given by their names
+func NewConverter(system System, stored map[string]string) Converter {
+	return Converter{system: system, stored: stored}
+}
+
+// System returns unit system values are converted to
+func (c Converter) System() System {
+	return c.system
+}
+
+// Unit returns label of unit the named value is shown in
+func (c Converter) Unit(name string) string {
+	unit := c.stored[name]
+	if c.system == Imperial {
+		if u, ok := imperial[unit]; ok {
+			return u.unit
+		}
+	}
+
+	return unit
+}
+
+// Units returns labels of units of the named values
+func (c Converter) Units(names ...string) map[string]string {
+	res := make(map[string]string, len(names))
+	for _, name := range names {
+		if unit := c.Unit(name); unit != "" {
+			res[name] = unit
+		}
+	}
+
+	return res
+}
+
+// FromStored converts stored value to the system. Converted values are rounded to hundredths, so
+// that values entered in the system are shown as they were entered.
+func (c Converter) FromStored(name string, v float64) float64 {
+	factor, ok := c.factor(name)
+	if !ok {
+		return v
+	}
+
+	return math.Round(v/factor*100) / 100
+}
+
+// ToStored converts value entered in the system to its stored unit
+func (c Converter) ToStored(name string, v float64) float64 {
+	factor, ok := c.factor(name)
+	if !ok {
+		return v
+	}
+
+	return v * factor
+}
+
+func (c Converter) factor(name string) (float64, bool) {
+	if c.system != Imperial {
+		return 0, false
+	}
+	u, ok := imperial[c.stored[name]]
+
+	return u.factor, ok
+}
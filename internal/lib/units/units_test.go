@@ -0,0 +1,57 @@
+package units
+
+import (
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFromRequest(t *testing.T) {
+	tests := []struct {
+		name     string
+		target   string
+		header   string
+		expected System
+		err      error
+	}{
+		{name: "No override", target: "/"},
+		{name: "Header", target: "/", header: "Imperial", expected: Imperial},
+		{name: "Query parameter takes precedence", target: "/?units=metric", header: "imperial", expected: Metric},
+		{name: "Unknown system", target: "/?units=stones", err: ErrUnknownSystem},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest("GET", tt.target, nil)
+			if tt.header != "" {
+				r.Header.Set(Header, tt.header)
+			}
+
+			system, err := FromRequest(r)
+			assert.ErrorIs(t, err, tt.err)
+			assert.Equal(t, tt.expected, system)
+		})
+	}
+}
+
+func TestConverter(t *testing.T) {
+	stored := map[string]string{"height": Centimetre, "weight": Kilogram, "body_fat": Percent}
+
+	imperial := NewConverter(Imperial, stored)
+	assert.Equal(t, map[string]string{"height": "in", "weight": "lb", "body_fat": "%"}, imperial.Units("height", "weight", "body_fat", "unknown"))
+	assert.Equal(t, 70.87, imperial.FromStored("height", 180))
+	assert.Equal(t, 20.0, imperial.FromStored("body_fat", 20))
+	assert.Equal(t, 20.0, imperial.ToStored("body_fat", 20))
+
+	// pounds entered by user are shown back as they were entered
+	kg := imperial.ToStored("weight", 176.4)
+	require.InDelta(t, 80.01, kg, 0.01)
+	assert.Equal(t, 176.4, imperial.FromStored("weight", kg))
+
+	metric := NewConverter(Metric, stored)
+	assert.Equal(t, "kg", metric.Unit("weight"))
+	assert.Equal(t, 80.123, metric.FromStored("weight", 80.123), "values are not rounded without conversion")
+	assert.Equal(t, 80.123, metric.ToStored("weight", 80.123))
+}
@@ -2,10 +2,17 @@ package middleware
 
 import (
 	"context"
+	"errors"
 	"net/http"
 	"strings"
 
+	"ChadProgress/internal/auth_client"
+	"ChadProgress/internal/lib/api/request"
+	"ChadProgress/internal/lib/api/response"
+	"ChadProgress/internal/lib/apperr"
 	"ChadProgress/internal/models"
+	service "ChadProgress/internal/services"
+	"ChadProgress/storage"
 )
 
 // TokenValidator interface for auth service structures
@@ -13,23 +20,49 @@ type TokenValidator interface {
 	ValidateToken(ctx context.Context, token string) (string, error)
 }
 
-// AuthMiddleware проверяет JWT токен и добавляет email пользователя в контекст запроса.
-func AuthMiddleware(tokenValidator TokenValidator) func(http.Handler) http.Handler {
+// SessionTracker checks that session of the token is not revoked and records its activity
+type SessionTracker interface {
+	TouchSession(ctx context.Context, login, token string, client models.ClientInfo) (string, error)
+}
+
+// AuthMiddleware проверяет JWT токен и добавляет email пользователя и id сессии в контекст запроса.
+// Id пользователя добавляется, когда у него есть профиль: по нему изменения записываются в аудит.
+func AuthMiddleware(tokenValidator TokenValidator, sessions SessionTracker, users UserGetter) func(http.Handler) http.Handler {
 	return func(next http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 			token := extractTokenFromHeader(r)
 			if token == "" {
-				http.Error(w, "Missing or invalid token", http.StatusUnauthorized)
+				response.RenderError(w, r, apperr.ErrUnauthorized.WithMessage("missing or invalid token"))
 				return
 			}
 
-			userEmail, err := tokenValidator.ValidateToken(context.Background(), token)
+			userEmail, err := tokenValidator.ValidateToken(r.Context(), token)
+			if errors.Is(err, auth_client.ErrClientUnavailable) {
+				response.RenderError(w, r, service.ErrAuthUnavailable)
+				return
+			}
 			if err != nil || userEmail == "" {
-				http.Error(w, "Invalid token", http.StatusUnauthorized)
+				response.RenderError(w, r, apperr.ErrUnauthorized.WithMessage("invalid token"))
+				return
+			}
+
+			sessionID, err := sessions.TouchSession(r.Context(), userEmail, token, request.ClientInfo(r))
+			if err != nil {
+				response.RenderError(w, r, err)
 				return
 			}
 
 			ctx := context.WithValue(r.Context(), models.ContextUserKey, userEmail)
+			ctx = context.WithValue(ctx, models.ContextSessionKey, sessionID)
+
+			user, err := users.GetUserByEmail(userEmail)
+			if err != nil && !errors.Is(err, storage.ErrRecordNotFound) {
+				response.RenderError(w, r, err)
+				return
+			}
+			if user != nil {
+				ctx = context.WithValue(ctx, models.ContextUserIDKey, user.ID)
+			}
 			next.ServeHTTP(w, r.WithContext(ctx))
 		})
 	}
@@ -0,0 +1,61 @@
+package middleware
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"ChadProgress/internal/models"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// staticTokens accepts token "valid" of login
+type staticTokens string
+
+func (s staticTokens) ValidateToken(ctx context.Context, token string) (string, error) {
+	if token != "valid" {
+		return "", assert.AnError
+	}
+
+	return string(s), nil
+}
+
+type openSessions struct{}
+
+func (openSessions) TouchSession(ctx context.Context, login, token string, client models.ClientInfo) (string, error) {
+	return "session", nil
+}
+
+func TestAuthMiddlewareAuditMeta(t *testing.T) {
+	users := usersMap{"client@example.com": {ID: 7, Email: "client@example.com"}}
+
+	tests := []struct {
+		name            string
+		login           string
+		expectedActorID uint
+	}{
+		{name: "User with profile", login: "client@example.com", expectedActorID: 7},
+		{name: "User without profile", login: "new@example.com"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var meta models.AuditMeta
+			handler := AuthMiddleware(staticTokens(tt.login), openSessions{}, users)(
+				http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+					meta = models.AuditMetaFromContext(r.Context())
+				}))
+
+			req := httptest.NewRequest(http.MethodGet, "/user/clients/profile", nil)
+			req.Header.Set("Authorization", "Bearer valid")
+			rr := httptest.NewRecorder()
+			handler.ServeHTTP(rr, req)
+
+			assert.Equal(t, http.StatusOK, rr.Code)
+			assert.Equal(t, tt.login, meta.Actor)
+			assert.Equal(t, tt.expectedActorID, meta.ActorID)
+		})
+	}
+}
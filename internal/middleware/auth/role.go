@@ -0,0 +1,54 @@
+package middleware
+
+import (
+	"errors"
+	"net/http"
+	"slices"
+	"strings"
+
+	"ChadProgress/internal/lib/api/response"
+	"ChadProgress/internal/lib/apperr"
+	"ChadProgress/internal/models"
+	service "ChadProgress/internal/services"
+	"ChadProgress/storage"
+)
+
+// UserGetter finds users by email
+type UserGetter interface {
+	GetUserByEmail(email string) (*models.User, error)
+}
+
+// RequireRole lets through active users with one of roles. It runs after AuthMiddleware, role is
+// read from storage on every request, so that revoking role takes effect immediately.
+func RequireRole(users UserGetter, roles ...string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			userEmail, _ := r.Context().Value(models.ContextUserKey).(string)
+			if userEmail == "" {
+				response.RenderError(w, r, apperr.ErrUnauthorized)
+				return
+			}
+
+			user, err := users.GetUserByEmail(userEmail)
+			if errors.Is(err, storage.ErrRecordNotFound) {
+				response.RenderError(w, r, apperr.ErrUnauthorized)
+				return
+			}
+			if err != nil {
+				response.RenderError(w, r, err)
+				return
+			}
+
+			if user.Status != models.UserStatusActive {
+				response.RenderError(w, r, service.ErrAccountDisabled)
+				return
+			}
+			if !slices.Contains(roles, user.Role) {
+				response.RenderError(w, r, apperr.ErrForbidden.WithMessage("endpoint requires role "+strings.Join(roles, " or ")))
+				return
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
@@ -0,0 +1,60 @@
+package middleware
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"ChadProgress/internal/models"
+	"ChadProgress/storage"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type usersMap map[string]*models.User
+
+func (u usersMap) GetUserByEmail(email string) (*models.User, error) {
+	if user, ok := u[email]; ok {
+		return user, nil
+	}
+
+	return nil, storage.ErrRecordNotFound
+}
+
+func TestRequireRole(t *testing.T) {
+	users := usersMap{
+		"admin@example.com":    {Email: "admin@example.com", Role: models.RoleAdmin, Status: models.UserStatusActive},
+		"disabled@example.com": {Email: "disabled@example.com", Role: models.RoleAdmin, Status: models.UserStatusDisabled},
+		"client@example.com":   {Email: "client@example.com", Role: models.RoleClient, Status: models.UserStatusActive},
+	}
+
+	tests := []struct {
+		name         string
+		email        string
+		expectedCode int
+	}{
+		{name: "Admin", email: "admin@example.com", expectedCode: http.StatusOK},
+		{name: "Other role", email: "client@example.com", expectedCode: http.StatusForbidden},
+		{name: "Disabled admin", email: "disabled@example.com", expectedCode: http.StatusForbidden},
+		{name: "Unknown user", email: "ghost@example.com", expectedCode: http.StatusUnauthorized},
+		{name: "Not authenticated", expectedCode: http.StatusUnauthorized},
+	}
+
+	handler := RequireRole(users, models.RoleAdmin)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/admin/users", nil)
+			if tt.email != "" {
+				req = req.WithContext(context.WithValue(req.Context(), models.ContextUserKey, tt.email))
+			}
+
+			rr := httptest.NewRecorder()
+			handler.ServeHTTP(rr, req)
+
+			assert.Equal(t, tt.expectedCode, rr.Code)
+		})
+	}
+}
@@ -0,0 +1,40 @@
+package deprecation
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Policy describes deprecation of a group of endpoints
+type Policy struct {
+	// Since is the moment endpoints became deprecated
+	Since time.Time
+	// Sunset is the moment endpoints stop working
+	Sunset time.Time
+	// From and To are path prefixes used to build successor link: From is replaced by To in request path
+	From string
+	To   string
+}
+
+// Deprecated marks responses with Deprecation (RFC 9745), Sunset (RFC 8594) and successor-version Link headers.
+// When several policies apply to the same endpoint, the earliest sunset wins and all successors are linked.
+func Deprecated(p Policy) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			h := w.Header()
+			successor := p.To + strings.TrimPrefix(r.URL.Path, p.From)
+
+			if h.Get("Deprecation") == "" {
+				h.Set("Deprecation", "@"+strconv.FormatInt(p.Since.Unix(), 10))
+			}
+			if sunset, err := http.ParseTime(h.Get("Sunset")); err != nil || p.Sunset.Before(sunset) {
+				h.Set("Sunset", p.Sunset.UTC().Format(http.TimeFormat))
+			}
+			h.Add("Link", "<"+successor+`>; rel="successor-version"`)
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
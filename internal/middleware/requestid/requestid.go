@@ -0,0 +1,40 @@
+package requestid
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"net/http"
+	"regexp"
+
+	"ChadProgress/internal/lib/api/request"
+	"ChadProgress/internal/models"
+)
+
+// Header carries request id. Ids sent by a proxy are kept, so that logs of both can be matched.
+const Header = "X-Request-ID"
+
+// validID limits ids accepted from clients, they end up in logs and audit events
+var validID = regexp.MustCompile(`^[A-Za-z0-9._-]{1,64}$`)
+
+// RequestID puts request id and client address into request context and echoes the id in response header
+func RequestID(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		id := r.Header.Get(Header)
+		if !validID.MatchString(id) {
+			id = newID()
+		}
+		w.Header().Set(Header, id)
+
+		ctx := context.WithValue(r.Context(), models.ContextRequestIDKey, id)
+		ctx = context.WithValue(ctx, models.ContextIPKey, request.IP(r))
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
+
+func newID() string {
+	b := make([]byte, 16)
+	_, _ = rand.Read(b)
+
+	return hex.EncodeToString(b)
+}
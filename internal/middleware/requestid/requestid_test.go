@@ -0,0 +1,50 @@
+package requestid
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"ChadProgress/internal/models"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRequestID(t *testing.T) {
+	tests := []struct {
+		name      string
+		header    string
+		keepsID   bool
+		expectLen int
+	}{
+		{name: "Generated", expectLen: 32},
+		{name: "From proxy", header: "req-42.a_b", keepsID: true},
+		{name: "Invalid id is replaced", header: "bad id\n", expectLen: 32},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var meta models.AuditMeta
+			handler := RequestID(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				meta = models.AuditMetaFromContext(r.Context())
+			}))
+
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			req.RemoteAddr = "10.0.0.1:5000"
+			if tt.header != "" {
+				req.Header.Set(Header, tt.header)
+			}
+			rr := httptest.NewRecorder()
+			handler.ServeHTTP(rr, req)
+
+			assert.Equal(t, meta.RequestID, rr.Header().Get(Header))
+			assert.Equal(t, "10.0.0.1", meta.IP)
+			assert.Equal(t, models.AuditActorSystem, meta.Actor)
+			if tt.keepsID {
+				assert.Equal(t, tt.header, meta.RequestID)
+			} else {
+				assert.Len(t, meta.RequestID, tt.expectLen)
+			}
+		})
+	}
+}
@@ -0,0 +1,156 @@
+package throttle
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"log/slog"
+	"net"
+	"net/http"
+	"strings"
+
+	"ChadProgress/internal/lib/api/response"
+	"ChadProgress/internal/lib/apperr"
+	"ChadProgress/internal/lib/ratelimit"
+	"ChadProgress/internal/models"
+
+	"github.com/go-chi/chi/v5"
+)
+
+// maxPeekBody limits how much of request body is read to find email
+const maxPeekBody = 1 << 16
+
+// KeyFunc extracts limiter key from request. Empty key means request is not limited.
+type KeyFunc func(r *http.Request) string
+
+// Limit rejects requests exceeding limit with 429 and Retry-After header.
+// Limiter failures are logged and requests are let through, so broken backend does not take API down.
+func Limit(limiter ratelimit.Limiter, limit ratelimit.Limit, name string, key KeyFunc, log *slog.Logger) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		if limit.Unlimited() {
+			return next
+		}
+
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			k := key(r)
+			if k == "" {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			if !allow(w, r, limiter, name, k, limit, log) {
+				return
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// PerRoute limits requests of a router separately for every route and user. Routes are looked up
+// in limits by "METHOD /pattern" relative to the router mount point, such as "GET /clients/{id}",
+// others get defaultLimit. Requests to a route share its limit whatever its parameters are.
+func PerRoute(limiter ratelimit.Limiter, defaultLimit ratelimit.Limit, limits map[string]ratelimit.Limit, name string, log *slog.Logger) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			route := r.Method + " " + routePattern(r)
+			limit, ok := limits[route]
+			if !ok {
+				limit = defaultLimit
+			}
+
+			k := ByUser(r)
+			if k == "" {
+				k = ByIP(r)
+			}
+
+			if !limit.Unlimited() && !allow(w, r, limiter, name, route+":"+k, limit, log) {
+				return
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// ByIP keys requests by client address
+func ByIP(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+
+	return host
+}
+
+// ByUser keys requests by email of authorized user
+func ByUser(r *http.Request) string {
+	email, _ := r.Context().Value(models.ContextUserKey).(string)
+
+	return email
+}
+
+// ByEmail keys requests by "email" field of JSON body. Body is restored for next handlers.
+func ByEmail(r *http.Request) string {
+	if r.Body == nil {
+		return ""
+	}
+
+	// the rest of a larger body is left unread for next handlers, server closes the original body
+	body, err := io.ReadAll(io.LimitReader(r.Body, maxPeekBody))
+	r.Body = io.NopCloser(io.MultiReader(bytes.NewReader(body), r.Body))
+	if err != nil {
+		return ""
+	}
+
+	var req struct {
+		Email string `json:"email"`
+	}
+	if err = json.Unmarshal(body, &req); err != nil {
+		return ""
+	}
+
+	return strings.ToLower(strings.TrimSpace(req.Email))
+}
+
+func allow(w http.ResponseWriter, r *http.Request, limiter ratelimit.Limiter, name, key string, limit ratelimit.Limit, log *slog.Logger) bool {
+	const op = "middleware.throttle.allow"
+
+	res, err := limiter.Allow(r.Context(), name+":"+key, limit)
+	if err != nil {
+		log.Error("rate limiter failed", slog.String("op", op), slog.String("error", err.Error()))
+
+		return true
+	}
+
+	if !res.Allowed {
+		log.Info("rate limit exceeded", slog.String("op", op), slog.String("limiter", name))
+		response.RenderError(w, r, apperr.ErrTooManyRequests.WithRetryAfter(res.RetryAfter))
+
+		return false
+	}
+
+	return true
+}
+
+// routePattern returns pattern of the route request is routed to, relative to the router mount point.
+// Middlewares of a router run before it routes, so the route is looked up again from the root.
+func routePattern(r *http.Request) string {
+	rctx := chi.RouteContext(r.Context())
+	if rctx == nil || rctx.Routes == nil {
+		return r.URL.Path
+	}
+
+	path := r.URL.RawPath
+	if path == "" {
+		path = r.URL.Path
+	}
+	mount := strings.TrimSuffix(rctx.RoutePattern(), "/*")
+	pattern := rctx.Routes.Find(chi.NewRouteContext(), r.Method, path)
+	if pattern == "" {
+		// unknown paths share one limit instead of getting one each
+		return "/*"
+	}
+
+	return strings.TrimPrefix(pattern, mount)
+}
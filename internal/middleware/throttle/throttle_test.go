@@ -0,0 +1,127 @@
+package throttle
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"ChadProgress/internal/lib/ratelimit"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type failingLimiter struct{}
+
+func (failingLimiter) Allow(context.Context, string, ratelimit.Limit) (ratelimit.Result, error) {
+	return ratelimit.Result{}, errors.New("connection refused")
+}
+
+func TestLimitByEmail(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	mw := Limit(ratelimit.NewMemory(), ratelimit.Limit{Requests: 1, Per: time.Minute}, "login_email", ByEmail, logger)
+
+	var gotBody string
+	h := mw(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		b, _ := io.ReadAll(r.Body)
+		gotBody = string(b)
+	}))
+
+	send := func(body string) *httptest.ResponseRecorder {
+		rr := httptest.NewRecorder()
+		h.ServeHTTP(rr, httptest.NewRequest(http.MethodPost, "/login", bytes.NewBufferString(body)))
+		return rr
+	}
+
+	body := `{"email":"User@Example.com","password":"secret"}`
+	rr := send(body)
+	require.Equal(t, http.StatusOK, rr.Code)
+	assert.Equal(t, body, gotBody, "body must be restored for handler")
+
+	rr = send(`{"email":"user@example.com","password":"other"}`)
+	assert.Equal(t, http.StatusTooManyRequests, rr.Code)
+	assert.Equal(t, "60", rr.Header().Get("Retry-After"))
+	assert.Contains(t, rr.Body.String(), `"code":"too_many_requests"`)
+
+	rr = send(`{"email":"another@example.com","password":"secret"}`)
+	assert.Equal(t, http.StatusOK, rr.Code)
+}
+
+func TestByEmailKeepsLargeBody(t *testing.T) {
+	body := `{"email":"user@example.com","note":"` + strings.Repeat("x", 2*maxPeekBody) + `"}`
+	r := httptest.NewRequest(http.MethodPost, "/register", strings.NewReader(body))
+
+	assert.Empty(t, ByEmail(r), "email is not found in truncated body")
+	got, err := io.ReadAll(r.Body)
+	require.NoError(t, err)
+	assert.Equal(t, body, string(got), "handler reads the whole body")
+}
+
+func TestLimitFailsOpen(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	mw := Limit(failingLimiter{}, ratelimit.Limit{Requests: 1, Per: time.Minute}, "login_ip", ByIP, logger)
+	h := mw(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+
+	for i := 0; i < 3; i++ {
+		rr := httptest.NewRecorder()
+		h.ServeHTTP(rr, httptest.NewRequest(http.MethodPost, "/login", nil))
+		assert.Equal(t, http.StatusOK, rr.Code)
+	}
+}
+
+func TestPerRoute(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	limits := map[string]ratelimit.Limit{
+		"POST /clients/metrics": {Requests: 1, Per: time.Minute},
+	}
+
+	router := chi.NewRouter()
+	router.Route("/user", func(r chi.Router) {
+		r.Use(PerRoute(ratelimit.NewMemory(), ratelimit.Limit{Requests: 2, Per: time.Minute}, limits, "user", logger))
+		r.Post("/clients/metrics", func(w http.ResponseWriter, r *http.Request) {})
+		r.Get("/clients/metrics", func(w http.ResponseWriter, r *http.Request) {})
+	})
+
+	do := func(method string) int {
+		rr := httptest.NewRecorder()
+		router.ServeHTTP(rr, httptest.NewRequest(method, "/user/clients/metrics", nil))
+		return rr.Code
+	}
+
+	assert.Equal(t, http.StatusOK, do(http.MethodPost))
+	assert.Equal(t, http.StatusTooManyRequests, do(http.MethodPost))
+
+	assert.Equal(t, http.StatusOK, do(http.MethodGet))
+	assert.Equal(t, http.StatusOK, do(http.MethodGet))
+	assert.Equal(t, http.StatusTooManyRequests, do(http.MethodGet))
+}
+
+func TestPerRouteSharesLimitOfPattern(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	limits := map[string]ratelimit.Limit{
+		"GET /clients/{id}/metrics": {Requests: 1, Per: time.Minute},
+	}
+
+	router := chi.NewRouter()
+	router.Route("/v1/user", func(r chi.Router) {
+		r.Use(PerRoute(ratelimit.NewMemory(), ratelimit.Limit{Requests: 5, Per: time.Minute}, limits, "user", logger))
+		r.Get("/clients/{id}/metrics", func(w http.ResponseWriter, r *http.Request) {})
+	})
+
+	do := func(target string) int {
+		rr := httptest.NewRecorder()
+		router.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, target, nil))
+		return rr.Code
+	}
+
+	assert.Equal(t, http.StatusOK, do("/v1/user/clients/1/metrics"))
+	assert.Equal(t, http.StatusTooManyRequests, do("/v1/user/clients/2/metrics"), "configured limit of pattern")
+}
@@ -0,0 +1,52 @@
+package models
+
+import (
+	"errors"
+	"time"
+)
+
+var (
+	// RetentionErase deletes records
+	RetentionErase = "erase"
+	// RetentionAnonymize keeps records detached from personal data
+	RetentionAnonymize = "anonymize"
+)
+
+// RetentionPolicy decides what happens to data of deleted account. Records can be kept only
+// when profile is anonymized, otherwise they would reference deleted profile.
+type RetentionPolicy struct {
+	// Profile covers user and trainer or client profile
+	Profile string `json:"profile"`
+	// Metrics covers body metrics of client
+	Metrics string `json:"metrics"`
+	// SharedRecords covers training plans and progress reports, which the other party sees as well
+	SharedRecords string `json:"shared_records"`
+}
+
+var ErrInvalidRetentionPolicy = errors.New("records can be kept only when profile is anonymized")
+
+func (p RetentionPolicy) Validate() error {
+	for _, v := range []string{p.Profile, p.Metrics, p.SharedRecords} {
+		if v != RetentionErase && v != RetentionAnonymize {
+			return errors.New("retention must be either erase or anonymize")
+		}
+	}
+	if p.Profile == RetentionErase && (p.Metrics != RetentionErase || p.SharedRecords != RetentionErase) {
+		return ErrInvalidRetentionPolicy
+	}
+
+	return nil
+}
+
+// DeletionReceipt confirms account deletion. Email is kept only as a hash, so that user
+// can prove the receipt is theirs without the service keeping the address.
+type DeletionReceipt struct {
+	ID         string           `gorm:"type:varchar(32);primaryKey" json:"id"`
+	EmailHash  string           `gorm:"type:varchar(64);not null;index" json:"email_sha256"`
+	Policy     RetentionPolicy  `gorm:"serializer:json" json:"policy"`
+	Erased     map[string]int64 `gorm:"serializer:json" json:"erased"`
+	Anonymized map[string]int64 `gorm:"serializer:json" json:"anonymized"`
+	// CredentialsRemoved is false when auth service could not be reached, credentials are removed by reconcile-users later
+	CredentialsRemoved bool      `json:"credentials_removed"`
+	DeletedAt          time.Time `json:"deleted_at"`
+}
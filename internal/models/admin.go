@@ -0,0 +1,18 @@
+package models
+
+// UserFilter selects users for admin search. Empty fields match everything.
+type UserFilter struct {
+	// Query matches part of email or name, case insensitive
+	Query  string
+	Role   string
+	Status string
+	Limit  int
+	Offset int
+}
+
+// UserDetails is user with profile of their role
+type UserDetails struct {
+	User    User
+	Trainer *Trainer
+	Client  *Client
+}
@@ -0,0 +1,77 @@
+package models
+
+import (
+	"context"
+	"time"
+)
+
+// audit actions, one per row written
+const (
+	AuditCreate = "create"
+	AuditUpdate = "update"
+	AuditDelete = "delete"
+)
+
+// AuditActorSystem is recorded for changes made outside of HTTP requests: migrations, CLI and background jobs
+const AuditActorSystem = "system"
+
+// Change is a value of field before and after an update
+type Change struct {
+	From any `json:"from"`
+	To   any `json:"to"`
+}
+
+// AuditEvent records a change of data, events are never updated or deleted
+type AuditEvent struct {
+	ID uint `gorm:"primaryKey" json:"id"`
+	// ActorID is id of user who made the change, 0 for AuditActorSystem. Events outlive account deletion,
+	// so the actor is not recorded by email.
+	ActorID   uint   `gorm:"not null;default:0;index" json:"actor_id"`
+	RequestID string `gorm:"type:varchar(64);index" json:"request_id,omitempty"`
+	IP        string `gorm:"type:varchar(45)" json:"ip,omitempty"`
+	Action    string `gorm:"type:varchar(32);not null" json:"action"`
+	// Entity is the name of changed table
+	Entity   string `gorm:"type:varchar(32);not null;index:idx_audit_entity" json:"entity"`
+	EntityID uint   `gorm:"not null;index:idx_audit_entity" json:"entity_id"`
+	// Diff holds changed fields
+	Diff      map[string]Change `gorm:"serializer:json" json:"diff"`
+	CreatedAt time.Time         `gorm:"autoCreateTime;index" json:"created_at"`
+}
+
+// AuditFilter selects audit events, newest first. Empty fields match everything.
+type AuditFilter struct {
+	// Actor is email of user who made changes or AuditActorSystem
+	Actor     string
+	Action    string
+	Entity    string
+	EntityID  uint
+	RequestID string
+	Limit     int
+	Offset    int
+}
+
+// AuditMeta describes who made a change and from where
+type AuditMeta struct {
+	Actor string
+	// ActorID is id of user Actor, 0 for AuditActorSystem
+	ActorID   uint
+	RequestID string
+	IP        string
+}
+
+// AuditMetaFromContext reads metadata put into request context by middlewares.
+// Changes made without a signed in user are attributed to AuditActorSystem.
+func AuditMetaFromContext(ctx context.Context) AuditMeta {
+	meta := AuditMeta{Actor: AuditActorSystem}
+	if ctx == nil {
+		return meta
+	}
+	if actor, _ := ctx.Value(ContextUserKey).(string); actor != "" {
+		meta.Actor = actor
+		meta.ActorID, _ = ctx.Value(ContextUserIDKey).(uint)
+	}
+	meta.RequestID, _ = ctx.Value(ContextRequestIDKey).(string)
+	meta.IP, _ = ctx.Value(ContextIPKey).(string)
+
+	return meta
+}
@@ -1,15 +1,17 @@
 package models
 
 type Client struct {
-	ID              uint `gorm:"primaryKey"`
-	UserID          uint `gorm:"unique;not null"`
-	TrainerID       uint `gorm:"not null"`
-	Height          float64
-	Weight          float64
-	BodyFat         float64
-	TrainingPlans   []TrainingPlan   `gorm:"foreignKey:ClientID"`
-	ProgressReports []ProgressReport `gorm:"foreignKey:ClientID"`
-	Metrics         []Metric         `gorm:"foreignKey:ClientID"`
+	ID        uint    `gorm:"primaryKey"`
+	UserID    uint    `gorm:"unique;not null"`
+	TrainerID uint    `gorm:"not null;index"`
+	Height    float64 `audit:"redact"`
+	Weight    float64 `audit:"redact"`
+	BodyFat   float64 `audit:"redact"`
+	// Goals are target values by code of measurement type
+	Goals           MeasurementValues `gorm:"type:jsonb;not null;default:'{}'" audit:"redact"`
+	TrainingPlans   []TrainingPlan    `gorm:"foreignKey:ClientID"`
+	ProgressReports []ProgressReport  `gorm:"foreignKey:ClientID"`
+	Metrics         []Metric          `gorm:"foreignKey:ClientID"`
 }
 
 type ClientResponse struct {
@@ -19,4 +21,6 @@ type ClientResponse struct {
 	Height    float64 `json:"height"`
 	Weight    float64 `json:"weight"`
 	BodyFat   float64 `json:"bodyfat"`
+	// Units are labels of units of values by their keys
+	Units map[string]string `json:"units"`
 }
@@ -1,3 +1,15 @@
 package models
 
 const ContextUserKey = "user_email"
+
+// ContextUserIDKey holds id of the signed in user, it is absent when the user has no profile yet
+const ContextUserIDKey = "user_id"
+
+// ContextSessionKey holds id of session the request is made from
+const ContextSessionKey = "session_id"
+
+// ContextRequestIDKey holds id of the request, it is echoed in X-Request-ID header
+const ContextRequestIDKey = "request_id"
+
+// ContextIPKey holds address of the client
+const ContextIPKey = "client_ip"
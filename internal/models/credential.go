@@ -0,0 +1,29 @@
+package models
+
+import "time"
+
+// Credential is a password of user managed by built-in identity provider
+type Credential struct {
+	ID           uint      `gorm:"primaryKey"`
+	Login        string    `gorm:"type:varchar(100);unique;not null"`
+	PasswordHash string    `gorm:"type:varchar(100);not null"`
+	CreatedAt    time.Time `gorm:"autoCreateTime"`
+}
+
+// RefreshToken is issued by built-in identity provider. Only hash of the token is stored.
+type RefreshToken struct {
+	ID        uint   `gorm:"primaryKey"`
+	Login     string `gorm:"type:varchar(100);not null;index"`
+	TokenHash string `gorm:"type:varchar(64);unique;not null"`
+	// AccessTokenID is id of access token issued with it, revoked when the refresh token is rotated
+	AccessTokenID string `gorm:"type:varchar(64)"`
+	ExpiresAt     time.Time
+	RevokedAt     *time.Time
+	CreatedAt     time.Time `gorm:"autoCreateTime"`
+}
+
+// RevokedToken is access token revoked before its expiration, kept until it expires
+type RevokedToken struct {
+	TokenID   string    `gorm:"type:varchar(64);primaryKey"`
+	ExpiresAt time.Time `gorm:"not null;index"`
+}
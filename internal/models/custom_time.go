@@ -1,27 +1,77 @@
 package models
 
 import (
-	"strings"
+	"bytes"
+	"encoding/json"
+	"fmt"
 	"time"
 )
 
+// LegacyTimeLayout is the layout times were taken in before RFC 3339, it has no time zone
+const LegacyTimeLayout = time.DateTime
+
+// CustomTime is a time of request body. It is RFC 3339 time, the legacy layout and dates without time zone
+// are accepted too and are taken in time zone of user, see In.
 type CustomTime struct {
 	time.Time
+	// zoneless is set when the time was given without time zone
+	zoneless bool
 }
 
 func (ct *CustomTime) UnmarshalJSON(b []byte) error {
-	str := string(b)
-	str = strings.Trim(str, `"`)
+	if bytes.Equal(b, []byte("null")) {
+		*ct = CustomTime{}
+		return nil
+	}
 
-	layout := "2006-01-02 15:04:05"
-	parsedTime, err := time.Parse(layout, str)
+	var str string
+	if err := json.Unmarshal(b, &str); err != nil {
+		return err
+	}
+	t, err := ParseCustomTime(str)
 	if err != nil {
 		return err
 	}
-	ct.Time = parsedTime
+	*ct = t
+
 	return nil
 }
 
+// ParseCustomTime parses RFC 3339 time, time of the legacy layout or date, empty string is zero time
+func ParseCustomTime(s string) (CustomTime, error) {
+	if s == "" {
+		return CustomTime{}, nil
+	}
+
+	if t, err := time.Parse(time.RFC3339, s); err == nil {
+		return CustomTime{Time: t}, nil
+	}
+	for _, layout := range []string{LegacyTimeLayout, time.DateOnly} {
+		if t, err := time.Parse(layout, s); err == nil {
+			return CustomTime{Time: t, zoneless: true}, nil
+		}
+	}
+
+	return CustomTime{}, fmt.Errorf("time %q is neither RFC 3339 time, %q nor date", s, LegacyTimeLayout)
+}
+
+func (ct CustomTime) MarshalJSON() ([]byte, error) {
+	if ct.IsZero() {
+		return []byte("null"), nil
+	}
+
+	return json.Marshal(ct.String())
+}
+
+// In returns the time, a time given without time zone is taken as wall clock in loc
+func (ct CustomTime) In(loc *time.Location) time.Time {
+	if !ct.zoneless || ct.IsZero() {
+		return ct.Time
+	}
+
+	return time.Date(ct.Year(), ct.Month(), ct.Day(), ct.Hour(), ct.Minute(), ct.Second(), ct.Nanosecond(), loc)
+}
+
 func (ct CustomTime) String() string {
-	return ct.Time.Format("2009-01-02 15:04:05")
-}
\ No newline at end of file
+	return ct.Time.Format(time.RFC3339)
+}
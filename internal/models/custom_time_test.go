@@ -0,0 +1,61 @@
+package models
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCustomTime(t *testing.T) {
+	moscow := time.FixedZone("MSK", 3*60*60)
+	expected := time.Date(2026, time.October, 19, 5, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name string
+		json string
+		zero bool
+	}{
+		{name: "RFC 3339 in UTC", json: `"2026-10-19T05:00:00Z"`},
+		{name: "RFC 3339 with offset", json: `"2026-10-19T08:00:00+03:00"`},
+		{name: "Legacy layout in time zone of user", json: `"2026-10-19 08:00:00"`},
+		{name: "Null", json: `null`, zero: true},
+		{name: "Empty", json: `""`, zero: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var ct CustomTime
+			require.NoError(t, json.Unmarshal([]byte(tt.json), &ct))
+			if tt.zero {
+				assert.True(t, ct.In(moscow).IsZero())
+				return
+			}
+			assert.True(t, expected.Equal(ct.In(moscow)), "got %s", ct.In(moscow))
+
+			// what is written is read back as the same instant
+			b, err := json.Marshal(CustomTime{Time: ct.In(moscow)})
+			require.NoError(t, err)
+			var back CustomTime
+			require.NoError(t, json.Unmarshal(b, &back))
+			assert.True(t, expected.Equal(back.In(time.UTC)), "got %s from %s", back.Time, b)
+		})
+	}
+
+	t.Run("Malformed", func(t *testing.T) {
+		var ct CustomTime
+		assert.Error(t, json.Unmarshal([]byte(`"19.10.2026"`), &ct))
+	})
+
+	t.Run("Date in time zone of user", func(t *testing.T) {
+		ct, err := ParseCustomTime("2026-10-19")
+		require.NoError(t, err)
+		assert.True(t, time.Date(2026, time.October, 18, 21, 0, 0, 0, time.UTC).Equal(ct.In(moscow)), "got %s", ct.In(moscow))
+	})
+
+	t.Run("String", func(t *testing.T) {
+		assert.Equal(t, "2026-10-19T08:00:00+03:00", CustomTime{Time: expected.In(moscow)}.String())
+	})
+}
@@ -0,0 +1,54 @@
+package models
+
+import "time"
+
+var (
+	ExportStatusPending = "pending"
+	ExportStatusReady   = "ready"
+	ExportStatusFailed  = "failed"
+)
+
+// DataExport is personal data archive built in background. Archive is downloaded by link with
+// single token, only hash of the token is stored.
+type DataExport struct {
+	ID        string     `gorm:"type:varchar(32);primaryKey" json:"id"`
+	Login     string     `gorm:"type:varchar(100);not null;index" json:"-"`
+	Status    string     `gorm:"type:varchar(16);not null;index:idx_data_exports_status_created,priority:1" json:"status"`
+	TokenHash string     `gorm:"type:varchar(64);not null;unique" json:"-"`
+	Path      string     `gorm:"type:varchar(255)" json:"-"`
+	Size      int64      `json:"size,omitempty"`
+	CreatedAt time.Time  `gorm:"autoCreateTime;index:idx_data_exports_status_created,priority:2" json:"created_at"`
+	ReadyAt   *time.Time `json:"ready_at,omitempty"`
+	ExpiresAt time.Time  `gorm:"not null;index" json:"expires_at"`
+}
+
+// ExportResult holds either Archive built right away or background Export which is downloaded by Token
+type ExportResult struct {
+	Archive []byte
+	Export  *DataExport
+	Token   string
+}
+
+// PersonalData is everything stored about user, it is collected for data export
+type PersonalData struct {
+	User            User
+	Client          *Client
+	Trainer         *Trainer
+	Metrics         []Metric
+	TrainingPlans   []TrainingPlan
+	ProgressReports []ProgressReport
+	Sessions        []Session
+}
+
+// Records returns number of rows in the data, it is used to estimate size of the export
+func (d *PersonalData) Records() int {
+	n := 1 + len(d.Metrics) + len(d.TrainingPlans) + len(d.ProgressReports) + len(d.Sessions)
+	if d.Client != nil {
+		n++
+	}
+	if d.Trainer != nil {
+		n++
+	}
+
+	return n
+}
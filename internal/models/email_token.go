@@ -0,0 +1,22 @@
+package models
+
+import "time"
+
+var (
+	TokenPurposeVerifyEmail   = "verify_email"
+	TokenPurposeResetPassword = "reset_password"
+	TokenPurposeChangeEmail   = "change_email"
+)
+
+// EmailToken is a single use token sent to user by email. Only hash of the token is stored.
+type EmailToken struct {
+	ID        uint   `gorm:"primaryKey"`
+	Login     string `gorm:"type:varchar(100);not null;index:idx_email_tokens_login_purpose,priority:1"`
+	Purpose   string `gorm:"type:varchar(32);not null;index:idx_email_tokens_login_purpose,priority:2"`
+	TokenHash string `gorm:"type:varchar(64);unique;not null"`
+	// NewEmail is set for email change tokens, which are sent to the new address
+	NewEmail  string `gorm:"type:varchar(100)"`
+	ExpiresAt time.Time
+	UsedAt    *time.Time
+	CreatedAt time.Time `gorm:"autoCreateTime"`
+}
@@ -0,0 +1,112 @@
+package models
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+)
+
+// Value types of measurements
+const (
+	ValueDecimal = "decimal"
+	ValueInteger = "integer"
+)
+
+// Codes of measurement types. Height, weight, body fat and BMI are the metrics clients recorded before
+// the catalog was introduced, they keep their fields in v1 responses.
+const (
+	MeasurementHeight           = "height"
+	MeasurementWeight           = "weight"
+	MeasurementBodyFat          = "body_fat"
+	MeasurementBMI              = "bmi"
+	MeasurementWaist            = "waist"
+	MeasurementHips             = "hips"
+	MeasurementChest            = "chest"
+	MeasurementArm              = "arm"
+	MeasurementThigh            = "thigh"
+	MeasurementRestingHeartRate = "resting_heart_rate"
+	MeasurementSystolic         = "blood_pressure_systolic"
+	MeasurementDiastolic        = "blood_pressure_diastolic"
+	MeasurementVO2Max           = "vo2max"
+	MeasurementSleep            = "sleep_hours"
+)
+
+// MeasurementType is an entry of measurement catalog: what may be measured, in which unit and which
+// values are plausible. Values outside [Min, Max] are rejected as typos.
+type MeasurementType struct {
+	Code      string  `gorm:"type:varchar(32);primaryKey"`
+	Name      string  `gorm:"not null"`
+	Unit      string  `gorm:"type:varchar(16);not null"`
+	ValueType string  `gorm:"type:varchar(16);not null"`
+	Min       float64 `gorm:"not null"`
+	Max       float64 `gorm:"not null"`
+}
+
+// DefaultMeasurementTypes are added to catalog at start, types changed or added by admins are kept
+var DefaultMeasurementTypes = []MeasurementType{
+	{Code: MeasurementHeight, Name: "Height", Unit: "cm", ValueType: ValueDecimal, Min: 50, Max: 272},
+	{Code: MeasurementWeight, Name: "Weight", Unit: "kg", ValueType: ValueDecimal, Min: 2, Max: 650},
+	{Code: MeasurementBodyFat, Name: "Body fat", Unit: "%", ValueType: ValueDecimal, Min: 1, Max: 75},
+	{Code: MeasurementBMI, Name: "Body mass index", Unit: "kg/m2", ValueType: ValueDecimal, Min: 5, Max: 100},
+	{Code: MeasurementWaist, Name: "Waist circumference", Unit: "cm", ValueType: ValueDecimal, Min: 30, Max: 300},
+	{Code: MeasurementHips, Name: "Hips circumference", Unit: "cm", ValueType: ValueDecimal, Min: 30, Max: 300},
+	{Code: MeasurementChest, Name: "Chest circumference", Unit: "cm", ValueType: ValueDecimal, Min: 30, Max: 300},
+	{Code: MeasurementArm, Name: "Arm circumference", Unit: "cm", ValueType: ValueDecimal, Min: 10, Max: 100},
+	{Code: MeasurementThigh, Name: "Thigh circumference", Unit: "cm", ValueType: ValueDecimal, Min: 20, Max: 150},
+	{Code: MeasurementRestingHeartRate, Name: "Resting heart rate", Unit: "bpm", ValueType: ValueInteger, Min: 20, Max: 250},
+	{Code: MeasurementSystolic, Name: "Systolic blood pressure", Unit: "mmHg", ValueType: ValueInteger, Min: 50, Max: 300},
+	{Code: MeasurementDiastolic, Name: "Diastolic blood pressure", Unit: "mmHg", ValueType: ValueInteger, Min: 30, Max: 200},
+	{Code: MeasurementVO2Max, Name: "VO2max", Unit: "ml/kg/min", ValueType: ValueDecimal, Min: 5, Max: 100},
+	{Code: MeasurementSleep, Name: "Sleep", Unit: "h", ValueType: ValueDecimal, Min: 0, Max: 24},
+}
+
+var (
+	ErrValueNotInteger = errors.New("must be a whole number")
+	ErrValueOutOfRange = errors.New("is out of range")
+)
+
+// Check reports whether v is a valid value of the type
+func (t MeasurementType) Check(v float64) error {
+	if math.IsNaN(v) || math.IsInf(v, 0) {
+		return ErrValueOutOfRange
+	}
+	if t.ValueType == ValueInteger && v != math.Trunc(v) {
+		return ErrValueNotInteger
+	}
+	if v < t.Min || v > t.Max {
+		return fmt.Errorf("%w %g..%g %s", ErrValueOutOfRange, t.Min, t.Max, t.Unit)
+	}
+
+	return nil
+}
+
+// MeasurementValues are values of a metric by code of measurement type, stored as jsonb
+type MeasurementValues map[string]float64
+
+func (v MeasurementValues) Value() (driver.Value, error) {
+	if v == nil {
+		return "{}", nil
+	}
+	b, err := json.Marshal(v)
+
+	return string(b), err
+}
+
+func (v *MeasurementValues) Scan(src any) error {
+	var b []byte
+	switch s := src.(type) {
+	case []byte:
+		b = s
+	case string:
+		b = []byte(s)
+	case nil:
+		*v = nil
+		return nil
+	default:
+		return fmt.Errorf("unsupported type %T of measurement values", src)
+	}
+
+	return json.Unmarshal(b, v)
+}
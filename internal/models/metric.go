@@ -1,17 +1,21 @@
 package models
 
-import "time"
+import (
+	"time"
 
+	"ChadProgress/internal/lib/apperr"
+)
+
+// Metric is an entry of measurements taken at once, its values are checked against measurement catalog
 type Metric struct {
-	ID         uint `gorm:"primaryKey"`
-	ClientID   uint `gorm:"not null"`
-	Height     float64
-	Weight     float64
-	BodyFat    float64
-	BMI        float64
-	MeasuredAt time.Time `gorm:"autoCreateTime"`
+	ID           uint              `gorm:"primaryKey"`
+	ClientID     uint              `gorm:"not null;index:idx_metrics_client_measured,priority:1"`
+	Measurements MeasurementValues `gorm:"type:jsonb;not null;default:'{}'" audit:"redact"`
+	MeasuredAt   time.Time         `gorm:"autoCreateTime;index:idx_metrics_client_measured,priority:2"`
 }
 
+// MetricResponse is v1 contract of metric, it has only measurements clients recorded before the
+// catalog was introduced, see MetricEntryResponse
 type MetricResponse struct {
 	ID         uint    `json:"id"`
 	ClientID   uint    `json:"client-id"`
@@ -20,4 +24,126 @@ type MetricResponse struct {
 	BodyFat    float64 `json:"bodyfat"`
 	BMI        float64 `json:"bmi"`
 	MeasuredAt string  `json:"measured-at"`
+	// Units are labels of units of values by their keys
+	Units map[string]string `json:"units"`
+}
+
+// MetricEntryResponse is v2 contract of metric with every measurement by code of its type
+type MetricEntryResponse struct {
+	ID           uint               `json:"id"`
+	ClientID     uint               `json:"client-id"`
+	Measurements map[string]float64 `json:"measurements"`
+	MeasuredAt   string             `json:"measured-at"`
+	// Units are labels of units of measurements by code of their type
+	Units map[string]string `json:"units"`
+}
+
+// MetricImportRow is an entry of imported file. Row is its number in the file, Errors are problems found
+// when it was read.
+type MetricImportRow struct {
+	Row          int
+	Measurements MeasurementValues
+	MeasuredAt   time.Time
+	Errors       []apperr.FieldError
+}
+
+// Statuses of imported rows
+const (
+	ImportAccepted  = "accepted"
+	ImportRejected  = "rejected"
+	ImportDuplicate = "duplicate"
+)
+
+// MetricImportReport tells what became of every row of imported file
+type MetricImportReport struct {
+	Accepted   int                  `json:"accepted"`
+	Rejected   int                  `json:"rejected"`
+	Duplicates int                  `json:"duplicates"`
+	Rows       []MetricImportResult `json:"rows"`
+}
+
+// MetricImportResult is the status of a row, rejected rows have errors
+type MetricImportResult struct {
+	Row    int                 `json:"row"`
+	Status string              `json:"status"`
+	Errors []apperr.FieldError `json:"errors,omitempty"`
+}
+
+// Metric rollup periods and resolutions of metric series
+const (
+	PeriodDay  = "day"
+	PeriodWeek = "week"
+	// ResolutionRaw series consists of measurements as they are
+	ResolutionRaw = "raw"
+)
+
+// MetricRollup aggregates values of a measurement type of a client measured within a day or a week
+// (starting on Monday) in time zone of the client. Rollups are updated with every added metric, so that long ranges are read
+// without scanning raw metrics.
+type MetricRollup struct {
+	ClientID    uint      `gorm:"primaryKey;autoIncrement:false"`
+	Type        string    `gorm:"type:varchar(32);primaryKey"`
+	Period      string    `gorm:"type:varchar(8);primaryKey"`
+	PeriodStart time.Time `gorm:"type:date;primaryKey"`
+	Count       int64     `gorm:"not null"`
+	ValueSum    float64   `gorm:"not null"`
+	ValueMin    float64   `gorm:"not null"`
+	ValueMax    float64   `gorm:"not null"`
+}
+
+// PeriodStart returns day or week (starting on Monday) of t in loc as midnight UTC of its date,
+// rollups are keyed by such dates
+func PeriodStart(period string, t time.Time, loc *time.Location) time.Time {
+	t = t.In(loc)
+	day := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+	if period == PeriodWeek {
+		// time.Sunday is 0
+		return day.AddDate(0, 0, -((int(day.Weekday()) + 6) % 7))
+	}
+
+	return day
+}
+
+// MetricStat is a value of a measurement type at a point of metric series: a single measurement or
+// average, minimum and maximum over a period
+type MetricStat struct {
+	Count int64
+	Avg   float64
+	Min   float64
+	Max   float64
+}
+
+// MetricPoint is a point of metric series, Values are keyed by measurement type
+type MetricPoint struct {
+	Start  time.Time
+	Values map[string]MetricStat
+}
+
+// MetricSeries is metrics of a client within a range, Resolution is ResolutionRaw, PeriodDay or PeriodWeek
+type MetricSeries struct {
+	From       time.Time
+	To         time.Time
+	Resolution string
+	Points     []MetricPoint
+}
+
+type MetricStatResponse struct {
+	Count int64   `json:"count"`
+	Avg   float64 `json:"avg"`
+	Min   float64 `json:"min"`
+	Max   float64 `json:"max"`
+}
+
+type MetricPointResponse struct {
+	Start  string                        `json:"start"`
+	Values map[string]MetricStatResponse `json:"values"`
+}
+
+type MetricSeriesResponse struct {
+	From       string                `json:"from"`
+	To         string                `json:"to"`
+	Resolution string                `json:"resolution"`
+	Points     []MetricPointResponse `json:"points"`
+	// Units are labels of units of values by code of measurement type
+	Units map[string]string `json:"units"`
 }
@@ -3,9 +3,9 @@ package models
 import "time"
 
 type ProgressReport struct {
-	ID        uint `gorm:"primaryKey"`
-	TrainerID uint `gorm:"not null"`
-	ClientID  uint `gorm:"not null"`
-	Comments  string
+	ID        uint      `gorm:"primaryKey"`
+	TrainerID uint      `gorm:"not null;index:idx_progress_reports_trainer_client,priority:1"`
+	ClientID  uint      `gorm:"not null;index:idx_progress_reports_trainer_client,priority:2;index"`
+	Comments  string    `audit:"redact"`
 	CreatedAt time.Time `gorm:"autoCreateTime"`
 }
@@ -0,0 +1,41 @@
+package models
+
+import "time"
+
+// Formats of metrics export
+const (
+	ReportCSV  = "csv"
+	ReportXLSX = "xlsx"
+)
+
+// Content types of generated reports
+const (
+	ContentTypeCSV  = "text/csv; charset=utf-8"
+	ContentTypeXLSX = "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet"
+	ContentTypePDF  = "application/pdf"
+)
+
+// ReportFile is a generated report, Name is the file name offered for download
+type ReportFile struct {
+	Name        string
+	ContentType string
+	Content     []byte
+}
+
+// ReportView is how a report shows values: in unit system and time zone of the user it is made for
+type ReportView struct {
+	Units    string
+	Location *time.Location
+}
+
+// ContentTypeSVG is content type of rendered charts
+const ContentTypeSVG = "image/svg+xml"
+
+// ChartOptions are range of a chart and how it looks. Zero range, size and theme are replaced by defaults.
+type ChartOptions struct {
+	From, To      time.Time
+	Width, Height int
+	Theme         string
+	// AverageDays is window of moving average in days, 0 draws no average
+	AverageDays int
+}
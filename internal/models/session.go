@@ -0,0 +1,28 @@
+package models
+
+import "time"
+
+// Session is a signed in device. Hashes of its current tokens are stored to find session by token.
+type Session struct {
+	ID               string     `gorm:"type:varchar(32);primaryKey"`
+	Login            string     `gorm:"type:varchar(100);not null;index"`
+	AccessTokenHash  string     `gorm:"type:varchar(64);not null;unique"`
+	RefreshTokenHash string     `gorm:"type:varchar(64);index"`
+	UserAgent        string     `gorm:"type:varchar(255)"`
+	IP               string     `gorm:"type:varchar(45)"`
+	CreatedAt        time.Time  `gorm:"autoCreateTime"`
+	LastSeenAt       time.Time  `gorm:"not null"`
+	RevokedAt        *time.Time `gorm:"index"`
+}
+
+// ClientInfo describes device which makes request
+type ClientInfo struct {
+	UserAgent string
+	IP        string
+}
+
+// TokenPair is issued on sign in. RefreshToken is empty when auth provider does not support refresh.
+type TokenPair struct {
+	AccessToken  string
+	RefreshToken string
+}
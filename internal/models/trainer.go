@@ -9,9 +9,9 @@ var (
 type Trainer struct {
 	ID              uint             `gorm:"primaryKey"`
 	UserID          uint             `gorm:"unique;not null"`
-	Qualifications  string           `gorm:"type:varchar(150)"`
-	Experience      string           `gorm:"type:varchar(250)"`
-	Achievements    string           `gorm:"type:varchar(250)"`
+	Qualifications  string           `gorm:"type:varchar(150)" audit:"redact"`
+	Experience      string           `gorm:"type:varchar(250)" audit:"redact"`
+	Achievements    string           `gorm:"type:varchar(250)" audit:"redact"`
 	Status          string           `gorm:"type:status;default:'ACTIVE';not null"`
 	Clients         []Client         `gorm:"foreignKey:TrainerID"`
 	TrainingPlans   []TrainingPlan   `gorm:"foreignKey:TrainerID"`
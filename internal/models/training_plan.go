@@ -3,11 +3,11 @@ package models
 import "time"
 
 type TrainingPlan struct {
-	ID          uint   `gorm:"primaryKey"`
-	TrainerID   uint   `gorm:"not null"`
-	ClientID    uint   `gorm:"not null"`
-	Description string `gorm:"not null"`
-	Schedule    string
+	ID          uint      `gorm:"primaryKey"`
+	TrainerID   uint      `gorm:"not null;index:idx_training_plans_trainer_client,priority:1"`
+	ClientID    uint      `gorm:"not null;index:idx_training_plans_trainer_client,priority:2;index"`
+	Description string    `gorm:"not null" audit:"redact"`
+	Schedule    string    `audit:"redact"`
 	CreatedAt   time.Time `gorm:"autoCreateTime"`
 }
 
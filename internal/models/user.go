@@ -5,14 +5,61 @@ import "time"
 var (
 	RoleTrainer = "trainer"
 	RoleClient  = "client"
+	// RoleAdmin is support staff, it is granted with cp grant-admin and can not be chosen at registration
+	RoleAdmin = "admin"
+)
+
+var (
+	// UserStatusPending is set while user is being registered in auth service
+	UserStatusPending = "pending"
+	UserStatusActive  = "active"
+	// UserStatusDeleted marks anonymized user kept after account deletion
+	UserStatusDeleted = "deleted"
+	// UserStatusDisabled is set by admin, such user can not sign in
+	UserStatusDisabled = "disabled"
 )
 
 type User struct {
 	ID           uint      `gorm:"primaryKey"`
-	Email        string    `gorm:"type:varchar(100);unique;not null"`
-	Name         string    `gorm:"type:varchar(100);not null"`
+	Email        string    `gorm:"type:varchar(100);unique;not null" audit:"redact"`
+	Name         string    `gorm:"type:varchar(100);not null" audit:"redact"`
 	Role         string    `gorm:"type:role_enum;not null"`
-	RegisteredAt time.Time `gorm:"autoCreateTime"`
-	Trainer      Trainer   `gorm:"foreignKey:UserID"`
-	Client       Client    `gorm:"foreignKey:UserID"`
+	Status       string    `gorm:"type:varchar(16);default:'active';not null;index:idx_users_status_registered,priority:1"`
+	RegisteredAt time.Time `gorm:"autoCreateTime;index:idx_users_status_registered,priority:2"`
+	// EmailVerifiedAt is nil until user follows the link sent to their email
+	EmailVerifiedAt *time.Time
+	// Units is unit system values are entered and shown in, values are stored in SI units regardless of it
+	Units string `gorm:"type:varchar(8);default:'metric';not null"`
+	// Timezone is IANA time zone times are shown in and metrics are grouped by days of
+	Timezone string  `gorm:"type:varchar(64);default:'UTC';not null"`
+	Trainer  Trainer `gorm:"foreignKey:UserID"`
+	Client   Client  `gorm:"foreignKey:UserID"`
+}
+
+func (u *User) EmailVerified() bool {
+	return u.EmailVerifiedAt != nil
+}
+
+// Location returns time zone of user, UTC when it is not set or unknown
+func (u *User) Location() *time.Location {
+	return LoadLocation(u.Timezone)
+}
+
+// LoadLocation returns IANA time zone by name, UTC when the name is empty or unknown
+func LoadLocation(name string) *time.Location {
+	if name == "" {
+		return time.UTC
+	}
+	loc, err := time.LoadLocation(name)
+	if err != nil {
+		return time.UTC
+	}
+
+	return loc
+}
+
+// UserSettings are preferences of user, empty fields are left unchanged on update
+type UserSettings struct {
+	Units    string
+	Timezone string
 }
@@ -0,0 +1,289 @@
+package adminservice
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"regexp"
+
+	"ChadProgress/internal/lib/apperr"
+	"ChadProgress/internal/models"
+	service "ChadProgress/internal/services"
+	"ChadProgress/storage"
+)
+
+const (
+	// defaultLimit and maxLimit bound pages of search results
+	defaultLimit = 50
+	maxLimit     = 200
+)
+
+//go:generate mockgen -source=admin.go -destination=./admin_mock.go -package=adminservice
+type Storage interface {
+	SearchUsers(filter models.UserFilter) ([]models.User, int64, error)
+	GetUserByID(id uint) (*models.User, error)
+	GetUserByEmail(email string) (*models.User, error)
+	GetTrainerByID(id uint) (*models.Trainer, error)
+	GetTrainerByUserID(id uint) (*models.Trainer, error)
+	GetClientByID(id uint) (*models.Client, error)
+	GetClientByUserID(id uint) (*models.Client, error)
+	SetUserStatus(ctx context.Context, id uint, status string) error
+	SetUserRole(ctx context.Context, email, role string) error
+	UpdateTrainerStatus(ctx context.Context, trainerID uint, status string) error
+	UpdateTrainerID(ctx context.Context, clientID, trainerID uint) error
+	GetAuditEvents(filter models.AuditFilter) ([]models.AuditEvent, error)
+	SaveMeasurementType(ctx context.Context, t *models.MeasurementType) error
+}
+
+// measurementCodeRe is format of measurement type codes, they are keys of metric measurements
+var measurementCodeRe = regexp.MustCompile(`^[a-z][a-z0-9_]{0,31}$`)
+
+type AdminService struct {
+	storage Storage
+	log     *slog.Logger
+}
+
+func NewAdminService(storage Storage, log *slog.Logger) *AdminService {
+	return &AdminService{
+		storage: storage,
+		log:     log,
+	}
+}
+
+// SearchUsers returns page of users matching filter and total number of matches
+func (a *AdminService) SearchUsers(ctx context.Context, filter models.UserFilter) ([]models.User, int64, error) {
+	const op = "services.admin.SearchUsers"
+
+	filter.Limit = clampLimit(filter.Limit)
+	users, total, err := a.storage.SearchUsers(filter)
+	if err != nil {
+		return nil, 0, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return users, total, nil
+}
+
+// GetUser returns user with profile of their role
+func (a *AdminService) GetUser(ctx context.Context, id uint) (*models.UserDetails, error) {
+	const op = "services.admin.GetUser"
+
+	user, err := a.storage.GetUserByID(id)
+	if err != nil {
+		if errors.Is(err, storage.ErrRecordNotFound) {
+			return nil, service.ErrUserNotFound
+		}
+
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	details := &models.UserDetails{User: *user}
+	switch user.Role {
+	case models.RoleTrainer:
+		details.Trainer, err = a.storage.GetTrainerByUserID(user.ID)
+	case models.RoleClient:
+		details.Client, err = a.storage.GetClientByUserID(user.ID)
+	}
+	if err != nil && !errors.Is(err, storage.ErrRecordNotFound) {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return details, nil
+}
+
+// SetUserStatus disables or enables account. Disabled user is signed out and can not sign in.
+func (a *AdminService) SetUserStatus(ctx context.Context, actor string, id uint, status string) error {
+	const op = "services.admin.SetUserStatus"
+	log := a.log.With(
+		slog.String("op", op),
+	)
+
+	if status != models.UserStatusActive && status != models.UserStatusDisabled {
+		return apperr.ErrBadRequest.WithMessage("status must be active or disabled")
+	}
+
+	user, err := a.storage.GetUserByID(id)
+	if err != nil {
+		if errors.Is(err, storage.ErrRecordNotFound) {
+			return service.ErrUserNotFound
+		}
+
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	if user.Email == actor {
+		return apperr.ErrBadRequest.WithMessage("admins can not change status of their own account")
+	}
+
+	if err = a.storage.SetUserStatus(ctx, id, status); err != nil {
+		if errors.Is(err, storage.ErrRecordNotFound) {
+			return service.ErrUserNotFound.WithMessage("user is not active")
+		}
+
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	log.Info("user status changed", slog.String("actor", actor), slog.Any("user_id", id), slog.String("status", status))
+
+	return nil
+}
+
+// SetTrainerStatus changes availability of trainer
+func (a *AdminService) SetTrainerStatus(ctx context.Context, actor string, trainerID uint, status string) error {
+	const op = "services.admin.SetTrainerStatus"
+	log := a.log.With(
+		slog.String("op", op),
+	)
+
+	switch status {
+	case models.StatusActive, models.StatusBusy, models.StatusOnVacation:
+	default:
+		return apperr.ErrBadRequest.WithMessage("unknown trainer status")
+	}
+
+	if err := a.storage.UpdateTrainerStatus(ctx, trainerID, status); err != nil {
+		if errors.Is(err, storage.ErrRecordNotFound) {
+			return service.ErrTrainerNotFound
+		}
+		if appErr, ok := service.FromStorage(err, nil); ok {
+			return fmt.Errorf("%s: %w", op, appErr)
+		}
+
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	log.Info("trainer status changed", slog.String("actor", actor), slog.Any("trainer_id", trainerID), slog.String("status", status))
+
+	return nil
+}
+
+// ReassignClient binds client to another trainer regardless of trainer availability
+func (a *AdminService) ReassignClient(ctx context.Context, actor string, clientID, trainerID uint) error {
+	const op = "services.admin.ReassignClient"
+	log := a.log.With(
+		slog.String("op", op),
+	)
+
+	if _, err := a.storage.GetClientByID(clientID); err != nil {
+		if errors.Is(err, storage.ErrRecordNotFound) {
+			return service.ErrClientNotFound
+		}
+
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	if _, err := a.storage.GetTrainerByID(trainerID); err != nil {
+		if errors.Is(err, storage.ErrRecordNotFound) {
+			return service.ErrTrainerNotFound
+		}
+
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	if err := a.storage.UpdateTrainerID(ctx, clientID, trainerID); err != nil {
+		// trainer may be deleted after the check
+		if errors.Is(err, storage.ErrForeignKeyViolation) {
+			return service.ErrTrainerNotFound
+		}
+
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	log.Info("client reassigned", slog.String("actor", actor), slog.Any("client_id", clientID), slog.Any("trainer_id", trainerID))
+
+	return nil
+}
+
+// GrantAdmin gives admin role to active user, it is used by cp grant-admin
+func (a *AdminService) GrantAdmin(ctx context.Context, actor, email string) error {
+	const op = "services.admin.GrantAdmin"
+	log := a.log.With(
+		slog.String("op", op),
+	)
+
+	user, err := a.storage.GetUserByEmail(email)
+	if err != nil {
+		if errors.Is(err, storage.ErrRecordNotFound) {
+			return service.ErrUserNotFound
+		}
+
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	if user.Role == models.RoleAdmin {
+		return nil
+	}
+
+	// admins have no profiles, their data would be left orphaned
+	if _, err = a.storage.GetTrainerByUserID(user.ID); err == nil {
+		return service.ErrInvalidRoleRequest.WithMessage("user has trainer profile")
+	} else if !errors.Is(err, storage.ErrRecordNotFound) {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	if _, err = a.storage.GetClientByUserID(user.ID); err == nil {
+		return service.ErrInvalidRoleRequest.WithMessage("user has client profile")
+	} else if !errors.Is(err, storage.ErrRecordNotFound) {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	if err = a.storage.SetUserRole(ctx, email, models.RoleAdmin); err != nil {
+		if errors.Is(err, storage.ErrRecordNotFound) {
+			return service.ErrUserNotFound.WithMessage("user is not active")
+		}
+
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	log.Info("admin role granted", slog.String("actor", actor), slog.String("email", email))
+
+	return nil
+}
+
+// SaveMeasurementType adds type to measurement catalog or changes name, unit and range of existing one.
+// Values saved before are kept, the new range applies to new metrics.
+func (a *AdminService) SaveMeasurementType(ctx context.Context, actor string, t models.MeasurementType) error {
+	const op = "services.admin.SaveMeasurementType"
+	log := a.log.With(
+		slog.String("op", op),
+	)
+
+	var fields []apperr.FieldError
+	if !measurementCodeRe.MatchString(t.Code) {
+		fields = append(fields, apperr.FieldError{Field: "code", Message: "must be lowercase letters, digits and underscores"})
+	}
+	if t.ValueType != models.ValueDecimal && t.ValueType != models.ValueInteger {
+		fields = append(fields, apperr.FieldError{Field: "value_type", Message: "must be decimal or integer"})
+	}
+	if t.Min >= t.Max {
+		fields = append(fields, apperr.FieldError{Field: "max", Message: "must be greater than min"})
+	}
+	if len(fields) > 0 {
+		return apperr.ErrValidation.WithFields(fields...)
+	}
+
+	if err := a.storage.SaveMeasurementType(ctx, &t); err != nil {
+		if appErr, ok := service.FromStorage(err, nil); ok {
+			return fmt.Errorf("%s: %w", op, appErr)
+		}
+
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	log.Info("measurement type saved", slog.String("actor", actor), slog.String("code", t.Code),
+		slog.String("unit", t.Unit), slog.Float64("min", t.Min), slog.Float64("max", t.Max))
+
+	return nil
+}
+
+// AuditHistory returns page of audit events, newest first
+func (a *AdminService) AuditHistory(ctx context.Context, filter models.AuditFilter) ([]models.AuditEvent, error) {
+	const op = "services.admin.AuditHistory"
+
+	filter.Limit = clampLimit(filter.Limit)
+	events, err := a.storage.GetAuditEvents(filter)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return events, nil
+}
+
+func clampLimit(limit int) int {
+	if limit <= 0 {
+		return defaultLimit
+	}
+
+	return min(limit, maxLimit)
+}
@@ -0,0 +1,227 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: admin.go
+
+// Package adminservice is a generated GoMock package.
+package adminservice
+
+import (
+	models "ChadProgress/internal/models"
+	context "context"
+	reflect "reflect"
+
+	gomock "github.com/golang/mock/gomock"
+)
+
+// MockStorage is a mock of Storage interface.
+type MockStorage struct {
+	ctrl     *gomock.Controller
+	recorder *MockStorageMockRecorder
+}
+
+// MockStorageMockRecorder is the mock recorder for MockStorage.
+type MockStorageMockRecorder struct {
+	mock *MockStorage
+}
+
+// NewMockStorage creates a new mock instance.
+func NewMockStorage(ctrl *gomock.Controller) *MockStorage {
+	mock := &MockStorage{ctrl: ctrl}
+	mock.recorder = &MockStorageMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockStorage) EXPECT() *MockStorageMockRecorder {
+	return m.recorder
+}
+
+// GetAuditEvents mocks base method.
+func (m *MockStorage) GetAuditEvents(filter models.AuditFilter) ([]models.AuditEvent, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetAuditEvents", filter)
+	ret0, _ := ret[0].([]models.AuditEvent)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetAuditEvents indicates an expected call of GetAuditEvents.
+func (mr *MockStorageMockRecorder) GetAuditEvents(filter interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAuditEvents", reflect.TypeOf((*MockStorage)(nil).GetAuditEvents), filter)
+}
+
+// GetClientByID mocks base method.
+func (m *MockStorage) GetClientByID(id uint) (*models.Client, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetClientByID", id)
+	ret0, _ := ret[0].(*models.Client)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetClientByID indicates an expected call of GetClientByID.
+func (mr *MockStorageMockRecorder) GetClientByID(id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetClientByID", reflect.TypeOf((*MockStorage)(nil).GetClientByID), id)
+}
+
+// GetClientByUserID mocks base method.
+func (m *MockStorage) GetClientByUserID(id uint) (*models.Client, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetClientByUserID", id)
+	ret0, _ := ret[0].(*models.Client)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetClientByUserID indicates an expected call of GetClientByUserID.
+func (mr *MockStorageMockRecorder) GetClientByUserID(id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetClientByUserID", reflect.TypeOf((*MockStorage)(nil).GetClientByUserID), id)
+}
+
+// GetTrainerByID mocks base method.
+func (m *MockStorage) GetTrainerByID(id uint) (*models.Trainer, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetTrainerByID", id)
+	ret0, _ := ret[0].(*models.Trainer)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetTrainerByID indicates an expected call of GetTrainerByID.
+func (mr *MockStorageMockRecorder) GetTrainerByID(id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetTrainerByID", reflect.TypeOf((*MockStorage)(nil).GetTrainerByID), id)
+}
+
+// GetTrainerByUserID mocks base method.
+func (m *MockStorage) GetTrainerByUserID(id uint) (*models.Trainer, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetTrainerByUserID", id)
+	ret0, _ := ret[0].(*models.Trainer)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetTrainerByUserID indicates an expected call of GetTrainerByUserID.
+func (mr *MockStorageMockRecorder) GetTrainerByUserID(id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetTrainerByUserID", reflect.TypeOf((*MockStorage)(nil).GetTrainerByUserID), id)
+}
+
+// GetUserByEmail mocks base method.
+func (m *MockStorage) GetUserByEmail(email string) (*models.User, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetUserByEmail", email)
+	ret0, _ := ret[0].(*models.User)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetUserByEmail indicates an expected call of GetUserByEmail.
+func (mr *MockStorageMockRecorder) GetUserByEmail(email interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUserByEmail", reflect.TypeOf((*MockStorage)(nil).GetUserByEmail), email)
+}
+
+// GetUserByID mocks base method.
+func (m *MockStorage) GetUserByID(id uint) (*models.User, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetUserByID", id)
+	ret0, _ := ret[0].(*models.User)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetUserByID indicates an expected call of GetUserByID.
+func (mr *MockStorageMockRecorder) GetUserByID(id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUserByID", reflect.TypeOf((*MockStorage)(nil).GetUserByID), id)
+}
+
+// SaveMeasurementType mocks base method.
+func (m *MockStorage) SaveMeasurementType(ctx context.Context, t *models.MeasurementType) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SaveMeasurementType", ctx, t)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SaveMeasurementType indicates an expected call of SaveMeasurementType.
+func (mr *MockStorageMockRecorder) SaveMeasurementType(ctx, t interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SaveMeasurementType", reflect.TypeOf((*MockStorage)(nil).SaveMeasurementType), ctx, t)
+}
+
+// SearchUsers mocks base method.
+func (m *MockStorage) SearchUsers(filter models.UserFilter) ([]models.User, int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SearchUsers", filter)
+	ret0, _ := ret[0].([]models.User)
+	ret1, _ := ret[1].(int64)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// SearchUsers indicates an expected call of SearchUsers.
+func (mr *MockStorageMockRecorder) SearchUsers(filter interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SearchUsers", reflect.TypeOf((*MockStorage)(nil).SearchUsers), filter)
+}
+
+// SetUserRole mocks base method.
+func (m *MockStorage) SetUserRole(ctx context.Context, email, role string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetUserRole", ctx, email, role)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SetUserRole indicates an expected call of SetUserRole.
+func (mr *MockStorageMockRecorder) SetUserRole(ctx, email, role interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetUserRole", reflect.TypeOf((*MockStorage)(nil).SetUserRole), ctx, email, role)
+}
+
+// SetUserStatus mocks base method.
+func (m *MockStorage) SetUserStatus(ctx context.Context, id uint, status string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetUserStatus", ctx, id, status)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SetUserStatus indicates an expected call of SetUserStatus.
+func (mr *MockStorageMockRecorder) SetUserStatus(ctx, id, status interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetUserStatus", reflect.TypeOf((*MockStorage)(nil).SetUserStatus), ctx, id, status)
+}
+
+// UpdateTrainerID mocks base method.
+func (m *MockStorage) UpdateTrainerID(ctx context.Context, clientID, trainerID uint) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateTrainerID", ctx, clientID, trainerID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateTrainerID indicates an expected call of UpdateTrainerID.
+func (mr *MockStorageMockRecorder) UpdateTrainerID(ctx, clientID, trainerID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateTrainerID", reflect.TypeOf((*MockStorage)(nil).UpdateTrainerID), ctx, clientID, trainerID)
+}
+
+// UpdateTrainerStatus mocks base method.
+func (m *MockStorage) UpdateTrainerStatus(ctx context.Context, trainerID uint, status string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateTrainerStatus", ctx, trainerID, status)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateTrainerStatus indicates an expected call of UpdateTrainerStatus.
+func (mr *MockStorageMockRecorder) UpdateTrainerStatus(ctx, trainerID, status interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateTrainerStatus", reflect.TypeOf((*MockStorage)(nil).UpdateTrainerStatus), ctx, trainerID, status)
+}
@@ -0,0 +1,159 @@
+package adminservice
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"testing"
+
+	"ChadProgress/internal/lib/apperr"
+	"ChadProgress/internal/models"
+	service "ChadProgress/internal/services"
+	"ChadProgress/storage"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const admin = "admin@example.com"
+
+func newTestService(t *testing.T) (*AdminService, *MockStorage) {
+	ctrl := gomock.NewController(t)
+	st := NewMockStorage(ctrl)
+
+	return NewAdminService(st, slog.New(slog.NewTextHandler(io.Discard, nil))), st
+}
+
+func TestSearchUsersLimitsPage(t *testing.T) {
+	s, st := newTestService(t)
+	st.EXPECT().SearchUsers(models.UserFilter{Query: "bob", Limit: maxLimit}).Return([]models.User{{ID: 1}}, int64(1), nil)
+
+	users, total, err := s.SearchUsers(context.Background(), models.UserFilter{Query: "bob", Limit: 10000})
+	require.NoError(t, err)
+	assert.Len(t, users, 1)
+	assert.EqualValues(t, 1, total)
+}
+
+func TestGetUser(t *testing.T) {
+	s, st := newTestService(t)
+	st.EXPECT().GetUserByID(uint(7)).Return(&models.User{ID: 7, Role: models.RoleClient}, nil)
+	st.EXPECT().GetClientByUserID(uint(7)).Return(&models.Client{ID: 3, UserID: 7}, nil)
+
+	details, err := s.GetUser(context.Background(), 7)
+	require.NoError(t, err)
+	require.NotNil(t, details.Client)
+	assert.Nil(t, details.Trainer)
+}
+
+func TestSetUserStatus(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("Disable", func(t *testing.T) {
+		s, st := newTestService(t)
+		st.EXPECT().GetUserByID(uint(7)).Return(&models.User{ID: 7, Email: "user@example.com", Status: models.UserStatusActive}, nil)
+		st.EXPECT().SetUserStatus(gomock.Any(), uint(7), models.UserStatusDisabled).Return(nil)
+
+		require.NoError(t, s.SetUserStatus(ctx, admin, 7, models.UserStatusDisabled))
+	})
+
+	t.Run("Own account", func(t *testing.T) {
+		s, st := newTestService(t)
+		st.EXPECT().GetUserByID(uint(1)).Return(&models.User{ID: 1, Email: admin, Status: models.UserStatusActive}, nil)
+
+		assert.ErrorIs(t, s.SetUserStatus(ctx, admin, 1, models.UserStatusDisabled), apperr.ErrBadRequest)
+	})
+
+	t.Run("Unknown status", func(t *testing.T) {
+		s, _ := newTestService(t)
+
+		assert.ErrorIs(t, s.SetUserStatus(ctx, admin, 7, models.UserStatusDeleted), apperr.ErrBadRequest)
+	})
+}
+
+func TestSetTrainerStatus(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("Success", func(t *testing.T) {
+		s, st := newTestService(t)
+		st.EXPECT().UpdateTrainerStatus(gomock.Any(), uint(2), models.StatusOnVacation).Return(nil)
+
+		require.NoError(t, s.SetTrainerStatus(ctx, admin, 2, models.StatusOnVacation))
+	})
+
+	t.Run("Unknown trainer", func(t *testing.T) {
+		s, st := newTestService(t)
+		st.EXPECT().UpdateTrainerStatus(gomock.Any(), uint(9), models.StatusBusy).Return(storage.ErrRecordNotFound)
+
+		assert.ErrorIs(t, s.SetTrainerStatus(ctx, admin, 9, models.StatusBusy), service.ErrTrainerNotFound)
+	})
+}
+
+func TestReassignClient(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("Success", func(t *testing.T) {
+		s, st := newTestService(t)
+		st.EXPECT().GetClientByID(uint(3)).Return(&models.Client{ID: 3, TrainerID: 1}, nil)
+		st.EXPECT().GetTrainerByID(uint(2)).Return(&models.Trainer{ID: 2, Status: models.StatusBusy}, nil)
+		st.EXPECT().UpdateTrainerID(gomock.Any(), uint(3), uint(2)).Return(nil)
+
+		require.NoError(t, s.ReassignClient(ctx, admin, 3, 2))
+	})
+
+	t.Run("Unknown trainer", func(t *testing.T) {
+		s, st := newTestService(t)
+		st.EXPECT().GetClientByID(uint(3)).Return(&models.Client{ID: 3, TrainerID: 1}, nil)
+		st.EXPECT().GetTrainerByID(uint(9)).Return(nil, storage.ErrRecordNotFound)
+
+		assert.ErrorIs(t, s.ReassignClient(ctx, admin, 3, 9), service.ErrTrainerNotFound)
+	})
+}
+
+func TestGrantAdmin(t *testing.T) {
+	ctx := context.Background()
+	user := &models.User{ID: 7, Email: "support@example.com", Role: models.RoleClient, Status: models.UserStatusActive}
+
+	t.Run("Success", func(t *testing.T) {
+		s, st := newTestService(t)
+		st.EXPECT().GetUserByEmail(user.Email).Return(user, nil)
+		st.EXPECT().GetTrainerByUserID(user.ID).Return(nil, storage.ErrRecordNotFound)
+		st.EXPECT().GetClientByUserID(user.ID).Return(nil, storage.ErrRecordNotFound)
+		st.EXPECT().SetUserRole(gomock.Any(), user.Email, models.RoleAdmin).Return(nil)
+
+		require.NoError(t, s.GrantAdmin(ctx, admin, user.Email))
+	})
+
+	t.Run("User with profile", func(t *testing.T) {
+		s, st := newTestService(t)
+		st.EXPECT().GetUserByEmail(user.Email).Return(user, nil)
+		st.EXPECT().GetTrainerByUserID(user.ID).Return(nil, storage.ErrRecordNotFound)
+		st.EXPECT().GetClientByUserID(user.ID).Return(&models.Client{ID: 3}, nil)
+
+		assert.ErrorIs(t, s.GrantAdmin(ctx, admin, user.Email), service.ErrInvalidRoleRequest)
+	})
+}
+
+func TestSaveMeasurementType(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("Success", func(t *testing.T) {
+		s, st := newTestService(t)
+		neck := models.MeasurementType{Code: "neck", Name: "Neck circumference", Unit: "cm", ValueType: models.ValueDecimal, Min: 20, Max: 80}
+		st.EXPECT().SaveMeasurementType(ctx, &neck).Return(nil)
+
+		require.NoError(t, s.SaveMeasurementType(ctx, admin, neck))
+	})
+
+	t.Run("Invalid type", func(t *testing.T) {
+		s, _ := newTestService(t)
+
+		err := s.SaveMeasurementType(ctx, admin, models.MeasurementType{Code: "Neck size", ValueType: "text", Min: 80, Max: 20})
+		require.ErrorIs(t, err, apperr.ErrValidation)
+		assert.Equal(t, []apperr.FieldError{
+			{Field: "code", Message: "must be lowercase letters, digits and underscores"},
+			{Field: "value_type", Message: "must be decimal or integer"},
+			{Field: "max", Message: "must be greater than min"},
+		}, apperr.From(err).Fields)
+	})
+}
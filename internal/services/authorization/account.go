@@ -0,0 +1,181 @@
+package userauthservice
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+
+	"ChadProgress/internal/auth_client"
+	"ChadProgress/internal/lib/apperr"
+	"ChadProgress/internal/models"
+	service "ChadProgress/internal/services"
+	"ChadProgress/storage"
+)
+
+const changeEmailTTL = time.Hour
+
+// RequestEmailChange sends confirmation link to the new email. Email is changed only when
+// the link is followed, so that account can not be moved to an address the user does not own.
+func (u *UserAuthService) RequestEmailChange(ctx context.Context, email, password, newEmail string) error {
+	const op = "services.user.account.RequestEmailChange"
+	log := u.log.With(
+		slog.String("op", op),
+	)
+
+	if !u.managesCredentials() {
+		return fmt.Errorf("%s: %w", op, service.ErrNotSupported.WithMessage("email change is not supported"))
+	}
+	if strings.EqualFold(email, newEmail) {
+		return fmt.Errorf("%s: %w", op, apperr.ErrBadRequest.WithMessage("new email is the same as current one"))
+	}
+
+	if _, err := u.checkPassword(ctx, log, email, password); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	if _, err := u.storage.GetUserByEmail(newEmail); err == nil {
+		return fmt.Errorf("%s: %w", op, service.ErrUserAlreadyExists)
+	} else if !errors.Is(err, storage.ErrRecordNotFound) {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	token, err := u.rotateEmailToken(ctx, &models.EmailToken{
+		Login:    email,
+		Purpose:  models.TokenPurposeChangeEmail,
+		NewEmail: newEmail,
+	}, changeEmailTTL)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	if err = u.notifier.SendEmailChange(ctx, newEmail, token); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	return nil
+}
+
+// ConfirmEmailChange changes email in auth service and locally. Local change failure is
+// compensated by renaming credentials back. User is signed out of every session.
+func (u *UserAuthService) ConfirmEmailChange(ctx context.Context, token string) error {
+	const op = "services.user.account.ConfirmEmailChange"
+	log := u.log.With(
+		slog.String("op", op),
+	)
+
+	if !u.managesCredentials() {
+		return fmt.Errorf("%s: %w", op, service.ErrNotSupported.WithMessage("email change is not supported"))
+	}
+
+	now := time.Now()
+	emailToken, err := consumeEmailToken(u.storage, token, models.TokenPurposeChangeEmail, now)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	email, newEmail := emailToken.Login, emailToken.NewEmail
+
+	if _, err = u.storage.GetUserByEmail(newEmail); err == nil {
+		return fmt.Errorf("%s: %w", op, service.ErrUserAlreadyExists)
+	} else if !errors.Is(err, storage.ErrRecordNotFound) {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	if err = u.authClient.ChangeLogin(ctx, email, newEmail); err != nil {
+		switch {
+		case errors.Is(err, auth_client.ErrUserAlreadyExists):
+			return fmt.Errorf("%s: %w", op, service.ErrUserAlreadyExists)
+		case errors.Is(err, auth_client.ErrUserNotFound):
+			return fmt.Errorf("%s: %w", op, service.ErrUserNotFound)
+		case errors.Is(err, auth_client.ErrNotSupported):
+			return fmt.Errorf("%s: %w", op, service.ErrNotSupported.WithMessage("email change is not supported"))
+		default:
+			return fmt.Errorf("%s: %w", op, authClientError(err))
+		}
+	}
+
+	if err = u.storage.ChangeUserEmail(ctx, email, newEmail, now); err != nil {
+		log.Error("failed to change email locally, compensating", slog.String("error", err.Error()))
+		if backErr := u.authClient.ChangeLogin(ctx, newEmail, email); backErr != nil {
+			log.Error("failed to restore login in auth service",
+				slog.String("email", email),
+				slog.String("new_email", newEmail),
+				slog.String("error", backErr.Error()),
+			)
+		}
+
+		if errors.Is(err, storage.ErrDuplicateKey) {
+			return fmt.Errorf("%s: %w", op, service.ErrUserAlreadyExists)
+		}
+		if errors.Is(err, storage.ErrRecordNotFound) {
+			return fmt.Errorf("%s: %w", op, service.ErrUserNotFound)
+		}
+
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	log.Info("email changed", slog.String("email", email), slog.String("new_email", newEmail))
+
+	return nil
+}
+
+// DeleteAccount erases or anonymizes data of user according to retention policy, removes
+// credentials from auth service and returns deletion receipt
+func (u *UserAuthService) DeleteAccount(ctx context.Context, email, password string) (*models.DeletionReceipt, error) {
+	const op = "services.user.account.DeleteAccount"
+	log := u.log.With(
+		slog.String("op", op),
+	)
+
+	// local data is not erased when credentials would be left behind
+	if !u.managesCredentials() {
+		return nil, fmt.Errorf("%s: %w", op, service.ErrNotSupported.WithMessage("account deletion is not supported"))
+	}
+
+	if _, err := u.checkPassword(ctx, log, email, password); err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	user, err := u.storage.GetUserByEmail(email)
+	if err != nil {
+		if errors.Is(err, storage.ErrRecordNotFound) {
+			return nil, fmt.Errorf("%s: %w", op, service.ErrUserNotFound)
+		}
+
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	id := make([]byte, 16)
+	if _, err = rand.Read(id); err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	receipt := &models.DeletionReceipt{
+		ID:        hex.EncodeToString(id),
+		EmailHash: hashToken(strings.ToLower(email)),
+		DeletedAt: time.Now().UTC(),
+	}
+
+	if err = u.storage.EraseAccount(ctx, user.ID, u.retention, receipt); err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	// local data is gone already, leftover credentials only block registration with the same email
+	// until they are removed with reconcile-users
+	if err = u.authClient.DeleteUser(ctx, email); err != nil && !errors.Is(err, auth_client.ErrUserNotFound) {
+		log.Error("failed to delete user from auth service", slog.String("error", err.Error()))
+		return receipt, nil
+	}
+
+	receipt.CredentialsRemoved = true
+	if err = u.storage.MarkCredentialsRemoved(receipt.ID); err != nil {
+		log.Error("failed to update deletion receipt", slog.String("error", err.Error()))
+	}
+
+	log.Info("account deleted", slog.String("receipt", receipt.ID))
+
+	return receipt, nil
+}
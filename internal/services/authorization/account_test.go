@@ -0,0 +1,181 @@
+package userauthservice
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"testing"
+	"time"
+
+	"ChadProgress/internal/auth_client"
+	"ChadProgress/internal/lib/ratelimit"
+	"ChadProgress/internal/models"
+	service "ChadProgress/internal/services"
+	"ChadProgress/storage"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const newEmail = "new@example.com"
+
+func TestRequestEmailChange(t *testing.T) {
+	ctx := context.Background()
+	loginResp := &auth_client.UserLoginResponse{Token: "token"}
+
+	t.Run("Success", func(t *testing.T) {
+		s, st, client, notifier := newTestService(t)
+		client.EXPECT().LoginUser(ctx, models.UserAuth{Login: email, Password: "secret"}).Return(loginResp, nil)
+		st.EXPECT().GetUserByEmail(newEmail).Return(nil, storage.ErrRecordNotFound)
+		expectTx(st)
+		st.EXPECT().InvalidateEmailTokens(email, models.TokenPurposeChangeEmail).Return(nil)
+		st.EXPECT().SaveEmailToken(gomock.Any()).DoAndReturn(func(token *models.EmailToken) error {
+			assert.Equal(t, email, token.Login)
+			assert.Equal(t, newEmail, token.NewEmail)
+			return nil
+		})
+		notifier.EXPECT().SendEmailChange(ctx, newEmail, gomock.Any()).Return(nil)
+
+		require.NoError(t, s.RequestEmailChange(ctx, email, "secret", newEmail))
+	})
+
+	t.Run("Wrong password", func(t *testing.T) {
+		s, _, client, _ := newTestService(t)
+		client.EXPECT().LoginUser(ctx, gomock.Any()).Return(nil, auth_client.ErrInvalidCredentials)
+
+		assert.ErrorIs(t, s.RequestEmailChange(ctx, email, "wrong", newEmail), service.ErrInvalidCredentials)
+	})
+
+	t.Run("Email is taken", func(t *testing.T) {
+		s, st, client, _ := newTestService(t)
+		client.EXPECT().LoginUser(ctx, gomock.Any()).Return(loginResp, nil)
+		st.EXPECT().GetUserByEmail(newEmail).Return(&models.User{Email: newEmail}, nil)
+
+		assert.ErrorIs(t, s.RequestEmailChange(ctx, email, "secret", newEmail), service.ErrUserAlreadyExists)
+	})
+}
+
+func TestConfirmEmailChange(t *testing.T) {
+	ctx := context.Background()
+	changeToken := &models.EmailToken{Login: email, Purpose: models.TokenPurposeChangeEmail, NewEmail: newEmail}
+
+	t.Run("Success", func(t *testing.T) {
+		s, st, client, _ := newTestService(t)
+		st.EXPECT().ConsumeEmailToken(hashToken("token"), models.TokenPurposeChangeEmail, gomock.Any()).Return(changeToken, nil)
+		st.EXPECT().GetUserByEmail(newEmail).Return(nil, storage.ErrRecordNotFound)
+		client.EXPECT().ChangeLogin(ctx, email, newEmail).Return(nil)
+		st.EXPECT().ChangeUserEmail(gomock.Any(), email, newEmail, gomock.Any()).Return(nil)
+
+		require.NoError(t, s.ConfirmEmailChange(ctx, "token"))
+	})
+
+	t.Run("Local failure renames credentials back", func(t *testing.T) {
+		s, st, client, _ := newTestService(t)
+		st.EXPECT().ConsumeEmailToken(hashToken("token"), models.TokenPurposeChangeEmail, gomock.Any()).Return(changeToken, nil)
+		st.EXPECT().GetUserByEmail(newEmail).Return(nil, storage.ErrRecordNotFound)
+		client.EXPECT().ChangeLogin(ctx, email, newEmail).Return(nil)
+		st.EXPECT().ChangeUserEmail(gomock.Any(), email, newEmail, gomock.Any()).Return(storage.ErrDuplicateKey)
+		client.EXPECT().ChangeLogin(ctx, newEmail, email).Return(nil)
+
+		assert.ErrorIs(t, s.ConfirmEmailChange(ctx, "token"), service.ErrUserAlreadyExists)
+	})
+
+	t.Run("Auth service is down", func(t *testing.T) {
+		s, st, client, _ := newTestService(t)
+		st.EXPECT().ConsumeEmailToken(hashToken("token"), models.TokenPurposeChangeEmail, gomock.Any()).Return(changeToken, nil)
+		st.EXPECT().GetUserByEmail(newEmail).Return(nil, storage.ErrRecordNotFound)
+		client.EXPECT().ChangeLogin(ctx, email, newEmail).Return(auth_client.ErrClientUnavailable)
+
+		assert.ErrorIs(t, s.ConfirmEmailChange(ctx, "token"), service.ErrAuthUnavailable)
+	})
+}
+
+func TestDeleteAccount(t *testing.T) {
+	ctx := context.Background()
+	loginResp := &auth_client.UserLoginResponse{Token: "token"}
+
+	t.Run("Success", func(t *testing.T) {
+		s, st, client, _ := newTestService(t)
+		client.EXPECT().LoginUser(ctx, gomock.Any()).Return(loginResp, nil)
+		st.EXPECT().GetUserByEmail(email).Return(&models.User{ID: 7, Email: email}, nil)
+		st.EXPECT().EraseAccount(gomock.Any(), uint(7), testRetention, gomock.Any()).DoAndReturn(
+			func(_ context.Context, _ uint, policy models.RetentionPolicy, receipt *models.DeletionReceipt) error {
+				receipt.Policy = policy
+				receipt.Erased = map[string]int64{"metrics": 3}
+				return nil
+			})
+		client.EXPECT().DeleteUser(ctx, email).Return(nil)
+		st.EXPECT().MarkCredentialsRemoved(gomock.Any()).Return(nil)
+
+		receipt, err := s.DeleteAccount(ctx, email, "secret")
+		require.NoError(t, err)
+		assert.NotEmpty(t, receipt.ID)
+		assert.Equal(t, hashToken(email), receipt.EmailHash)
+		assert.Equal(t, int64(3), receipt.Erased["metrics"])
+		assert.True(t, receipt.CredentialsRemoved)
+	})
+
+	t.Run("Auth service failure does not undo erasure", func(t *testing.T) {
+		s, st, client, _ := newTestService(t)
+		client.EXPECT().LoginUser(ctx, gomock.Any()).Return(loginResp, nil)
+		st.EXPECT().GetUserByEmail(email).Return(&models.User{ID: 7, Email: email}, nil)
+		st.EXPECT().EraseAccount(gomock.Any(), uint(7), testRetention, gomock.Any()).Return(nil)
+		client.EXPECT().DeleteUser(ctx, email).Return(auth_client.ErrClientUnavailable)
+
+		receipt, err := s.DeleteAccount(ctx, email, "secret")
+		require.NoError(t, err)
+		assert.False(t, receipt.CredentialsRemoved)
+	})
+
+	t.Run("Erasure failure keeps credentials", func(t *testing.T) {
+		s, st, client, _ := newTestService(t)
+		client.EXPECT().LoginUser(ctx, gomock.Any()).Return(loginResp, nil)
+		st.EXPECT().GetUserByEmail(email).Return(&models.User{ID: 7, Email: email}, nil)
+		st.EXPECT().EraseAccount(gomock.Any(), uint(7), testRetention, gomock.Any()).Return(errors.New("connection reset"))
+
+		_, err := s.DeleteAccount(ctx, email, "secret")
+		assert.Error(t, err)
+	})
+}
+
+// remoteClient is auth service which can neither delete nor rename credentials
+type remoteClient struct {
+	*MockAuthServiceClient
+}
+
+func (remoteClient) ManagesCredentials() bool {
+	return false
+}
+
+func TestUnsupportedCredentialChanges(t *testing.T) {
+	ctx := context.Background()
+	ctrl := gomock.NewController(t)
+	st := NewMockStorage(ctrl)
+	client := remoteClient{MockAuthServiceClient: NewMockAuthServiceClient(ctrl)}
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	s := NewUserAuthService(st, client, ratelimit.NewLockout(ratelimit.LockoutPolicy{}), NewMockNotifier(ctrl), testRetention, logger)
+
+	// nothing is checked, erased or sent
+	_, err := s.DeleteAccount(ctx, email, "secret")
+	assert.ErrorIs(t, err, service.ErrNotSupported)
+	assert.ErrorIs(t, s.RequestEmailChange(ctx, email, "secret", newEmail), service.ErrNotSupported)
+	assert.ErrorIs(t, s.ConfirmEmailChange(ctx, "token"), service.ErrNotSupported)
+
+	t.Run("Pending users are removed locally", func(t *testing.T) {
+		before := time.Now()
+		st.EXPECT().GetPendingUsers(before).Return([]models.User{{ID: 1, Email: "stuck@example.com", Status: models.UserStatusPending}}, nil)
+		client.EXPECT().DeleteUser(ctx, "stuck@example.com").Return(auth_client.ErrNotSupported)
+		st.EXPECT().DeletePendingUser(gomock.Any(), uint(1)).Return(nil)
+
+		report, err := s.ReconcileUsers(ctx, before, nil)
+		require.NoError(t, err)
+		assert.Equal(t, []string{"stuck@example.com"}, report.Removed)
+	})
+
+	t.Run("Orphans are refused before any change", func(t *testing.T) {
+		_, err := s.ReconcileUsers(ctx, time.Now(), []string{"orphan@example.com"})
+		assert.ErrorIs(t, err, service.ErrNotSupported)
+	})
+}
@@ -0,0 +1,227 @@
+package userauthservice
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+
+	"ChadProgress/internal/auth_client"
+	"ChadProgress/internal/models"
+	service "ChadProgress/internal/services"
+	"ChadProgress/storage"
+)
+
+const (
+	verifyEmailTTL   = 48 * time.Hour
+	resetPasswordTTL = time.Hour
+)
+
+// sendVerification issues email verification token and sends it to user
+func (u *UserAuthService) sendVerification(ctx context.Context, email string) error {
+	token, err := u.issueEmailToken(email, models.TokenPurposeVerifyEmail, verifyEmailTTL)
+	if err != nil {
+		return err
+	}
+
+	return u.notifier.SendVerification(ctx, email, token)
+}
+
+// ResendVerification sends new verification email. Links sent earlier stop working.
+func (u *UserAuthService) ResendVerification(ctx context.Context, email string) error {
+	const op = "services.user.email.ResendVerification"
+
+	user, err := u.storage.GetUserByEmail(email)
+	if err != nil {
+		if errors.Is(err, storage.ErrRecordNotFound) {
+			return fmt.Errorf("%s: %w", op, service.ErrUserNotFound)
+		}
+
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	if user.EmailVerified() {
+		return nil
+	}
+
+	token, err := u.rotateEmailToken(ctx, &models.EmailToken{Login: email, Purpose: models.TokenPurposeVerifyEmail}, verifyEmailTTL)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	if err = u.notifier.SendVerification(ctx, email, token); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	return nil
+}
+
+// VerifyEmail confirms email of the user the token was sent to. Token is used up only if email
+// is confirmed, so the link can be followed again after a failure.
+func (u *UserAuthService) VerifyEmail(ctx context.Context, token string) error {
+	const op = "services.user.email.VerifyEmail"
+
+	now := time.Now()
+	err := u.storage.WithTx(ctx, func(tx Storage) error {
+		emailToken, err := consumeEmailToken(tx, token, models.TokenPurposeVerifyEmail, now)
+		if err != nil {
+			return err
+		}
+
+		if err = tx.MarkEmailVerified(ctx, emailToken.Login, now); err != nil {
+			if errors.Is(err, storage.ErrRecordNotFound) {
+				return service.ErrUserNotFound
+			}
+
+			return err
+		}
+
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	return nil
+}
+
+// RequestPasswordReset sends password reset link to email. Unknown emails are not reported,
+// so that the endpoint can not be used to find out who is registered.
+func (u *UserAuthService) RequestPasswordReset(ctx context.Context, email string) error {
+	const op = "services.user.email.RequestPasswordReset"
+	log := u.log.With(
+		slog.String("op", op),
+	)
+
+	if _, ok := u.authClient.(PasswordSetter); !ok {
+		return fmt.Errorf("%s: %w", op, service.ErrNotSupported.WithMessage("password reset is not supported"))
+	}
+
+	user, err := u.storage.GetUserByEmail(email)
+	if err != nil {
+		if errors.Is(err, storage.ErrRecordNotFound) {
+			log.Info("password reset requested for unknown email")
+			return nil
+		}
+
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	if user.Status != models.UserStatusActive {
+		log.Info("password reset requested for pending user")
+		return nil
+	}
+
+	token, err := u.rotateEmailToken(ctx, &models.EmailToken{Login: email, Purpose: models.TokenPurposeResetPassword}, resetPasswordTTL)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	if err = u.notifier.SendPasswordReset(ctx, email, token); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	return nil
+}
+
+// ResetPassword sets new password using token from reset email and signs user out everywhere. Token is
+// used up only after the password is set, so the link keeps working if auth provider fails or rejects it.
+func (u *UserAuthService) ResetPassword(ctx context.Context, token, password string) error {
+	const op = "services.user.email.ResetPassword"
+	log := u.log.With(
+		slog.String("op", op),
+	)
+
+	setter, ok := u.authClient.(PasswordSetter)
+	if !ok {
+		return fmt.Errorf("%s: %w", op, service.ErrNotSupported.WithMessage("password reset is not supported"))
+	}
+
+	now := time.Now()
+	emailToken, err := u.storage.GetEmailToken(hashToken(token), models.TokenPurposeResetPassword, now)
+	if err != nil {
+		if errors.Is(err, storage.ErrRecordNotFound) {
+			return fmt.Errorf("%s: %w", op, service.ErrInvalidEmailToken)
+		}
+
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	login := emailToken.Login
+
+	if err = setter.SetPassword(ctx, login, password); err != nil {
+		if errors.Is(err, auth_client.ErrUserNotFound) {
+			return fmt.Errorf("%s: %w", op, service.ErrUserNotFound)
+		}
+
+		return fmt.Errorf("%s: %w", op, authClientError(err))
+	}
+
+	if _, err = consumeEmailToken(u.storage, token, models.TokenPurposeResetPassword, now); err != nil {
+		// concurrent reset with the same link has used it, the password was set by its holder anyway
+		log.Warn("failed to use reset token", slog.String("error", err.Error()))
+	}
+
+	if _, err = u.storage.RevokeSessions(login, nil, nil); err != nil {
+		log.Error("failed to revoke sessions", slog.String("error", err.Error()))
+	}
+	u.loginGuard.Reset(ctx, strings.ToLower(login))
+
+	// the link was delivered to the mailbox, so it is confirmed as well
+	if err = u.storage.MarkEmailVerified(ctx, login, now); err != nil {
+		log.Error("failed to mark email verified", slog.String("error", err.Error()))
+	}
+
+	return nil
+}
+
+func (u *UserAuthService) issueEmailToken(login, purpose string, ttl time.Duration) (string, error) {
+	return saveEmailToken(u.storage, &models.EmailToken{Login: login, Purpose: purpose}, ttl)
+}
+
+// rotateEmailToken replaces tokens of the same purpose sent earlier with a new one. Both are done in
+// one transaction, so that the user is never left without a working link.
+func (u *UserAuthService) rotateEmailToken(ctx context.Context, emailToken *models.EmailToken, ttl time.Duration) (string, error) {
+	var token string
+	err := u.storage.WithTx(ctx, func(tx Storage) error {
+		if err := tx.InvalidateEmailTokens(emailToken.Login, emailToken.Purpose); err != nil {
+			return err
+		}
+
+		var err error
+		token, err = saveEmailToken(tx, emailToken, ttl)
+
+		return err
+	})
+
+	return token, err
+}
+
+// saveEmailToken generates token, stores its hash in emailToken and returns the token
+func saveEmailToken(st Storage, emailToken *models.EmailToken, ttl time.Duration) (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	token := base64.RawURLEncoding.EncodeToString(b)
+
+	emailToken.TokenHash = hashToken(token)
+	emailToken.ExpiresAt = time.Now().Add(ttl)
+	if err := st.SaveEmailToken(emailToken); err != nil {
+		return "", err
+	}
+
+	return token, nil
+}
+
+func consumeEmailToken(st Storage, token, purpose string, now time.Time) (*models.EmailToken, error) {
+	emailToken, err := st.ConsumeEmailToken(hashToken(token), purpose, now)
+	if err != nil {
+		if errors.Is(err, storage.ErrRecordNotFound) {
+			return nil, service.ErrInvalidEmailToken
+		}
+
+		return nil, err
+	}
+
+	return emailToken, nil
+}
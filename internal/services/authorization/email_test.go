@@ -0,0 +1,151 @@
+package userauthservice
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"ChadProgress/internal/auth_client"
+	"ChadProgress/internal/models"
+	service "ChadProgress/internal/services"
+	"ChadProgress/storage"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestVerifyEmail(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("Success", func(t *testing.T) {
+		s, st, _, _ := newTestService(t)
+		expectTx(st)
+		st.EXPECT().ConsumeEmailToken(hashToken("token"), models.TokenPurposeVerifyEmail, gomock.Any()).
+			Return(&models.EmailToken{Login: email, Purpose: models.TokenPurposeVerifyEmail}, nil)
+		st.EXPECT().MarkEmailVerified(gomock.Any(), email, gomock.Any()).Return(nil)
+
+		require.NoError(t, s.VerifyEmail(ctx, "token"))
+	})
+
+	t.Run("Token is kept when email is not confirmed", func(t *testing.T) {
+		s, st, _, _ := newTestService(t)
+		dbErr := errors.New("connection reset")
+		var txErr error
+		st.EXPECT().WithTx(gomock.Any(), gomock.Any()).DoAndReturn(func(_ context.Context, fn func(Storage) error) error {
+			txErr = fn(st)
+			return txErr
+		})
+		st.EXPECT().ConsumeEmailToken(hashToken("token"), models.TokenPurposeVerifyEmail, gomock.Any()).
+			Return(&models.EmailToken{Login: email, Purpose: models.TokenPurposeVerifyEmail}, nil)
+		st.EXPECT().MarkEmailVerified(gomock.Any(), email, gomock.Any()).Return(dbErr)
+
+		assert.ErrorIs(t, s.VerifyEmail(ctx, "token"), dbErr)
+		// error returned from the transaction rolls back consumption of the token
+		assert.ErrorIs(t, txErr, dbErr)
+	})
+
+	t.Run("Used or expired token", func(t *testing.T) {
+		s, st, _, _ := newTestService(t)
+		expectTx(st)
+		st.EXPECT().ConsumeEmailToken(hashToken("token"), models.TokenPurposeVerifyEmail, gomock.Any()).
+			Return(nil, storage.ErrRecordNotFound)
+
+		assert.ErrorIs(t, s.VerifyEmail(ctx, "token"), service.ErrInvalidEmailToken)
+	})
+}
+
+func TestResendVerification(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("Already verified", func(t *testing.T) {
+		s, st, _, _ := newTestService(t)
+		verifiedAt := time.Now()
+		st.EXPECT().GetUserByEmail(email).Return(&models.User{Email: email, EmailVerifiedAt: &verifiedAt}, nil)
+
+		require.NoError(t, s.ResendVerification(ctx, email))
+	})
+
+	t.Run("Previous links are invalidated", func(t *testing.T) {
+		s, st, _, notifier := newTestService(t)
+		st.EXPECT().GetUserByEmail(email).Return(&models.User{Email: email}, nil)
+		expectTx(st)
+		st.EXPECT().InvalidateEmailTokens(email, models.TokenPurposeVerifyEmail).Return(nil)
+		st.EXPECT().SaveEmailToken(gomock.Any()).Return(nil)
+		notifier.EXPECT().SendVerification(ctx, email, gomock.Any()).Return(nil)
+
+		require.NoError(t, s.ResendVerification(ctx, email))
+	})
+}
+
+func TestRequestPasswordReset(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("Provider without password reset", func(t *testing.T) {
+		s, _, _, _ := newTestService(t)
+
+		assert.ErrorIs(t, s.RequestPasswordReset(ctx, email), service.ErrNotSupported)
+	})
+
+	t.Run("Unknown email is not reported", func(t *testing.T) {
+		s, st, _, _ := newExtendedService(t)
+		st.EXPECT().GetUserByEmail(email).Return(nil, storage.ErrRecordNotFound)
+
+		require.NoError(t, s.RequestPasswordReset(ctx, email))
+	})
+
+	t.Run("Token is stored hashed and sent", func(t *testing.T) {
+		s, st, _, notifier := newExtendedService(t)
+		var saved *models.EmailToken
+		st.EXPECT().GetUserByEmail(email).Return(&models.User{Email: email, Status: models.UserStatusActive}, nil)
+		expectTx(st)
+		st.EXPECT().InvalidateEmailTokens(email, models.TokenPurposeResetPassword).Return(nil)
+		st.EXPECT().SaveEmailToken(gomock.Any()).DoAndReturn(func(token *models.EmailToken) error {
+			saved = token
+			return nil
+		})
+		notifier.EXPECT().SendPasswordReset(ctx, email, gomock.Any()).DoAndReturn(func(_ context.Context, _, token string) error {
+			assert.Equal(t, hashToken(token), saved.TokenHash)
+			return nil
+		})
+
+		require.NoError(t, s.RequestPasswordReset(ctx, email))
+		assert.WithinDuration(t, time.Now().Add(resetPasswordTTL), saved.ExpiresAt, time.Minute)
+	})
+}
+
+func TestResetPassword(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("Success", func(t *testing.T) {
+		s, st, client, _ := newExtendedService(t)
+		resetToken := &models.EmailToken{Login: email, Purpose: models.TokenPurposeResetPassword}
+		st.EXPECT().GetEmailToken(hashToken("token"), models.TokenPurposeResetPassword, gomock.Any()).Return(resetToken, nil)
+		st.EXPECT().ConsumeEmailToken(hashToken("token"), models.TokenPurposeResetPassword, gomock.Any()).Return(resetToken, nil)
+		st.EXPECT().RevokeSessions(email, nil, nil).Return(int64(2), nil)
+		st.EXPECT().MarkEmailVerified(gomock.Any(), email, gomock.Any()).Return(nil)
+
+		require.NoError(t, s.ResetPassword(ctx, "token", "new-secret"))
+		assert.Equal(t, "new-secret", client.passwords[email])
+	})
+
+	t.Run("Reused token", func(t *testing.T) {
+		s, st, client, _ := newExtendedService(t)
+		st.EXPECT().GetEmailToken(hashToken("token"), models.TokenPurposeResetPassword, gomock.Any()).
+			Return(nil, storage.ErrRecordNotFound)
+
+		assert.ErrorIs(t, s.ResetPassword(ctx, "token", "new-secret"), service.ErrInvalidEmailToken)
+		assert.Empty(t, client.passwords)
+	})
+
+	t.Run("Failure of auth provider keeps the link", func(t *testing.T) {
+		s, st, client, _ := newExtendedService(t)
+		client.setPasswordErr = auth_client.ErrClientUnavailable
+		st.EXPECT().GetEmailToken(hashToken("token"), models.TokenPurposeResetPassword, gomock.Any()).
+			Return(&models.EmailToken{Login: email, Purpose: models.TokenPurposeResetPassword}, nil)
+
+		// ConsumeEmailToken is not expected
+		assert.ErrorIs(t, s.ResetPassword(ctx, "token", "new-secret"), service.ErrAuthUnavailable)
+	})
+}
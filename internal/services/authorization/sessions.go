@@ -0,0 +1,217 @@
+package userauthservice
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+	"unicode/utf8"
+
+	"ChadProgress/internal/auth_client"
+	"ChadProgress/internal/models"
+	service "ChadProgress/internal/services"
+	"ChadProgress/storage"
+)
+
+// lastSeenPrecision limits how often session activity is written to storage
+const lastSeenPrecision = time.Minute
+
+// startSession records device the user signed in from. Tokens without session are rejected by
+// TouchSession, so failure to save it fails sign in and the tokens are revoked if provider supports it.
+func (u *UserAuthService) startSession(ctx context.Context, login string, tokens models.TokenPair, client models.ClientInfo) error {
+	const op = "services.user.sessions.startSession"
+
+	id := make([]byte, 16)
+	if _, err := rand.Read(id); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	session := &models.Session{
+		ID:              hex.EncodeToString(id),
+		Login:           login,
+		AccessTokenHash: hashToken(tokens.AccessToken),
+		UserAgent:       truncate(client.UserAgent, 255),
+		IP:              client.IP,
+		LastSeenAt:      time.Now(),
+	}
+	if tokens.RefreshToken != "" {
+		session.RefreshTokenHash = hashToken(tokens.RefreshToken)
+	}
+
+	if err := u.storage.SaveSession(session); err != nil {
+		u.log.Error("failed to save session", slog.String("op", op), slog.String("error", err.Error()))
+		if revoker, ok := u.authClient.(TokenRevoker); ok {
+			if revokeErr := revoker.Logout(context.WithoutCancel(ctx), tokens.AccessToken, tokens.RefreshToken); revokeErr != nil {
+				u.log.Error("failed to revoke tokens without session", slog.String("op", op), slog.String("error", revokeErr.Error()))
+			}
+		}
+
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	return nil
+}
+
+// TouchSession checks that session of already validated token is not revoked, updates its
+// last activity and returns its id. Token without session is rejected: its session was rotated by
+// refresh or it was issued before sessions were tracked.
+func (u *UserAuthService) TouchSession(ctx context.Context, login, token string, client models.ClientInfo) (string, error) {
+	const op = "services.user.sessions.TouchSession"
+
+	session, err := u.storage.GetSessionByAccessToken(hashToken(token))
+	if errors.Is(err, storage.ErrRecordNotFound) {
+		return "", fmt.Errorf("%s: %w", op, service.ErrInvalidToken.WithMessage("session not found"))
+	}
+	if err != nil {
+		return "", fmt.Errorf("%s: %w", op, err)
+	}
+
+	if session.RevokedAt != nil || session.Login != login {
+		return "", fmt.Errorf("%s: %w", op, service.ErrInvalidToken.WithMessage("session is revoked"))
+	}
+
+	now := time.Now()
+	if now.Sub(session.LastSeenAt) >= lastSeenPrecision || session.IP != client.IP {
+		if err = u.storage.TouchSession(session.ID, client.IP, now); err != nil {
+			u.log.Error("failed to update session", slog.String("op", op), slog.String("error", err.Error()))
+		}
+	}
+
+	return session.ID, nil
+}
+
+// Refresh exchanges refresh token of active session for new tokens
+func (u *UserAuthService) Refresh(ctx context.Context, refreshToken string, client models.ClientInfo) (models.TokenPair, error) {
+	const op = "services.user.sessions.Refresh"
+
+	refresher, ok := u.authClient.(TokenRefresher)
+	if !ok {
+		return models.TokenPair{}, fmt.Errorf("%s: %w", op, service.ErrNotSupported.WithMessage("token refresh is not supported"))
+	}
+
+	session, err := u.storage.GetSessionByRefreshToken(hashToken(refreshToken))
+	if err != nil {
+		if errors.Is(err, storage.ErrRecordNotFound) {
+			return models.TokenPair{}, fmt.Errorf("%s: %w", op, service.ErrInvalidToken)
+		}
+
+		return models.TokenPair{}, fmt.Errorf("%s: %w", op, err)
+	}
+	if session.RevokedAt != nil {
+		return models.TokenPair{}, fmt.Errorf("%s: %w", op, service.ErrInvalidToken.WithMessage("session is revoked"))
+	}
+
+	resp, err := refresher.Refresh(ctx, refreshToken)
+	if err != nil {
+		if errors.Is(err, auth_client.ErrInvalidToken) {
+			return models.TokenPair{}, fmt.Errorf("%s: %w", op, service.ErrInvalidToken)
+		}
+
+		return models.TokenPair{}, fmt.Errorf("%s: %w", op, authClientError(err))
+	}
+
+	tokens := models.TokenPair{AccessToken: resp.Token, RefreshToken: resp.RefreshToken}
+	err = u.storage.UpdateSessionTokens(session.ID, hashToken(tokens.AccessToken), hashToken(tokens.RefreshToken), client)
+	if err != nil {
+		if errors.Is(err, storage.ErrRecordNotFound) {
+			return models.TokenPair{}, fmt.Errorf("%s: %w", op, service.ErrInvalidToken.WithMessage("session is revoked"))
+		}
+
+		return models.TokenPair{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return tokens, nil
+}
+
+// Logout revokes session of access token and, if provider supports it, the tokens themselves
+func (u *UserAuthService) Logout(ctx context.Context, accessToken, refreshToken string) error {
+	const op = "services.user.sessions.Logout"
+
+	login, err := u.authClient.ValidateToken(ctx, accessToken)
+	if err != nil {
+		if errors.Is(err, auth_client.ErrClientUnavailable) {
+			return fmt.Errorf("%s: %w", op, authClientError(err))
+		}
+
+		return fmt.Errorf("%s: %w", op, service.ErrInvalidToken)
+	}
+
+	session, err := u.storage.GetSessionByAccessToken(hashToken(accessToken))
+	if err != nil && !errors.Is(err, storage.ErrRecordNotFound) {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	if session != nil {
+		if _, err = u.storage.RevokeSessions(login, []string{session.ID}, nil); err != nil {
+			return fmt.Errorf("%s: %w", op, err)
+		}
+	}
+
+	if revoker, ok := u.authClient.(TokenRevoker); ok {
+		if err = revoker.Logout(ctx, accessToken, refreshToken); err != nil {
+			return fmt.Errorf("%s: %w", op, authClientError(err))
+		}
+	}
+
+	return nil
+}
+
+// Sessions lists active sessions of user
+func (u *UserAuthService) Sessions(ctx context.Context, email string) ([]models.Session, error) {
+	const op = "services.user.sessions.Sessions"
+
+	sessions, err := u.storage.GetSessions(email)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return sessions, nil
+}
+
+// RevokeSession signs user out of one of their sessions
+func (u *UserAuthService) RevokeSession(ctx context.Context, email, sessionID string) error {
+	const op = "services.user.sessions.RevokeSession"
+
+	n, err := u.storage.RevokeSessions(email, []string{sessionID}, nil)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	if n == 0 {
+		return fmt.Errorf("%s: %w", op, service.ErrSessionNotFound)
+	}
+
+	return nil
+}
+
+// RevokeOtherSessions signs user out everywhere except the current session
+func (u *UserAuthService) RevokeOtherSessions(ctx context.Context, email, currentID string) (int64, error) {
+	const op = "services.user.sessions.RevokeOtherSessions"
+
+	n, err := u.storage.RevokeSessions(email, nil, []string{currentID})
+	if err != nil {
+		return 0, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return n, nil
+}
+
+func hashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+
+	return hex.EncodeToString(sum[:])
+}
+
+// truncate cuts s to at most n bytes without splitting a multi-byte character
+func truncate(s string, n int) string {
+	if len(s) <= n {
+		return s
+	}
+	for n > 0 && !utf8.RuneStart(s[n]) {
+		n--
+	}
+
+	return s[:n]
+}
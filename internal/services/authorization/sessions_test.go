@@ -0,0 +1,268 @@
+package userauthservice
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"testing"
+	"time"
+	"unicode/utf8"
+
+	"ChadProgress/internal/auth_client"
+	"ChadProgress/internal/lib/ratelimit"
+	"ChadProgress/internal/models"
+	service "ChadProgress/internal/services"
+	"ChadProgress/storage"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// extendedClient is auth provider which supports refresh tokens, logout and password reset
+type extendedClient struct {
+	*MockAuthServiceClient
+	refresh   func(refreshToken string) (*auth_client.UserLoginResponse, error)
+	revoked   []string
+	passwords map[string]string
+	// setPasswordErr is returned by SetPassword when set
+	setPasswordErr error
+}
+
+func (c *extendedClient) Refresh(_ context.Context, refreshToken string) (*auth_client.UserLoginResponse, error) {
+	return c.refresh(refreshToken)
+}
+
+func (c *extendedClient) Logout(_ context.Context, accessToken, _ string) error {
+	c.revoked = append(c.revoked, accessToken)
+	return nil
+}
+
+func (c *extendedClient) SetPassword(_ context.Context, login, password string) error {
+	if c.setPasswordErr != nil {
+		return c.setPasswordErr
+	}
+	c.passwords[login] = password
+	return nil
+}
+
+func newExtendedService(t *testing.T) (*UserAuthService, *MockStorage, *extendedClient, *MockNotifier) {
+	ctrl := gomock.NewController(t)
+	st := NewMockStorage(ctrl)
+	client := &extendedClient{MockAuthServiceClient: NewMockAuthServiceClient(ctrl), passwords: make(map[string]string)}
+	notifier := NewMockNotifier(ctrl)
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+
+	return NewUserAuthService(st, client, ratelimit.NewLockout(ratelimit.LockoutPolicy{}), notifier, testRetention, logger), st, client, notifier
+}
+
+func TestTouchSession(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("Unknown token is rejected", func(t *testing.T) {
+		s, st, _, _ := newTestService(t)
+		st.EXPECT().GetSessionByAccessToken(hashToken("token")).Return(nil, storage.ErrRecordNotFound)
+
+		_, err := s.TouchSession(ctx, email, "token", device)
+		assert.ErrorIs(t, err, service.ErrInvalidToken)
+	})
+
+	t.Run("Recent activity is not written again", func(t *testing.T) {
+		s, st, _, _ := newTestService(t)
+		st.EXPECT().GetSessionByAccessToken(hashToken("token")).
+			Return(&models.Session{ID: "id", Login: email, IP: device.IP, LastSeenAt: time.Now()}, nil)
+
+		id, err := s.TouchSession(ctx, email, "token", device)
+		require.NoError(t, err)
+		assert.Equal(t, "id", id)
+	})
+
+	t.Run("New address is recorded", func(t *testing.T) {
+		s, st, _, _ := newTestService(t)
+		st.EXPECT().GetSessionByAccessToken(hashToken("token")).
+			Return(&models.Session{ID: "id", Login: email, IP: "10.0.0.2", LastSeenAt: time.Now()}, nil)
+		st.EXPECT().TouchSession("id", device.IP, gomock.Any()).Return(nil)
+
+		_, err := s.TouchSession(ctx, email, "token", device)
+		require.NoError(t, err)
+	})
+
+	t.Run("Revoked session", func(t *testing.T) {
+		s, st, _, _ := newTestService(t)
+		revokedAt := time.Now()
+		st.EXPECT().GetSessionByAccessToken(hashToken("token")).
+			Return(&models.Session{ID: "id", Login: email, RevokedAt: &revokedAt}, nil)
+
+		_, err := s.TouchSession(ctx, email, "token", device)
+		assert.ErrorIs(t, err, service.ErrInvalidToken)
+	})
+}
+
+func TestRefresh(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("Provider without refresh tokens", func(t *testing.T) {
+		s, _, _, _ := newTestService(t)
+
+		_, err := s.Refresh(ctx, "refresh", device)
+		assert.ErrorIs(t, err, service.ErrNotSupported)
+	})
+
+	t.Run("Tokens of session are rotated", func(t *testing.T) {
+		s, st, client, _ := newExtendedService(t)
+		client.refresh = func(string) (*auth_client.UserLoginResponse, error) {
+			return &auth_client.UserLoginResponse{Token: "new-access", RefreshToken: "new-refresh"}, nil
+		}
+		st.EXPECT().GetSessionByRefreshToken(hashToken("refresh")).Return(&models.Session{ID: "id", Login: email}, nil)
+		st.EXPECT().UpdateSessionTokens("id", hashToken("new-access"), hashToken("new-refresh"), device).Return(nil)
+
+		tokens, err := s.Refresh(ctx, "refresh", device)
+		require.NoError(t, err)
+		assert.Equal(t, models.TokenPair{AccessToken: "new-access", RefreshToken: "new-refresh"}, tokens)
+	})
+
+	t.Run("Revoked session", func(t *testing.T) {
+		s, st, _, _ := newExtendedService(t)
+		revokedAt := time.Now()
+		st.EXPECT().GetSessionByRefreshToken(hashToken("refresh")).
+			Return(&models.Session{ID: "id", Login: email, RevokedAt: &revokedAt}, nil)
+
+		_, err := s.Refresh(ctx, "refresh", device)
+		assert.ErrorIs(t, err, service.ErrInvalidToken)
+	})
+
+	t.Run("Reused refresh token", func(t *testing.T) {
+		s, st, client, _ := newExtendedService(t)
+		client.refresh = func(string) (*auth_client.UserLoginResponse, error) {
+			return nil, auth_client.ErrInvalidToken
+		}
+		st.EXPECT().GetSessionByRefreshToken(hashToken("refresh")).Return(&models.Session{ID: "id", Login: email}, nil)
+
+		_, err := s.Refresh(ctx, "refresh", device)
+		assert.ErrorIs(t, err, service.ErrInvalidToken)
+	})
+}
+
+// sessionTable backs mocked session storage, so that a token can be followed through refresh and revocation
+type sessionTable map[string]*models.Session
+
+func (tbl sessionTable) expect(st *MockStorage) {
+	st.EXPECT().SaveSession(gomock.Any()).AnyTimes().DoAndReturn(func(session *models.Session) error {
+		tbl[session.ID] = session
+		return nil
+	})
+	st.EXPECT().GetSessionByAccessToken(gomock.Any()).AnyTimes().DoAndReturn(func(hash string) (*models.Session, error) {
+		for _, session := range tbl {
+			if session.AccessTokenHash == hash {
+				return session, nil
+			}
+		}
+		return nil, storage.ErrRecordNotFound
+	})
+	st.EXPECT().GetSessionByRefreshToken(gomock.Any()).AnyTimes().DoAndReturn(func(hash string) (*models.Session, error) {
+		for _, session := range tbl {
+			if session.RefreshTokenHash == hash {
+				return session, nil
+			}
+		}
+		return nil, storage.ErrRecordNotFound
+	})
+	st.EXPECT().UpdateSessionTokens(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).AnyTimes().
+		DoAndReturn(func(id, accessHash, refreshHash string, _ models.ClientInfo) error {
+			tbl[id].AccessTokenHash, tbl[id].RefreshTokenHash = accessHash, refreshHash
+			return nil
+		})
+	st.EXPECT().RevokeSessions(email, gomock.Any(), gomock.Any()).AnyTimes().DoAndReturn(func(_ string, ids, _ []string) (int64, error) {
+		now := time.Now()
+		for _, id := range ids {
+			tbl[id].RevokedAt = &now
+		}
+		return int64(len(ids)), nil
+	})
+	st.EXPECT().TouchSession(gomock.Any(), gomock.Any(), gomock.Any()).AnyTimes().Return(nil)
+}
+
+func TestAccessTokenStopsWorking(t *testing.T) {
+	ctx := context.Background()
+	loginResp := &auth_client.UserLoginResponse{Token: "access", RefreshToken: "refresh"}
+
+	t.Run("After refresh", func(t *testing.T) {
+		s, st, client, _ := newExtendedService(t)
+		sessionTable{}.expect(st)
+		client.EXPECT().LoginUser(ctx, gomock.Any()).Return(loginResp, nil)
+		st.EXPECT().GetUserByEmail(email).Return(&models.User{Email: email, Status: models.UserStatusActive}, nil)
+		client.refresh = func(string) (*auth_client.UserLoginResponse, error) {
+			return &auth_client.UserLoginResponse{Token: "new-access", RefreshToken: "new-refresh"}, nil
+		}
+
+		_, err := s.Login(ctx, email, "secret", device)
+		require.NoError(t, err)
+		_, err = s.TouchSession(ctx, email, "access", device)
+		require.NoError(t, err)
+
+		_, err = s.Refresh(ctx, "refresh", device)
+		require.NoError(t, err)
+		_, err = s.TouchSession(ctx, email, "access", device)
+		assert.ErrorIs(t, err, service.ErrInvalidToken, "old access token")
+		_, err = s.TouchSession(ctx, email, "new-access", device)
+		assert.NoError(t, err)
+	})
+
+	t.Run("After revocation", func(t *testing.T) {
+		s, st, client, _ := newExtendedService(t)
+		sessionTable{}.expect(st)
+		client.EXPECT().LoginUser(ctx, gomock.Any()).Return(loginResp, nil)
+		st.EXPECT().GetUserByEmail(email).Return(&models.User{Email: email, Status: models.UserStatusActive}, nil)
+
+		_, err := s.Login(ctx, email, "secret", device)
+		require.NoError(t, err)
+		id, err := s.TouchSession(ctx, email, "access", device)
+		require.NoError(t, err)
+
+		require.NoError(t, s.RevokeSession(ctx, email, id))
+		_, err = s.TouchSession(ctx, email, "access", device)
+		assert.ErrorIs(t, err, service.ErrInvalidToken)
+	})
+}
+
+func TestLogout(t *testing.T) {
+	ctx := context.Background()
+	s, st, client, _ := newExtendedService(t)
+	client.EXPECT().ValidateToken(ctx, "access").Return(email, nil)
+	st.EXPECT().GetSessionByAccessToken(hashToken("access")).Return(&models.Session{ID: "id", Login: email}, nil)
+	st.EXPECT().RevokeSessions(email, []string{"id"}, nil).Return(int64(1), nil)
+
+	require.NoError(t, s.Logout(ctx, "access", "refresh"))
+	assert.Equal(t, []string{"access"}, client.revoked)
+}
+
+func TestRevokeSession(t *testing.T) {
+	ctx := context.Background()
+	s, st, _, _ := newTestService(t)
+	st.EXPECT().RevokeSessions(email, []string{"foreign"}, nil).Return(int64(0), nil)
+
+	err := s.RevokeSession(ctx, email, "foreign")
+	assert.ErrorIs(t, err, service.ErrSessionNotFound)
+}
+
+func TestTruncate(t *testing.T) {
+	tests := []struct {
+		name     string
+		s        string
+		n        int
+		expected string
+	}{
+		{name: "Short", s: "curl/8.0", n: 255, expected: "curl/8.0"},
+		{name: "ASCII", s: "Mozilla/5.0", n: 7, expected: "Mozilla"},
+		{name: "Cut inside character", s: "Браузер", n: 5, expected: "Бр"},
+		{name: "Cut on character boundary", s: "Браузер", n: 4, expected: "Бр"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := truncate(tt.s, tt.n)
+			assert.Equal(t, tt.expected, got)
+			assert.True(t, utf8.ValidString(got))
+		})
+	}
+}
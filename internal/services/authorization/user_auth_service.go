@@ -2,6 +2,7 @@ package userauthservice
 
 import (
 	"ChadProgress/internal/auth_client"
+	"ChadProgress/internal/lib/apperr"
 	"ChadProgress/internal/models"
 	"ChadProgress/internal/services"
 	"ChadProgress/storage"
@@ -9,54 +10,154 @@ import (
 	"errors"
 	"fmt"
 	"log/slog"
+	"strings"
+	"time"
 )
 
+//go:generate mockgen -source=user_auth_service.go -destination=./user_auth_service_mock.go -package=userauthservice
 type AuthServiceClient interface {
 	RegisterUser(ctx context.Context, authReq auth_client.UserAuthRequestInterface) (*auth_client.UserRegistrationResponse, error)
 	LoginUser(ctx context.Context, authReq auth_client.UserAuthRequestInterface) (*auth_client.UserLoginResponse, error)
+	DeleteUser(ctx context.Context, login string) error
+	ChangeLogin(ctx context.Context, login, newLogin string) error
+	ValidateToken(ctx context.Context, token string) (string, error)
 }
 
 type Storage interface {
+	// WithTx runs fn in a transaction, fn must use storage it is given
+	WithTx(ctx context.Context, fn func(tx Storage) error) error
 	GetUserByEmail(email string) (*models.User, error)
-	SaveUser(user *models.User) (int64, error)
+	SaveUser(ctx context.Context, user *models.User) (int64, error)
+	ActivateUser(ctx context.Context, id uint) error
+	DeletePendingUser(ctx context.Context, id uint) error
+	GetPendingUsers(registeredBefore time.Time) ([]models.User, error)
+	SaveSession(session *models.Session) error
+	GetSessionByAccessToken(hash string) (*models.Session, error)
+	GetSessionByRefreshToken(hash string) (*models.Session, error)
+	GetSessions(login string) ([]models.Session, error)
+	UpdateSessionTokens(id, accessHash, refreshHash string, client models.ClientInfo) error
+	TouchSession(id, ip string, seenAt time.Time) error
+	RevokeSessions(login string, ids []string, except []string) (int64, error)
+	SaveEmailToken(token *models.EmailToken) error
+	GetEmailToken(hash, purpose string, now time.Time) (*models.EmailToken, error)
+	ConsumeEmailToken(hash, purpose string, now time.Time) (*models.EmailToken, error)
+	InvalidateEmailTokens(login, purpose string) error
+	MarkEmailVerified(ctx context.Context, email string, at time.Time) error
+	ChangeUserEmail(ctx context.Context, email, newEmail string, verifiedAt time.Time) error
+	EraseAccount(ctx context.Context, userID uint, policy models.RetentionPolicy, receipt *models.DeletionReceipt) error
+	MarkCredentialsRemoved(receiptID string) error
+}
+
+// Notifier sends account emails with single use tokens
+type Notifier interface {
+	SendVerification(ctx context.Context, email, token string) error
+	SendPasswordReset(ctx context.Context, email, token string) error
+	SendEmailChange(ctx context.Context, newEmail, token string) error
+}
+
+// TokenRefresher is implemented by auth providers which issue refresh tokens
+type TokenRefresher interface {
+	Refresh(ctx context.Context, refreshToken string) (*auth_client.UserLoginResponse, error)
+}
+
+// TokenRevoker is implemented by auth providers which can revoke issued tokens
+type TokenRevoker interface {
+	Logout(ctx context.Context, accessToken, refreshToken string) error
+}
+
+// PasswordSetter is implemented by auth providers which allow to reset forgotten password
+type PasswordSetter interface {
+	SetPassword(ctx context.Context, login, password string) error
+}
+
+// CredentialsManager is implemented by auth clients which tell whether they can delete and rename
+// credentials, clients which do not implement it can do both
+type CredentialsManager interface {
+	ManagesCredentials() bool
+}
+
+// LoginGuard tracks failed logins and locks out emails that are being brute-forced
+type LoginGuard interface {
+	Locked(ctx context.Context, key string) time.Duration
+	Fail(ctx context.Context, key string) time.Duration
+	Reset(ctx context.Context, key string)
 }
 
 type UserAuthService struct {
 	// TODO: MAKE OWN STORAGE INTERFACE
 	storage    Storage
 	authClient AuthServiceClient
+	loginGuard LoginGuard
+	notifier   Notifier
+	retention  models.RetentionPolicy
 	log        *slog.Logger
 }
 
 func NewUserAuthService(
 	storage Storage,
 	authServiceClient AuthServiceClient,
+	loginGuard LoginGuard,
+	notifier Notifier,
+	retention models.RetentionPolicy,
 	log *slog.Logger,
 ) *UserAuthService {
 	return &UserAuthService{
 		storage:    storage,
 		authClient: authServiceClient,
+		loginGuard: loginGuard,
+		notifier:   notifier,
+		retention:  retention,
 		log:        log,
 	}
 }
 
-// RegisterUser This function returns token from side authorization service and error
-func (u *UserAuthService) RegisterUser(email, password, name, role string) (string, error) {
+// RegisterUser registers user in auth service and locally and returns token from auth service.
+// Registration is a saga: pending local user is saved first (so that local constraints are checked
+// before anything is created remotely), then credentials are created in auth service and the user
+// is activated. Every failed step undoes previous ones, leftovers are cleaned by ReconcileUsers.
+func (u *UserAuthService) RegisterUser(ctx context.Context, email, password, name, role string, client models.ClientInfo) (models.TokenPair, error) {
 	const op = "services.user.user_service.RegisterUser"
 	log := u.log.With(
 		slog.String("op", op),
 	)
 
-	_, err := u.storage.GetUserByEmail(email)
+	if role == models.RoleAdmin {
+		log.Warn("attempt to register as admin", slog.String("email", email))
+		return models.TokenPair{}, fmt.Errorf("%s: %w", op, service.ErrInvalidRoleRequest.WithMessage("admin role can not be registered"))
+	}
+
+	existing, err := u.storage.GetUserByEmail(email)
 	if err == nil {
+		if existing.Status == models.UserStatusPending {
+			log.Info("registration is already in progress", slog.String("email", email))
+			return models.TokenPair{}, fmt.Errorf("%s: %w", op, service.ErrUserAlreadyExists.WithMessage("registration is in progress"))
+		}
 		log.Info("user already exists")
-		return "", fmt.Errorf("%s: %w", op, service.ErrUserAlreadyExists)
+		return models.TokenPair{}, fmt.Errorf("%s: %w", op, service.ErrUserAlreadyExists)
+	}
+	if !errors.Is(err, storage.ErrRecordNotFound) {
+		return models.TokenPair{}, fmt.Errorf("%s: %w", op, err)
 	}
 
 	newUser := &models.User{
-		Email: email,
-		Name:  name,
-		Role:  role,
+		Email:  email,
+		Name:   name,
+		Role:   role,
+		Status: models.UserStatusPending,
+	}
+
+	_, err = u.storage.SaveUser(ctx, newUser)
+	if err != nil {
+		if errors.Is(err, storage.ErrDuplicateKey) {
+			log.Info("user was registered concurrently")
+			return models.TokenPair{}, fmt.Errorf("%s: %w", op, service.ErrUserAlreadyExists)
+		}
+		if appErr, ok := service.FromStorage(err, nil); ok {
+			log.Info("user rejected by storage", slog.String("error", err.Error()))
+			return models.TokenPair{}, fmt.Errorf("%s: %w", op, appErr)
+		}
+		log.Error("save user failed", slog.String("error", err.Error()))
+		return models.TokenPair{}, fmt.Errorf("%s: %w", op, err)
 	}
 
 	regReq := models.UserAuth{
@@ -64,42 +165,215 @@ func (u *UserAuthService) RegisterUser(email, password, name, role string) (stri
 		Password: password,
 	}
 
-	resp, err := u.authClient.RegisterUser(context.Background(), regReq)
-
+	tokens, err := u.registerRemote(ctx, regReq)
 	if err != nil {
-		return "", fmt.Errorf("%s: %w", op, err)
+		u.discardPendingUser(ctx, log, newUser)
+		return models.TokenPair{}, fmt.Errorf("%s: %w", op, err)
 	}
 
-	_, err = u.storage.SaveUser(newUser)
+	if err = u.storage.ActivateUser(ctx, newUser.ID); err != nil {
+		log.Error("failed to activate user, compensating", slog.String("error", err.Error()))
+		// credentials which can not be deleted are adopted when the user registers again with the same password
+		if delErr := u.authClient.DeleteUser(ctx, email); delErr != nil && !errors.Is(delErr, auth_client.ErrUserNotFound) &&
+			!errors.Is(delErr, auth_client.ErrNotSupported) {
+			// pending user stays in storage, ReconcileUsers will remove both records later
+			log.Error("failed to delete user from auth service", slog.String("error", delErr.Error()))
+			return models.TokenPair{}, fmt.Errorf("%s: %w", op, err)
+		}
+		u.discardPendingUser(ctx, log, newUser)
+
+		return models.TokenPair{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	log.Info("user registered", slog.String("email", email))
+	if err = u.sendVerification(ctx, email); err != nil {
+		// user can request another email later
+		log.Error("failed to send verification email", slog.String("error", err.Error()))
+	}
+	if err = u.startSession(ctx, email, tokens, client); err != nil {
+		// account is created, user signs in again
+		return models.TokenPair{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return tokens, nil
+}
+
+// registerRemote creates credentials in auth service. Credentials left by an earlier failed
+// registration are adopted if the same password is used, otherwise the email is taken.
+func (u *UserAuthService) registerRemote(ctx context.Context, regReq models.UserAuth) (models.TokenPair, error) {
+	resp, err := u.authClient.RegisterUser(ctx, regReq)
+	if err == nil {
+		return models.TokenPair{AccessToken: resp.Token, RefreshToken: resp.RefreshToken}, nil
+	}
+	if !errors.Is(err, auth_client.ErrUserAlreadyExists) {
+		return models.TokenPair{}, authClientError(err)
+	}
+
+	loginResp, loginErr := u.authClient.LoginUser(ctx, regReq)
+	if loginErr != nil {
+		return models.TokenPair{}, service.ErrUserAlreadyExists
+	}
+	u.log.Info("adopted credentials of unfinished registration", slog.String("email", regReq.Login))
+
+	return models.TokenPair{AccessToken: loginResp.Token, RefreshToken: loginResp.RefreshToken}, nil
+}
+
+// discardPendingUser is compensation of the first registration step, it runs even if request was cancelled
+func (u *UserAuthService) discardPendingUser(ctx context.Context, log *slog.Logger, user *models.User) {
+	if err := u.storage.DeletePendingUser(context.WithoutCancel(ctx), user.ID); err != nil {
+		log.Error("failed to delete pending user", slog.String("error", err.Error()))
+	}
+}
+
+// ReconcileReport is a result of ReconcileUsers
+type ReconcileReport struct {
+	Removed []string
+	Failed  []string
+}
+
+// ReconcileUsers repairs drift between local users and auth service. Users stuck in pending state
+// since before registeredBefore are removed from both sides, so that their emails can be registered again.
+// Orphans are emails known to have credentials in auth service without local user, they are removed remotely.
+// Providers that do not manage credentials can not remove orphans, such calls are refused before any change.
+func (u *UserAuthService) ReconcileUsers(ctx context.Context, registeredBefore time.Time, orphans []string) (ReconcileReport, error) {
+	const op = "services.user.user_service.ReconcileUsers"
+	log := u.log.With(
+		slog.String("op", op),
+	)
+
+	var report ReconcileReport
+	if len(orphans) > 0 && !u.managesCredentials() {
+		return report, fmt.Errorf("%s: %w", op,
+			service.ErrNotSupported.WithMessage("removing orphaned credentials is not supported by provider, remove them on the auth service"))
+	}
+
+	pending, err := u.storage.GetPendingUsers(registeredBefore)
 	if err != nil {
-		if errors.Is(err, storage.ErrFieldIsTooLong) {
-			log.Info("field is too long")
-			return "", fmt.Errorf("%s: %w", op, service.ErrFieldIsTooLong)
+		return report, fmt.Errorf("%s: %w", op, err)
+	}
+
+	for _, user := range pending {
+		err = u.authClient.DeleteUser(ctx, user.Email)
+		if err == nil || errors.Is(err, auth_client.ErrUserNotFound) || errors.Is(err, auth_client.ErrNotSupported) {
+			err = u.storage.DeletePendingUser(ctx, user.ID)
+		}
+		if err != nil {
+			log.Error("failed to reconcile pending user", slog.String("email", user.Email), slog.String("error", err.Error()))
+			report.Failed = append(report.Failed, user.Email)
+			continue
+		}
+		report.Removed = append(report.Removed, user.Email)
+	}
+
+	for _, email := range orphans {
+		_, err = u.storage.GetUserByEmail(email)
+		if err == nil {
+			log.Info("user exists locally, skipping", slog.String("email", email))
+			continue
 		}
-		log.Error("save user failed", slog.String("errorType", err.Error()))
-		return "", err
+		if !errors.Is(err, storage.ErrRecordNotFound) {
+			report.Failed = append(report.Failed, email)
+			continue
+		}
+
+		err = u.authClient.DeleteUser(ctx, email)
+		if err != nil && !errors.Is(err, auth_client.ErrUserNotFound) {
+			log.Error("failed to delete orphaned credentials", slog.String("email", email), slog.String("error", err.Error()))
+			report.Failed = append(report.Failed, email)
+			continue
+		}
+		report.Removed = append(report.Removed, email)
 	}
 
-	jwtToken := resp.Token
-	return jwtToken, nil
+	log.Info("users reconciled", slog.Int("removed", len(report.Removed)), slog.Int("failed", len(report.Failed)))
+
+	return report, nil
 }
 
-func (u *UserAuthService) Login(email, password string) (string, error) {
+func (u *UserAuthService) Login(ctx context.Context, email, password string, client models.ClientInfo) (models.TokenPair, error) {
 	const op = "services.user.user_service.Login"
 	log := u.log.With(
 		slog.String("op", op),
 	)
 
+	loginResp, err := u.checkPassword(ctx, log, email, password)
+	if err != nil {
+		return models.TokenPair{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	// status is checked after password, so that disabled accounts can not be discovered by email
+	user, err := u.storage.GetUserByEmail(email)
+	if err != nil && !errors.Is(err, storage.ErrRecordNotFound) {
+		return models.TokenPair{}, fmt.Errorf("%s: %w", op, err)
+	}
+	if user != nil && user.Status == models.UserStatusDisabled {
+		log.Info("disabled user tried to sign in", slog.String("email", email))
+		if revoker, ok := u.authClient.(TokenRevoker); ok {
+			if err = revoker.Logout(ctx, loginResp.Token, loginResp.RefreshToken); err != nil {
+				log.Error("failed to revoke tokens of disabled user", slog.String("error", err.Error()))
+			}
+		}
+
+		return models.TokenPair{}, fmt.Errorf("%s: %w", op, service.ErrAccountDisabled)
+	}
+
+	log.Info("user successfully signed in")
+	tokens := models.TokenPair{AccessToken: loginResp.Token, RefreshToken: loginResp.RefreshToken}
+	if err = u.startSession(ctx, email, tokens, client); err != nil {
+		return models.TokenPair{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return tokens, nil
+}
+
+// checkPassword signs user in with auth service, failed attempts are counted by login guard
+func (u *UserAuthService) checkPassword(ctx context.Context, log *slog.Logger, email, password string) (*auth_client.UserLoginResponse, error) {
+	guardKey := strings.ToLower(email)
+	if lock := u.loginGuard.Locked(ctx, guardKey); lock > 0 {
+		log.Info("login attempt while locked out", slog.String("email", email))
+		return nil, service.ErrAccountLocked.WithRetryAfter(lock)
+	}
+
 	regReq := models.UserAuth{
 		Login:    email,
 		Password: password,
 	}
 
-	loginResp, err := u.authClient.LoginUser(context.Background(), regReq)
-
+	loginResp, err := u.authClient.LoginUser(ctx, regReq)
 	if err != nil {
-		return "", fmt.Errorf("%s: %w", op, err)
+		// unknown email is reported as invalid credentials so that registered emails can not be enumerated
+		if errors.Is(err, auth_client.ErrInvalidCredentials) || errors.Is(err, auth_client.ErrUserNotFound) {
+			if lock := u.loginGuard.Fail(ctx, guardKey); lock > 0 {
+				log.Warn("user locked out after failed logins", slog.String("email", email))
+				return nil, service.ErrAccountLocked.WithRetryAfter(lock)
+			}
+
+			return nil, service.ErrInvalidCredentials
+		}
+
+		return nil, authClientError(err)
 	}
-	log.Info("user successfully signed in")
-	return loginResp.Token, nil
+	u.loginGuard.Reset(ctx, guardKey)
+
+	return loginResp, nil
+}
+
+// managesCredentials reports whether auth provider can delete and rename credentials
+func (u *UserAuthService) managesCredentials() bool {
+	m, ok := u.authClient.(CredentialsManager)
+
+	return !ok || m.ManagesCredentials()
+}
+
+// authClientError converts unexpected auth client error to service error keeping the cause
+func authClientError(err error) error {
+	if errors.Is(err, auth_client.ErrInvalidPassword) {
+		return fmt.Errorf("%w: %w", apperr.ErrValidation.WithFields(
+			apperr.FieldError{Field: "password", Message: "does not meet password requirements"}), err)
+	}
+	if errors.Is(err, auth_client.ErrClientUnavailable) {
+		return fmt.Errorf("%w: %w", service.ErrAuthUnavailable, err)
+	}
+
+	return fmt.Errorf("%w: %w", service.ErrAuthServiceFailed, err)
 }
@@ -0,0 +1,714 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: user_auth_service.go
+
+// Package userauthservice is a generated GoMock package.
+package userauthservice
+
+import (
+	auth_client "ChadProgress/internal/auth_client"
+	models "ChadProgress/internal/models"
+	context "context"
+	reflect "reflect"
+	time "time"
+
+	gomock "github.com/golang/mock/gomock"
+)
+
+// MockAuthServiceClient is a mock of AuthServiceClient interface.
+type MockAuthServiceClient struct {
+	ctrl     *gomock.Controller
+	recorder *MockAuthServiceClientMockRecorder
+}
+
+// MockAuthServiceClientMockRecorder is the mock recorder for MockAuthServiceClient.
+type MockAuthServiceClientMockRecorder struct {
+	mock *MockAuthServiceClient
+}
+
+// NewMockAuthServiceClient creates a new mock instance.
+func NewMockAuthServiceClient(ctrl *gomock.Controller) *MockAuthServiceClient {
+	mock := &MockAuthServiceClient{ctrl: ctrl}
+	mock.recorder = &MockAuthServiceClientMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockAuthServiceClient) EXPECT() *MockAuthServiceClientMockRecorder {
+	return m.recorder
+}
+
+// ChangeLogin mocks base method.
+func (m *MockAuthServiceClient) ChangeLogin(ctx context.Context, login, newLogin string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ChangeLogin", ctx, login, newLogin)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ChangeLogin indicates an expected call of ChangeLogin.
+func (mr *MockAuthServiceClientMockRecorder) ChangeLogin(ctx, login, newLogin interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ChangeLogin", reflect.TypeOf((*MockAuthServiceClient)(nil).ChangeLogin), ctx, login, newLogin)
+}
+
+// DeleteUser mocks base method.
+func (m *MockAuthServiceClient) DeleteUser(ctx context.Context, login string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteUser", ctx, login)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteUser indicates an expected call of DeleteUser.
+func (mr *MockAuthServiceClientMockRecorder) DeleteUser(ctx, login interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteUser", reflect.TypeOf((*MockAuthServiceClient)(nil).DeleteUser), ctx, login)
+}
+
+// LoginUser mocks base method.
+func (m *MockAuthServiceClient) LoginUser(ctx context.Context, authReq auth_client.UserAuthRequestInterface) (*auth_client.UserLoginResponse, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "LoginUser", ctx, authReq)
+	ret0, _ := ret[0].(*auth_client.UserLoginResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// LoginUser indicates an expected call of LoginUser.
+func (mr *MockAuthServiceClientMockRecorder) LoginUser(ctx, authReq interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "LoginUser", reflect.TypeOf((*MockAuthServiceClient)(nil).LoginUser), ctx, authReq)
+}
+
+// RegisterUser mocks base method.
+func (m *MockAuthServiceClient) RegisterUser(ctx context.Context, authReq auth_client.UserAuthRequestInterface) (*auth_client.UserRegistrationResponse, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RegisterUser", ctx, authReq)
+	ret0, _ := ret[0].(*auth_client.UserRegistrationResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RegisterUser indicates an expected call of RegisterUser.
+func (mr *MockAuthServiceClientMockRecorder) RegisterUser(ctx, authReq interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RegisterUser", reflect.TypeOf((*MockAuthServiceClient)(nil).RegisterUser), ctx, authReq)
+}
+
+// ValidateToken mocks base method.
+func (m *MockAuthServiceClient) ValidateToken(ctx context.Context, token string) (string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ValidateToken", ctx, token)
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ValidateToken indicates an expected call of ValidateToken.
+func (mr *MockAuthServiceClientMockRecorder) ValidateToken(ctx, token interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ValidateToken", reflect.TypeOf((*MockAuthServiceClient)(nil).ValidateToken), ctx, token)
+}
+
+// MockStorage is a mock of Storage interface.
+type MockStorage struct {
+	ctrl     *gomock.Controller
+	recorder *MockStorageMockRecorder
+}
+
+// MockStorageMockRecorder is the mock recorder for MockStorage.
+type MockStorageMockRecorder struct {
+	mock *MockStorage
+}
+
+// NewMockStorage creates a new mock instance.
+func NewMockStorage(ctrl *gomock.Controller) *MockStorage {
+	mock := &MockStorage{ctrl: ctrl}
+	mock.recorder = &MockStorageMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockStorage) EXPECT() *MockStorageMockRecorder {
+	return m.recorder
+}
+
+// ActivateUser mocks base method.
+func (m *MockStorage) ActivateUser(ctx context.Context, id uint) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ActivateUser", ctx, id)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ActivateUser indicates an expected call of ActivateUser.
+func (mr *MockStorageMockRecorder) ActivateUser(ctx, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ActivateUser", reflect.TypeOf((*MockStorage)(nil).ActivateUser), ctx, id)
+}
+
+// ChangeUserEmail mocks base method.
+func (m *MockStorage) ChangeUserEmail(ctx context.Context, email, newEmail string, verifiedAt time.Time) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ChangeUserEmail", ctx, email, newEmail, verifiedAt)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ChangeUserEmail indicates an expected call of ChangeUserEmail.
+func (mr *MockStorageMockRecorder) ChangeUserEmail(ctx, email, newEmail, verifiedAt interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ChangeUserEmail", reflect.TypeOf((*MockStorage)(nil).ChangeUserEmail), ctx, email, newEmail, verifiedAt)
+}
+
+// ConsumeEmailToken mocks base method.
+func (m *MockStorage) ConsumeEmailToken(hash, purpose string, now time.Time) (*models.EmailToken, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ConsumeEmailToken", hash, purpose, now)
+	ret0, _ := ret[0].(*models.EmailToken)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ConsumeEmailToken indicates an expected call of ConsumeEmailToken.
+func (mr *MockStorageMockRecorder) ConsumeEmailToken(hash, purpose, now interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ConsumeEmailToken", reflect.TypeOf((*MockStorage)(nil).ConsumeEmailToken), hash, purpose, now)
+}
+
+// DeletePendingUser mocks base method.
+func (m *MockStorage) DeletePendingUser(ctx context.Context, id uint) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeletePendingUser", ctx, id)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeletePendingUser indicates an expected call of DeletePendingUser.
+func (mr *MockStorageMockRecorder) DeletePendingUser(ctx, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeletePendingUser", reflect.TypeOf((*MockStorage)(nil).DeletePendingUser), ctx, id)
+}
+
+// EraseAccount mocks base method.
+func (m *MockStorage) EraseAccount(ctx context.Context, userID uint, policy models.RetentionPolicy, receipt *models.DeletionReceipt) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "EraseAccount", ctx, userID, policy, receipt)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// EraseAccount indicates an expected call of EraseAccount.
+func (mr *MockStorageMockRecorder) EraseAccount(ctx, userID, policy, receipt interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "EraseAccount", reflect.TypeOf((*MockStorage)(nil).EraseAccount), ctx, userID, policy, receipt)
+}
+
+// GetEmailToken mocks base method.
+func (m *MockStorage) GetEmailToken(hash, purpose string, now time.Time) (*models.EmailToken, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetEmailToken", hash, purpose, now)
+	ret0, _ := ret[0].(*models.EmailToken)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetEmailToken indicates an expected call of GetEmailToken.
+func (mr *MockStorageMockRecorder) GetEmailToken(hash, purpose, now interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetEmailToken", reflect.TypeOf((*MockStorage)(nil).GetEmailToken), hash, purpose, now)
+}
+
+// GetPendingUsers mocks base method.
+func (m *MockStorage) GetPendingUsers(registeredBefore time.Time) ([]models.User, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetPendingUsers", registeredBefore)
+	ret0, _ := ret[0].([]models.User)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetPendingUsers indicates an expected call of GetPendingUsers.
+func (mr *MockStorageMockRecorder) GetPendingUsers(registeredBefore interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetPendingUsers", reflect.TypeOf((*MockStorage)(nil).GetPendingUsers), registeredBefore)
+}
+
+// GetSessionByAccessToken mocks base method.
+func (m *MockStorage) GetSessionByAccessToken(hash string) (*models.Session, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetSessionByAccessToken", hash)
+	ret0, _ := ret[0].(*models.Session)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetSessionByAccessToken indicates an expected call of GetSessionByAccessToken.
+func (mr *MockStorageMockRecorder) GetSessionByAccessToken(hash interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetSessionByAccessToken", reflect.TypeOf((*MockStorage)(nil).GetSessionByAccessToken), hash)
+}
+
+// GetSessionByRefreshToken mocks base method.
+func (m *MockStorage) GetSessionByRefreshToken(hash string) (*models.Session, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetSessionByRefreshToken", hash)
+	ret0, _ := ret[0].(*models.Session)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetSessionByRefreshToken indicates an expected call of GetSessionByRefreshToken.
+func (mr *MockStorageMockRecorder) GetSessionByRefreshToken(hash interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetSessionByRefreshToken", reflect.TypeOf((*MockStorage)(nil).GetSessionByRefreshToken), hash)
+}
+
+// GetSessions mocks base method.
+func (m *MockStorage) GetSessions(login string) ([]models.Session, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetSessions", login)
+	ret0, _ := ret[0].([]models.Session)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetSessions indicates an expected call of GetSessions.
+func (mr *MockStorageMockRecorder) GetSessions(login interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetSessions", reflect.TypeOf((*MockStorage)(nil).GetSessions), login)
+}
+
+// GetUserByEmail mocks base method.
+func (m *MockStorage) GetUserByEmail(email string) (*models.User, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetUserByEmail", email)
+	ret0, _ := ret[0].(*models.User)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetUserByEmail indicates an expected call of GetUserByEmail.
+func (mr *MockStorageMockRecorder) GetUserByEmail(email interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUserByEmail", reflect.TypeOf((*MockStorage)(nil).GetUserByEmail), email)
+}
+
+// InvalidateEmailTokens mocks base method.
+func (m *MockStorage) InvalidateEmailTokens(login, purpose string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "InvalidateEmailTokens", login, purpose)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// InvalidateEmailTokens indicates an expected call of InvalidateEmailTokens.
+func (mr *MockStorageMockRecorder) InvalidateEmailTokens(login, purpose interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "InvalidateEmailTokens", reflect.TypeOf((*MockStorage)(nil).InvalidateEmailTokens), login, purpose)
+}
+
+// MarkCredentialsRemoved mocks base method.
+func (m *MockStorage) MarkCredentialsRemoved(receiptID string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "MarkCredentialsRemoved", receiptID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// MarkCredentialsRemoved indicates an expected call of MarkCredentialsRemoved.
+func (mr *MockStorageMockRecorder) MarkCredentialsRemoved(receiptID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "MarkCredentialsRemoved", reflect.TypeOf((*MockStorage)(nil).MarkCredentialsRemoved), receiptID)
+}
+
+// MarkEmailVerified mocks base method.
+func (m *MockStorage) MarkEmailVerified(ctx context.Context, email string, at time.Time) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "MarkEmailVerified", ctx, email, at)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// MarkEmailVerified indicates an expected call of MarkEmailVerified.
+func (mr *MockStorageMockRecorder) MarkEmailVerified(ctx, email, at interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "MarkEmailVerified", reflect.TypeOf((*MockStorage)(nil).MarkEmailVerified), ctx, email, at)
+}
+
+// RevokeSessions mocks base method.
+func (m *MockStorage) RevokeSessions(login string, ids, except []string) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RevokeSessions", login, ids, except)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RevokeSessions indicates an expected call of RevokeSessions.
+func (mr *MockStorageMockRecorder) RevokeSessions(login, ids, except interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RevokeSessions", reflect.TypeOf((*MockStorage)(nil).RevokeSessions), login, ids, except)
+}
+
+// SaveEmailToken mocks base method.
+func (m *MockStorage) SaveEmailToken(token *models.EmailToken) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SaveEmailToken", token)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SaveEmailToken indicates an expected call of SaveEmailToken.
+func (mr *MockStorageMockRecorder) SaveEmailToken(token interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SaveEmailToken", reflect.TypeOf((*MockStorage)(nil).SaveEmailToken), token)
+}
+
+// SaveSession mocks base method.
+func (m *MockStorage) SaveSession(session *models.Session) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SaveSession", session)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SaveSession indicates an expected call of SaveSession.
+func (mr *MockStorageMockRecorder) SaveSession(session interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SaveSession", reflect.TypeOf((*MockStorage)(nil).SaveSession), session)
+}
+
+// SaveUser mocks base method.
+func (m *MockStorage) SaveUser(ctx context.Context, user *models.User) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SaveUser", ctx, user)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SaveUser indicates an expected call of SaveUser.
+func (mr *MockStorageMockRecorder) SaveUser(ctx, user interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SaveUser", reflect.TypeOf((*MockStorage)(nil).SaveUser), ctx, user)
+}
+
+// TouchSession mocks base method.
+func (m *MockStorage) TouchSession(id, ip string, seenAt time.Time) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "TouchSession", id, ip, seenAt)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// TouchSession indicates an expected call of TouchSession.
+func (mr *MockStorageMockRecorder) TouchSession(id, ip, seenAt interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "TouchSession", reflect.TypeOf((*MockStorage)(nil).TouchSession), id, ip, seenAt)
+}
+
+// UpdateSessionTokens mocks base method.
+func (m *MockStorage) UpdateSessionTokens(id, accessHash, refreshHash string, client models.ClientInfo) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateSessionTokens", id, accessHash, refreshHash, client)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateSessionTokens indicates an expected call of UpdateSessionTokens.
+func (mr *MockStorageMockRecorder) UpdateSessionTokens(id, accessHash, refreshHash, client interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateSessionTokens", reflect.TypeOf((*MockStorage)(nil).UpdateSessionTokens), id, accessHash, refreshHash, client)
+}
+
+// WithTx mocks base method.
+func (m *MockStorage) WithTx(ctx context.Context, fn func(Storage) error) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "WithTx", ctx, fn)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// WithTx indicates an expected call of WithTx.
+func (mr *MockStorageMockRecorder) WithTx(ctx, fn interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "WithTx", reflect.TypeOf((*MockStorage)(nil).WithTx), ctx, fn)
+}
+
+// MockNotifier is a mock of Notifier interface.
+type MockNotifier struct {
+	ctrl     *gomock.Controller
+	recorder *MockNotifierMockRecorder
+}
+
+// MockNotifierMockRecorder is the mock recorder for MockNotifier.
+type MockNotifierMockRecorder struct {
+	mock *MockNotifier
+}
+
+// NewMockNotifier creates a new mock instance.
+func NewMockNotifier(ctrl *gomock.Controller) *MockNotifier {
+	mock := &MockNotifier{ctrl: ctrl}
+	mock.recorder = &MockNotifierMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockNotifier) EXPECT() *MockNotifierMockRecorder {
+	return m.recorder
+}
+
+// SendEmailChange mocks base method.
+func (m *MockNotifier) SendEmailChange(ctx context.Context, newEmail, token string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SendEmailChange", ctx, newEmail, token)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SendEmailChange indicates an expected call of SendEmailChange.
+func (mr *MockNotifierMockRecorder) SendEmailChange(ctx, newEmail, token interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SendEmailChange", reflect.TypeOf((*MockNotifier)(nil).SendEmailChange), ctx, newEmail, token)
+}
+
+// SendPasswordReset mocks base method.
+func (m *MockNotifier) SendPasswordReset(ctx context.Context, email, token string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SendPasswordReset", ctx, email, token)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SendPasswordReset indicates an expected call of SendPasswordReset.
+func (mr *MockNotifierMockRecorder) SendPasswordReset(ctx, email, token interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SendPasswordReset", reflect.TypeOf((*MockNotifier)(nil).SendPasswordReset), ctx, email, token)
+}
+
+// SendVerification mocks base method.
+func (m *MockNotifier) SendVerification(ctx context.Context, email, token string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SendVerification", ctx, email, token)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SendVerification indicates an expected call of SendVerification.
+func (mr *MockNotifierMockRecorder) SendVerification(ctx, email, token interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SendVerification", reflect.TypeOf((*MockNotifier)(nil).SendVerification), ctx, email, token)
+}
+
+// MockTokenRefresher is a mock of TokenRefresher interface.
+type MockTokenRefresher struct {
+	ctrl     *gomock.Controller
+	recorder *MockTokenRefresherMockRecorder
+}
+
+// MockTokenRefresherMockRecorder is the mock recorder for MockTokenRefresher.
+type MockTokenRefresherMockRecorder struct {
+	mock *MockTokenRefresher
+}
+
+// NewMockTokenRefresher creates a new mock instance.
+func NewMockTokenRefresher(ctrl *gomock.Controller) *MockTokenRefresher {
+	mock := &MockTokenRefresher{ctrl: ctrl}
+	mock.recorder = &MockTokenRefresherMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockTokenRefresher) EXPECT() *MockTokenRefresherMockRecorder {
+	return m.recorder
+}
+
+// Refresh mocks base method.
+func (m *MockTokenRefresher) Refresh(ctx context.Context, refreshToken string) (*auth_client.UserLoginResponse, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Refresh", ctx, refreshToken)
+	ret0, _ := ret[0].(*auth_client.UserLoginResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Refresh indicates an expected call of Refresh.
+func (mr *MockTokenRefresherMockRecorder) Refresh(ctx, refreshToken interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Refresh", reflect.TypeOf((*MockTokenRefresher)(nil).Refresh), ctx, refreshToken)
+}
+
+// MockTokenRevoker is a mock of TokenRevoker interface.
+type MockTokenRevoker struct {
+	ctrl     *gomock.Controller
+	recorder *MockTokenRevokerMockRecorder
+}
+
+// MockTokenRevokerMockRecorder is the mock recorder for MockTokenRevoker.
+type MockTokenRevokerMockRecorder struct {
+	mock *MockTokenRevoker
+}
+
+// NewMockTokenRevoker creates a new mock instance.
+func NewMockTokenRevoker(ctrl *gomock.Controller) *MockTokenRevoker {
+	mock := &MockTokenRevoker{ctrl: ctrl}
+	mock.recorder = &MockTokenRevokerMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockTokenRevoker) EXPECT() *MockTokenRevokerMockRecorder {
+	return m.recorder
+}
+
+// Logout mocks base method.
+func (m *MockTokenRevoker) Logout(ctx context.Context, accessToken, refreshToken string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Logout", ctx, accessToken, refreshToken)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Logout indicates an expected call of Logout.
+func (mr *MockTokenRevokerMockRecorder) Logout(ctx, accessToken, refreshToken interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Logout", reflect.TypeOf((*MockTokenRevoker)(nil).Logout), ctx, accessToken, refreshToken)
+}
+
+// MockPasswordSetter is a mock of PasswordSetter interface.
+type MockPasswordSetter struct {
+	ctrl     *gomock.Controller
+	recorder *MockPasswordSetterMockRecorder
+}
+
+// MockPasswordSetterMockRecorder is the mock recorder for MockPasswordSetter.
+type MockPasswordSetterMockRecorder struct {
+	mock *MockPasswordSetter
+}
+
+// NewMockPasswordSetter creates a new mock instance.
+func NewMockPasswordSetter(ctrl *gomock.Controller) *MockPasswordSetter {
+	mock := &MockPasswordSetter{ctrl: ctrl}
+	mock.recorder = &MockPasswordSetterMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockPasswordSetter) EXPECT() *MockPasswordSetterMockRecorder {
+	return m.recorder
+}
+
+// SetPassword mocks base method.
+func (m *MockPasswordSetter) SetPassword(ctx context.Context, login, password string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetPassword", ctx, login, password)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SetPassword indicates an expected call of SetPassword.
+func (mr *MockPasswordSetterMockRecorder) SetPassword(ctx, login, password interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetPassword", reflect.TypeOf((*MockPasswordSetter)(nil).SetPassword), ctx, login, password)
+}
+
+// MockCredentialsManager is a mock of CredentialsManager interface.
+type MockCredentialsManager struct {
+	ctrl     *gomock.Controller
+	recorder *MockCredentialsManagerMockRecorder
+}
+
+// MockCredentialsManagerMockRecorder is the mock recorder for MockCredentialsManager.
+type MockCredentialsManagerMockRecorder struct {
+	mock *MockCredentialsManager
+}
+
+// NewMockCredentialsManager creates a new mock instance.
+func NewMockCredentialsManager(ctrl *gomock.Controller) *MockCredentialsManager {
+	mock := &MockCredentialsManager{ctrl: ctrl}
+	mock.recorder = &MockCredentialsManagerMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockCredentialsManager) EXPECT() *MockCredentialsManagerMockRecorder {
+	return m.recorder
+}
+
+// ManagesCredentials mocks base method.
+func (m *MockCredentialsManager) ManagesCredentials() bool {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ManagesCredentials")
+	ret0, _ := ret[0].(bool)
+	return ret0
+}
+
+// ManagesCredentials indicates an expected call of ManagesCredentials.
+func (mr *MockCredentialsManagerMockRecorder) ManagesCredentials() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ManagesCredentials", reflect.TypeOf((*MockCredentialsManager)(nil).ManagesCredentials))
+}
+
+// MockLoginGuard is a mock of LoginGuard interface.
+type MockLoginGuard struct {
+	ctrl     *gomock.Controller
+	recorder *MockLoginGuardMockRecorder
+}
+
+// MockLoginGuardMockRecorder is the mock recorder for MockLoginGuard.
+type MockLoginGuardMockRecorder struct {
+	mock *MockLoginGuard
+}
+
+// NewMockLoginGuard creates a new mock instance.
+func NewMockLoginGuard(ctrl *gomock.Controller) *MockLoginGuard {
+	mock := &MockLoginGuard{ctrl: ctrl}
+	mock.recorder = &MockLoginGuardMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockLoginGuard) EXPECT() *MockLoginGuardMockRecorder {
+	return m.recorder
+}
+
+// Fail mocks base method.
+func (m *MockLoginGuard) Fail(ctx context.Context, key string) time.Duration {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Fail", ctx, key)
+	ret0, _ := ret[0].(time.Duration)
+	return ret0
+}
+
+// Fail indicates an expected call of Fail.
+func (mr *MockLoginGuardMockRecorder) Fail(ctx, key interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Fail", reflect.TypeOf((*MockLoginGuard)(nil).Fail), ctx, key)
+}
+
+// Locked mocks base method.
+func (m *MockLoginGuard) Locked(ctx context.Context, key string) time.Duration {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Locked", ctx, key)
+	ret0, _ := ret[0].(time.Duration)
+	return ret0
+}
+
+// Locked indicates an expected call of Locked.
+func (mr *MockLoginGuardMockRecorder) Locked(ctx, key interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Locked", reflect.TypeOf((*MockLoginGuard)(nil).Locked), ctx, key)
+}
+
+// Reset mocks base method.
+func (m *MockLoginGuard) Reset(ctx context.Context, key string) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "Reset", ctx, key)
+}
+
+// Reset indicates an expected call of Reset.
+func (mr *MockLoginGuardMockRecorder) Reset(ctx, key interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Reset", reflect.TypeOf((*MockLoginGuard)(nil).Reset), ctx, key)
+}
@@ -0,0 +1,247 @@
+package userauthservice
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"testing"
+	"time"
+
+	"ChadProgress/internal/auth_client"
+	"ChadProgress/internal/lib/apperr"
+	"ChadProgress/internal/lib/ratelimit"
+	"ChadProgress/internal/models"
+	service "ChadProgress/internal/services"
+	"ChadProgress/storage"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const email = "user@example.com"
+
+var device = models.ClientInfo{UserAgent: "curl/8.0", IP: "10.0.0.1"}
+
+var testRetention = models.RetentionPolicy{
+	Profile:       models.RetentionAnonymize,
+	Metrics:       models.RetentionErase,
+	SharedRecords: models.RetentionAnonymize,
+}
+
+func newTestService(t *testing.T) (*UserAuthService, *MockStorage, *MockAuthServiceClient, *MockNotifier) {
+	ctrl := gomock.NewController(t)
+	st := NewMockStorage(ctrl)
+	client := NewMockAuthServiceClient(ctrl)
+	notifier := NewMockNotifier(ctrl)
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	guard := ratelimit.NewLockout(ratelimit.LockoutPolicy{})
+
+	return NewUserAuthService(st, client, guard, notifier, testRetention, logger), st, client, notifier
+}
+
+// expectTx expects a transaction and runs it on the same mock, the returned error is what storage
+// would get to decide between commit and rollback
+func expectTx(st *MockStorage) *gomock.Call {
+	return st.EXPECT().WithTx(gomock.Any(), gomock.Any()).DoAndReturn(func(_ context.Context, fn func(Storage) error) error {
+		return fn(st)
+	})
+}
+
+// expectPendingUser expects the first saga step and assigns id to saved user
+func expectPendingUser(st *MockStorage, id uint) {
+	st.EXPECT().GetUserByEmail(email).Return(nil, storage.ErrRecordNotFound)
+	st.EXPECT().SaveUser(gomock.Any(), gomock.Any()).DoAndReturn(func(_ context.Context, u *models.User) (int64, error) {
+		if u.Status != models.UserStatusPending {
+			return 0, errors.New("user must be saved as pending")
+		}
+		u.ID = id
+		return int64(id), nil
+	})
+}
+
+func TestRegisterUser(t *testing.T) {
+	ctx := context.Background()
+	regResp := &auth_client.UserRegistrationResponse{Status: "OK", Token: "token"}
+
+	t.Run("Success", func(t *testing.T) {
+		s, st, client, notifier := newTestService(t)
+		expectPendingUser(st, 7)
+		client.EXPECT().RegisterUser(ctx, gomock.Any()).Return(regResp, nil)
+		st.EXPECT().ActivateUser(gomock.Any(), uint(7)).Return(nil)
+		st.EXPECT().SaveSession(gomock.Any()).Return(nil)
+		st.EXPECT().SaveEmailToken(gomock.Any()).Return(nil)
+		notifier.EXPECT().SendVerification(ctx, email, gomock.Any()).Return(nil)
+
+		tokens, err := s.RegisterUser(ctx, email, "secret", "User", models.RoleClient, device)
+		require.NoError(t, err)
+		assert.Equal(t, "token", tokens.AccessToken)
+	})
+
+	t.Run("Local validation fails before remote call", func(t *testing.T) {
+		s, st, _, _ := newTestService(t)
+		st.EXPECT().GetUserByEmail(email).Return(nil, storage.ErrRecordNotFound)
+		st.EXPECT().SaveUser(gomock.Any(), gomock.Any()).
+			Return(int64(-1), &storage.FieldError{Err: storage.ErrFieldIsTooLong, Field: "name"})
+
+		_, err := s.RegisterUser(ctx, email, "secret", "User", models.RoleClient, device)
+		assert.ErrorIs(t, err, service.ErrFieldIsTooLong)
+		assert.Equal(t, []apperr.FieldError{{Field: "name", Message: "is too long"}}, apperr.From(err).Fields)
+	})
+
+	t.Run("Remote failure removes pending user", func(t *testing.T) {
+		s, st, client, _ := newTestService(t)
+		expectPendingUser(st, 7)
+		client.EXPECT().RegisterUser(ctx, gomock.Any()).Return(nil, auth_client.ErrClientUnavailable)
+		st.EXPECT().DeletePendingUser(gomock.Any(), uint(7)).Return(nil)
+
+		_, err := s.RegisterUser(ctx, email, "secret", "User", models.RoleClient, device)
+		assert.ErrorIs(t, err, service.ErrAuthUnavailable)
+	})
+
+	t.Run("Password refused by provider is validation error", func(t *testing.T) {
+		s, st, client, _ := newTestService(t)
+		expectPendingUser(st, 7)
+		client.EXPECT().RegisterUser(ctx, gomock.Any()).Return(nil, auth_client.ErrInvalidPassword)
+		st.EXPECT().DeletePendingUser(gomock.Any(), uint(7)).Return(nil)
+
+		_, err := s.RegisterUser(ctx, email, "secret", "User", models.RoleClient, device)
+		assert.ErrorIs(t, err, apperr.ErrValidation)
+		assert.Equal(t, []apperr.FieldError{{Field: "password", Message: "does not meet password requirements"}}, apperr.From(err).Fields)
+	})
+
+	t.Run("Activation failure deletes remote credentials", func(t *testing.T) {
+		s, st, client, _ := newTestService(t)
+		expectPendingUser(st, 7)
+		client.EXPECT().RegisterUser(ctx, gomock.Any()).Return(regResp, nil)
+		st.EXPECT().ActivateUser(gomock.Any(), uint(7)).Return(errors.New("connection reset"))
+		client.EXPECT().DeleteUser(ctx, email).Return(nil)
+		st.EXPECT().DeletePendingUser(gomock.Any(), uint(7)).Return(nil)
+
+		_, err := s.RegisterUser(ctx, email, "secret", "User", models.RoleClient, device)
+		assert.Error(t, err)
+	})
+
+	t.Run("Failed compensation keeps pending user for reconciliation", func(t *testing.T) {
+		s, st, client, _ := newTestService(t)
+		expectPendingUser(st, 7)
+		client.EXPECT().RegisterUser(ctx, gomock.Any()).Return(regResp, nil)
+		st.EXPECT().ActivateUser(gomock.Any(), uint(7)).Return(errors.New("connection reset"))
+		client.EXPECT().DeleteUser(ctx, email).Return(auth_client.ErrClientUnavailable)
+
+		_, err := s.RegisterUser(ctx, email, "secret", "User", models.RoleClient, device)
+		assert.Error(t, err)
+	})
+
+	t.Run("Leftover credentials with same password are adopted", func(t *testing.T) {
+		s, st, client, notifier := newTestService(t)
+		expectPendingUser(st, 7)
+		client.EXPECT().RegisterUser(ctx, gomock.Any()).Return(nil, auth_client.ErrUserAlreadyExists)
+		client.EXPECT().LoginUser(ctx, gomock.Any()).Return(&auth_client.UserLoginResponse{Token: "login-token"}, nil)
+		st.EXPECT().ActivateUser(gomock.Any(), uint(7)).Return(nil)
+		st.EXPECT().SaveSession(gomock.Any()).Return(nil)
+		st.EXPECT().SaveEmailToken(gomock.Any()).Return(nil)
+		notifier.EXPECT().SendVerification(ctx, email, gomock.Any()).Return(errors.New("smtp is down"))
+
+		tokens, err := s.RegisterUser(ctx, email, "secret", "User", models.RoleClient, device)
+		require.NoError(t, err)
+		assert.Equal(t, "login-token", tokens.AccessToken)
+	})
+
+	t.Run("Email taken in auth service", func(t *testing.T) {
+		s, st, client, _ := newTestService(t)
+		expectPendingUser(st, 7)
+		client.EXPECT().RegisterUser(ctx, gomock.Any()).Return(nil, auth_client.ErrUserAlreadyExists)
+		client.EXPECT().LoginUser(ctx, gomock.Any()).Return(nil, auth_client.ErrInvalidCredentials)
+		st.EXPECT().DeletePendingUser(gomock.Any(), uint(7)).Return(nil)
+
+		_, err := s.RegisterUser(ctx, email, "secret", "User", models.RoleClient, device)
+		assert.ErrorIs(t, err, service.ErrUserAlreadyExists)
+	})
+
+	t.Run("Registration in progress", func(t *testing.T) {
+		s, st, _, _ := newTestService(t)
+		st.EXPECT().GetUserByEmail(email).Return(&models.User{ID: 7, Email: email, Status: models.UserStatusPending}, nil)
+
+		_, err := s.RegisterUser(ctx, email, "secret", "User", models.RoleClient, device)
+		assert.ErrorIs(t, err, service.ErrUserAlreadyExists)
+	})
+
+	t.Run("Admin role can not be registered", func(t *testing.T) {
+		s, _, _, _ := newTestService(t)
+
+		_, err := s.RegisterUser(ctx, email, "secret", "User", models.RoleAdmin, device)
+		assert.ErrorIs(t, err, service.ErrInvalidRoleRequest)
+	})
+}
+
+func TestLogin(t *testing.T) {
+	ctx := context.Background()
+	loginResp := &auth_client.UserLoginResponse{Token: "token", RefreshToken: "refresh"}
+
+	t.Run("Success", func(t *testing.T) {
+		s, st, client, _ := newTestService(t)
+		client.EXPECT().LoginUser(ctx, models.UserAuth{Login: email, Password: "secret"}).Return(loginResp, nil)
+		st.EXPECT().GetUserByEmail(email).Return(&models.User{Email: email, Status: models.UserStatusActive}, nil)
+		st.EXPECT().SaveSession(gomock.Any()).Return(nil)
+
+		tokens, err := s.Login(ctx, email, "secret", device)
+		require.NoError(t, err)
+		assert.Equal(t, "token", tokens.AccessToken)
+	})
+
+	t.Run("Tokens without session are not returned", func(t *testing.T) {
+		s, st, client, _ := newExtendedService(t)
+		client.EXPECT().LoginUser(ctx, gomock.Any()).Return(loginResp, nil)
+		st.EXPECT().GetUserByEmail(email).Return(&models.User{Email: email, Status: models.UserStatusActive}, nil)
+		st.EXPECT().SaveSession(gomock.Any()).Return(errors.New("connection reset"))
+
+		tokens, err := s.Login(ctx, email, "secret", device)
+		assert.Error(t, err)
+		assert.Empty(t, tokens.AccessToken)
+		assert.Equal(t, []string{"token"}, client.revoked, "issued tokens are revoked")
+	})
+
+	t.Run("Disabled user gets no tokens", func(t *testing.T) {
+		s, st, client, _ := newExtendedService(t)
+		client.EXPECT().LoginUser(ctx, gomock.Any()).Return(loginResp, nil)
+		st.EXPECT().GetUserByEmail(email).Return(&models.User{Email: email, Status: models.UserStatusDisabled}, nil)
+
+		tokens, err := s.Login(ctx, email, "secret", device)
+		assert.ErrorIs(t, err, service.ErrAccountDisabled)
+		assert.Empty(t, tokens.AccessToken)
+		assert.Equal(t, []string{"token"}, client.revoked, "issued tokens are revoked")
+	})
+
+	t.Run("Wrong password does not reveal status", func(t *testing.T) {
+		s, _, client, _ := newTestService(t)
+		client.EXPECT().LoginUser(ctx, gomock.Any()).Return(nil, auth_client.ErrInvalidCredentials)
+
+		_, err := s.Login(ctx, email, "wrong", device)
+		assert.ErrorIs(t, err, service.ErrInvalidCredentials)
+	})
+}
+
+func TestReconcileUsers(t *testing.T) {
+	ctx := context.Background()
+	before := time.Date(2026, time.October, 19, 12, 0, 0, 0, time.UTC)
+
+	s, st, client, _ := newTestService(t)
+	st.EXPECT().GetPendingUsers(before).Return([]models.User{
+		{ID: 1, Email: "stuck@example.com", Status: models.UserStatusPending},
+		{ID: 2, Email: "down@example.com", Status: models.UserStatusPending},
+	}, nil)
+	client.EXPECT().DeleteUser(ctx, "stuck@example.com").Return(auth_client.ErrUserNotFound)
+	st.EXPECT().DeletePendingUser(gomock.Any(), uint(1)).Return(nil)
+	client.EXPECT().DeleteUser(ctx, "down@example.com").Return(auth_client.ErrClientUnavailable)
+
+	st.EXPECT().GetUserByEmail("orphan@example.com").Return(nil, storage.ErrRecordNotFound)
+	client.EXPECT().DeleteUser(ctx, "orphan@example.com").Return(nil)
+	st.EXPECT().GetUserByEmail(email).Return(&models.User{ID: 3, Email: email, Status: models.UserStatusActive}, nil)
+
+	report, err := s.ReconcileUsers(ctx, before, []string{"orphan@example.com", email})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"stuck@example.com", "orphan@example.com"}, report.Removed)
+	assert.Equal(t, []string{"down@example.com"}, report.Failed)
+}
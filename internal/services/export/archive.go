@@ -0,0 +1,262 @@
+package exportservice
+
+import (
+	"archive/zip"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"ChadProgress/internal/models"
+)
+
+// Records of the archive. Field json tags name both JSON keys and CSV columns.
+type (
+	userRecord struct {
+		ID              uint       `json:"id"`
+		Email           string     `json:"email"`
+		Name            string     `json:"name"`
+		Role            string     `json:"role"`
+		Status          string     `json:"status"`
+		RegisteredAt    time.Time  `json:"registered_at"`
+		EmailVerifiedAt *time.Time `json:"email_verified_at"`
+	}
+	clientRecord struct {
+		ID        uint    `json:"id"`
+		TrainerID uint    `json:"trainer_id"`
+		Height    float64 `json:"height"`
+		Weight    float64 `json:"weight"`
+		BodyFat   float64 `json:"body_fat"`
+	}
+	goalRecord struct {
+		Type   string  `json:"type"`
+		Target float64 `json:"target"`
+	}
+	trainerRecord struct {
+		ID             uint   `json:"id"`
+		Qualifications string `json:"qualifications"`
+		Experience     string `json:"experience"`
+		Achievements   string `json:"achievements"`
+		Status         string `json:"status"`
+	}
+	// metricRecord is a single measurement, metrics have measurements of any types of the catalog
+	metricRecord struct {
+		MetricID   uint      `json:"metric_id"`
+		Type       string    `json:"type"`
+		Value      float64   `json:"value"`
+		MeasuredAt time.Time `json:"measured_at"`
+	}
+	planRecord struct {
+		ID          uint      `json:"id"`
+		TrainerID   uint      `json:"trainer_id"`
+		ClientID    uint      `json:"client_id"`
+		Description string    `json:"description"`
+		Schedule    string    `json:"schedule"`
+		CreatedAt   time.Time `json:"created_at"`
+	}
+	reportRecord struct {
+		ID        uint      `json:"id"`
+		TrainerID uint      `json:"trainer_id"`
+		ClientID  uint      `json:"client_id"`
+		Comments  string    `json:"comments"`
+		CreatedAt time.Time `json:"created_at"`
+	}
+	sessionRecord struct {
+		ID         string     `json:"id"`
+		UserAgent  string     `json:"user_agent"`
+		IP         string     `json:"ip"`
+		CreatedAt  time.Time  `json:"created_at"`
+		LastSeenAt time.Time  `json:"last_seen_at"`
+		RevokedAt  *time.Time `json:"revoked_at"`
+	}
+)
+
+type manifestFile struct {
+	Name    string `json:"name"`
+	Records int    `json:"records"`
+}
+
+type manifest struct {
+	GeneratedAt time.Time      `json:"generated_at"`
+	Files       []manifestFile `json:"files"`
+}
+
+// dataset is written to archive as name.json and name.csv, records is a slice of record structs
+type dataset struct {
+	name    string
+	records any
+}
+
+// writeArchive writes every dataset of data as JSON and CSV into ZIP archive with manifest.json listing them
+func writeArchive(w io.Writer, data *models.PersonalData, generatedAt time.Time) error {
+	zw := zip.NewWriter(w)
+	m := manifest{GeneratedAt: generatedAt.UTC()}
+
+	for _, ds := range datasets(data) {
+		n := reflect.ValueOf(ds.records).Len()
+		if err := writeJSON(zw, ds.name+".json", ds.records); err != nil {
+			return err
+		}
+		if err := writeCSV(zw, ds.name+".csv", ds.records); err != nil {
+			return err
+		}
+		m.Files = append(m.Files,
+			manifestFile{Name: ds.name + ".json", Records: n},
+			manifestFile{Name: ds.name + ".csv", Records: n},
+		)
+	}
+
+	if err := writeJSON(zw, "manifest.json", m); err != nil {
+		return err
+	}
+
+	return zw.Close()
+}
+
+func datasets(data *models.PersonalData) []dataset {
+	u := data.User
+	result := []dataset{{name: "user", records: []userRecord{{
+		ID: u.ID, Email: u.Email, Name: u.Name, Role: u.Role, Status: u.Status,
+		RegisteredAt: u.RegisteredAt, EmailVerifiedAt: u.EmailVerifiedAt,
+	}}}}
+
+	if c := data.Client; c != nil {
+		result = append(result, dataset{name: "client_profile", records: []clientRecord{{
+			ID: c.ID, TrainerID: c.TrainerID, Height: c.Height, Weight: c.Weight, BodyFat: c.BodyFat,
+		}}})
+
+		goals := make([]goalRecord, 0, len(c.Goals))
+		for _, t := range sortedCodes(c.Goals) {
+			goals = append(goals, goalRecord{Type: t, Target: c.Goals[t]})
+		}
+		result = append(result, dataset{name: "goals", records: goals})
+
+		metrics := make([]metricRecord, 0, len(data.Metrics))
+		for _, m := range data.Metrics {
+			for _, t := range sortedCodes(m.Measurements) {
+				metrics = append(metrics, metricRecord{
+					MetricID: m.ID, Type: t, Value: m.Measurements[t], MeasuredAt: m.MeasuredAt,
+				})
+			}
+		}
+		result = append(result, dataset{name: "metrics", records: metrics})
+	}
+	if t := data.Trainer; t != nil {
+		result = append(result, dataset{name: "trainer_profile", records: []trainerRecord{{
+			ID: t.ID, Qualifications: t.Qualifications, Experience: t.Experience, Achievements: t.Achievements, Status: t.Status,
+		}}})
+	}
+
+	plans := make([]planRecord, 0, len(data.TrainingPlans))
+	for _, p := range data.TrainingPlans {
+		plans = append(plans, planRecord{
+			ID: p.ID, TrainerID: p.TrainerID, ClientID: p.ClientID, Description: p.Description, Schedule: p.Schedule, CreatedAt: p.CreatedAt,
+		})
+	}
+	reports := make([]reportRecord, 0, len(data.ProgressReports))
+	for _, r := range data.ProgressReports {
+		reports = append(reports, reportRecord{
+			ID: r.ID, TrainerID: r.TrainerID, ClientID: r.ClientID, Comments: r.Comments, CreatedAt: r.CreatedAt,
+		})
+	}
+	sessions := make([]sessionRecord, 0, len(data.Sessions))
+	for _, s := range data.Sessions {
+		sessions = append(sessions, sessionRecord{
+			ID: s.ID, UserAgent: s.UserAgent, IP: s.IP, CreatedAt: s.CreatedAt, LastSeenAt: s.LastSeenAt, RevokedAt: s.RevokedAt,
+		})
+	}
+
+	return append(result,
+		dataset{name: "training_plans", records: plans},
+		dataset{name: "progress_reports", records: reports},
+		dataset{name: "sessions", records: sessions},
+	)
+}
+
+func sortedCodes(values models.MeasurementValues) []string {
+	codes := make([]string, 0, len(values))
+	for code := range values {
+		codes = append(codes, code)
+	}
+	sort.Strings(codes)
+
+	return codes
+}
+
+func writeJSON(zw *zip.Writer, name string, v any) error {
+	f, err := zw.Create(name)
+	if err != nil {
+		return err
+	}
+	enc := json.NewEncoder(f)
+	enc.SetIndent("", "  ")
+
+	return enc.Encode(v)
+}
+
+// writeCSV writes slice of record structs as CSV with header taken from json tags
+func writeCSV(zw *zip.Writer, name string, records any) error {
+	f, err := zw.Create(name)
+	if err != nil {
+		return err
+	}
+	w := csv.NewWriter(f)
+
+	rv := reflect.ValueOf(records)
+	rt := rv.Type().Elem()
+	header := make([]string, rt.NumField())
+	for i := range header {
+		header[i], _, _ = strings.Cut(rt.Field(i).Tag.Get("json"), ",")
+	}
+	if err = w.Write(header); err != nil {
+		return err
+	}
+
+	row := make([]string, len(header))
+	for i := 0; i < rv.Len(); i++ {
+		rec := rv.Index(i)
+		for j := range row {
+			row[j] = csvValue(rec.Field(j))
+		}
+		if err = w.Write(row); err != nil {
+			return err
+		}
+	}
+	w.Flush()
+
+	return w.Error()
+}
+
+func csvValue(v reflect.Value) string {
+	if v.Kind() == reflect.Pointer {
+		if v.IsNil() {
+			return ""
+		}
+		v = v.Elem()
+	}
+
+	switch val := v.Interface().(type) {
+	case time.Time:
+		return val.UTC().Format(time.RFC3339)
+	case float64:
+		return strconv.FormatFloat(val, 'f', -1, 64)
+	case string:
+		return escapeFormula(val)
+	default:
+		return fmt.Sprint(val)
+	}
+}
+
+// escapeFormula keeps spreadsheet programs from evaluating user text as formula
+func escapeFormula(s string) string {
+	if s != "" && strings.ContainsRune("=+-@\t\r", rune(s[0])) {
+		return "'" + s
+	}
+
+	return s
+}
@@ -0,0 +1,354 @@
+package exportservice
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"ChadProgress/internal/models"
+	service "ChadProgress/internal/services"
+	"ChadProgress/storage"
+)
+
+//go:generate mockgen -source=export.go -destination=./export_mock.go -package=exportservice
+type Storage interface {
+	GetPersonalData(email string) (*models.PersonalData, error)
+	SaveDataExport(export *models.DataExport) error
+	UpdateDataExport(export *models.DataExport) error
+	GetDataExport(login, id string) (*models.DataExport, error)
+	GetDataExportByToken(hash string) (*models.DataExport, error)
+	HasPendingDataExport(login string) (bool, error)
+	GetExpiredDataExports(now time.Time) ([]models.DataExport, error)
+	DeleteDataExport(id string) error
+	FailPendingDataExports(createdBefore time.Time) (int64, error)
+}
+
+type Options struct {
+	// Dir keeps archives of background exports
+	Dir string
+	// TTL is how long background export can be downloaded
+	TTL time.Duration
+	// SyncMaxRecords is the largest number of records exported right in the request
+	SyncMaxRecords int
+	// Workers build archives in background, at most QueueSize exports wait for them
+	Workers   int
+	QueueSize int
+	// CleanupInterval is how often expired archives are removed
+	CleanupInterval time.Duration
+}
+
+type job struct {
+	export *models.DataExport
+	data   *models.PersonalData
+}
+
+type ExportService struct {
+	storage Storage
+	opts    Options
+	jobs    chan job
+	started time.Time
+	log     *slog.Logger
+}
+
+func NewExportService(storage Storage, opts Options, log *slog.Logger) *ExportService {
+	if opts.Workers < 1 {
+		opts.Workers = 1
+	}
+	if opts.QueueSize < 1 {
+		opts.QueueSize = 1
+	}
+
+	return &ExportService{
+		storage: storage,
+		opts:    opts,
+		jobs:    make(chan job, opts.QueueSize),
+		started: time.Now(),
+		log:     log,
+	}
+}
+
+// Export collects personal data of user. Small accounts get archive right away, for large ones
+// archive is built in background and the returned export tells where to download it.
+func (e *ExportService) Export(ctx context.Context, email string) (*models.ExportResult, error) {
+	const op = "services.export.Export"
+	log := e.log.With(
+		slog.String("op", op),
+	)
+
+	pending, err := e.storage.HasPendingDataExport(email)
+	if err != nil {
+		log.Error("failed to check pending exports", slog.String("error", err.Error()))
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	if pending {
+		return nil, service.ErrExportInProgress
+	}
+
+	data, err := e.storage.GetPersonalData(email)
+	if err != nil {
+		if errors.Is(err, storage.ErrRecordNotFound) {
+			return nil, service.ErrUserNotFound
+		}
+		log.Error("failed to collect personal data", slog.String("error", err.Error()))
+
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	if data.Records() <= e.opts.SyncMaxRecords {
+		var buf bytes.Buffer
+		if err = writeArchive(&buf, data, time.Now()); err != nil {
+			log.Error("failed to write archive", slog.String("error", err.Error()))
+			return nil, fmt.Errorf("%s: %w", op, err)
+		}
+
+		return &models.ExportResult{Archive: buf.Bytes()}, nil
+	}
+
+	return e.enqueue(data)
+}
+
+// enqueue saves pending export and hands data to background workers
+func (e *ExportService) enqueue(data *models.PersonalData) (*models.ExportResult, error) {
+	const op = "services.export.enqueue"
+	log := e.log.With(
+		slog.String("op", op),
+	)
+
+	id, err := randomHex(16)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	token, err := randomHex(32)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	export := &models.DataExport{
+		ID:        id,
+		Login:     data.User.Email,
+		Status:    models.ExportStatusPending,
+		TokenHash: hashToken(token),
+		ExpiresAt: time.Now().Add(e.opts.TTL),
+	}
+	if err = e.storage.SaveDataExport(export); err != nil {
+		log.Error("failed to save export", slog.String("error", err.Error()))
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	select {
+	case e.jobs <- job{export: export, data: data}:
+	default:
+		log.Warn("export queue is full", slog.Int("records", data.Records()))
+		export.Status = models.ExportStatusFailed
+		if err = e.storage.UpdateDataExport(export); err != nil {
+			log.Error("failed to mark export failed", slog.String("error", err.Error()))
+		}
+
+		return nil, service.ErrExportBusy
+	}
+	log.Info("export is queued", slog.String("id", id), slog.Int("records", data.Records()))
+
+	return &models.ExportResult{Export: export, Token: token}, nil
+}
+
+// Status returns export of user
+func (e *ExportService) Status(ctx context.Context, email, id string) (*models.DataExport, error) {
+	const op = "services.export.Status"
+
+	export, err := e.storage.GetDataExport(email, id)
+	if err != nil {
+		if errors.Is(err, storage.ErrRecordNotFound) {
+			return nil, service.ErrExportNotFound
+		}
+
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return export, nil
+}
+
+// Open finds export by download token and opens its archive. Caller closes the archive.
+func (e *ExportService) Open(ctx context.Context, token string) (*models.DataExport, io.ReadCloser, error) {
+	const op = "services.export.Open"
+	log := e.log.With(
+		slog.String("op", op),
+	)
+
+	export, err := e.storage.GetDataExportByToken(hashToken(token))
+	if err != nil {
+		if errors.Is(err, storage.ErrRecordNotFound) {
+			return nil, nil, service.ErrExportNotFound
+		}
+
+		return nil, nil, fmt.Errorf("%s: %w", op, err)
+	}
+	if !time.Now().Before(export.ExpiresAt) {
+		return nil, nil, service.ErrExportExpired
+	}
+	if export.Status != models.ExportStatusReady {
+		return nil, nil, service.ErrExportNotReady
+	}
+
+	f, err := os.Open(export.Path)
+	if err != nil {
+		log.Error("failed to open archive", slog.String("id", export.ID), slog.String("error", err.Error()))
+		return nil, nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return export, f, nil
+}
+
+// Run marks exports interrupted by previous run as failed, then builds queued archives and
+// removes expired ones until ctx is done
+func (e *ExportService) Run(ctx context.Context) {
+	const op = "services.export.Run"
+	log := e.log.With(
+		slog.String("op", op),
+	)
+
+	if n, err := e.storage.FailPendingDataExports(e.started); err != nil {
+		log.Error("failed to fail interrupted exports", slog.String("error", err.Error()))
+	} else if n > 0 {
+		log.Warn("exports were interrupted by restart", slog.Int64("count", n))
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < e.opts.Workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for {
+				select {
+				case <-ctx.Done():
+					return
+				case j := <-e.jobs:
+					e.build(j)
+				}
+			}
+		}()
+	}
+
+	interval := e.opts.CleanupInterval
+	if interval <= 0 {
+		interval = time.Hour
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		e.Cleanup()
+		select {
+		case <-ctx.Done():
+			wg.Wait()
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// build writes archive of the job to disk and marks export ready or failed
+func (e *ExportService) build(j job) {
+	const op = "services.export.build"
+	log := e.log.With(
+		slog.String("op", op),
+		slog.String("id", j.export.ID),
+	)
+
+	export := j.export
+	path := filepath.Join(e.opts.Dir, export.ID+".zip")
+	size, err := writeArchiveFile(path, j.data)
+	if err != nil {
+		log.Error("failed to build archive", slog.String("error", err.Error()))
+		export.Status = models.ExportStatusFailed
+	} else {
+		now := time.Now()
+		export.Status = models.ExportStatusReady
+		export.Path = path
+		export.Size = size
+		export.ReadyAt = &now
+	}
+
+	if err = e.storage.UpdateDataExport(export); err != nil {
+		log.Error("failed to update export", slog.String("error", err.Error()))
+		return
+	}
+	log.Info("export is built", slog.String("status", export.Status), slog.Int64("size", size))
+}
+
+// Cleanup removes expired exports and their archives
+func (e *ExportService) Cleanup() {
+	const op = "services.export.Cleanup"
+	log := e.log.With(
+		slog.String("op", op),
+	)
+
+	exports, err := e.storage.GetExpiredDataExports(time.Now())
+	if err != nil {
+		log.Error("failed to get expired exports", slog.String("error", err.Error()))
+		return
+	}
+
+	for _, export := range exports {
+		if export.Path != "" {
+			if err = os.Remove(export.Path); err != nil && !errors.Is(err, os.ErrNotExist) {
+				log.Error("failed to remove archive", slog.String("id", export.ID), slog.String("error", err.Error()))
+				continue
+			}
+		}
+		if err = e.storage.DeleteDataExport(export.ID); err != nil {
+			log.Error("failed to delete export", slog.String("id", export.ID), slog.String("error", err.Error()))
+		}
+	}
+}
+
+// writeArchiveFile writes archive to temporary file first, so that half written archive is never served
+func writeArchiveFile(path string, data *models.PersonalData) (int64, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return 0, err
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".export-*")
+	if err != nil {
+		return 0, err
+	}
+	defer os.Remove(tmp.Name())
+
+	if err = writeArchive(tmp, data, time.Now()); err != nil {
+		tmp.Close()
+		return 0, err
+	}
+	info, err := tmp.Stat()
+	if err != nil {
+		tmp.Close()
+		return 0, err
+	}
+	if err = tmp.Close(); err != nil {
+		return 0, err
+	}
+
+	return info.Size(), os.Rename(tmp.Name(), path)
+}
+
+func randomHex(n int) (string, error) {
+	b := make([]byte, n)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+
+	return hex.EncodeToString(b), nil
+}
+
+func hashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+
+	return hex.EncodeToString(sum[:])
+}
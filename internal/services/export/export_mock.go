@@ -0,0 +1,168 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: export.go
+
+// Package exportservice is a generated GoMock package.
+package exportservice
+
+import (
+	models "ChadProgress/internal/models"
+	reflect "reflect"
+	time "time"
+
+	gomock "github.com/golang/mock/gomock"
+)
+
+// MockStorage is a mock of Storage interface.
+type MockStorage struct {
+	ctrl     *gomock.Controller
+	recorder *MockStorageMockRecorder
+}
+
+// MockStorageMockRecorder is the mock recorder for MockStorage.
+type MockStorageMockRecorder struct {
+	mock *MockStorage
+}
+
+// NewMockStorage creates a new mock instance.
+func NewMockStorage(ctrl *gomock.Controller) *MockStorage {
+	mock := &MockStorage{ctrl: ctrl}
+	mock.recorder = &MockStorageMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockStorage) EXPECT() *MockStorageMockRecorder {
+	return m.recorder
+}
+
+// DeleteDataExport mocks base method.
+func (m *MockStorage) DeleteDataExport(id string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteDataExport", id)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteDataExport indicates an expected call of DeleteDataExport.
+func (mr *MockStorageMockRecorder) DeleteDataExport(id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteDataExport", reflect.TypeOf((*MockStorage)(nil).DeleteDataExport), id)
+}
+
+// FailPendingDataExports mocks base method.
+func (m *MockStorage) FailPendingDataExports(createdBefore time.Time) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FailPendingDataExports", createdBefore)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FailPendingDataExports indicates an expected call of FailPendingDataExports.
+func (mr *MockStorageMockRecorder) FailPendingDataExports(createdBefore interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FailPendingDataExports", reflect.TypeOf((*MockStorage)(nil).FailPendingDataExports), createdBefore)
+}
+
+// GetDataExport mocks base method.
+func (m *MockStorage) GetDataExport(login, id string) (*models.DataExport, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetDataExport", login, id)
+	ret0, _ := ret[0].(*models.DataExport)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetDataExport indicates an expected call of GetDataExport.
+func (mr *MockStorageMockRecorder) GetDataExport(login, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetDataExport", reflect.TypeOf((*MockStorage)(nil).GetDataExport), login, id)
+}
+
+// GetDataExportByToken mocks base method.
+func (m *MockStorage) GetDataExportByToken(hash string) (*models.DataExport, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetDataExportByToken", hash)
+	ret0, _ := ret[0].(*models.DataExport)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetDataExportByToken indicates an expected call of GetDataExportByToken.
+func (mr *MockStorageMockRecorder) GetDataExportByToken(hash interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetDataExportByToken", reflect.TypeOf((*MockStorage)(nil).GetDataExportByToken), hash)
+}
+
+// GetExpiredDataExports mocks base method.
+func (m *MockStorage) GetExpiredDataExports(now time.Time) ([]models.DataExport, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetExpiredDataExports", now)
+	ret0, _ := ret[0].([]models.DataExport)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetExpiredDataExports indicates an expected call of GetExpiredDataExports.
+func (mr *MockStorageMockRecorder) GetExpiredDataExports(now interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetExpiredDataExports", reflect.TypeOf((*MockStorage)(nil).GetExpiredDataExports), now)
+}
+
+// GetPersonalData mocks base method.
+func (m *MockStorage) GetPersonalData(email string) (*models.PersonalData, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetPersonalData", email)
+	ret0, _ := ret[0].(*models.PersonalData)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetPersonalData indicates an expected call of GetPersonalData.
+func (mr *MockStorageMockRecorder) GetPersonalData(email interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetPersonalData", reflect.TypeOf((*MockStorage)(nil).GetPersonalData), email)
+}
+
+// HasPendingDataExport mocks base method.
+func (m *MockStorage) HasPendingDataExport(login string) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "HasPendingDataExport", login)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// HasPendingDataExport indicates an expected call of HasPendingDataExport.
+func (mr *MockStorageMockRecorder) HasPendingDataExport(login interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "HasPendingDataExport", reflect.TypeOf((*MockStorage)(nil).HasPendingDataExport), login)
+}
+
+// SaveDataExport mocks base method.
+func (m *MockStorage) SaveDataExport(export *models.DataExport) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SaveDataExport", export)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SaveDataExport indicates an expected call of SaveDataExport.
+func (mr *MockStorageMockRecorder) SaveDataExport(export interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SaveDataExport", reflect.TypeOf((*MockStorage)(nil).SaveDataExport), export)
+}
+
+// UpdateDataExport mocks base method.
+func (m *MockStorage) UpdateDataExport(export *models.DataExport) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateDataExport", export)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateDataExport indicates an expected call of UpdateDataExport.
+func (mr *MockStorageMockRecorder) UpdateDataExport(export interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateDataExport", reflect.TypeOf((*MockStorage)(nil).UpdateDataExport), export)
+}
@@ -0,0 +1,234 @@
+package exportservice
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"ChadProgress/internal/models"
+	service "ChadProgress/internal/services"
+	"ChadProgress/storage"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const email = "user@example.com"
+
+func newTestService(t *testing.T, opts Options) (*ExportService, *MockStorage) {
+	ctrl := gomock.NewController(t)
+	st := NewMockStorage(ctrl)
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	if opts.Dir == "" {
+		opts.Dir = t.TempDir()
+	}
+	if opts.TTL == 0 {
+		opts.TTL = time.Hour
+	}
+
+	return NewExportService(st, opts, logger), st
+}
+
+func testData() *models.PersonalData {
+	measured := time.Date(2026, time.March, 1, 10, 0, 0, 0, time.UTC)
+
+	return &models.PersonalData{
+		User:   models.User{ID: 7, Email: email, Name: "=HYPERLINK(\"x\")", Role: models.RoleClient, Status: models.UserStatusActive},
+		Client: &models.Client{ID: 3, UserID: 7, TrainerID: 1, Height: 180, Weight: 80.5, Goals: models.MeasurementValues{"weight": 75}},
+		Metrics: []models.Metric{
+			{ID: 1, ClientID: 3, Measurements: models.MeasurementValues{"height": 180, "weight": 81}, MeasuredAt: measured},
+			{ID: 2, ClientID: 3, Measurements: models.MeasurementValues{"height": 180, "weight": 80.5}, MeasuredAt: measured.Add(24 * time.Hour)},
+		},
+		TrainingPlans:   []models.TrainingPlan{{ID: 5, TrainerID: 1, ClientID: 3, Description: "Run"}},
+		ProgressReports: []models.ProgressReport{{ID: 6, TrainerID: 1, ClientID: 3, Comments: "Good"}},
+		Sessions:        []models.Session{{ID: "s1", Login: email, UserAgent: "curl/8.0", IP: "10.0.0.1"}},
+	}
+}
+
+func readArchive(t *testing.T, b []byte) map[string][]byte {
+	zr, err := zip.NewReader(bytes.NewReader(b), int64(len(b)))
+	require.NoError(t, err)
+
+	files := make(map[string][]byte)
+	for _, f := range zr.File {
+		rc, err := f.Open()
+		require.NoError(t, err)
+		files[f.Name], err = io.ReadAll(rc)
+		require.NoError(t, err)
+		rc.Close()
+	}
+
+	return files
+}
+
+func TestExportSmallAccount(t *testing.T) {
+	s, st := newTestService(t, Options{SyncMaxRecords: 100})
+	st.EXPECT().HasPendingDataExport(email).Return(false, nil)
+	st.EXPECT().GetPersonalData(email).Return(testData(), nil)
+
+	res, err := s.Export(context.Background(), email)
+	require.NoError(t, err)
+	require.Nil(t, res.Export)
+
+	files := readArchive(t, res.Archive)
+	for _, name := range []string{"user", "client_profile", "goals", "metrics", "training_plans", "progress_reports", "sessions"} {
+		assert.Contains(t, files, name+".json")
+		assert.Contains(t, files, name+".csv")
+	}
+	assert.NotContains(t, files, "trainer_profile.json")
+
+	var m manifest
+	require.NoError(t, json.Unmarshal(files["manifest.json"], &m))
+	assert.Contains(t, m.Files, manifestFile{Name: "metrics.csv", Records: 4})
+
+	var metrics []metricRecord
+	require.NoError(t, json.Unmarshal(files["metrics.json"], &metrics))
+	assert.Equal(t, metricRecord{MetricID: 2, Type: "weight", Value: 80.5, MeasuredAt: time.Date(2026, time.March, 2, 10, 0, 0, 0, time.UTC)}, metrics[3])
+
+	rows, err := csv.NewReader(bytes.NewReader(files["metrics.csv"])).ReadAll()
+	require.NoError(t, err)
+	require.Len(t, rows, 5, "a row per measurement")
+	assert.Equal(t, []string{"metric_id", "type", "value", "measured_at"}, rows[0])
+	assert.Equal(t, []string{"2", "weight", "80.5", "2026-03-02T10:00:00Z"}, rows[4])
+
+	rows, err = csv.NewReader(bytes.NewReader(files["user.csv"])).ReadAll()
+	require.NoError(t, err)
+	assert.Equal(t, `'=HYPERLINK("x")`, rows[1][2], "formulas must not reach spreadsheets")
+	assert.Equal(t, "", rows[1][6], "nil time is empty")
+}
+
+func TestExportLargeAccount(t *testing.T) {
+	ctx := context.Background()
+	s, st := newTestService(t, Options{SyncMaxRecords: 3})
+	st.EXPECT().HasPendingDataExport(email).Return(false, nil)
+	st.EXPECT().GetPersonalData(email).Return(testData(), nil)
+
+	var saved *models.DataExport
+	st.EXPECT().SaveDataExport(gomock.Any()).DoAndReturn(func(e *models.DataExport) error {
+		saved = e
+		return nil
+	})
+
+	res, err := s.Export(ctx, email)
+	require.NoError(t, err)
+	require.Nil(t, res.Archive)
+	require.NotEmpty(t, res.Token)
+	assert.Equal(t, models.ExportStatusPending, res.Export.Status)
+	assert.Equal(t, hashToken(res.Token), saved.TokenHash)
+
+	st.EXPECT().UpdateDataExport(saved).Return(nil)
+	s.build(<-s.jobs)
+	require.Equal(t, models.ExportStatusReady, saved.Status)
+	require.NotNil(t, saved.ReadyAt)
+
+	st.EXPECT().GetDataExportByToken(hashToken(res.Token)).Return(saved, nil)
+	export, archive, err := s.Open(ctx, res.Token)
+	require.NoError(t, err)
+	defer archive.Close()
+	assert.Equal(t, saved.ID, export.ID)
+
+	b, err := io.ReadAll(archive)
+	require.NoError(t, err)
+	assert.EqualValues(t, saved.Size, len(b))
+	assert.Contains(t, readArchive(t, b), "sessions.csv")
+}
+
+func TestExportErrors(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("Export in progress", func(t *testing.T) {
+		s, st := newTestService(t, Options{})
+		st.EXPECT().HasPendingDataExport(email).Return(true, nil)
+
+		_, err := s.Export(ctx, email)
+		assert.ErrorIs(t, err, service.ErrExportInProgress)
+	})
+
+	t.Run("User not found", func(t *testing.T) {
+		s, st := newTestService(t, Options{})
+		st.EXPECT().HasPendingDataExport(email).Return(false, nil)
+		st.EXPECT().GetPersonalData(email).Return(nil, storage.ErrRecordNotFound)
+
+		_, err := s.Export(ctx, email)
+		assert.ErrorIs(t, err, service.ErrUserNotFound)
+	})
+
+	t.Run("Queue is full", func(t *testing.T) {
+		s, st := newTestService(t, Options{QueueSize: 1})
+		s.jobs <- job{}
+		st.EXPECT().HasPendingDataExport(email).Return(false, nil)
+		st.EXPECT().GetPersonalData(email).Return(testData(), nil)
+		st.EXPECT().SaveDataExport(gomock.Any()).Return(nil)
+		st.EXPECT().UpdateDataExport(gomock.Any()).DoAndReturn(func(e *models.DataExport) error {
+			assert.Equal(t, models.ExportStatusFailed, e.Status)
+			return nil
+		})
+
+		_, err := s.Export(ctx, email)
+		assert.ErrorIs(t, err, service.ErrExportBusy)
+	})
+}
+
+func TestOpen(t *testing.T) {
+	ctx := context.Background()
+
+	tests := []struct {
+		name    string
+		export  *models.DataExport
+		findErr error
+		wantErr error
+	}{
+		{
+			name:    "Unknown token",
+			findErr: storage.ErrRecordNotFound,
+			wantErr: service.ErrExportNotFound,
+		},
+		{
+			name:    "Expired",
+			export:  &models.DataExport{Status: models.ExportStatusReady, ExpiresAt: time.Now().Add(-time.Minute)},
+			wantErr: service.ErrExportExpired,
+		},
+		{
+			name:    "Not ready",
+			export:  &models.DataExport{Status: models.ExportStatusPending, ExpiresAt: time.Now().Add(time.Hour)},
+			wantErr: service.ErrExportNotReady,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, st := newTestService(t, Options{})
+			st.EXPECT().GetDataExportByToken(hashToken("token")).Return(tt.export, tt.findErr)
+
+			_, _, err := s.Open(ctx, "token")
+			assert.ErrorIs(t, err, tt.wantErr)
+		})
+	}
+}
+
+func TestCleanup(t *testing.T) {
+	s, st := newTestService(t, Options{})
+	path := filepath.Join(s.opts.Dir, "a.zip")
+	require.NoError(t, os.WriteFile(path, []byte("zip"), 0o600))
+
+	st.EXPECT().GetExpiredDataExports(gomock.Any()).Return([]models.DataExport{
+		{ID: "a", Path: path},
+		{ID: "b", Path: filepath.Join(s.opts.Dir, "missing.zip")},
+		{ID: "c"},
+	}, nil)
+	st.EXPECT().DeleteDataExport("a").Return(nil)
+	st.EXPECT().DeleteDataExport("b").Return(nil)
+	st.EXPECT().DeleteDataExport("c").Return(nil)
+
+	s.Cleanup()
+	assert.NoFileExists(t, path)
+}
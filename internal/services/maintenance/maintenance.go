@@ -0,0 +1,81 @@
+package maintenanceservice
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+)
+
+//go:generate mockgen -source=maintenance.go -destination=./maintenance_mock.go -package=maintenanceservice
+type Storage interface {
+	EnsureMetricPartitions(ctx context.Context, from, to time.Time) (int, error)
+}
+
+type Options struct {
+	// Interval is how often partitions are checked
+	Interval time.Duration
+	// MonthsAhead is how many months after the current one get partitions in advance
+	MonthsAhead int
+}
+
+// MaintenanceService keeps storage ready for new data: metrics are partitioned by month and
+// partitions must exist before metrics of their month are added
+type MaintenanceService struct {
+	storage Storage
+	opts    Options
+	now     func() time.Time
+	log     *slog.Logger
+}
+
+func NewMaintenanceService(storage Storage, opts Options, log *slog.Logger) *MaintenanceService {
+	if opts.Interval <= 0 {
+		opts.Interval = 24 * time.Hour
+	}
+	if opts.MonthsAhead < 1 {
+		opts.MonthsAhead = 1
+	}
+
+	return &MaintenanceService{
+		storage: storage,
+		opts:    opts,
+		now:     time.Now,
+		log:     log,
+	}
+}
+
+// EnsurePartitions creates metric partitions of the current month and MonthsAhead next ones
+func (m *MaintenanceService) EnsurePartitions(ctx context.Context) error {
+	const op = "services.maintenance.EnsurePartitions"
+	log := m.log.With(
+		slog.String("op", op),
+	)
+
+	now := m.now()
+	created, err := m.storage.EnsureMetricPartitions(ctx, now, now.AddDate(0, m.opts.MonthsAhead, 0))
+	if err != nil {
+		log.Error("failed to create metric partitions", slog.String("error", err.Error()))
+
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	if created > 0 {
+		log.Info("metric partitions created", slog.Int("count", created))
+	}
+
+	return nil
+}
+
+// Run ensures partitions right away and then every Interval until ctx is done
+func (m *MaintenanceService) Run(ctx context.Context) {
+	ticker := time.NewTicker(m.opts.Interval)
+	defer ticker.Stop()
+	for {
+		// failures are logged, the next tick retries
+		_ = m.EnsurePartitions(ctx)
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
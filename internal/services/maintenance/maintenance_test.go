@@ -0,0 +1,45 @@
+package maintenanceservice
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEnsurePartitions(t *testing.T) {
+	ctx := context.Background()
+	now := time.Date(2026, time.October, 19, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name    string
+		opts    Options
+		to      time.Time
+		err     error
+		wantErr bool
+	}{
+		{name: "Months ahead", opts: Options{MonthsAhead: 3}, to: now.AddDate(0, 3, 0)},
+		{name: "At least next month", opts: Options{}, to: now.AddDate(0, 1, 0)},
+		{name: "Storage failure", opts: Options{MonthsAhead: 1}, to: now.AddDate(0, 1, 0), err: errors.New("lock timeout"), wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			st := NewMockStorage(gomock.NewController(t))
+			s := NewMaintenanceService(st, tt.opts, slog.New(slog.NewTextHandler(io.Discard, nil)))
+			s.now = func() time.Time { return now }
+			st.EXPECT().EnsureMetricPartitions(ctx, now, tt.to).Return(1, tt.err)
+
+			err := s.EnsurePartitions(ctx)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+		})
+	}
+}
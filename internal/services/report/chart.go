@@ -0,0 +1,182 @@
+package reportservice
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"log/slog"
+	"math"
+	"time"
+
+	"ChadProgress/internal/lib/apperr"
+	"ChadProgress/internal/lib/chart"
+	"ChadProgress/internal/models"
+)
+
+// Defaults and limits of chart options
+const (
+	defaultChartWidth  = 640
+	defaultChartHeight = 320
+	defaultChartRange  = 90 * 24 * time.Hour
+	maxChartRange      = 5 * 366 * 24 * time.Hour
+	maxAverageDays     = 90
+)
+
+// Chart returns SVG line chart of measurement of client with code over [opts.From, opts.To), with goal
+// line of client and moving average of the measurement. BMI is derived from weight and height of
+// metrics recorded without it.
+func (s *ReportService) Chart(ctx context.Context, viewerEmail string, clientID uint, code string,
+	opts models.ChartOptions, view models.ReportView) (*models.ReportFile, error) {
+	const op = "services.report.Chart"
+	log := s.log.With(
+		slog.String("op", op),
+	)
+
+	opts, err := s.chartOptions(opts)
+	if err != nil {
+		return nil, err
+	}
+
+	sub, err := s.subject(log, viewerEmail, clientID, view)
+	if err != nil {
+		return nil, err
+	}
+	var typ *models.MeasurementType
+	for i := range sub.types {
+		if sub.types[i].Code == code {
+			typ = &sub.types[i]
+		}
+	}
+	if typ == nil {
+		return nil, apperr.ErrValidation.WithFields(apperr.FieldError{Field: "code", Message: "unknown measurement type"})
+	}
+
+	// the average of the first days of the range takes measurements before it
+	window := time.Duration(opts.AverageDays) * 24 * time.Hour
+	metrics, err := s.storage.GetMetrics(clientID, opts.From.Add(-window), opts.To)
+	if err != nil {
+		log.Error("failed to get metrics", slog.String("error", err.Error()))
+
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	var b bytes.Buffer
+	svg := chart.SVGOptions{Width: float64(opts.Width), Height: float64(opts.Height), Theme: chart.Themes[opts.Theme]}
+	if err := chart.WriteSVG(&b, measurementChart(metrics, sub, *typ, opts), svg); err != nil {
+		log.Error("failed to write chart", slog.String("error", err.Error()))
+
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return &models.ReportFile{
+		Name:        fmt.Sprintf("chadprogress-%s-%d-%s.svg", code, clientID, s.now().In(sub.loc).Format(time.DateOnly)),
+		ContentType: models.ContentTypeSVG,
+		Content:     b.Bytes(),
+	}, nil
+}
+
+// chartOptions fills in defaults of options and checks them
+func (s *ReportService) chartOptions(opts models.ChartOptions) (models.ChartOptions, error) {
+	if opts.To.IsZero() {
+		opts.To = s.now()
+	}
+	if opts.From.IsZero() {
+		opts.From = opts.To.Add(-defaultChartRange)
+	}
+	if opts.Width == 0 {
+		opts.Width = defaultChartWidth
+	}
+	if opts.Height == 0 {
+		opts.Height = defaultChartHeight
+	}
+	if opts.Theme == "" {
+		opts.Theme = chart.ThemeLight
+	}
+
+	var fields []apperr.FieldError
+	if !opts.From.Before(opts.To) {
+		fields = append(fields, apperr.FieldError{Field: "from", Message: "must be before to"})
+	} else if opts.To.Sub(opts.From) > maxChartRange {
+		fields = append(fields, apperr.FieldError{Field: "from", Message: "range must not be longer than 5 years"})
+	}
+	if opts.Width < 200 || opts.Width > 2000 {
+		fields = append(fields, apperr.FieldError{Field: "width", Message: "must be between 200 and 2000"})
+	}
+	if opts.Height < 120 || opts.Height > 1200 {
+		fields = append(fields, apperr.FieldError{Field: "height", Message: "must be between 120 and 1200"})
+	}
+	if _, ok := chart.Themes[opts.Theme]; !ok {
+		fields = append(fields, apperr.FieldError{Field: "theme", Message: "must be light or dark"})
+	}
+	if opts.AverageDays < 0 || opts.AverageDays > maxAverageDays {
+		fields = append(fields, apperr.FieldError{Field: "average", Message: fmt.Sprintf("must be between 0 and %d", maxAverageDays)})
+	}
+	if len(fields) > 0 {
+		return opts, apperr.ErrValidation.WithFields(fields...)
+	}
+
+	return opts, nil
+}
+
+// measurementChart makes chart of measurement in units and time zone of the viewer. Metrics may start
+// before the range for the moving average.
+func measurementChart(metrics []models.Metric, sub *subject, typ models.MeasurementType, opts models.ChartOptions) chart.Chart {
+	c := chart.Chart{
+		Title: typ.Name,
+		Unit:  sub.conv.Unit(typ.Code),
+		From:  opts.From.In(sub.loc),
+		To:    opts.To.In(sub.loc),
+	}
+
+	var all []chart.Point
+	for _, m := range metrics {
+		if v, ok := chartValue(m, typ.Code, sub.client.Height); ok {
+			all = append(all, chart.Point{Time: m.MeasuredAt.In(sub.loc), Value: sub.conv.FromStored(typ.Code, v)})
+		}
+	}
+	c.Series = []chart.Series{{Name: typ.Name, Points: inRange(all, opts.From)}}
+	if opts.AverageDays > 0 {
+		avg := chart.MovingAverage(all, time.Duration(opts.AverageDays)*24*time.Hour)
+		c.Series = append(c.Series, chart.Series{
+			Name:   fmt.Sprintf("%d-day average", opts.AverageDays),
+			Points: inRange(avg, opts.From),
+			Trend:  true,
+		})
+	}
+	if goal, ok := sub.client.Goals[typ.Code]; ok {
+		c.Goals = []float64{sub.conv.FromStored(typ.Code, goal)}
+	}
+
+	return c
+}
+
+// chartValue returns stored value of measurement with code in metric. BMI is derived from weight and
+// height, height of the metric or else of the client profile.
+func chartValue(m models.Metric, code string, height float64) (float64, bool) {
+	if v, ok := m.Measurements[code]; ok {
+		return v, true
+	}
+	if code != models.MeasurementBMI {
+		return 0, false
+	}
+	weight, ok := m.Measurements[models.MeasurementWeight]
+	if h, measured := m.Measurements[models.MeasurementHeight]; measured {
+		height = h
+	}
+	if !ok || height <= 0 {
+		return 0, false
+	}
+
+	return math.Round(weight/math.Pow(height/100, 2)*10) / 10, true
+}
+
+// inRange returns points measured at from or later, points are ordered by time
+func inRange(points []chart.Point, from time.Time) []chart.Point {
+	for i, p := range points {
+		if !p.Time.Before(from) {
+			return points[i:]
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,96 @@
+package reportservice
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"ChadProgress/internal/lib/apperr"
+	"ChadProgress/internal/lib/chart"
+	"ChadProgress/internal/lib/units"
+	"ChadProgress/internal/models"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestChart(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("Weight with goal and average in units of viewer", func(t *testing.T) {
+		s, st := newTestService(t)
+		from := time.Date(2026, time.October, 1, 0, 0, 0, 0, time.UTC)
+		to := from.AddDate(0, 0, 18)
+		st.EXPECT().GetUserByEmail(trainerEmail).Return(trainerUser, nil)
+		st.EXPECT().GetTrainerByUserID(uint(7)).Return(trainer, nil)
+		st.EXPECT().GetClientByID(uint(3)).Return(client, nil)
+		st.EXPECT().GetMeasurementTypes().Return(models.DefaultMeasurementTypes, nil)
+		st.EXPECT().GetMetrics(uint(3), from.AddDate(0, 0, -7), to).Return(metrics, nil)
+
+		file, err := s.Chart(ctx, trainerEmail, 3, models.MeasurementWeight,
+			models.ChartOptions{From: from, To: to, Theme: chart.ThemeDark, AverageDays: 7}, imperial)
+		require.NoError(t, err)
+		assert.Equal(t, "chadprogress-weight-3-2026-10-19.svg", file.Name)
+		assert.Equal(t, models.ContentTypeSVG, file.ContentType)
+
+		doc := string(file.Content)
+		assert.True(t, strings.HasPrefix(doc, `<svg xmlns="http://www.w3.org/2000/svg" width="640" height="320"`))
+		assert.Contains(t, doc, "<title>Weight, lb</title>")
+		assert.Contains(t, doc, ">Goal 165</text>", "75 kg")
+		assert.Contains(t, doc, ">7-day average</text>")
+		assert.Contains(t, doc, chart.Themes[chart.ThemeDark].Background)
+	})
+
+	t.Run("Invalid options", func(t *testing.T) {
+		s, _ := newTestService(t)
+
+		_, err := s.Chart(ctx, clientEmail, 3, models.MeasurementWeight,
+			models.ChartOptions{From: now, To: now.AddDate(0, 0, -1), Width: 50, Theme: "sepia", AverageDays: 365}, moscowView)
+		require.ErrorIs(t, err, apperr.ErrValidation)
+		var fields []string
+		for _, f := range apperr.From(err).Fields {
+			fields = append(fields, f.Field)
+		}
+		assert.Equal(t, []string{"from", "width", "theme", "average"}, fields)
+	})
+
+	t.Run("Unknown measurement type", func(t *testing.T) {
+		s, st := newTestService(t)
+		st.EXPECT().GetUserByEmail(clientEmail).Return(clientUser, nil)
+		st.EXPECT().GetClientByUserID(uint(5)).Return(client, nil)
+		st.EXPECT().GetMeasurementTypes().Return(models.DefaultMeasurementTypes, nil)
+
+		_, err := s.Chart(ctx, clientEmail, 3, "wingspan", models.ChartOptions{}, moscowView)
+		require.ErrorIs(t, err, apperr.ErrValidation)
+		assert.Equal(t, "code", apperr.From(err).Fields[0].Field)
+	})
+}
+
+func TestMeasurementChart(t *testing.T) {
+	s, _ := newTestService(t)
+	opts, err := s.chartOptions(models.ChartOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, now.Add(-defaultChartRange), opts.From, "the last 90 days by default")
+	assert.Equal(t, now, opts.To)
+
+	from := time.Date(2026, time.September, 25, 0, 0, 0, 0, time.UTC)
+	opts, err = s.chartOptions(models.ChartOptions{From: from, AverageDays: 30})
+	require.NoError(t, err)
+	sub := &subject{
+		client: client,
+		types:  models.DefaultMeasurementTypes,
+		conv:   units.NewConverter(units.Metric, map[string]string{"weight": "kg", "bmi": "kg/m2"}),
+		loc:    time.UTC,
+	}
+	old := models.Metric{Measurements: models.MeasurementValues{"weight": 90}, MeasuredAt: from.AddDate(0, 0, -4)}
+	c := measurementChart(append([]models.Metric{old}, metrics...), sub, models.DefaultMeasurementTypes[3], opts)
+
+	require.Len(t, c.Series, 2)
+	assert.Equal(t, "Body mass index", c.Title)
+	assert.Len(t, c.Series[0].Points, 2, "measurements before the range are left out")
+	assert.Equal(t, []float64{25.5, 24.7}, []float64{c.Series[0].Points[0].Value, c.Series[0].Points[1].Value}, "derived from weight and height of profile")
+	assert.InDelta(t, (27.8+25.5)/2, c.Series[1].Points[0].Value, 1e-9, "average takes measurements before the range")
+	assert.Equal(t, "30-day average", c.Series[1].Name)
+	assert.Empty(t, c.Goals)
+}
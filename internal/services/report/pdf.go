@@ -0,0 +1,317 @@
+package reportservice
+
+import (
+	"bytes"
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"time"
+
+	"ChadProgress/internal/lib/chart"
+	"ChadProgress/internal/models"
+
+	"github.com/go-pdf/fpdf"
+	"golang.org/x/image/font/gofont/gobold"
+	"golang.org/x/image/font/gofont/goregular"
+)
+
+const (
+	// summaryReports is how many latest progress reports summary shows
+	summaryReports = 3
+
+	fontFamily = "Go"
+	margin     = 15.0
+	lineHeight = 5.0
+	chartWidth = 180.0
+	// chartHeight includes title of chart and labels of time axis
+	chartHeight = 70.0
+)
+
+// summaryCharts are measurements summary draws charts of
+var summaryCharts = []string{models.MeasurementWeight, models.MeasurementBodyFat}
+
+// summaryData is everything summary report shows
+type summaryData struct {
+	*subject
+	user *models.User
+	// trainer is nil when client has not selected one
+	trainer     *models.User
+	metrics     []models.Metric
+	plan        *models.TrainingPlan
+	reports     []models.ProgressReport
+	generatedAt time.Time
+}
+
+// writeSummary renders summary report as A4 PDF. Go fonts are embedded, so that names and comments in
+// any European script are printed.
+func writeSummary(d summaryData) ([]byte, error) {
+	pdf := fpdf.New("P", "mm", "A4", "")
+	pdf.AddUTF8FontFromBytes(fontFamily, "", goregular.TTF)
+	pdf.AddUTF8FontFromBytes(fontFamily, "B", gobold.TTF)
+	pdf.SetMargins(margin, margin, margin)
+	pdf.SetAutoPageBreak(true, margin)
+	pdf.SetTitle("Progress report of "+d.user.Name, true)
+	pdf.SetCreator("ChadProgress", true)
+	pdf.SetCreationDate(d.generatedAt)
+	pdf.AliasNbPages("")
+	pdf.SetFooterFunc(func() {
+		pdf.SetY(-margin + 3)
+		pdf.SetFont(fontFamily, "", 8)
+		pdf.SetTextColor(128, 128, 128)
+		pdf.CellFormat(0, lineHeight, fmt.Sprintf("Page %d of {nb}", pdf.PageNo()), "", 0, "C", false, 0, "")
+	})
+	pdf.AddPage()
+
+	pdf.SetFont(fontFamily, "B", 18)
+	pdf.CellFormat(0, 10, "Progress report", "", 1, "", false, 0, "")
+	pdf.SetFont(fontFamily, "", 9)
+	pdf.SetTextColor(128, 128, 128)
+	pdf.CellFormat(0, lineHeight, fmt.Sprintf("Generated %s, %s time", d.generatedAt.Format("2 January 2006 15:04"),
+		d.loc.String()), "", 1, "", false, 0, "")
+	pdf.SetTextColor(0, 0, 0)
+
+	heading(pdf, "Profile")
+	field(pdf, "Name", d.user.Name)
+	field(pdf, "Email", d.user.Email)
+	if d.client.Height > 0 {
+		field(pdf, "Height", d.formatValue(models.MeasurementHeight, d.client.Height))
+	}
+	trainer := "not selected"
+	if d.trainer != nil {
+		trainer = d.trainer.Name
+	}
+	field(pdf, "Trainer", trainer)
+
+	latest := latestValues(d.metrics)
+	heading(pdf, "Goals")
+	goalsTable(pdf, d, latest)
+
+	heading(pdf, "Progress over the last year")
+	for _, code := range summaryCharts {
+		summaryChart(pdf, d, code)
+	}
+
+	heading(pdf, "Training plan")
+	if d.plan == nil {
+		note(pdf, "No training plan yet")
+	} else {
+		note(pdf, "Created "+d.plan.CreatedAt.In(d.loc).Format("2 January 2006"))
+		pdf.MultiCell(0, lineHeight, d.plan.Description, "", "", false)
+		if d.plan.Schedule != "" {
+			pdf.SetFont(fontFamily, "B", 10)
+			pdf.CellFormat(0, lineHeight+2, "Schedule", "", 1, "", false, 0, "")
+			pdf.SetFont(fontFamily, "", 10)
+			pdf.MultiCell(0, lineHeight, d.plan.Schedule, "", "", false)
+		}
+	}
+
+	heading(pdf, "Trainer comments")
+	if len(d.reports) == 0 {
+		note(pdf, "No progress reports yet")
+	}
+	for _, r := range d.reports {
+		pdf.SetFont(fontFamily, "B", 10)
+		pdf.CellFormat(0, lineHeight+1, r.CreatedAt.In(d.loc).Format("2 January 2006"), "", 1, "", false, 0, "")
+		pdf.SetFont(fontFamily, "", 10)
+		pdf.MultiCell(0, lineHeight, r.Comments, "", "", false)
+		pdf.Ln(2)
+	}
+
+	var buf bytes.Buffer
+	if err := pdf.Output(&buf); err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
+
+func heading(pdf *fpdf.Fpdf, text string) {
+	pdf.Ln(4)
+	pdf.SetFont(fontFamily, "B", 13)
+	pdf.CellFormat(0, 8, text, "B", 1, "", false, 0, "")
+	pdf.Ln(2)
+	pdf.SetFont(fontFamily, "", 10)
+}
+
+func field(pdf *fpdf.Fpdf, name, value string) {
+	pdf.SetFont(fontFamily, "B", 10)
+	pdf.CellFormat(30, lineHeight+1, name, "", 0, "", false, 0, "")
+	pdf.SetFont(fontFamily, "", 10)
+	pdf.CellFormat(0, lineHeight+1, value, "", 1, "", false, 0, "")
+}
+
+func note(pdf *fpdf.Fpdf, text string) {
+	pdf.SetTextColor(128, 128, 128)
+	pdf.CellFormat(0, lineHeight+1, text, "", 1, "", false, 0, "")
+	pdf.SetTextColor(0, 0, 0)
+}
+
+// goalsTable lists goals of client with latest values and what is left to reach them
+func goalsTable(pdf *fpdf.Fpdf, d summaryData, latest map[string]models.Metric) {
+	if len(d.client.Goals) == 0 {
+		note(pdf, "No goals are set")
+		return
+	}
+
+	widths := []float64{55, 30, 35, 30, 30}
+	pdf.SetFont(fontFamily, "B", 10)
+	for i, h := range []string{"Measurement", "Latest", "Measured", "Goal", "To go"} {
+		pdf.CellFormat(widths[i], lineHeight+2, h, "B", 0, "", false, 0, "")
+	}
+	pdf.Ln(-1)
+	pdf.SetFont(fontFamily, "", 10)
+
+	for _, t := range d.types {
+		goal, ok := d.client.Goals[t.Code]
+		if !ok {
+			continue
+		}
+		cells := []string{t.Name, "-", "-", d.formatValue(t.Code, goal), "-"}
+		if m, ok := latest[t.Code]; ok {
+			v := m.Measurements[t.Code]
+			cells[1] = d.formatValue(t.Code, v)
+			cells[2] = m.MeasuredAt.In(d.loc).Format("2 Jan 2006")
+			left := d.conv.FromStored(t.Code, goal) - d.conv.FromStored(t.Code, v)
+			cells[4] = strconv.FormatFloat(math.Round(left*100)/100, 'f', -1, 64) + " " + d.conv.Unit(t.Code)
+			if left > 0 {
+				cells[4] = "+" + cells[4]
+			}
+		}
+		for i, c := range cells {
+			pdf.CellFormat(widths[i], lineHeight+1, c, "", 0, "", false, 0, "")
+		}
+		pdf.Ln(-1)
+	}
+}
+
+// summaryChart draws chart of measurement with goal line of client
+func summaryChart(pdf *fpdf.Fpdf, d summaryData, code string) {
+	name, unit := code, d.conv.Unit(code)
+	for _, t := range d.types {
+		if t.Code == code {
+			name = t.Name
+		}
+	}
+
+	_, pageHeight := pdf.GetPageSize()
+	if pdf.GetY()+chartHeight > pageHeight-margin {
+		pdf.AddPage()
+	}
+
+	c := chart.Chart{Title: name, Unit: unit, Series: []chart.Series{{Name: name}}}
+	for _, m := range d.metrics {
+		if v, ok := m.Measurements[code]; ok {
+			c.Series[0].Points = append(c.Series[0].Points, chart.Point{Time: m.MeasuredAt.In(d.loc), Value: d.conv.FromStored(code, v)})
+		}
+	}
+	if goal, ok := d.client.Goals[code]; ok {
+		c.Goals = []float64{d.conv.FromStored(code, goal)}
+	}
+
+	pdf.SetFont(fontFamily, "B", 10)
+	pdf.CellFormat(0, lineHeight+1, fmt.Sprintf("%s, %s", name, unit), "", 1, "", false, 0, "")
+	pdf.SetFont(fontFamily, "", 10)
+	top := pdf.GetY()
+	// room for value labels on the left and time labels below
+	plot := chart.Rect{X: margin + 12, Y: top + 2, W: chartWidth - 12, H: chartHeight - 20}
+	layout, ok := chart.NewLayout(c, plot)
+	if !ok {
+		note(pdf, "No measurements in the last year")
+		pdf.Ln(2)
+		return
+	}
+	drawChart(pdf, c, layout)
+	pdf.SetY(top + chartHeight - 6)
+}
+
+// drawChart draws axes, grid, goal lines and series of the chart laid out on the page
+func drawChart(pdf *fpdf.Fpdf, c chart.Chart, l chart.Layout) {
+	p := l.Plot
+	pdf.SetFont(fontFamily, "", 7)
+	pdf.SetTextColor(96, 96, 96)
+	pdf.SetLineWidth(0.1)
+	pdf.SetDrawColor(220, 220, 220)
+	for _, v := range l.ValueTicks {
+		y := l.Y(v)
+		pdf.Line(p.X, y, p.X+p.W, y)
+		pdf.SetXY(p.X-13, y-2)
+		pdf.CellFormat(12, 4, l.ValueLabel(v), "", 0, "R", false, 0, "")
+	}
+	pdf.SetDrawColor(128, 128, 128)
+	for _, t := range l.TimeTicks {
+		x := l.X(t)
+		pdf.Line(x, p.Y+p.H, x, p.Y+p.H+1)
+		pdf.SetXY(x-10, p.Y+p.H+1.5)
+		pdf.CellFormat(20, 4, l.TimeLabel(t), "", 0, "C", false, 0, "")
+	}
+	pdf.Rect(p.X, p.Y, p.W, p.H, "D")
+
+	pdf.SetDrawColor(46, 160, 67)
+	pdf.SetTextColor(46, 160, 67)
+	pdf.SetLineWidth(0.3)
+	pdf.SetDashPattern([]float64{1.5, 1}, 0)
+	for _, g := range c.Goals {
+		y := l.Y(g)
+		pdf.Line(p.X, y, p.X+p.W, y)
+		pdf.SetXY(p.X+p.W-30, y-4.5)
+		pdf.CellFormat(30, 4, "Goal "+l.ValueLabel(g), "", 0, "R", false, 0, "")
+	}
+	pdf.SetDashPattern([]float64{}, 0)
+
+	pdf.SetDrawColor(31, 119, 180)
+	pdf.SetFillColor(31, 119, 180)
+	pdf.SetLineWidth(0.5)
+	for _, s := range c.Series {
+		for i, pt := range s.Points {
+			x, y := l.X(pt.Time), l.Y(pt.Value)
+			if i > 0 {
+				prev := s.Points[i-1]
+				pdf.Line(l.X(prev.Time), l.Y(prev.Value), x, y)
+			}
+			if len(s.Points) <= 60 {
+				pdf.Circle(x, y, 0.6, "F")
+			}
+		}
+	}
+	pdf.SetTextColor(0, 0, 0)
+	pdf.SetDrawColor(0, 0, 0)
+	pdf.SetLineWidth(0.2)
+}
+
+// formatValue formats stored value of measurement in units of the viewer
+func (d summaryData) formatValue(code string, v float64) string {
+	return strconv.FormatFloat(d.conv.FromStored(code, v), 'f', -1, 64) + " " + d.conv.Unit(code)
+}
+
+// latestValues returns the latest metric with each measurement, metrics are ordered by time
+func latestValues(metrics []models.Metric) map[string]models.Metric {
+	latest := make(map[string]models.Metric)
+	for _, m := range metrics {
+		for code := range m.Measurements {
+			latest[code] = m
+		}
+	}
+
+	return latest
+}
+
+// latestPlan returns the most recently created plan, nil if there are none
+func latestPlan(plans []models.TrainingPlan) *models.TrainingPlan {
+	var latest *models.TrainingPlan
+	for i := range plans {
+		if latest == nil || plans[i].CreatedAt.After(latest.CreatedAt) {
+			latest = &plans[i]
+		}
+	}
+
+	return latest
+}
+
+// latestReports returns at most n most recent reports, newest first
+func latestReports(reports []models.ProgressReport, n int) []models.ProgressReport {
+	sorted := append([]models.ProgressReport(nil), reports...)
+	sort.SliceStable(sorted, func(i, j int) bool { return sorted[i].CreatedAt.After(sorted[j].CreatedAt) })
+
+	return sorted[:min(n, len(sorted))]
+}
@@ -0,0 +1,251 @@
+package reportservice
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"ChadProgress/internal/lib/apperr"
+	"ChadProgress/internal/lib/units"
+	"ChadProgress/internal/models"
+	service "ChadProgress/internal/services"
+)
+
+// summaryRange is the period charts and latest values of summary report cover
+const summaryRange = 365 * 24 * time.Hour
+
+//go:generate mockgen -source=report.go -destination=./report_mock.go -package=reportservice
+type Storage interface {
+	GetUserByEmail(email string) (*models.User, error)
+	GetUserByID(id uint) (*models.User, error)
+	GetTrainerByUserID(id uint) (*models.Trainer, error)
+	GetTrainerByID(id uint) (*models.Trainer, error)
+	GetClientByUserID(id uint) (*models.Client, error)
+	GetClientByID(id uint) (*models.Client, error)
+	GetMetrics(clientID uint, from, to time.Time) ([]models.Metric, error)
+	GetMeasurementTypes() ([]models.MeasurementType, error)
+	GetPlan(trainerID, clientID uint) ([]models.TrainingPlan, error)
+	GetProgressReport(trainerID, clientID uint) ([]models.ProgressReport, error)
+}
+
+type ReportService struct {
+	storage Storage
+	log     *slog.Logger
+	// now is replaced in tests
+	now func() time.Time
+}
+
+func NewReportService(storage Storage, log *slog.Logger) *ReportService {
+	return &ReportService{
+		storage: storage,
+		log:     log,
+		now:     time.Now,
+	}
+}
+
+// subject is client a report is made of and preferences of user it is made for: values are shown
+// in their unit system and times in their time zone
+type subject struct {
+	client *models.Client
+	types  []models.MeasurementType
+	conv   units.Converter
+	loc    *time.Location
+}
+
+// subject returns client with clientID if viewer may see their reports: clients see their own ones and
+// trainers those of their clients
+func (s *ReportService) subject(log *slog.Logger, viewerEmail string, clientID uint, view models.ReportView) (*subject, error) {
+	viewer, _ := s.storage.GetUserByEmail(viewerEmail)
+	if viewer == nil {
+		log.Error("user not found", slog.String("email", viewerEmail))
+
+		return nil, service.ErrUserNotFound
+	}
+
+	var client *models.Client
+	switch viewer.Role {
+	case models.RoleClient:
+		own, err := s.storage.GetClientByUserID(viewer.ID)
+		if err != nil {
+			log.Error("client profile not found")
+
+			return nil, service.ErrClientNotFound
+		}
+		if own.ID != clientID {
+			log.Warn("client requested report of another client", slog.Uint64("client_id", uint64(clientID)))
+
+			return nil, apperr.ErrForbidden
+		}
+		client = own
+	case models.RoleTrainer:
+		trainer, err := s.storage.GetTrainerByUserID(viewer.ID)
+		if err != nil {
+			log.Error("trainer profile not found")
+
+			return nil, service.ErrTrainerNotFound
+		}
+		client, err = s.storage.GetClientByID(clientID)
+		if err != nil {
+			return nil, service.ErrClientNotFound
+		}
+		if client.TrainerID != trainer.ID {
+			log.Warn("trainer requested report of client of another trainer", slog.Uint64("client_id", uint64(clientID)))
+
+			return nil, apperr.ErrForbidden
+		}
+	default:
+		return nil, service.ErrInvalidRoleRequest
+	}
+
+	types, err := s.storage.GetMeasurementTypes()
+	if err != nil {
+		log.Error("failed to get measurement types", slog.String("error", err.Error()))
+
+		return nil, err
+	}
+
+	system, err := units.Parse(view.Units)
+	if err != nil {
+		system = units.Metric
+	}
+	loc := view.Location
+	if loc == nil {
+		loc = time.UTC
+	}
+	stored := make(map[string]string, len(types))
+	for _, t := range types {
+		stored[t.Code] = t.Unit
+	}
+
+	return &subject{
+		client: client,
+		types:  types,
+		conv:   units.NewConverter(system, stored),
+		loc:    loc,
+	}, nil
+}
+
+// MetricsExport returns metrics of client measured within [from, to) as CSV or XLSX file, zero from or
+// to leaves the range open
+func (s *ReportService) MetricsExport(ctx context.Context, viewerEmail string, clientID uint, format string,
+	from, to time.Time, view models.ReportView) (*models.ReportFile, error) {
+	const op = "services.report.MetricsExport"
+	log := s.log.With(
+		slog.String("op", op),
+	)
+
+	if format != models.ReportCSV && format != models.ReportXLSX {
+		return nil, apperr.ErrValidation.WithFields(apperr.FieldError{Field: "format", Message: "must be csv or xlsx"})
+	}
+	if !from.IsZero() && !to.IsZero() && !from.Before(to) {
+		return nil, apperr.ErrValidation.WithFields(apperr.FieldError{Field: "from", Message: "must be before to"})
+	}
+
+	sub, err := s.subject(log, viewerEmail, clientID, view)
+	if err != nil {
+		return nil, err
+	}
+
+	metrics, err := s.storage.GetMetrics(clientID, from, to)
+	if err != nil {
+		log.Error("failed to get metrics", slog.String("error", err.Error()))
+
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	table := newMetricsTable(metrics, sub)
+	file := &models.ReportFile{
+		Name: fmt.Sprintf("chadprogress-metrics-%d-%s.%s", clientID, s.now().In(sub.loc).Format(time.DateOnly), format),
+	}
+	if format == models.ReportCSV {
+		file.ContentType = models.ContentTypeCSV
+		file.Content, err = table.csv()
+	} else {
+		file.ContentType = models.ContentTypeXLSX
+		file.Content, err = table.xlsx()
+	}
+	if err != nil {
+		log.Error("failed to write metrics", slog.String("format", format), slog.String("error", err.Error()))
+
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return file, nil
+}
+
+// Summary returns PDF report of client progress: profile, goals and latest values, charts of weight and
+// body fat over the last year, current training plan and latest comments of the trainer
+func (s *ReportService) Summary(ctx context.Context, viewerEmail string, clientID uint, view models.ReportView) (*models.ReportFile, error) {
+	const op = "services.report.Summary"
+	log := s.log.With(
+		slog.String("op", op),
+	)
+
+	sub, err := s.subject(log, viewerEmail, clientID, view)
+	if err != nil {
+		return nil, err
+	}
+
+	now := s.now()
+	data := summaryData{subject: sub, generatedAt: now.In(sub.loc)}
+
+	if data.user, err = s.storage.GetUserByID(sub.client.UserID); err != nil {
+		log.Error("failed to get user of client", slog.String("error", err.Error()))
+
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	if data.metrics, err = s.storage.GetMetrics(clientID, now.Add(-summaryRange), time.Time{}); err != nil {
+		log.Error("failed to get metrics", slog.String("error", err.Error()))
+
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	// client without trainer has neither plans nor reports
+	if sub.client.TrainerID != 0 {
+		if data.trainer, err = s.trainerUser(sub.client.TrainerID); err != nil {
+			log.Error("failed to get trainer of client", slog.String("error", err.Error()))
+
+			return nil, fmt.Errorf("%s: %w", op, err)
+		}
+
+		plans, err := s.storage.GetPlan(sub.client.TrainerID, clientID)
+		if err != nil {
+			log.Error("failed to get training plans", slog.String("error", err.Error()))
+
+			return nil, fmt.Errorf("%s: %w", op, err)
+		}
+		data.plan = latestPlan(plans)
+
+		reports, err := s.storage.GetProgressReport(sub.client.TrainerID, clientID)
+		if err != nil {
+			log.Error("failed to get progress reports", slog.String("error", err.Error()))
+
+			return nil, fmt.Errorf("%s: %w", op, err)
+		}
+		data.reports = latestReports(reports, summaryReports)
+	}
+
+	content, err := writeSummary(data)
+	if err != nil {
+		log.Error("failed to write summary", slog.String("error", err.Error()))
+
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return &models.ReportFile{
+		Name:        fmt.Sprintf("chadprogress-summary-%d-%s.pdf", clientID, data.generatedAt.Format(time.DateOnly)),
+		ContentType: models.ContentTypePDF,
+		Content:     content,
+	}, nil
+}
+
+// trainerUser returns user of trainer with id
+func (s *ReportService) trainerUser(id uint) (*models.User, error) {
+	trainer, err := s.storage.GetTrainerByID(id)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.storage.GetUserByID(trainer.UserID)
+}
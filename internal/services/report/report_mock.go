@@ -0,0 +1,186 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: report.go
+
+// Package reportservice is a generated GoMock package.
+package reportservice
+
+import (
+	models "ChadProgress/internal/models"
+	reflect "reflect"
+	time "time"
+
+	gomock "github.com/golang/mock/gomock"
+)
+
+// MockStorage is a mock of Storage interface.
+type MockStorage struct {
+	ctrl     *gomock.Controller
+	recorder *MockStorageMockRecorder
+}
+
+// MockStorageMockRecorder is the mock recorder for MockStorage.
+type MockStorageMockRecorder struct {
+	mock *MockStorage
+}
+
+// NewMockStorage creates a new mock instance.
+func NewMockStorage(ctrl *gomock.Controller) *MockStorage {
+	mock := &MockStorage{ctrl: ctrl}
+	mock.recorder = &MockStorageMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockStorage) EXPECT() *MockStorageMockRecorder {
+	return m.recorder
+}
+
+// GetClientByID mocks base method.
+func (m *MockStorage) GetClientByID(id uint) (*models.Client, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetClientByID", id)
+	ret0, _ := ret[0].(*models.Client)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetClientByID indicates an expected call of GetClientByID.
+func (mr *MockStorageMockRecorder) GetClientByID(id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetClientByID", reflect.TypeOf((*MockStorage)(nil).GetClientByID), id)
+}
+
+// GetClientByUserID mocks base method.
+func (m *MockStorage) GetClientByUserID(id uint) (*models.Client, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetClientByUserID", id)
+	ret0, _ := ret[0].(*models.Client)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetClientByUserID indicates an expected call of GetClientByUserID.
+func (mr *MockStorageMockRecorder) GetClientByUserID(id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetClientByUserID", reflect.TypeOf((*MockStorage)(nil).GetClientByUserID), id)
+}
+
+// GetMeasurementTypes mocks base method.
+func (m *MockStorage) GetMeasurementTypes() ([]models.MeasurementType, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetMeasurementTypes")
+	ret0, _ := ret[0].([]models.MeasurementType)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetMeasurementTypes indicates an expected call of GetMeasurementTypes.
+func (mr *MockStorageMockRecorder) GetMeasurementTypes() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetMeasurementTypes", reflect.TypeOf((*MockStorage)(nil).GetMeasurementTypes))
+}
+
+// GetMetrics mocks base method.
+func (m *MockStorage) GetMetrics(clientID uint, from, to time.Time) ([]models.Metric, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetMetrics", clientID, from, to)
+	ret0, _ := ret[0].([]models.Metric)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetMetrics indicates an expected call of GetMetrics.
+func (mr *MockStorageMockRecorder) GetMetrics(clientID, from, to interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetMetrics", reflect.TypeOf((*MockStorage)(nil).GetMetrics), clientID, from, to)
+}
+
+// GetPlan mocks base method.
+func (m *MockStorage) GetPlan(trainerID, clientID uint) ([]models.TrainingPlan, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetPlan", trainerID, clientID)
+	ret0, _ := ret[0].([]models.TrainingPlan)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetPlan indicates an expected call of GetPlan.
+func (mr *MockStorageMockRecorder) GetPlan(trainerID, clientID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetPlan", reflect.TypeOf((*MockStorage)(nil).GetPlan), trainerID, clientID)
+}
+
+// GetProgressReport mocks base method.
+func (m *MockStorage) GetProgressReport(trainerID, clientID uint) ([]models.ProgressReport, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetProgressReport", trainerID, clientID)
+	ret0, _ := ret[0].([]models.ProgressReport)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetProgressReport indicates an expected call of GetProgressReport.
+func (mr *MockStorageMockRecorder) GetProgressReport(trainerID, clientID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetProgressReport", reflect.TypeOf((*MockStorage)(nil).GetProgressReport), trainerID, clientID)
+}
+
+// GetTrainerByID mocks base method.
+func (m *MockStorage) GetTrainerByID(id uint) (*models.Trainer, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetTrainerByID", id)
+	ret0, _ := ret[0].(*models.Trainer)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetTrainerByID indicates an expected call of GetTrainerByID.
+func (mr *MockStorageMockRecorder) GetTrainerByID(id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetTrainerByID", reflect.TypeOf((*MockStorage)(nil).GetTrainerByID), id)
+}
+
+// GetTrainerByUserID mocks base method.
+func (m *MockStorage) GetTrainerByUserID(id uint) (*models.Trainer, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetTrainerByUserID", id)
+	ret0, _ := ret[0].(*models.Trainer)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetTrainerByUserID indicates an expected call of GetTrainerByUserID.
+func (mr *MockStorageMockRecorder) GetTrainerByUserID(id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetTrainerByUserID", reflect.TypeOf((*MockStorage)(nil).GetTrainerByUserID), id)
+}
+
+// GetUserByEmail mocks base method.
+func (m *MockStorage) GetUserByEmail(email string) (*models.User, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetUserByEmail", email)
+	ret0, _ := ret[0].(*models.User)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetUserByEmail indicates an expected call of GetUserByEmail.
+func (mr *MockStorageMockRecorder) GetUserByEmail(email interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUserByEmail", reflect.TypeOf((*MockStorage)(nil).GetUserByEmail), email)
+}
+
+// GetUserByID mocks base method.
+func (m *MockStorage) GetUserByID(id uint) (*models.User, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetUserByID", id)
+	ret0, _ := ret[0].(*models.User)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetUserByID indicates an expected call of GetUserByID.
+func (mr *MockStorageMockRecorder) GetUserByID(id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUserByID", reflect.TypeOf((*MockStorage)(nil).GetUserByID), id)
+}
@@ -0,0 +1,247 @@
+package reportservice
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"log/slog"
+	"testing"
+	"time"
+
+	"ChadProgress/internal/lib/apperr"
+	"ChadProgress/internal/lib/units"
+	"ChadProgress/internal/models"
+	service "ChadProgress/internal/services"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/xuri/excelize/v2"
+)
+
+const (
+	clientEmail  = "client@example.com"
+	trainerEmail = "trainer@example.com"
+)
+
+var (
+	now         = time.Date(2026, time.October, 19, 9, 0, 0, 0, time.UTC)
+	clientUser  = &models.User{ID: 5, Email: clientEmail, Name: "Иван Петров", Role: models.RoleClient}
+	trainerUser = &models.User{ID: 7, Email: trainerEmail, Name: "Anna Coach", Role: models.RoleTrainer}
+	moscowView  = models.ReportView{Units: string(units.Metric), Location: time.FixedZone("MSK", 3*60*60)}
+	imperial    = models.ReportView{Units: string(units.Imperial), Location: time.UTC}
+	client      = &models.Client{ID: 3, UserID: 5, TrainerID: 2, Height: 180, Goals: models.MeasurementValues{"weight": 75, "waist": 80}}
+	trainer     = &models.Trainer{ID: 2, UserID: 7}
+	metrics     = []models.Metric{
+		{ClientID: 3, Measurements: models.MeasurementValues{"weight": 82.5, "body_fat": 21}, MeasuredAt: time.Date(2026, time.October, 1, 5, 0, 0, 0, time.UTC)},
+		{ClientID: 3, Measurements: models.MeasurementValues{"weight": 80}, MeasuredAt: time.Date(2026, time.October, 18, 21, 30, 0, 0, time.UTC)},
+	}
+)
+
+func newTestService(t *testing.T) (*ReportService, *MockStorage) {
+	ctrl := gomock.NewController(t)
+	st := NewMockStorage(ctrl)
+
+	s := NewReportService(st, slog.New(slog.NewTextHandler(io.Discard, nil)))
+	s.now = func() time.Time { return now }
+
+	return s, st
+}
+
+func TestSubject(t *testing.T) {
+	tests := []struct {
+		name     string
+		email    string
+		clientID uint
+		mock     func(st *MockStorage)
+		err      error
+	}{
+		{
+			name:     "Client sees own reports",
+			email:    clientEmail,
+			clientID: 3,
+			mock: func(st *MockStorage) {
+				st.EXPECT().GetUserByEmail(clientEmail).Return(clientUser, nil)
+				st.EXPECT().GetClientByUserID(uint(5)).Return(client, nil)
+				st.EXPECT().GetMeasurementTypes().Return(models.DefaultMeasurementTypes, nil)
+			},
+		},
+		{
+			name:     "Client does not see reports of others",
+			email:    clientEmail,
+			clientID: 4,
+			mock: func(st *MockStorage) {
+				st.EXPECT().GetUserByEmail(clientEmail).Return(clientUser, nil)
+				st.EXPECT().GetClientByUserID(uint(5)).Return(client, nil)
+			},
+			err: apperr.ErrForbidden,
+		},
+		{
+			name:     "Trainer sees reports of their client",
+			email:    trainerEmail,
+			clientID: 3,
+			mock: func(st *MockStorage) {
+				st.EXPECT().GetUserByEmail(trainerEmail).Return(trainerUser, nil)
+				st.EXPECT().GetTrainerByUserID(uint(7)).Return(trainer, nil)
+				st.EXPECT().GetClientByID(uint(3)).Return(client, nil)
+				st.EXPECT().GetMeasurementTypes().Return(models.DefaultMeasurementTypes, nil)
+			},
+		},
+		{
+			name:     "Trainer does not see reports of clients of other trainers",
+			email:    trainerEmail,
+			clientID: 4,
+			mock: func(st *MockStorage) {
+				st.EXPECT().GetUserByEmail(trainerEmail).Return(trainerUser, nil)
+				st.EXPECT().GetTrainerByUserID(uint(7)).Return(trainer, nil)
+				st.EXPECT().GetClientByID(uint(4)).Return(&models.Client{ID: 4, TrainerID: 9}, nil)
+			},
+			err: apperr.ErrForbidden,
+		},
+		{
+			name:     "Unknown client",
+			email:    trainerEmail,
+			clientID: 4,
+			mock: func(st *MockStorage) {
+				st.EXPECT().GetUserByEmail(trainerEmail).Return(trainerUser, nil)
+				st.EXPECT().GetTrainerByUserID(uint(7)).Return(trainer, nil)
+				st.EXPECT().GetClientByID(uint(4)).Return(nil, assert.AnError)
+			},
+			err: service.ErrClientNotFound,
+		},
+		{
+			name:     "Admins have no clients",
+			email:    "admin@example.com",
+			clientID: 3,
+			mock: func(st *MockStorage) {
+				st.EXPECT().GetUserByEmail("admin@example.com").Return(&models.User{ID: 1, Role: models.RoleAdmin}, nil)
+			},
+			err: service.ErrInvalidRoleRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, st := newTestService(t)
+			tt.mock(st)
+
+			sub, err := s.subject(s.log, tt.email, tt.clientID, moscowView)
+			if tt.err != nil {
+				assert.ErrorIs(t, err, tt.err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, client, sub.client)
+		})
+	}
+}
+
+func TestMetricsExport(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("CSV in units and time zone of viewer", func(t *testing.T) {
+		s, st := newTestService(t)
+		st.EXPECT().GetUserByEmail(clientEmail).Return(clientUser, nil)
+		st.EXPECT().GetClientByUserID(uint(5)).Return(client, nil)
+		st.EXPECT().GetMeasurementTypes().Return(models.DefaultMeasurementTypes, nil)
+		st.EXPECT().GetMetrics(uint(3), time.Time{}, time.Time{}).Return(metrics, nil)
+
+		file, err := s.MetricsExport(ctx, clientEmail, 3, models.ReportCSV, time.Time{}, time.Time{}, moscowView)
+		require.NoError(t, err)
+		assert.Equal(t, "chadprogress-metrics-3-2026-10-19.csv", file.Name)
+		assert.Equal(t, models.ContentTypeCSV, file.ContentType)
+		assert.Equal(t, "measured-at,weight,body_fat\n"+
+			"2026-10-01T08:00:00+03:00,82.5,21\n"+
+			"2026-10-19T00:30:00+03:00,80,\n", string(file.Content))
+	})
+
+	t.Run("XLSX in imperial units", func(t *testing.T) {
+		s, st := newTestService(t)
+		st.EXPECT().GetUserByEmail(trainerEmail).Return(trainerUser, nil)
+		st.EXPECT().GetTrainerByUserID(uint(7)).Return(trainer, nil)
+		st.EXPECT().GetClientByID(uint(3)).Return(client, nil)
+		st.EXPECT().GetMeasurementTypes().Return(models.DefaultMeasurementTypes, nil)
+		st.EXPECT().GetMetrics(uint(3), time.Time{}, time.Time{}).Return(metrics, nil)
+
+		file, err := s.MetricsExport(ctx, trainerEmail, 3, models.ReportXLSX, time.Time{}, time.Time{}, imperial)
+		require.NoError(t, err)
+		assert.Equal(t, models.ContentTypeXLSX, file.ContentType)
+
+		f, err := excelize.OpenReader(bytes.NewReader(file.Content))
+		require.NoError(t, err)
+		defer f.Close()
+		rows, err := f.GetRows(metricsSheet)
+		require.NoError(t, err)
+		assert.Equal(t, [][]string{
+			{"Measured at", "Weight (lb)", "Body fat (%)"},
+			{"2026-10-01 05:00", "181.88", "21"},
+			{"2026-10-18 21:30", "176.37"},
+		}, rows)
+	})
+
+	t.Run("Unknown format", func(t *testing.T) {
+		s, _ := newTestService(t)
+
+		_, err := s.MetricsExport(ctx, clientEmail, 3, "pdf", time.Time{}, time.Time{}, moscowView)
+		require.ErrorIs(t, err, apperr.ErrValidation)
+		assert.Equal(t, "format", apperr.From(err).Fields[0].Field)
+	})
+}
+
+func TestSummary(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("Trainer gets report of client", func(t *testing.T) {
+		s, st := newTestService(t)
+		st.EXPECT().GetUserByEmail(trainerEmail).Return(trainerUser, nil)
+		st.EXPECT().GetTrainerByUserID(uint(7)).Return(trainer, nil)
+		st.EXPECT().GetClientByID(uint(3)).Return(client, nil)
+		st.EXPECT().GetMeasurementTypes().Return(models.DefaultMeasurementTypes, nil)
+		st.EXPECT().GetUserByID(uint(5)).Return(clientUser, nil)
+		st.EXPECT().GetMetrics(uint(3), now.Add(-summaryRange), time.Time{}).Return(metrics, nil)
+		st.EXPECT().GetTrainerByID(uint(2)).Return(trainer, nil)
+		st.EXPECT().GetUserByID(uint(7)).Return(trainerUser, nil)
+		st.EXPECT().GetPlan(uint(2), uint(3)).Return([]models.TrainingPlan{
+			{Description: "Strength, 3 times a week", Schedule: "Mon, Wed, Fri", CreatedAt: now.AddDate(0, -1, 0)},
+		}, nil)
+		st.EXPECT().GetProgressReport(uint(2), uint(3)).Return([]models.ProgressReport{
+			{Comments: "Отличный прогресс, так держать", CreatedAt: now.AddDate(0, 0, -3)},
+		}, nil)
+
+		file, err := s.Summary(ctx, trainerEmail, 3, imperial)
+		require.NoError(t, err)
+		assert.Equal(t, "chadprogress-summary-3-2026-10-19.pdf", file.Name)
+		assert.Equal(t, models.ContentTypePDF, file.ContentType)
+		assert.True(t, bytes.HasPrefix(file.Content, []byte("%PDF-")), "PDF header")
+		assert.True(t, bytes.HasSuffix(bytes.TrimSpace(file.Content), []byte("%%EOF")), "PDF trailer")
+	})
+
+	t.Run("Client without trainer and metrics", func(t *testing.T) {
+		s, st := newTestService(t)
+		alone := &models.Client{ID: 3, UserID: 5}
+		st.EXPECT().GetUserByEmail(clientEmail).Return(clientUser, nil)
+		st.EXPECT().GetClientByUserID(uint(5)).Return(alone, nil)
+		st.EXPECT().GetMeasurementTypes().Return(models.DefaultMeasurementTypes, nil)
+		st.EXPECT().GetUserByID(uint(5)).Return(clientUser, nil)
+		st.EXPECT().GetMetrics(uint(3), now.Add(-summaryRange), time.Time{}).Return([]models.Metric{}, nil)
+
+		file, err := s.Summary(ctx, clientEmail, 3, moscowView)
+		require.NoError(t, err)
+		assert.True(t, bytes.HasPrefix(file.Content, []byte("%PDF-")))
+	})
+}
+
+func TestLatestReports(t *testing.T) {
+	reports := []models.ProgressReport{
+		{ID: 1, CreatedAt: now.AddDate(0, 0, -30)},
+		{ID: 2, CreatedAt: now.AddDate(0, 0, -1)},
+		{ID: 3, CreatedAt: now.AddDate(0, 0, -60)},
+		{ID: 4, CreatedAt: now.AddDate(0, 0, -7)},
+	}
+
+	latest := latestReports(reports, 3)
+	require.Len(t, latest, 3)
+	assert.Equal(t, []uint{2, 4, 1}, []uint{latest[0].ID, latest[1].ID, latest[2].ID})
+	assert.Empty(t, latestReports(nil, 3))
+	assert.Nil(t, latestPlan(nil))
+}
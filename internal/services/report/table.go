@@ -0,0 +1,163 @@
+package reportservice
+
+import (
+	"bytes"
+	"encoding/csv"
+	"fmt"
+	"strconv"
+	"time"
+
+	"ChadProgress/internal/models"
+
+	"github.com/xuri/excelize/v2"
+)
+
+const (
+	// measuredAtColumn heads times in CSV, the same column metrics import reads
+	measuredAtColumn = "measured-at"
+	metricsSheet     = "Metrics"
+)
+
+// metricsTable is metrics laid out by columns of measurement types present in them, in catalog order.
+// Values are in units of the viewer and times in their time zone.
+type metricsTable struct {
+	columns []models.MeasurementType
+	units   []string
+	rows    []metricsRow
+}
+
+type metricsRow struct {
+	measuredAt time.Time
+	values     models.MeasurementValues
+}
+
+func newMetricsTable(metrics []models.Metric, sub *subject) *metricsTable {
+	present := make(map[string]bool)
+	for _, m := range metrics {
+		for code := range m.Measurements {
+			present[code] = true
+		}
+	}
+
+	t := &metricsTable{rows: make([]metricsRow, 0, len(metrics))}
+	for _, typ := range sub.types {
+		if present[typ.Code] {
+			t.columns = append(t.columns, typ)
+			t.units = append(t.units, sub.conv.Unit(typ.Code))
+		}
+	}
+	for _, m := range metrics {
+		row := metricsRow{measuredAt: m.MeasuredAt.In(sub.loc), values: make(models.MeasurementValues, len(m.Measurements))}
+		for code, v := range m.Measurements {
+			row.values[code] = sub.conv.FromStored(code, v)
+		}
+		t.rows = append(t.rows, row)
+	}
+
+	return t
+}
+
+// csv writes the table with measurement codes as headers, so that the file can be imported back
+func (t *metricsTable) csv() ([]byte, error) {
+	var buf bytes.Buffer
+	w := csv.NewWriter(&buf)
+
+	record := make([]string, len(t.columns)+1)
+	record[0] = measuredAtColumn
+	for i, c := range t.columns {
+		record[i+1] = c.Code
+	}
+	if err := w.Write(record); err != nil {
+		return nil, err
+	}
+
+	for _, row := range t.rows {
+		record[0] = row.measuredAt.Format(time.RFC3339)
+		for i, c := range t.columns {
+			record[i+1] = ""
+			if v, ok := row.values[c.Code]; ok {
+				record[i+1] = strconv.FormatFloat(v, 'f', -1, 64)
+			}
+		}
+		if err := w.Write(record); err != nil {
+			return nil, err
+		}
+	}
+	w.Flush()
+
+	return buf.Bytes(), w.Error()
+}
+
+// xlsx writes the table as a spreadsheet with names and units of measurements as headers, times are
+// date cells
+func (t *metricsTable) xlsx() ([]byte, error) {
+	f := excelize.NewFile()
+	defer f.Close()
+
+	if err := f.SetSheetName(f.GetSheetName(0), metricsSheet); err != nil {
+		return nil, err
+	}
+	header := make([]any, len(t.columns)+1)
+	header[0] = "Measured at"
+	for i, c := range t.columns {
+		header[i+1] = fmt.Sprintf("%s (%s)", c.Name, t.units[i])
+	}
+	if err := f.SetSheetRow(metricsSheet, "A1", &header); err != nil {
+		return nil, err
+	}
+
+	timeStyle, err := f.NewStyle(&excelize.Style{CustomNumFmt: ptr("yyyy-mm-dd hh:mm")})
+	if err != nil {
+		return nil, err
+	}
+	for i, row := range t.rows {
+		// spreadsheets have no time zones, times are written as the viewer sees them
+		local := row.measuredAt
+		cell, err := excelize.CoordinatesToCellName(1, i+2)
+		if err != nil {
+			return nil, err
+		}
+		err = f.SetCellValue(metricsSheet, cell,
+			time.Date(local.Year(), local.Month(), local.Day(), local.Hour(), local.Minute(), local.Second(), 0, time.UTC))
+		if err != nil {
+			return nil, err
+		}
+		// cells of missing values are left out rather than written empty
+		for j, c := range t.columns {
+			v, ok := row.values[c.Code]
+			if !ok {
+				continue
+			}
+			if cell, err = excelize.CoordinatesToCellName(j+2, i+2); err != nil {
+				return nil, err
+			}
+			if err = f.SetCellFloat(metricsSheet, cell, v, -1, 64); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	if len(t.rows) > 0 {
+		if err = f.SetCellStyle(metricsSheet, "A2", fmt.Sprintf("A%d", len(t.rows)+1), timeStyle); err != nil {
+			return nil, err
+		}
+	}
+	if err = f.SetColWidth(metricsSheet, "A", "A", 18); err != nil {
+		return nil, err
+	}
+	// header stays visible while scrolling
+	if err = f.SetPanes(metricsSheet, &excelize.Panes{Freeze: true, YSplit: 1, TopLeftCell: "A2", ActivePane: "bottomLeft"}); err != nil {
+		return nil, err
+	}
+
+	buf, err := f.WriteToBuffer()
+	if err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
+
+func ptr[T any](v T) *T {
+	return &v
+}
@@ -1,17 +1,34 @@
 package service
 
 import (
-	"errors"
+	"net/http"
+
+	"ChadProgress/internal/lib/apperr"
 )
 
 var (
-	ErrUserAlreadyExists  = errors.New("user already exists")
-	ErrFieldIsTooLong     = errors.New("field is too long")
-	ErrInvalidCredentials = errors.New("invalid credentials")
-	ErrDuplicateKey       = errors.New("duplicate key value violates unique constraint")
-	ErrInvalidRoleRequest = errors.New("invalid request due to role")
-	ErrUserNotFound       = errors.New("user not found")
-	ErrClientNotFound     = errors.New("clients profile not found")
-	ErrTrainerNotFound    = errors.New("trainer profile not found")
-	ErrNotActiveTrainer   = errors.New("not active trainer")
+	ErrUserAlreadyExists  = apperr.New(apperr.CodeUserAlreadyExists, http.StatusConflict, "user already exists")
+	ErrFieldIsTooLong     = apperr.New(apperr.CodeFieldTooLong, http.StatusBadRequest, "field is too long")
+	ErrInvalidCredentials = apperr.New(apperr.CodeInvalidCredentials, http.StatusUnauthorized, "invalid credentials")
+	ErrAccountLocked      = apperr.New(apperr.CodeAccountLocked, http.StatusTooManyRequests, "too many failed login attempts")
+	ErrAuthServiceFailed  = apperr.New(apperr.CodeAuthServiceFailed, http.StatusBadGateway, "authorization service failed")
+	ErrAuthUnavailable    = apperr.New(apperr.CodeAuthUnavailable, http.StatusServiceUnavailable, "authorization service is unavailable")
+	ErrInvalidToken       = apperr.New(apperr.CodeInvalidToken, http.StatusUnauthorized, "invalid or expired token")
+	ErrSessionNotFound    = apperr.New(apperr.CodeSessionNotFound, http.StatusNotFound, "session not found")
+	ErrNotSupported       = apperr.New(apperr.CodeNotSupported, http.StatusNotImplemented, "not supported by authorization provider")
+	ErrInvalidEmailToken  = apperr.New(apperr.CodeInvalidEmailToken, http.StatusBadRequest, "link is invalid or expired")
+	ErrEmailNotVerified   = apperr.New(apperr.CodeEmailNotVerified, http.StatusForbidden, "email is not verified")
+	ErrAccountDisabled    = apperr.New(apperr.CodeAccountDisabled, http.StatusForbidden, "account is disabled")
+	ErrDuplicateKey       = apperr.New(apperr.CodeAlreadyExists, http.StatusConflict, "profile already exists")
+	ErrInvalidReference   = apperr.New(apperr.CodeInvalidReference, http.StatusBadRequest, "referenced record does not exist")
+	ErrInvalidRoleRequest = apperr.New(apperr.CodeInvalidRole, http.StatusForbidden, "invalid request due to role")
+	ErrUserNotFound       = apperr.New(apperr.CodeUserNotFound, http.StatusNotFound, "user not found")
+	ErrClientNotFound     = apperr.New(apperr.CodeClientNotFound, http.StatusNotFound, "clients profile not found")
+	ErrTrainerNotFound    = apperr.New(apperr.CodeTrainerNotFound, http.StatusNotFound, "trainer profile not found")
+	ErrNotActiveTrainer   = apperr.New(apperr.CodeTrainerNotActive, http.StatusConflict, "trainer is busy or on vacation")
+	ErrExportNotFound     = apperr.New(apperr.CodeExportNotFound, http.StatusNotFound, "data export not found")
+	ErrExportNotReady     = apperr.New(apperr.CodeExportNotReady, http.StatusConflict, "data export is not ready yet")
+	ErrExportInProgress   = apperr.New(apperr.CodeExportInProgress, http.StatusConflict, "data export is already in progress")
+	ErrExportExpired      = apperr.New(apperr.CodeExportExpired, http.StatusGone, "data export has expired")
+	ErrExportBusy         = apperr.New(apperr.CodeExportBusy, http.StatusServiceUnavailable, "too many data exports in progress, try again later")
 )
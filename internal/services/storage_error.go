@@ -0,0 +1,43 @@
+package service
+
+import (
+	"errors"
+
+	"ChadProgress/internal/lib/apperr"
+	"ChadProgress/storage"
+)
+
+// Fields maps storage columns to request fields their values come from
+type Fields map[string]string
+
+// FromStorage converts constraint violation reported by storage to application error that names
+// the request field to fix when storage knows the column. Columns missing in fields are named as is.
+// ok is false for other errors.
+func FromStorage(err error, fields Fields) (appErr *apperr.Error, ok bool) {
+	var msg string
+	switch {
+	case errors.Is(err, storage.ErrFieldIsTooLong):
+		appErr, msg = ErrFieldIsTooLong, "is too long"
+	case errors.Is(err, storage.ErrInvalidEnumValue):
+		appErr, msg = apperr.ErrValidation, "is not one of allowed values"
+	case errors.Is(err, storage.ErrCheckViolation):
+		appErr, msg = apperr.ErrValidation, "is out of allowed range"
+	case errors.Is(err, storage.ErrForeignKeyViolation):
+		appErr, msg = ErrInvalidReference, "refers to a record that does not exist"
+	case errors.Is(err, storage.ErrDuplicateKey):
+		appErr, msg = ErrDuplicateKey, "is already taken"
+	default:
+		return nil, false
+	}
+
+	var fieldErr *storage.FieldError
+	if !errors.As(err, &fieldErr) || fieldErr.Field == "" {
+		return appErr, true
+	}
+	field, found := fields[fieldErr.Field]
+	if !found {
+		field = fieldErr.Field
+	}
+
+	return appErr.WithFields(apperr.FieldError{Field: field, Message: msg}), true
+}
@@ -0,0 +1,54 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"ChadProgress/internal/lib/apperr"
+	"ChadProgress/storage"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFromStorage(t *testing.T) {
+	fields := Fields{"body_fat": "bodyfat"}
+
+	tests := []struct {
+		name   string
+		err    error
+		want   *apperr.Error
+		fields []apperr.FieldError
+	}{
+		{
+			name:   "Column is renamed to request field",
+			err:    &storage.FieldError{Err: storage.ErrCheckViolation, Field: "body_fat"},
+			want:   apperr.ErrValidation,
+			fields: []apperr.FieldError{{Field: "bodyfat", Message: "is out of allowed range"}},
+		},
+		{
+			name:   "Unknown column is named as is",
+			err:    &storage.FieldError{Err: storage.ErrForeignKeyViolation, Field: "client_id"},
+			want:   ErrInvalidReference,
+			fields: []apperr.FieldError{{Field: "client_id", Message: "refers to a record that does not exist"}},
+		},
+		{
+			name: "Column is not known",
+			err:  fmt.Errorf("op: %w", storage.ErrFieldIsTooLong),
+			want: ErrFieldIsTooLong,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			appErr, ok := FromStorage(fmt.Errorf("op: %w", tt.err), fields)
+			require.True(t, ok)
+			assert.ErrorIs(t, appErr, tt.want)
+			assert.Equal(t, tt.fields, appErr.Fields)
+		})
+	}
+
+	_, ok := FromStorage(errors.New("connection reset"), fields)
+	assert.False(t, ok)
+}
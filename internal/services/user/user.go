@@ -1,28 +1,62 @@
 package userservice
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"log/slog"
+	"sort"
+	"time"
 
+	"ChadProgress/internal/lib/apperr"
+	"ChadProgress/internal/lib/units"
 	"ChadProgress/internal/models"
 	service "ChadProgress/internal/services"
 	"ChadProgress/storage"
 )
 
+// request fields columns are filled from, used to name the field in constraint violations
+var (
+	trainerFields = service.Fields{"qualifications": "qualification", "achievements": "achievement"}
+	clientFields  = service.Fields{"body_fat": "bodyfat"}
+	planFields    = service.Fields{"client_id": "client-id"}
+	metricFields  = service.Fields{"measured_at": "measured-at"}
+	reportFields  = service.Fields{"client_id": "client-id"}
+)
+
+// Metric series of ranges up to rawSeriesRange consist of measurements, longer ranges are read from
+// daily rollups and ranges longer than daySeriesRange from weekly ones, so that a series has at most
+// about a hundred points and is read without scanning raw metrics.
+const (
+	rawSeriesRange     = 62 * 24 * time.Hour
+	daySeriesRange     = 2 * 365 * 24 * time.Hour
+	defaultSeriesRange = 90 * 24 * time.Hour
+)
+
+var errInvalidRange = apperr.ErrValidation.WithFields(apperr.FieldError{Field: "from", Message: "must be before to"})
+
+//go:generate mockgen -source=user.go -destination=./user_mock.go -package=userservice
 type Storage interface {
+	// WithTx runs fn in a transaction, fn must use storage it is given
+	WithTx(ctx context.Context, fn func(tx Storage) error) error
 	GetUserByEmail(email string) (*models.User, error)
-	GetTrainerByID(id uint) (*models.Trainer, error)
+	LockTrainer(id uint) (*models.Trainer, error)
 	GetTrainerByUserID(id uint) (*models.Trainer, error)
 	GetClientByUserID(id uint) (*models.Client, error)
-	SaveTrainer(trainer *models.Trainer) error
-	SaveClient(client *models.Client) error
-	UpdateTrainerID(clientID, trainerID uint) error
+	SaveTrainer(ctx context.Context, trainer *models.Trainer) error
+	SaveClient(ctx context.Context, client *models.Client) error
+	UpdateTrainerID(ctx context.Context, clientID, trainerID uint) error
+	UpdateClientGoals(ctx context.Context, clientID uint, goals models.MeasurementValues) error
 	GetTrainersClients(trainerID uint) ([]models.Client, error)
-	CreatePlan(plan *models.TrainingPlan) error
-	AddMetrics(metric *models.Metric) error
-	GetMetrics(clientID uint) ([]models.Metric, error)
-	AddProgressReport(report *models.ProgressReport) error
+	CreatePlan(ctx context.Context, plan *models.TrainingPlan) error
+	AddMetrics(ctx context.Context, metric *models.Metric) error
+	ImportMetrics(ctx context.Context, metrics []models.Metric) error
+	GetMetrics(clientID uint, from, to time.Time) ([]models.Metric, error)
+	GetMetricRollups(clientID uint, period string, from, to time.Time) ([]models.MetricRollup, error)
+	GetMeasurementTypes() ([]models.MeasurementType, error)
+	UpdateUserSettings(ctx context.Context, email string, settings models.UserSettings) error
+	RebuildMetricRollups(ctx context.Context, clientID uint) error
+	AddProgressReport(ctx context.Context, report *models.ProgressReport) error
 	GetProgressReport(trainerID, clientID uint) ([]models.ProgressReport, error)
 	GetPlan(trainerID, clientId uint) ([]models.TrainingPlan, error)
 }
@@ -42,7 +76,7 @@ func NewUserService(
 	}
 }
 
-func (u *UserService) CreateTrainer(userEmail, qualification, experience, achievement string) error {
+func (u *UserService) CreateTrainer(ctx context.Context, userEmail, qualification, experience, achievement string) error {
 	const op = "services.user.user.CreateTrainer"
 	log := u.log.With(
 		slog.String("op", op),
@@ -50,12 +84,16 @@ func (u *UserService) CreateTrainer(userEmail, qualification, experience, achiev
 
 	user, _ := u.storage.GetUserByEmail(userEmail)
 	if user == nil {
-		log.Error(fmt.Sprintf("user with email <%s> not found", userEmail))
-		return errors.New("user not found")
+		log.Error("user not found", slog.String("email", userEmail))
+		return service.ErrUserNotFound
 	}
-	if user.Role == models.RoleClient {
-		log.Error(fmt.Sprintf("user with email <%s> tried to create trainer profile while being client", userEmail))
-		return service.ErrInvalidRoleRequest
+	if user.Role != models.RoleTrainer {
+		log.Error("non trainer tried to create trainer profile", slog.String("email", userEmail), slog.String("role", user.Role))
+		return service.ErrInvalidRoleRequest.WithMessage("cannot create trainer profile while being " + user.Role)
+	}
+	if !user.EmailVerified() {
+		log.Info("unverified user tried to create trainer profile", slog.String("email", userEmail))
+		return service.ErrEmailNotVerified
 	}
 
 	newTrainer := &models.Trainer{
@@ -65,12 +103,12 @@ func (u *UserService) CreateTrainer(userEmail, qualification, experience, achiev
 		Achievements:   achievement,
 	}
 
-	err := u.storage.SaveTrainer(newTrainer)
+	err := u.storage.SaveTrainer(ctx, newTrainer)
 	if err != nil {
 		if errors.Is(err, storage.ErrDuplicateKey) {
 			return service.ErrDuplicateKey
-		} else if errors.Is(err, storage.ErrFieldIsTooLong) {
-			return fmt.Errorf("%s: %w", op, service.ErrFieldIsTooLong)
+		} else if appErr, ok := service.FromStorage(err, trainerFields); ok {
+			return fmt.Errorf("%s: %w", op, appErr)
 		}
 		return err
 	}
@@ -78,7 +116,7 @@ func (u *UserService) CreateTrainer(userEmail, qualification, experience, achiev
 	return nil
 }
 
-func (u *UserService) CreateClient(userEmail string, height, weight, bodyFat float64) error {
+func (u *UserService) CreateClient(ctx context.Context, userEmail string, height, weight, bodyFat float64) error {
 	const op = "services.user.user.CreateClient"
 	log := u.log.With(
 		slog.String("op", op),
@@ -86,14 +124,19 @@ func (u *UserService) CreateClient(userEmail string, height, weight, bodyFat flo
 
 	user, _ := u.storage.GetUserByEmail(userEmail)
 	if user == nil {
-		log.Error(fmt.Sprintf("user with email <%s> not found", userEmail))
+		log.Error("user not found", slog.String("email", userEmail))
 
-		return errors.New("user not found")
+		return service.ErrUserNotFound
 	}
-	if user.Role == models.RoleTrainer {
-		log.Error("trainer cant create client profile")
+	if user.Role != models.RoleClient {
+		log.Error("non client tried to create client profile", slog.String("role", user.Role))
 
-		return service.ErrInvalidRoleRequest
+		return service.ErrInvalidRoleRequest.WithMessage("cannot create client profile while being " + user.Role)
+	}
+	if !user.EmailVerified() {
+		log.Info("unverified user tried to create client profile")
+
+		return service.ErrEmailNotVerified
 	}
 
 	newClient := &models.Client{
@@ -104,12 +147,12 @@ func (u *UserService) CreateClient(userEmail string, height, weight, bodyFat flo
 		BodyFat:   bodyFat,
 	}
 
-	err := u.storage.SaveClient(newClient)
+	err := u.storage.SaveClient(ctx, newClient)
 	if err != nil {
 		if errors.Is(err, storage.ErrDuplicateKey) {
 			return service.ErrDuplicateKey
-		} else if errors.Is(err, storage.ErrFieldIsTooLong) {
-			return fmt.Errorf("%s: %w", op, service.ErrFieldIsTooLong)
+		} else if appErr, ok := service.FromStorage(err, clientFields); ok {
+			return fmt.Errorf("%s: %w", op, appErr)
 		}
 
 		return err
@@ -118,7 +161,7 @@ func (u *UserService) CreateClient(userEmail string, height, weight, bodyFat flo
 	return nil
 }
 
-func (u *UserService) SelectTrainer(userEmail string, trainerID uint) error {
+func (u *UserService) SelectTrainer(ctx context.Context, userEmail string, trainerID uint) error {
 	const op = "services.user.user.SelectTrainer"
 	log := u.log.With(
 		slog.String("op", op),
@@ -126,7 +169,7 @@ func (u *UserService) SelectTrainer(userEmail string, trainerID uint) error {
 
 	clientUser, _ := u.storage.GetUserByEmail(userEmail)
 	if clientUser == nil {
-		log.Error(fmt.Sprintf("profile with email <%s> not found", userEmail))
+		log.Error("user not found", slog.String("email", userEmail))
 
 		return service.ErrUserNotFound
 	}
@@ -136,27 +179,25 @@ func (u *UserService) SelectTrainer(userEmail string, trainerID uint) error {
 		return service.ErrInvalidRoleRequest
 	}
 
-	client, _ := u.storage.GetClientByUserID(clientUser.ID)
-	if client == nil {
-		log.Error(fmt.Sprintf("client profile with email <%s> not found", userEmail))
-
-		return service.ErrClientNotFound
-	}
+	// trainer is locked, so that they can not leave for vacation before client is bound to them
+	return u.storage.WithTx(ctx, func(tx Storage) error {
+		client, _ := tx.GetClientByUserID(clientUser.ID)
+		if client == nil {
+			log.Error("client profile not found", slog.String("email", userEmail))
 
-	trainer, err := u.storage.GetTrainerByID(trainerID)
-	if err != nil {
-		return service.ErrTrainerNotFound
-	}
-	if trainer.Status != models.StatusActive {
-		return service.ErrNotActiveTrainer
-	}
+			return service.ErrClientNotFound
+		}
 
-	err = u.storage.UpdateTrainerID(client.ID, trainerID)
-	if err != nil {
-		return err
-	}
+		trainer, err := tx.LockTrainer(trainerID)
+		if err != nil {
+			return service.ErrTrainerNotFound
+		}
+		if trainer.Status != models.StatusActive {
+			return service.ErrNotActiveTrainer
+		}
 
-	return nil
+		return tx.UpdateTrainerID(ctx, client.ID, trainerID)
+	})
 }
 
 func (u *UserService) GetClientProfile(userEmail string) (*models.Client, error) {
@@ -167,12 +208,12 @@ func (u *UserService) GetClientProfile(userEmail string) (*models.Client, error)
 
 	user, _ := u.storage.GetUserByEmail(userEmail)
 	if user == nil {
-		log.Error(fmt.Sprintf("user with email <%s> not found", userEmail))
+		log.Error("user not found", slog.String("email", userEmail))
 		return nil, service.ErrUserNotFound
 	}
 	if user.Role != models.RoleClient {
 		log.Error("trainer cant get info about client")
-		return nil, service.ErrInvalidRoleRequest
+		return nil, service.ErrInvalidRoleRequest.WithMessage("you cant get info about client as a trainer")
 	}
 
 	client, _ := u.storage.GetClientByUserID(user.ID)
@@ -183,6 +224,36 @@ func (u *UserService) GetClientProfile(userEmail string) (*models.Client, error)
 	return client, nil
 }
 
+// SetGoals replaces goals of client, every target is checked against measurement catalog. Empty goals
+// clear them.
+func (u *UserService) SetGoals(ctx context.Context, clientEmail string, goals models.MeasurementValues) error {
+	const op = "services.user.user.SetGoals"
+	log := u.log.With(
+		slog.String("op", op),
+	)
+
+	_, client, err := u.metricsOwner(log, clientEmail)
+	if err != nil {
+		return err
+	}
+
+	catalog, err := u.measurementCatalog()
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	if fields := measurementErrors(catalog, "goals", goals); len(fields) > 0 {
+		return apperr.ErrValidation.WithFields(fields...)
+	}
+
+	if err = u.storage.UpdateClientGoals(ctx, client.ID, goals); err != nil {
+		log.Error("failed to update goals", slog.String("error", err.Error()))
+
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	return nil
+}
+
 func (u *UserService) GetTrainerProfile(userEmail string) (*models.Trainer, error) {
 	const op = "services.user.user.GetTrainerProfile"
 	log := u.log.With(
@@ -191,7 +262,7 @@ func (u *UserService) GetTrainerProfile(userEmail string) (*models.Trainer, erro
 
 	user, _ := u.storage.GetUserByEmail(userEmail)
 	if user == nil {
-		log.Error(fmt.Sprintf("user with email <%s> not found", userEmail))
+		log.Error("user not found", slog.String("email", userEmail))
 		return nil, service.ErrUserNotFound
 	}
 
@@ -212,7 +283,7 @@ func (u *UserService) GetTrainersClients(userEmail string) ([]models.Client, err
 
 	user, _ := u.storage.GetUserByEmail(userEmail)
 	if user == nil {
-		log.Error(fmt.Sprintf("user with email <%s> not found", userEmail))
+		log.Error("user not found", slog.String("email", userEmail))
 
 		return nil, service.ErrUserNotFound
 	}
@@ -237,7 +308,7 @@ func (u *UserService) GetTrainersClients(userEmail string) ([]models.Client, err
 	return clients, nil
 }
 
-func (u *UserService) CreatePlan(trainerEmail string, clientID uint, description, schedule string) error {
+func (u *UserService) CreatePlan(ctx context.Context, trainerEmail string, clientID uint, description, schedule string) error {
 	const op = "services.user.user.CreatePlan"
 	log := u.log.With(
 		slog.String("op", op),
@@ -245,7 +316,7 @@ func (u *UserService) CreatePlan(trainerEmail string, clientID uint, description
 
 	user, _ := u.storage.GetUserByEmail(trainerEmail)
 	if user == nil {
-		log.Error(fmt.Sprintf("user with email <%s> not found", trainerEmail))
+		log.Error("user not found", slog.String("email", trainerEmail))
 
 		return service.ErrUserNotFound
 	}
@@ -270,15 +341,20 @@ func (u *UserService) CreatePlan(trainerEmail string, clientID uint, description
 		Schedule:    schedule,
 	}
 
-	err := u.storage.CreatePlan(&plan)
+	err := u.storage.CreatePlan(ctx, &plan)
 	if err != nil {
+		if appErr, ok := service.FromStorage(err, planFields); ok {
+			return fmt.Errorf("%s: %w", op, appErr)
+		}
+
 		return err
 	}
 
 	return nil
 }
 
-func (u *UserService) AddMetrics(clientEmail string, height, weight, bodyFat, bmi float64, measuredAt models.CustomTime) error {
+// AddMetrics saves measurements of client taken at once, every value is checked against measurement catalog
+func (u *UserService) AddMetrics(ctx context.Context, clientEmail string, values models.MeasurementValues, measuredAt time.Time) error {
 	const op = "services.user.user.AddMetrics"
 	log := u.log.With(
 		slog.String("op", op),
@@ -287,7 +363,7 @@ func (u *UserService) AddMetrics(clientEmail string, height, weight, bodyFat, bm
 	user, _ := u.storage.GetUserByEmail(clientEmail)
 
 	if user == nil {
-		log.Error(fmt.Sprintf("user with email <%s> not found", clientEmail))
+		log.Error("user not found", slog.String("email", clientEmail))
 		return service.ErrUserNotFound
 	}
 
@@ -300,63 +376,441 @@ func (u *UserService) AddMetrics(clientEmail string, height, weight, bodyFat, bm
 	if err != nil {
 		log.Error("client profile not found")
 
-		return service.ErrUserNotFound
+		return service.ErrClientNotFound
+	}
+
+	// metrics without time would be kept in default partition outside of every rollup, imports reject them too
+	if measuredAt.IsZero() {
+		return apperr.ErrValidation.WithFields(apperr.FieldError{Field: "measured-at", Message: "is required"})
+	}
+	if err = u.checkMeasurements(values); err != nil {
+		return err
 	}
 
 	metric := &models.Metric{
-		ClientID:   client.ID,
-		Height:     height,
-		Weight:     weight,
-		BodyFat:    bodyFat,
-		BMI:        bmi,
-		MeasuredAt: measuredAt.Time,
+		ClientID:     client.ID,
+		Measurements: values,
+		MeasuredAt:   measuredAt,
 	}
 
-	err = u.storage.AddMetrics(metric)
+	err = u.storage.AddMetrics(ctx, metric)
 	if err != nil {
+		if appErr, ok := service.FromStorage(err, metricFields); ok {
+			return fmt.Errorf("%s: %w", op, appErr)
+		}
+
 		return err
 	}
 
 	return nil
 }
 
-func (u *UserService) GetMetrics(clientEmail string) ([]models.Metric, error) {
-	const op = "services.user.user.AddMetrics"
+// ImportMetrics adds rows of imported file to metrics of client. Every row is checked against measurement
+// catalog, rows measured at the same time as an existing metric or an earlier row are duplicates. Valid
+// rows are added in one transaction, the report tells what became of each row.
+func (u *UserService) ImportMetrics(ctx context.Context, clientEmail string, rows []models.MetricImportRow) (*models.MetricImportReport, error) {
+	const op = "services.user.user.ImportMetrics"
+	log := u.log.With(
+		slog.String("op", op),
+	)
+
+	_, client, err := u.metricsOwner(log, clientEmail)
+	if err != nil {
+		return nil, err
+	}
+	if len(rows) == 0 {
+		return nil, apperr.ErrValidation.WithMessage("file has no metrics")
+	}
+
+	catalog, err := u.measurementCatalog()
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	report := &models.MetricImportReport{Rows: make([]models.MetricImportResult, len(rows))}
+	valid := make([]int, 0, len(rows))
+	var from, to time.Time
+	for i, row := range rows {
+		report.Rows[i] = models.MetricImportResult{Row: row.Row, Status: models.ImportRejected}
+		fields := row.Errors
+		if len(fields) == 0 {
+			if row.MeasuredAt.IsZero() {
+				fields = append(fields, apperr.FieldError{Field: "measured-at", Message: "is required"})
+			}
+			if len(row.Measurements) == 0 {
+				fields = append(fields, apperr.FieldError{Field: "measurements", Message: "at least one measurement is required"})
+			}
+			fields = append(fields, measurementErrors(catalog, "measurements", row.Measurements)...)
+		}
+		if len(fields) > 0 {
+			report.Rows[i].Errors = fields
+			continue
+		}
+
+		valid = append(valid, i)
+		if from.IsZero() || row.MeasuredAt.Before(from) {
+			from = row.MeasuredAt
+		}
+		if row.MeasuredAt.After(to) {
+			to = row.MeasuredAt
+		}
+	}
+
+	if len(valid) > 0 {
+		err = u.storage.WithTx(ctx, func(tx Storage) error {
+			existing, err := tx.GetMetrics(client.ID, from, to.Add(time.Microsecond))
+			if err != nil {
+				return err
+			}
+			// database keeps microseconds
+			seen := make(map[int64]bool, len(existing)+len(valid))
+			for _, m := range existing {
+				seen[m.MeasuredAt.UnixMicro()] = true
+			}
+
+			metrics := make([]models.Metric, 0, len(valid))
+			for _, i := range valid {
+				at := rows[i].MeasuredAt.UnixMicro()
+				if seen[at] {
+					report.Rows[i].Status = models.ImportDuplicate
+					continue
+				}
+				seen[at] = true
+				report.Rows[i].Status = models.ImportAccepted
+				metrics = append(metrics, models.Metric{
+					ClientID:     client.ID,
+					Measurements: rows[i].Measurements,
+					MeasuredAt:   rows[i].MeasuredAt,
+				})
+			}
+
+			return tx.ImportMetrics(ctx, metrics)
+		})
+	}
+	if err != nil {
+		log.Error("failed to import metrics", slog.String("error", err.Error()))
+		if appErr, ok := service.FromStorage(err, metricFields); ok {
+			return nil, fmt.Errorf("%s: %w", op, appErr)
+		}
+
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	for _, r := range report.Rows {
+		switch r.Status {
+		case models.ImportAccepted:
+			report.Accepted++
+		case models.ImportDuplicate:
+			report.Duplicates++
+		default:
+			report.Rejected++
+		}
+	}
+	log.Info("metrics imported", slog.Int("accepted", report.Accepted), slog.Int("rejected", report.Rejected),
+		slog.Int("duplicates", report.Duplicates))
+
+	return report, nil
+}
+
+// GetMetrics returns metrics of client measured within [from, to), zero from or to leaves the range open
+func (u *UserService) GetMetrics(clientEmail string, from, to time.Time) ([]models.Metric, error) {
+	const op = "services.user.user.GetMetrics"
+	log := u.log.With(
+		slog.String("op", op),
+	)
+
+	_, client, err := u.metricsOwner(log, clientEmail)
+	if err != nil {
+		return []models.Metric{}, err
+	}
+
+	if !from.IsZero() && !to.IsZero() && !from.Before(to) {
+		return []models.Metric{}, errInvalidRange
+	}
+
+	metrics, err := u.storage.GetMetrics(client.ID, from, to)
+	if err != nil {
+		// TODO: return more detailed error
+		return []models.Metric{}, err
+	}
+
+	return metrics, nil
+}
+
+// MetricSeries returns metrics of client within [from, to) in resolution fitting the range, see
+// rawSeriesRange. Zero to is now and zero from is defaultSeriesRange before to. Days and weeks are
+// those of time zone of the client, times of the series are in it.
+func (u *UserService) MetricSeries(clientEmail string, from, to time.Time) (*models.MetricSeries, error) {
+	const op = "services.user.user.MetricSeries"
 	log := u.log.With(
 		slog.String("op", op),
 	)
 
+	user, client, err := u.metricsOwner(log, clientEmail)
+	if err != nil {
+		return nil, err
+	}
+
+	loc := user.Location()
+	if to.IsZero() {
+		to = time.Now()
+	}
+	if from.IsZero() {
+		from = to.Add(-defaultSeriesRange)
+	}
+	if !from.Before(to) {
+		return nil, errInvalidRange
+	}
+
+	series := &models.MetricSeries{From: from.In(loc), To: to.In(loc), Resolution: seriesResolution(from, to)}
+	if series.Resolution == models.ResolutionRaw {
+		metrics, err := u.storage.GetMetrics(client.ID, from, to)
+		if err != nil {
+			log.Error("failed to get metrics", slog.String("error", err.Error()))
+
+			return nil, fmt.Errorf("%s: %w", op, err)
+		}
+		series.Points = make([]models.MetricPoint, 0, len(metrics))
+		for _, m := range metrics {
+			point := models.MetricPoint{Start: m.MeasuredAt.In(loc), Values: make(map[string]models.MetricStat, len(m.Measurements))}
+			for code, v := range m.Measurements {
+				point.Values[code] = models.MetricStat{Count: 1, Avg: v, Min: v, Max: v}
+			}
+			series.Points = append(series.Points, point)
+		}
+
+		return series, nil
+	}
+
+	// rollups are keyed by local dates. Periods are included when they start within the range, so the
+	// first one may start before from; the last one starts on the day to falls within at the latest.
+	last := models.PeriodStart(models.PeriodDay, to.Add(-time.Nanosecond), loc)
+	rollups, err := u.storage.GetMetricRollups(client.ID, series.Resolution,
+		models.PeriodStart(series.Resolution, from, loc), last.AddDate(0, 0, 1))
+	if err != nil {
+		log.Error("failed to get metric rollups", slog.String("error", err.Error()))
+
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	// rollups are ordered by start, every type of a period makes a value of its point
+	series.Points = []models.MetricPoint{}
+	for _, r := range rollups {
+		if r.Count == 0 {
+			continue
+		}
+		start := time.Date(r.PeriodStart.Year(), r.PeriodStart.Month(), r.PeriodStart.Day(), 0, 0, 0, 0, loc)
+		if n := len(series.Points); n == 0 || !series.Points[n-1].Start.Equal(start) {
+			series.Points = append(series.Points, models.MetricPoint{Start: start, Values: map[string]models.MetricStat{}})
+		}
+		series.Points[len(series.Points)-1].Values[r.Type] = models.MetricStat{
+			Count: r.Count,
+			Avg:   r.ValueSum / float64(r.Count),
+			Min:   r.ValueMin,
+			Max:   r.ValueMax,
+		}
+	}
+
+	return series, nil
+}
+
+// metricsOwner returns user whose metrics are requested and their client profile
+func (u *UserService) metricsOwner(log *slog.Logger, clientEmail string) (*models.User, *models.Client, error) {
 	user, _ := u.storage.GetUserByEmail(clientEmail)
 
 	if user == nil {
-		log.Error(fmt.Sprintf("user with email <%s> not found", clientEmail))
+		log.Error("user not found", slog.String("email", clientEmail))
 
-		return []models.Metric{}, service.ErrUserNotFound
+		return nil, nil, service.ErrUserNotFound
 	}
 
 	if user.Role != models.RoleClient {
-		log.Error("trainers cant add metrics")
+		log.Error("trainers have no metrics")
 
-		return []models.Metric{}, service.ErrInvalidRoleRequest
+		return nil, nil, service.ErrInvalidRoleRequest
 	}
 
 	client, err := u.storage.GetClientByUserID(user.ID)
 	if err != nil {
 		log.Error("client profile not found")
 
-		return []models.Metric{}, service.ErrUserNotFound
+		return nil, nil, service.ErrClientNotFound
 	}
 
-	metrics, err := u.storage.GetMetrics(client.ID)
+	return user, client, nil
+}
+
+// MeasurementTypes returns measurement catalog
+func (u *UserService) MeasurementTypes() ([]models.MeasurementType, error) {
+	const op = "services.user.user.MeasurementTypes"
+
+	types, err := u.storage.GetMeasurementTypes()
 	if err != nil {
-		// TODO: return more detailed error
-		return []models.Metric{}, err
+		return nil, fmt.Errorf("%s: %w", op, err)
 	}
 
-	return metrics, nil
+	return types, nil
+}
+
+// Settings returns preferences of user, unit system is metric and time zone is UTC unless user has
+// chosen others
+func (u *UserService) Settings(userEmail string) (*models.UserSettings, error) {
+	const op = "services.user.user.Settings"
+	log := u.log.With(
+		slog.String("op", op),
+	)
+
+	user, _ := u.storage.GetUserByEmail(userEmail)
+	if user == nil {
+		log.Error("user not found", slog.String("email", userEmail))
+
+		return nil, service.ErrUserNotFound
+	}
+
+	settings := &models.UserSettings{Units: user.Units, Timezone: user.Location().String()}
+	if settings.Units == "" {
+		settings.Units = string(units.Metric)
+	}
+
+	return settings, nil
 }
 
-func (u *UserService) AddProgressReport(trainerEmail, comments string, clientID uint) error {
+// UpdateSettings changes nonempty preferences of user. Metric rollups of a client changing time zone are
+// computed again, so that they are grouped by days of the new one.
+func (u *UserService) UpdateSettings(ctx context.Context, userEmail string, settings models.UserSettings) error {
+	const op = "services.user.user.UpdateSettings"
+	log := u.log.With(
+		slog.String("op", op),
+	)
+
+	if settings == (models.UserSettings{}) {
+		return apperr.ErrValidation.WithMessage("no settings to change")
+	}
+	var fields []apperr.FieldError
+	if settings.Units != "" {
+		system, err := units.Parse(settings.Units)
+		if err != nil {
+			fields = append(fields, apperr.FieldError{Field: "units", Message: err.Error()})
+		}
+		settings.Units = string(system)
+	}
+	if settings.Timezone != "" {
+		// Local is time zone of the server, not a zone user may live in
+		if _, err := time.LoadLocation(settings.Timezone); err != nil || settings.Timezone == "Local" {
+			fields = append(fields, apperr.FieldError{Field: "timezone", Message: "must be IANA time zone, e.g. Europe/Moscow"})
+		}
+	}
+	if len(fields) > 0 {
+		return apperr.ErrValidation.WithFields(fields...)
+	}
+
+	user, _ := u.storage.GetUserByEmail(userEmail)
+	if user == nil {
+		log.Error("user not found", slog.String("email", userEmail))
+
+		return service.ErrUserNotFound
+	}
+
+	err := u.storage.WithTx(ctx, func(tx Storage) error {
+		if err := tx.UpdateUserSettings(ctx, userEmail, settings); err != nil {
+			return err
+		}
+		if settings.Timezone == "" || settings.Timezone == user.Timezone || user.Role != models.RoleClient {
+			return nil
+		}
+
+		client, err := tx.GetClientByUserID(user.ID)
+		if errors.Is(err, storage.ErrRecordNotFound) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		return tx.RebuildMetricRollups(ctx, client.ID)
+	})
+	if err != nil {
+		if errors.Is(err, storage.ErrRecordNotFound) {
+			return service.ErrUserNotFound
+		}
+		if appErr, ok := service.FromStorage(err, nil); ok {
+			return fmt.Errorf("%s: %w", op, appErr)
+		}
+
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	log.Info("settings updated", slog.String("units", settings.Units), slog.String("timezone", settings.Timezone))
+
+	return nil
+}
+
+// checkMeasurements validates values against measurement catalog, every invalid value is reported
+// as a field of measurements
+func (u *UserService) checkMeasurements(values models.MeasurementValues) error {
+	if len(values) == 0 {
+		return apperr.ErrValidation.WithFields(apperr.FieldError{Field: "measurements", Message: "at least one measurement is required"})
+	}
+
+	catalog, err := u.measurementCatalog()
+	if err != nil {
+		return err
+	}
+	if fields := measurementErrors(catalog, "measurements", values); len(fields) > 0 {
+		return apperr.ErrValidation.WithFields(fields...)
+	}
+
+	return nil
+}
+
+// measurementCatalog returns measurement types by code
+func (u *UserService) measurementCatalog() (map[string]models.MeasurementType, error) {
+	types, err := u.storage.GetMeasurementTypes()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get measurement catalog: %w", err)
+	}
+	catalog := make(map[string]models.MeasurementType, len(types))
+	for _, t := range types {
+		catalog[t.Code] = t
+	}
+
+	return catalog, nil
+}
+
+// measurementErrors checks values against catalog, errors of fields named field.code are ordered by code
+func measurementErrors(catalog map[string]models.MeasurementType, field string, values models.MeasurementValues) []apperr.FieldError {
+	codes := make([]string, 0, len(values))
+	for code := range values {
+		codes = append(codes, code)
+	}
+	sort.Strings(codes)
+
+	var fields []apperr.FieldError
+	for _, code := range codes {
+		field := field + "." + code
+		t, ok := catalog[code]
+		if !ok {
+			fields = append(fields, apperr.FieldError{Field: field, Message: "is not a known measurement type"})
+			continue
+		}
+		if err := t.Check(values[code]); err != nil {
+			fields = append(fields, apperr.FieldError{Field: field, Message: err.Error()})
+		}
+	}
+
+	return fields
+}
+
+func seriesResolution(from, to time.Time) string {
+	switch d := to.Sub(from); {
+	case d <= rawSeriesRange:
+		return models.ResolutionRaw
+	case d <= daySeriesRange:
+		return models.PeriodDay
+	default:
+		return models.PeriodWeek
+	}
+}
+
+func (u *UserService) AddProgressReport(ctx context.Context, trainerEmail, comments string, clientID uint) error {
 	const op = "services.user.user.AddProgressReport"
 	log := u.log.With(
 		slog.String("op", op),
@@ -365,7 +819,7 @@ func (u *UserService) AddProgressReport(trainerEmail, comments string, clientID
 	user, _ := u.storage.GetUserByEmail(trainerEmail)
 
 	if user == nil {
-		log.Error(fmt.Sprintf("user with email <%s> not found", user.Email))
+		log.Error("user not found", slog.String("email", trainerEmail))
 
 		return service.ErrUserNotFound
 	}
@@ -379,7 +833,7 @@ func (u *UserService) AddProgressReport(trainerEmail, comments string, clientID
 	trainer, err := u.storage.GetTrainerByUserID(user.ID)
 	if err != nil {
 		log.Error("trainer profile not found")
-		return service.ErrInvalidRoleRequest
+		return service.ErrTrainerNotFound
 	}
 
 	report := &models.ProgressReport{
@@ -388,8 +842,11 @@ func (u *UserService) AddProgressReport(trainerEmail, comments string, clientID
 		Comments:  comments,
 	}
 
-	err = u.storage.AddProgressReport(report)
+	err = u.storage.AddProgressReport(ctx, report)
 	if err != nil {
+		if appErr, ok := service.FromStorage(err, reportFields); ok {
+			return fmt.Errorf("%s: %w", op, appErr)
+		}
 		log.Error("error occurred while adding progress report")
 
 		return err
@@ -406,7 +863,7 @@ func (u *UserService) GetProgressReport(userEmail string, trainerID, clientID ui
 
 	user, _ := u.storage.GetUserByEmail(userEmail)
 	if user == nil {
-		log.Error(fmt.Sprintf("user with email <%s> not found", user.Email))
+		log.Error("user not found", slog.String("email", userEmail))
 
 		return []models.ProgressReport{}, service.ErrUserNotFound
 	}
@@ -429,7 +886,7 @@ func (u *UserService) GetPlan(userEmail string, trainerID, clientID uint) ([]mod
 
 	user, _ := u.storage.GetUserByEmail(userEmail)
 	if user == nil {
-		log.Error(fmt.Sprintf("user with email <%s> not found", user.Email))
+		log.Error("user not found", slog.String("email", userEmail))
 
 		return []models.TrainingPlan{}, service.ErrUserNotFound
 	}
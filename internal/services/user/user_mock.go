@@ -0,0 +1,341 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: user.go
+
+// Package userservice is a generated GoMock package.
+package userservice
+
+import (
+	models "ChadProgress/internal/models"
+	context "context"
+	reflect "reflect"
+	time "time"
+
+	gomock "github.com/golang/mock/gomock"
+)
+
+// MockStorage is a mock of Storage interface.
+type MockStorage struct {
+	ctrl     *gomock.Controller
+	recorder *MockStorageMockRecorder
+}
+
+// MockStorageMockRecorder is the mock recorder for MockStorage.
+type MockStorageMockRecorder struct {
+	mock *MockStorage
+}
+
+// NewMockStorage creates a new mock instance.
+func NewMockStorage(ctrl *gomock.Controller) *MockStorage {
+	mock := &MockStorage{ctrl: ctrl}
+	mock.recorder = &MockStorageMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockStorage) EXPECT() *MockStorageMockRecorder {
+	return m.recorder
+}
+
+// AddMetrics mocks base method.
+func (m *MockStorage) AddMetrics(ctx context.Context, metric *models.Metric) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AddMetrics", ctx, metric)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// AddMetrics indicates an expected call of AddMetrics.
+func (mr *MockStorageMockRecorder) AddMetrics(ctx, metric interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddMetrics", reflect.TypeOf((*MockStorage)(nil).AddMetrics), ctx, metric)
+}
+
+// AddProgressReport mocks base method.
+func (m *MockStorage) AddProgressReport(ctx context.Context, report *models.ProgressReport) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AddProgressReport", ctx, report)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// AddProgressReport indicates an expected call of AddProgressReport.
+func (mr *MockStorageMockRecorder) AddProgressReport(ctx, report interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddProgressReport", reflect.TypeOf((*MockStorage)(nil).AddProgressReport), ctx, report)
+}
+
+// CreatePlan mocks base method.
+func (m *MockStorage) CreatePlan(ctx context.Context, plan *models.TrainingPlan) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreatePlan", ctx, plan)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CreatePlan indicates an expected call of CreatePlan.
+func (mr *MockStorageMockRecorder) CreatePlan(ctx, plan interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreatePlan", reflect.TypeOf((*MockStorage)(nil).CreatePlan), ctx, plan)
+}
+
+// GetClientByUserID mocks base method.
+func (m *MockStorage) GetClientByUserID(id uint) (*models.Client, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetClientByUserID", id)
+	ret0, _ := ret[0].(*models.Client)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetClientByUserID indicates an expected call of GetClientByUserID.
+func (mr *MockStorageMockRecorder) GetClientByUserID(id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetClientByUserID", reflect.TypeOf((*MockStorage)(nil).GetClientByUserID), id)
+}
+
+// GetMeasurementTypes mocks base method.
+func (m *MockStorage) GetMeasurementTypes() ([]models.MeasurementType, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetMeasurementTypes")
+	ret0, _ := ret[0].([]models.MeasurementType)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetMeasurementTypes indicates an expected call of GetMeasurementTypes.
+func (mr *MockStorageMockRecorder) GetMeasurementTypes() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetMeasurementTypes", reflect.TypeOf((*MockStorage)(nil).GetMeasurementTypes))
+}
+
+// GetMetricRollups mocks base method.
+func (m *MockStorage) GetMetricRollups(clientID uint, period string, from, to time.Time) ([]models.MetricRollup, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetMetricRollups", clientID, period, from, to)
+	ret0, _ := ret[0].([]models.MetricRollup)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetMetricRollups indicates an expected call of GetMetricRollups.
+func (mr *MockStorageMockRecorder) GetMetricRollups(clientID, period, from, to interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetMetricRollups", reflect.TypeOf((*MockStorage)(nil).GetMetricRollups), clientID, period, from, to)
+}
+
+// GetMetrics mocks base method.
+func (m *MockStorage) GetMetrics(clientID uint, from, to time.Time) ([]models.Metric, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetMetrics", clientID, from, to)
+	ret0, _ := ret[0].([]models.Metric)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetMetrics indicates an expected call of GetMetrics.
+func (mr *MockStorageMockRecorder) GetMetrics(clientID, from, to interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetMetrics", reflect.TypeOf((*MockStorage)(nil).GetMetrics), clientID, from, to)
+}
+
+// GetPlan mocks base method.
+func (m *MockStorage) GetPlan(trainerID, clientId uint) ([]models.TrainingPlan, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetPlan", trainerID, clientId)
+	ret0, _ := ret[0].([]models.TrainingPlan)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetPlan indicates an expected call of GetPlan.
+func (mr *MockStorageMockRecorder) GetPlan(trainerID, clientId interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetPlan", reflect.TypeOf((*MockStorage)(nil).GetPlan), trainerID, clientId)
+}
+
+// GetProgressReport mocks base method.
+func (m *MockStorage) GetProgressReport(trainerID, clientID uint) ([]models.ProgressReport, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetProgressReport", trainerID, clientID)
+	ret0, _ := ret[0].([]models.ProgressReport)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetProgressReport indicates an expected call of GetProgressReport.
+func (mr *MockStorageMockRecorder) GetProgressReport(trainerID, clientID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetProgressReport", reflect.TypeOf((*MockStorage)(nil).GetProgressReport), trainerID, clientID)
+}
+
+// GetTrainerByUserID mocks base method.
+func (m *MockStorage) GetTrainerByUserID(id uint) (*models.Trainer, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetTrainerByUserID", id)
+	ret0, _ := ret[0].(*models.Trainer)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetTrainerByUserID indicates an expected call of GetTrainerByUserID.
+func (mr *MockStorageMockRecorder) GetTrainerByUserID(id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetTrainerByUserID", reflect.TypeOf((*MockStorage)(nil).GetTrainerByUserID), id)
+}
+
+// GetTrainersClients mocks base method.
+func (m *MockStorage) GetTrainersClients(trainerID uint) ([]models.Client, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetTrainersClients", trainerID)
+	ret0, _ := ret[0].([]models.Client)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetTrainersClients indicates an expected call of GetTrainersClients.
+func (mr *MockStorageMockRecorder) GetTrainersClients(trainerID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetTrainersClients", reflect.TypeOf((*MockStorage)(nil).GetTrainersClients), trainerID)
+}
+
+// GetUserByEmail mocks base method.
+func (m *MockStorage) GetUserByEmail(email string) (*models.User, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetUserByEmail", email)
+	ret0, _ := ret[0].(*models.User)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetUserByEmail indicates an expected call of GetUserByEmail.
+func (mr *MockStorageMockRecorder) GetUserByEmail(email interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUserByEmail", reflect.TypeOf((*MockStorage)(nil).GetUserByEmail), email)
+}
+
+// ImportMetrics mocks base method.
+func (m *MockStorage) ImportMetrics(ctx context.Context, metrics []models.Metric) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ImportMetrics", ctx, metrics)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ImportMetrics indicates an expected call of ImportMetrics.
+func (mr *MockStorageMockRecorder) ImportMetrics(ctx, metrics interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ImportMetrics", reflect.TypeOf((*MockStorage)(nil).ImportMetrics), ctx, metrics)
+}
+
+// LockTrainer mocks base method.
+func (m *MockStorage) LockTrainer(id uint) (*models.Trainer, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "LockTrainer", id)
+	ret0, _ := ret[0].(*models.Trainer)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// LockTrainer indicates an expected call of LockTrainer.
+func (mr *MockStorageMockRecorder) LockTrainer(id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "LockTrainer", reflect.TypeOf((*MockStorage)(nil).LockTrainer), id)
+}
+
+// RebuildMetricRollups mocks base method.
+func (m *MockStorage) RebuildMetricRollups(ctx context.Context, clientID uint) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RebuildMetricRollups", ctx, clientID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RebuildMetricRollups indicates an expected call of RebuildMetricRollups.
+func (mr *MockStorageMockRecorder) RebuildMetricRollups(ctx, clientID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RebuildMetricRollups", reflect.TypeOf((*MockStorage)(nil).RebuildMetricRollups), ctx, clientID)
+}
+
+// SaveClient mocks base method.
+func (m *MockStorage) SaveClient(ctx context.Context, client *models.Client) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SaveClient", ctx, client)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SaveClient indicates an expected call of SaveClient.
+func (mr *MockStorageMockRecorder) SaveClient(ctx, client interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SaveClient", reflect.TypeOf((*MockStorage)(nil).SaveClient), ctx, client)
+}
+
+// SaveTrainer mocks base method.
+func (m *MockStorage) SaveTrainer(ctx context.Context, trainer *models.Trainer) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SaveTrainer", ctx, trainer)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SaveTrainer indicates an expected call of SaveTrainer.
+func (mr *MockStorageMockRecorder) SaveTrainer(ctx, trainer interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SaveTrainer", reflect.TypeOf((*MockStorage)(nil).SaveTrainer), ctx, trainer)
+}
+
+// UpdateClientGoals mocks base method.
+func (m *MockStorage) UpdateClientGoals(ctx context.Context, clientID uint, goals models.MeasurementValues) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateClientGoals", ctx, clientID, goals)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateClientGoals indicates an expected call of UpdateClientGoals.
+func (mr *MockStorageMockRecorder) UpdateClientGoals(ctx, clientID, goals interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateClientGoals", reflect.TypeOf((*MockStorage)(nil).UpdateClientGoals), ctx, clientID, goals)
+}
+
+// UpdateTrainerID mocks base method.
+func (m *MockStorage) UpdateTrainerID(ctx context.Context, clientID, trainerID uint) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateTrainerID", ctx, clientID, trainerID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateTrainerID indicates an expected call of UpdateTrainerID.
+func (mr *MockStorageMockRecorder) UpdateTrainerID(ctx, clientID, trainerID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateTrainerID", reflect.TypeOf((*MockStorage)(nil).UpdateTrainerID), ctx, clientID, trainerID)
+}
+
+// UpdateUserSettings mocks base method.
+func (m *MockStorage) UpdateUserSettings(ctx context.Context, email string, settings models.UserSettings) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateUserSettings", ctx, email, settings)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateUserSettings indicates an expected call of UpdateUserSettings.
+func (mr *MockStorageMockRecorder) UpdateUserSettings(ctx, email, settings interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateUserSettings", reflect.TypeOf((*MockStorage)(nil).UpdateUserSettings), ctx, email, settings)
+}
+
+// WithTx mocks base method.
+func (m *MockStorage) WithTx(ctx context.Context, fn func(Storage) error) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "WithTx", ctx, fn)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// WithTx indicates an expected call of WithTx.
+func (mr *MockStorageMockRecorder) WithTx(ctx, fn interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "WithTx", reflect.TypeOf((*MockStorage)(nil).WithTx), ctx, fn)
+}
@@ -0,0 +1,425 @@
+package userservice
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"testing"
+	"time"
+
+	"ChadProgress/internal/lib/apperr"
+	"ChadProgress/internal/models"
+	service "ChadProgress/internal/services"
+	"ChadProgress/storage"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const clientEmail = "client@example.com"
+
+func newTestService(t *testing.T) (*UserService, *MockStorage) {
+	ctrl := gomock.NewController(t)
+	st := NewMockStorage(ctrl)
+
+	return NewUserService(st, slog.New(slog.NewTextHandler(io.Discard, nil))), st
+}
+
+// expectTx expects a transaction, runs it on the same mock and stores the error it ends with:
+// storage commits on nil and rolls back otherwise
+func expectTx(st *MockStorage, txErr *error) {
+	st.EXPECT().WithTx(gomock.Any(), gomock.Any()).DoAndReturn(func(_ context.Context, fn func(Storage) error) error {
+		*txErr = fn(st)
+		return *txErr
+	})
+}
+
+func TestSelectTrainer(t *testing.T) {
+	ctx := context.Background()
+	clientUser := &models.User{ID: 5, Email: clientEmail, Role: models.RoleClient}
+	client := &models.Client{ID: 3, UserID: 5, TrainerID: 1}
+
+	t.Run("Success", func(t *testing.T) {
+		s, st := newTestService(t)
+		var txErr error
+		st.EXPECT().GetUserByEmail(clientEmail).Return(clientUser, nil)
+		expectTx(st, &txErr)
+		st.EXPECT().GetClientByUserID(uint(5)).Return(client, nil)
+		st.EXPECT().LockTrainer(uint(2)).Return(&models.Trainer{ID: 2, Status: models.StatusActive}, nil)
+		st.EXPECT().UpdateTrainerID(gomock.Any(), uint(3), uint(2)).Return(nil)
+
+		require.NoError(t, s.SelectTrainer(ctx, clientEmail, 2))
+		assert.NoError(t, txErr)
+	})
+
+	t.Run("Trainer is not active", func(t *testing.T) {
+		s, st := newTestService(t)
+		var txErr error
+		st.EXPECT().GetUserByEmail(clientEmail).Return(clientUser, nil)
+		expectTx(st, &txErr)
+		st.EXPECT().GetClientByUserID(uint(5)).Return(client, nil)
+		st.EXPECT().LockTrainer(uint(2)).Return(&models.Trainer{ID: 2, Status: models.StatusOnVacation}, nil)
+
+		assert.ErrorIs(t, s.SelectTrainer(ctx, clientEmail, 2), service.ErrNotActiveTrainer)
+		assert.ErrorIs(t, txErr, service.ErrNotActiveTrainer)
+	})
+
+	t.Run("Update failure rolls back", func(t *testing.T) {
+		s, st := newTestService(t)
+		var txErr error
+		dbErr := errors.New("connection reset")
+		st.EXPECT().GetUserByEmail(clientEmail).Return(clientUser, nil)
+		expectTx(st, &txErr)
+		st.EXPECT().GetClientByUserID(uint(5)).Return(client, nil)
+		st.EXPECT().LockTrainer(uint(2)).Return(&models.Trainer{ID: 2, Status: models.StatusActive}, nil)
+		st.EXPECT().UpdateTrainerID(gomock.Any(), uint(3), uint(2)).Return(dbErr)
+
+		assert.ErrorIs(t, s.SelectTrainer(ctx, clientEmail, 2), dbErr)
+		assert.ErrorIs(t, txErr, dbErr)
+	})
+
+	t.Run("Unknown trainer", func(t *testing.T) {
+		s, st := newTestService(t)
+		var txErr error
+		st.EXPECT().GetUserByEmail(clientEmail).Return(clientUser, nil)
+		expectTx(st, &txErr)
+		st.EXPECT().GetClientByUserID(uint(5)).Return(client, nil)
+		st.EXPECT().LockTrainer(uint(9)).Return(nil, storage.ErrRecordNotFound)
+
+		assert.ErrorIs(t, s.SelectTrainer(ctx, clientEmail, 9), service.ErrTrainerNotFound)
+	})
+}
+
+func TestAddMetrics(t *testing.T) {
+	ctx := context.Background()
+	clientUser := &models.User{ID: 5, Email: clientEmail, Role: models.RoleClient}
+	client := &models.Client{ID: 3, UserID: 5}
+	measuredAt := time.Date(2026, time.October, 19, 8, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name   string
+		values models.MeasurementValues
+		noTime bool
+		fields []apperr.FieldError
+	}{
+		{
+			name:   "Valid",
+			values: models.MeasurementValues{"weight": 80.5, "resting_heart_rate": 58},
+		},
+		{
+			name:   "No measurement time",
+			values: models.MeasurementValues{"weight": 80.5},
+			noTime: true,
+			fields: []apperr.FieldError{{Field: "measured-at", Message: "is required"}},
+		},
+		{
+			name:   "No measurements",
+			values: models.MeasurementValues{},
+			fields: []apperr.FieldError{{Field: "measurements", Message: "at least one measurement is required"}},
+		},
+		{
+			name:   "Every invalid value is reported",
+			values: models.MeasurementValues{"weight": 80, "wingspan": 190, "resting_heart_rate": 58.5, "height": 18},
+			fields: []apperr.FieldError{
+				{Field: "measurements.height", Message: "is out of range 50..272 cm"},
+				{Field: "measurements.resting_heart_rate", Message: "must be a whole number"},
+				{Field: "measurements.wingspan", Message: "is not a known measurement type"},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, st := newTestService(t)
+			st.EXPECT().GetUserByEmail(clientEmail).Return(clientUser, nil)
+			st.EXPECT().GetClientByUserID(uint(5)).Return(client, nil)
+			st.EXPECT().GetMeasurementTypes().Return(models.DefaultMeasurementTypes, nil).AnyTimes()
+			if tt.fields == nil {
+				st.EXPECT().AddMetrics(ctx, &models.Metric{ClientID: 3, Measurements: tt.values, MeasuredAt: measuredAt}).Return(nil)
+			}
+
+			at := measuredAt
+			if tt.noTime {
+				at = time.Time{}
+			}
+			err := s.AddMetrics(ctx, clientEmail, tt.values, at)
+			if tt.fields == nil {
+				require.NoError(t, err)
+				return
+			}
+			require.ErrorIs(t, err, apperr.ErrValidation)
+			assert.Equal(t, tt.fields, apperr.From(err).Fields)
+		})
+	}
+}
+
+func TestSetGoals(t *testing.T) {
+	ctx := context.Background()
+	clientUser := &models.User{ID: 5, Email: clientEmail, Role: models.RoleClient}
+
+	tests := []struct {
+		name   string
+		goals  models.MeasurementValues
+		fields []apperr.FieldError
+	}{
+		{
+			name:  "Valid",
+			goals: models.MeasurementValues{"weight": 75, "body_fat": 15},
+		},
+		{
+			name:  "Empty goals clear them",
+			goals: models.MeasurementValues{},
+		},
+		{
+			name:  "Targets are checked against catalog",
+			goals: models.MeasurementValues{"weight": 1, "wingspan": 190},
+			fields: []apperr.FieldError{
+				{Field: "goals.weight", Message: "is out of range 2..650 kg"},
+				{Field: "goals.wingspan", Message: "is not a known measurement type"},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, st := newTestService(t)
+			st.EXPECT().GetUserByEmail(clientEmail).Return(clientUser, nil)
+			st.EXPECT().GetClientByUserID(uint(5)).Return(&models.Client{ID: 3, UserID: 5}, nil)
+			st.EXPECT().GetMeasurementTypes().Return(models.DefaultMeasurementTypes, nil)
+			if tt.fields == nil {
+				st.EXPECT().UpdateClientGoals(ctx, uint(3), tt.goals).Return(nil)
+			}
+
+			err := s.SetGoals(ctx, clientEmail, tt.goals)
+			if tt.fields == nil {
+				require.NoError(t, err)
+				return
+			}
+			require.ErrorIs(t, err, apperr.ErrValidation)
+			assert.Equal(t, tt.fields, apperr.From(err).Fields)
+		})
+	}
+}
+
+func TestImportMetrics(t *testing.T) {
+	ctx := context.Background()
+	clientUser := &models.User{ID: 5, Email: clientEmail, Role: models.RoleClient}
+	client := &models.Client{ID: 3, UserID: 5}
+	first := time.Date(2026, time.October, 1, 8, 0, 0, 0, time.UTC)
+	last := first.AddDate(0, 0, 2)
+	readErr := apperr.FieldError{Field: "weight", Message: "must be a number"}
+
+	t.Run("Report of every row", func(t *testing.T) {
+		s, st := newTestService(t)
+		var txErr error
+		st.EXPECT().GetUserByEmail(clientEmail).Return(clientUser, nil)
+		st.EXPECT().GetClientByUserID(uint(5)).Return(client, nil)
+		st.EXPECT().GetMeasurementTypes().Return(models.DefaultMeasurementTypes, nil)
+		expectTx(st, &txErr)
+		st.EXPECT().GetMetrics(uint(3), first, last.Add(time.Microsecond)).Return([]models.Metric{
+			{ClientID: 3, Measurements: models.MeasurementValues{"weight": 80}, MeasuredAt: first},
+		}, nil)
+		st.EXPECT().ImportMetrics(ctx, []models.Metric{
+			{ClientID: 3, Measurements: models.MeasurementValues{"weight": 79}, MeasuredAt: last},
+		}).Return(nil)
+
+		report, err := s.ImportMetrics(ctx, clientEmail, []models.MetricImportRow{
+			{Row: 2, Measurements: models.MeasurementValues{"weight": 80}, MeasuredAt: first},
+			{Row: 3, Measurements: models.MeasurementValues{"weight": 79}, MeasuredAt: last},
+			{Row: 4, Measurements: models.MeasurementValues{"weight": 79}, MeasuredAt: last},
+			{Row: 5, Measurements: models.MeasurementValues{"wingspan": 190}, MeasuredAt: first.AddDate(0, 0, 1)},
+			{Row: 6, Errors: []apperr.FieldError{readErr}},
+			{Row: 7, Measurements: models.MeasurementValues{"weight": 79}},
+		})
+		require.NoError(t, err)
+		assert.Equal(t, &models.MetricImportReport{Accepted: 1, Rejected: 3, Duplicates: 2, Rows: []models.MetricImportResult{
+			{Row: 2, Status: models.ImportDuplicate},
+			{Row: 3, Status: models.ImportAccepted},
+			{Row: 4, Status: models.ImportDuplicate},
+			{Row: 5, Status: models.ImportRejected, Errors: []apperr.FieldError{{Field: "measurements.wingspan", Message: "is not a known measurement type"}}},
+			{Row: 6, Status: models.ImportRejected, Errors: []apperr.FieldError{readErr}},
+			{Row: 7, Status: models.ImportRejected, Errors: []apperr.FieldError{{Field: "measured-at", Message: "is required"}}},
+		}}, report)
+	})
+
+	t.Run("Failure adds nothing", func(t *testing.T) {
+		s, st := newTestService(t)
+		var txErr error
+		dbErr := errors.New("connection reset")
+		st.EXPECT().GetUserByEmail(clientEmail).Return(clientUser, nil)
+		st.EXPECT().GetClientByUserID(uint(5)).Return(client, nil)
+		st.EXPECT().GetMeasurementTypes().Return(models.DefaultMeasurementTypes, nil)
+		expectTx(st, &txErr)
+		st.EXPECT().GetMetrics(uint(3), first, first.Add(time.Microsecond)).Return(nil, nil)
+		st.EXPECT().ImportMetrics(ctx, gomock.Any()).Return(dbErr)
+
+		_, err := s.ImportMetrics(ctx, clientEmail, []models.MetricImportRow{
+			{Row: 1, Measurements: models.MeasurementValues{"weight": 80}, MeasuredAt: first},
+		})
+		assert.ErrorIs(t, err, dbErr)
+		assert.ErrorIs(t, txErr, dbErr)
+	})
+
+	t.Run("Empty file", func(t *testing.T) {
+		s, st := newTestService(t)
+		st.EXPECT().GetUserByEmail(clientEmail).Return(clientUser, nil)
+		st.EXPECT().GetClientByUserID(uint(5)).Return(client, nil)
+
+		_, err := s.ImportMetrics(ctx, clientEmail, nil)
+		assert.ErrorIs(t, err, apperr.ErrValidation)
+	})
+}
+
+func TestMetricSeries(t *testing.T) {
+	clientUser := &models.User{ID: 5, Email: clientEmail, Role: models.RoleClient}
+	client := &models.Client{ID: 3, UserID: 5}
+	to := time.Date(2026, time.October, 19, 12, 0, 0, 0, time.UTC)
+
+	t.Run("Short range is raw", func(t *testing.T) {
+		s, st := newTestService(t)
+		from := to.AddDate(0, 0, -30)
+		st.EXPECT().GetUserByEmail(clientEmail).Return(clientUser, nil)
+		st.EXPECT().GetClientByUserID(uint(5)).Return(client, nil)
+		st.EXPECT().GetMetrics(uint(3), from, to).Return([]models.Metric{
+			{Measurements: models.MeasurementValues{"weight": 80}, MeasuredAt: from},
+		}, nil)
+
+		series, err := s.MetricSeries(clientEmail, from, to)
+		require.NoError(t, err)
+		assert.Equal(t, models.ResolutionRaw, series.Resolution)
+		assert.Equal(t, []models.MetricPoint{
+			{Start: from, Values: map[string]models.MetricStat{"weight": {Count: 1, Avg: 80, Min: 80, Max: 80}}},
+		}, series.Points)
+	})
+
+	t.Run("Year is read from daily rollups", func(t *testing.T) {
+		s, st := newTestService(t)
+		from := to.AddDate(-1, 0, 0)
+		day := models.PeriodStart(models.PeriodDay, from, time.UTC)
+		st.EXPECT().GetUserByEmail(clientEmail).Return(clientUser, nil)
+		st.EXPECT().GetClientByUserID(uint(5)).Return(client, nil)
+		// the day of to is included, it starts before to
+		st.EXPECT().GetMetricRollups(uint(3), models.PeriodDay, day, time.Date(2026, time.October, 20, 0, 0, 0, 0, time.UTC)).Return([]models.MetricRollup{
+			{Type: "height", PeriodStart: day, Count: 1, ValueSum: 180, ValueMin: 180, ValueMax: 180},
+			{Type: "weight", PeriodStart: day, Count: 2, ValueSum: 158, ValueMin: 78, ValueMax: 80},
+			{Type: "weight", PeriodStart: day.AddDate(0, 0, 1), Count: 1, ValueSum: 77, ValueMin: 77, ValueMax: 77},
+		}, nil)
+
+		series, err := s.MetricSeries(clientEmail, from, to)
+		require.NoError(t, err)
+		assert.Equal(t, models.PeriodDay, series.Resolution)
+		assert.Equal(t, []models.MetricPoint{
+			{Start: day, Values: map[string]models.MetricStat{
+				"height": {Count: 1, Avg: 180, Min: 180, Max: 180},
+				"weight": {Count: 2, Avg: 79, Min: 78, Max: 80},
+			}},
+			{Start: day.AddDate(0, 0, 1), Values: map[string]models.MetricStat{"weight": {Count: 1, Avg: 77, Min: 77, Max: 77}}},
+		}, series.Points)
+	})
+
+	t.Run("Years are read from weekly rollups", func(t *testing.T) {
+		s, st := newTestService(t)
+		from := to.AddDate(-5, 0, 0)
+		st.EXPECT().GetUserByEmail(clientEmail).Return(clientUser, nil)
+		st.EXPECT().GetClientByUserID(uint(5)).Return(client, nil)
+		st.EXPECT().GetMetricRollups(uint(3), models.PeriodWeek, models.PeriodStart(models.PeriodWeek, from, time.UTC), gomock.Any()).
+			Return([]models.MetricRollup{}, nil)
+
+		series, err := s.MetricSeries(clientEmail, from, to)
+		require.NoError(t, err)
+		assert.Equal(t, models.PeriodWeek, series.Resolution)
+		assert.Empty(t, series.Points)
+	})
+
+	t.Run("Days are in time zone of user", func(t *testing.T) {
+		s, st := newTestService(t)
+		moscow, err := time.LoadLocation("Europe/Moscow")
+		require.NoError(t, err)
+		// 22:00 UTC is already October 20 in Moscow
+		late := time.Date(2026, time.October, 19, 22, 0, 0, 0, time.UTC)
+		from := late.AddDate(-1, 0, 0)
+		day := time.Date(2026, time.October, 20, 0, 0, 0, 0, time.UTC)
+		st.EXPECT().GetUserByEmail(clientEmail).Return(&models.User{ID: 5, Email: clientEmail, Role: models.RoleClient, Timezone: "Europe/Moscow"}, nil)
+		st.EXPECT().GetClientByUserID(uint(5)).Return(client, nil)
+		st.EXPECT().GetMetricRollups(uint(3), models.PeriodDay, models.PeriodStart(models.PeriodDay, from, moscow), day.AddDate(0, 0, 1)).
+			Return([]models.MetricRollup{{Type: "weight", PeriodStart: day, Count: 1, ValueSum: 80, ValueMin: 80, ValueMax: 80}}, nil)
+
+		series, err := s.MetricSeries(clientEmail, from, late)
+		require.NoError(t, err)
+		require.Len(t, series.Points, 1)
+		assert.True(t, time.Date(2026, time.October, 20, 0, 0, 0, 0, moscow).Equal(series.Points[0].Start), "got %s", series.Points[0].Start)
+		assert.Equal(t, moscow, series.To.Location())
+	})
+
+	t.Run("Inverted range", func(t *testing.T) {
+		s, st := newTestService(t)
+		st.EXPECT().GetUserByEmail(clientEmail).Return(clientUser, nil)
+		st.EXPECT().GetClientByUserID(uint(5)).Return(client, nil)
+
+		_, err := s.MetricSeries(clientEmail, to, to.AddDate(0, 0, -1))
+		assert.ErrorIs(t, err, apperr.ErrValidation)
+	})
+
+	t.Run("Trainer has no metrics", func(t *testing.T) {
+		s, st := newTestService(t)
+		st.EXPECT().GetUserByEmail(clientEmail).Return(&models.User{ID: 5, Role: models.RoleTrainer}, nil)
+
+		_, err := s.MetricSeries(clientEmail, time.Time{}, time.Time{})
+		assert.ErrorIs(t, err, service.ErrInvalidRoleRequest)
+	})
+}
+
+func TestSettings(t *testing.T) {
+	ctx := context.Background()
+
+	clientUser := &models.User{ID: 5, Email: clientEmail, Role: models.RoleClient, Timezone: "UTC"}
+
+	t.Run("Success", func(t *testing.T) {
+		s, st := newTestService(t)
+		var txErr error
+		st.EXPECT().GetUserByEmail(clientEmail).Return(clientUser, nil)
+		expectTx(st, &txErr)
+		st.EXPECT().UpdateUserSettings(ctx, clientEmail, models.UserSettings{Units: "imperial"}).Return(nil)
+
+		require.NoError(t, s.UpdateSettings(ctx, clientEmail, models.UserSettings{Units: "Imperial"}))
+	})
+
+	t.Run("Time zone change rebuilds rollups", func(t *testing.T) {
+		s, st := newTestService(t)
+		var txErr error
+		st.EXPECT().GetUserByEmail(clientEmail).Return(clientUser, nil)
+		expectTx(st, &txErr)
+		st.EXPECT().UpdateUserSettings(ctx, clientEmail, models.UserSettings{Timezone: "Europe/Moscow"}).Return(nil)
+		st.EXPECT().GetClientByUserID(uint(5)).Return(&models.Client{ID: 3, UserID: 5}, nil)
+		st.EXPECT().RebuildMetricRollups(ctx, uint(3)).Return(nil)
+
+		require.NoError(t, s.UpdateSettings(ctx, clientEmail, models.UserSettings{Timezone: "Europe/Moscow"}))
+	})
+
+	t.Run("Unknown time zone", func(t *testing.T) {
+		s, _ := newTestService(t)
+
+		err := s.UpdateSettings(ctx, clientEmail, models.UserSettings{Timezone: "Local"})
+		require.ErrorIs(t, err, apperr.ErrValidation)
+		assert.Equal(t, []apperr.FieldError{{Field: "timezone", Message: "must be IANA time zone, e.g. Europe/Moscow"}}, apperr.From(err).Fields)
+	})
+
+	t.Run("Unknown unit system", func(t *testing.T) {
+		s, _ := newTestService(t)
+
+		err := s.UpdateSettings(ctx, clientEmail, models.UserSettings{Units: "stones"})
+		require.ErrorIs(t, err, apperr.ErrValidation)
+		assert.Equal(t, []apperr.FieldError{{Field: "units", Message: "must be metric or imperial"}}, apperr.From(err).Fields)
+	})
+
+	t.Run("Metric by default", func(t *testing.T) {
+		s, st := newTestService(t)
+		st.EXPECT().GetUserByEmail(clientEmail).Return(&models.User{Email: clientEmail}, nil)
+
+		settings, err := s.Settings(clientEmail)
+		require.NoError(t, err)
+		assert.Equal(t, "metric", settings.Units)
+	})
+}
@@ -0,0 +1,228 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"ChadProgress/internal/models"
+	"ChadProgress/storage"
+
+	"gorm.io/gorm"
+)
+
+// dummyTrainerID is the trainer clients are bound to until they select a real one
+const dummyTrainerID = 1
+
+// ChangeUserEmail changes email of user, marks it verified and signs user out of every session
+func (s *Storage) ChangeUserEmail(ctx context.Context, email, newEmail string, verifiedAt time.Time) error {
+	const op = "postgres.ChangeUserEmail"
+	err := s.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		res := tx.Model(&models.User{}).
+			Where("email = ? AND status = ?", email, models.UserStatusActive).
+			Updates(map[string]any{"email": newEmail, "email_verified_at": verifiedAt})
+		if res.Error != nil {
+			return res.Error
+		}
+		if res.RowsAffected == 0 {
+			return storage.ErrRecordNotFound
+		}
+
+		now := time.Now()
+		err := tx.Model(&models.Session{}).
+			Where("login = ? AND revoked_at IS NULL", email).
+			Update("revoked_at", now).Error
+		if err != nil {
+			return err
+		}
+
+		err = tx.Model(&models.EmailToken{}).
+			Where("login = ? AND used_at IS NULL", email).
+			Update("used_at", now).Error
+		if err != nil {
+			return err
+		}
+
+		return tx.Model(&models.DataExport{}).Where("login = ?", email).Update("login", newEmail).Error
+	})
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, s.classifyError(err, &models.User{Email: newEmail}))
+	}
+
+	return nil
+}
+
+// EraseAccount removes or anonymizes all data of user according to policy and saves receipt
+// filled with numbers of affected records. Everything is done in one transaction.
+func (s *Storage) EraseAccount(ctx context.Context, userID uint, policy models.RetentionPolicy, receipt *models.DeletionReceipt) error {
+	const op = "postgres.EraseAccount"
+	if err := policy.Validate(); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	receipt.Policy = policy
+	receipt.Erased = make(map[string]int64)
+	receipt.Anonymized = make(map[string]int64)
+	count := func(retention, kind string, n int64) {
+		if n == 0 {
+			return
+		}
+		if retention == models.RetentionErase {
+			receipt.Erased[kind] += n
+		} else {
+			receipt.Anonymized[kind] += n
+		}
+	}
+
+	err := s.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var user models.User
+		if err := tx.First(&user, userID).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return storage.ErrRecordNotFound
+			}
+			return err
+		}
+
+		var client models.Client
+		err := tx.Where("user_id = ?", userID).First(&client).Error
+		if err == nil {
+			n, err := applyRetention(tx, policy.Metrics, &models.Metric{}, "client_id = ?", client.ID, nil)
+			if err != nil {
+				return err
+			}
+			count(policy.Metrics, "metrics", n)
+			if policy.Metrics == models.RetentionErase {
+				// rollups are derived from metrics, they are not counted separately
+				if err = tx.Where("client_id = ?", client.ID).Delete(&models.MetricRollup{}).Error; err != nil {
+					return err
+				}
+			}
+
+			n, err = applyShared(tx, policy.SharedRecords, "client_id = ?", client.ID, count)
+			if err != nil {
+				return err
+			}
+
+			n, err = applyRetention(tx, policy.Profile, &models.Client{}, "id = ?", client.ID,
+				map[string]any{"height": 0, "weight": 0, "body_fat": 0})
+			if err != nil {
+				return err
+			}
+			count(policy.Profile, "client_profile", n)
+		} else if !errors.Is(err, gorm.ErrRecordNotFound) {
+			return err
+		}
+
+		var trainer models.Trainer
+		err = tx.Where("user_id = ?", userID).First(&trainer).Error
+		if err == nil {
+			if _, err = applyShared(tx, policy.SharedRecords, "trainer_id = ?", trainer.ID, count); err != nil {
+				return err
+			}
+
+			// clients stay with the service, they can select another trainer
+			err = tx.Model(&models.Client{}).Where("trainer_id = ?", trainer.ID).Update("trainer_id", dummyTrainerID).Error
+			if err != nil {
+				return err
+			}
+
+			n, err := applyRetention(tx, policy.Profile, &models.Trainer{}, "id = ?", trainer.ID,
+				map[string]any{"qualifications": "", "experience": "", "achievements": "", "status": models.StatusOnVacation})
+			if err != nil {
+				return err
+			}
+			count(policy.Profile, "trainer_profile", n)
+		} else if !errors.Is(err, gorm.ErrRecordNotFound) {
+			return err
+		}
+
+		if err = tx.Where("login = ?", user.Email).Delete(&models.Session{}).Error; err != nil {
+			return err
+		}
+		if err = tx.Where("login = ?", user.Email).Delete(&models.EmailToken{}).Error; err != nil {
+			return err
+		}
+		// archives are removed from disk by export cleanup once they expire
+		err = tx.Model(&models.DataExport{}).
+			Where("login = ? AND expires_at > ?", user.Email, time.Now()).
+			Update("expires_at", time.Now()).Error
+		if err != nil {
+			return err
+		}
+
+		n, err := applyRetention(tx, policy.Profile, &models.User{}, "id = ?", user.ID, map[string]any{
+			"email":             fmt.Sprintf("deleted-%d@deleted.invalid", user.ID),
+			"name":              "Deleted user",
+			"status":            models.UserStatusDeleted,
+			"email_verified_at": nil,
+		})
+		if err != nil {
+			return err
+		}
+		count(policy.Profile, "user", n)
+
+		return tx.Create(receipt).Error
+	})
+	if err != nil {
+		if errors.Is(err, storage.ErrRecordNotFound) {
+			return fmt.Errorf("%s: %w", op, storage.ErrRecordNotFound)
+		}
+
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	return nil
+}
+
+// MarkCredentialsRemoved records in receipt that credentials were removed from auth service
+func (s *Storage) MarkCredentialsRemoved(receiptID string) error {
+	const op = "postgres.MarkCredentialsRemoved"
+	err := s.DB.Model(&models.DeletionReceipt{}).
+		Where("id = ?", receiptID).
+		Update("credentials_removed", true).Error
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	return nil
+}
+
+// applyRetention deletes records matching condition or, when retention is anonymize, applies
+// anonymized values to them. Records without personal fields (nil values) are kept as is.
+func applyRetention(tx *gorm.DB, retention string, model any, cond string, arg any, anonymized map[string]any) (int64, error) {
+	if retention == models.RetentionErase {
+		res := tx.Where(cond, arg).Delete(model)
+		return res.RowsAffected, res.Error
+	}
+
+	if anonymized == nil {
+		var n int64
+		err := tx.Model(model).Where(cond, arg).Count(&n).Error
+		return n, err
+	}
+
+	res := tx.Model(model).Where(cond, arg).Updates(anonymized)
+	return res.RowsAffected, res.Error
+}
+
+// applyShared applies retention to training plans and progress reports
+func applyShared(tx *gorm.DB, retention, cond string, arg any, count func(retention, kind string, n int64)) (int64, error) {
+	var total int64
+
+	n, err := applyRetention(tx, retention, &models.TrainingPlan{}, cond, arg, nil)
+	if err != nil {
+		return 0, err
+	}
+	count(retention, "training_plans", n)
+	total += n
+
+	n, err = applyRetention(tx, retention, &models.ProgressReport{}, cond, arg, nil)
+	if err != nil {
+		return 0, err
+	}
+	count(retention, "progress_reports", n)
+	total += n
+
+	return total, nil
+}
@@ -0,0 +1,167 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"ChadProgress/internal/models"
+	"ChadProgress/storage"
+
+	"gorm.io/gorm"
+)
+
+func (s *Storage) GetUserByID(id uint) (*models.User, error) {
+	const op = "postgres.GetUserByID"
+	var user models.User
+	if err := s.DB.First(&user, "id = ?", id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, fmt.Errorf("%s: %w", op, storage.ErrRecordNotFound)
+		}
+
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return &user, nil
+}
+
+// SearchUsers returns page of users matching filter ordered by id and total number of matching users
+func (s *Storage) SearchUsers(filter models.UserFilter) ([]models.User, int64, error) {
+	const op = "postgres.SearchUsers"
+	query := s.reader().Model(&models.User{})
+	if filter.Query != "" {
+		pattern := "%" + escapeLike(strings.ToLower(filter.Query)) + "%"
+		query = query.Where("LOWER(email) LIKE ? OR LOWER(name) LIKE ?", pattern, pattern)
+	}
+	if filter.Role != "" {
+		query = query.Where("role = ?", filter.Role)
+	}
+	if filter.Status != "" {
+		query = query.Where("status = ?", filter.Status)
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, fmt.Errorf("%s: %w", op, err)
+	}
+
+	var users []models.User
+	err := query.Order("id").Limit(filter.Limit).Offset(filter.Offset).Find(&users).Error
+	if err != nil {
+		return nil, 0, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return users, total, nil
+}
+
+// SetUserStatus enables or disables user. Disabled user is signed out of every session.
+// Pending and deleted users can not be changed.
+func (s *Storage) SetUserStatus(ctx context.Context, id uint, status string) error {
+	const op = "postgres.SetUserStatus"
+	err := s.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var user models.User
+		err := tx.Where("id = ? AND status IN ?", id, []string{models.UserStatusActive, models.UserStatusDisabled}).
+			First(&user).Error
+		if err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return storage.ErrRecordNotFound
+			}
+
+			return err
+		}
+
+		if err = tx.Model(&user).Update("status", status).Error; err != nil {
+			return err
+		}
+		if status != models.UserStatusDisabled {
+			return nil
+		}
+
+		return tx.Model(&models.Session{}).
+			Where("login = ? AND revoked_at IS NULL", user.Email).
+			Update("revoked_at", time.Now()).Error
+	})
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	return nil
+}
+
+// SetUserRole changes role of active user
+func (s *Storage) SetUserRole(ctx context.Context, email, role string) error {
+	const op = "postgres.SetUserRole"
+	res := s.DB.WithContext(ctx).Model(&models.User{}).
+		Where("email = ? AND status = ?", email, models.UserStatusActive).
+		Update("role", role)
+	if err := res.Error; err != nil {
+		return fmt.Errorf("%s: %w", op, s.classifyError(err, &models.User{}))
+	}
+	if res.RowsAffected == 0 {
+		return fmt.Errorf("%s: %w", op, storage.ErrRecordNotFound)
+	}
+
+	return nil
+}
+
+func (s *Storage) UpdateTrainerStatus(ctx context.Context, trainerID uint, status string) error {
+	const op = "postgres.UpdateTrainerStatus"
+	res := s.DB.WithContext(ctx).Model(&models.Trainer{}).Where("id = ?", trainerID).Update("status", status)
+	if err := res.Error; err != nil {
+		return fmt.Errorf("%s: %w", op, s.classifyError(err, &models.Trainer{}))
+	}
+	if res.RowsAffected == 0 {
+		return fmt.Errorf("%s: %w", op, storage.ErrRecordNotFound)
+	}
+
+	return nil
+}
+
+func (s *Storage) SaveAuditEvent(event *models.AuditEvent) error {
+	const op = "postgres.SaveAuditEvent"
+	if err := s.DB.Create(event).Error; err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	return nil
+}
+
+// GetAuditEvents returns page of events matching filter, newest first
+func (s *Storage) GetAuditEvents(filter models.AuditFilter) ([]models.AuditEvent, error) {
+	const op = "postgres.GetAuditEvents"
+	query := s.reader().Model(&models.AuditEvent{})
+	switch filter.Actor {
+	case "":
+	case models.AuditActorSystem:
+		query = query.Where("actor_id = 0")
+	default:
+		query = query.Where("actor_id IN (?)", s.reader().Model(&models.User{}).Select("id").Where("email = ?", filter.Actor))
+	}
+	if filter.Action != "" {
+		query = query.Where("action = ?", filter.Action)
+	}
+	if filter.Entity != "" {
+		query = query.Where("entity = ?", filter.Entity)
+	}
+	if filter.EntityID != 0 {
+		query = query.Where("entity_id = ?", filter.EntityID)
+	}
+	if filter.RequestID != "" {
+		query = query.Where("request_id = ?", filter.RequestID)
+	}
+
+	var events []models.AuditEvent
+	err := query.Order("id DESC").Limit(filter.Limit).Offset(filter.Offset).Find(&events).Error
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return events, nil
+}
+
+// escapeLike escapes wildcards of LIKE pattern
+func escapeLike(s string) string {
+	return strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`).Replace(s)
+}
@@ -0,0 +1,326 @@
+package postgres
+
+import (
+	"database/sql/driver"
+	"fmt"
+	"reflect"
+
+	"ChadProgress/internal/models"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"gorm.io/gorm/schema"
+)
+
+// auditedModels are tables whose every create, update and delete is recorded in audit_events
+var auditedModels = []any{
+	&models.User{},
+	&models.Trainer{},
+	&models.Client{},
+	&models.TrainingPlan{},
+	&models.ProgressReport{},
+	&models.Metric{},
+}
+
+// redacted replaces values of fields tagged `audit:"redact"`. Audit events outlive account deletion,
+// so personal data is not copied into them, only the fact that the field changed. For the same reason
+// actors are recorded by user id.
+const redacted = "[redacted]"
+
+const auditBeforeKey = "audit:before"
+
+// registerAuditCallbacks hooks into gorm create, update and delete of audited tables. Events are written
+// in the transaction of the change, so a change is never saved without its event. Actor, request id and IP
+// are read from context of the statement, see models.AuditMetaFromContext.
+func registerAuditCallbacks(db *gorm.DB) error {
+	tables := make(map[string]bool, len(auditedModels))
+	for _, model := range auditedModels {
+		stmt := &gorm.Statement{DB: db}
+		if err := stmt.Parse(model); err != nil {
+			return err
+		}
+		tables[stmt.Schema.Table] = true
+	}
+	audited := func(db *gorm.DB) bool {
+		return db.Error == nil && db.Statement.Schema != nil && tables[db.Statement.Schema.Table]
+	}
+
+	cb := db.Callback()
+	if err := cb.Create().After("gorm:create").Register("audit:create", func(db *gorm.DB) {
+		if audited(db) {
+			auditCreate(db)
+		}
+	}); err != nil {
+		return err
+	}
+	if err := cb.Update().Before("gorm:update").Register("audit:before_update", func(db *gorm.DB) {
+		if audited(db) {
+			auditSnapshot(db)
+		}
+	}); err != nil {
+		return err
+	}
+	if err := cb.Update().After("gorm:update").Register("audit:update", func(db *gorm.DB) {
+		if audited(db) {
+			auditChanges(db, models.AuditUpdate)
+		}
+	}); err != nil {
+		return err
+	}
+	if err := cb.Delete().Before("gorm:delete").Register("audit:before_delete", func(db *gorm.DB) {
+		if audited(db) {
+			auditSnapshot(db)
+		}
+	}); err != nil {
+		return err
+	}
+
+	return cb.Delete().After("gorm:delete").Register("audit:delete", func(db *gorm.DB) {
+		if audited(db) {
+			auditChanges(db, models.AuditDelete)
+		}
+	})
+}
+
+// auditCreate records every field of created rows
+func auditCreate(db *gorm.DB) {
+	stmt := db.Statement
+	var events []models.AuditEvent
+	eachRow(stmt.ReflectValue, func(row reflect.Value) {
+		diff := make(map[string]models.Change)
+		for _, f := range stmt.Schema.Fields {
+			if f.DBName == "" {
+				continue
+			}
+			v, zero := f.ValueOf(stmt.Context, row)
+			if zero {
+				continue
+			}
+			diff[f.DBName] = models.Change{To: auditValue(f, v)}
+		}
+		events = append(events, newAuditEvent(db, models.AuditCreate, primaryKey(stmt, row), diff))
+	})
+
+	saveAuditEvents(db, events)
+}
+
+// auditSnapshot reads rows about to be changed, they are compared with rows after the change by auditChanges
+func auditSnapshot(db *gorm.DB) {
+	stmt := db.Statement
+	pk := stmt.Schema.PrioritizedPrimaryField
+	if pk == nil {
+		return
+	}
+
+	var exprs []clause.Expression
+	if where, ok := stmt.Clauses["WHERE"].Expression.(clause.Where); ok {
+		exprs = append(exprs, where.Exprs...)
+	}
+	// conditions on primary key of the model are added by gorm after before-callbacks
+	var ids []any
+	eachRow(stmt.ReflectValue, func(row reflect.Value) {
+		if id, zero := pk.ValueOf(stmt.Context, row); !zero {
+			ids = append(ids, id)
+		}
+	})
+	if len(ids) > 0 {
+		exprs = append(exprs, clause.IN{Column: clause.Column{Name: pk.DBName}, Values: ids})
+	}
+	if len(exprs) == 0 {
+		// gorm refuses global updates and deletes
+		return
+	}
+
+	var rows []map[string]any
+	err := db.Session(&gorm.Session{NewDB: true}).Table(stmt.Table).Clauses(clause.Where{Exprs: exprs}).Find(&rows).Error
+	if err != nil {
+		db.AddError(fmt.Errorf("audit: failed to read rows before change: %w", err))
+		return
+	}
+	db.InstanceSet(auditBeforeKey, rows)
+}
+
+// auditChanges compares rows read by auditSnapshot with their current state. Deleted rows are recorded
+// without values.
+func auditChanges(db *gorm.DB, action string) {
+	stmt := db.Statement
+	v, ok := db.InstanceGet(auditBeforeKey)
+	before, _ := v.([]map[string]any)
+	if !ok || len(before) == 0 || db.RowsAffected == 0 {
+		return
+	}
+	pk := stmt.Schema.PrioritizedPrimaryField.DBName
+
+	after := make(map[any]map[string]any, len(before))
+	if action == models.AuditUpdate {
+		ids := make([]any, 0, len(before))
+		for _, row := range before {
+			ids = append(ids, row[pk])
+		}
+		var rows []map[string]any
+		err := db.Session(&gorm.Session{NewDB: true}).Table(stmt.Table).Where(clause.IN{Column: clause.Column{Name: pk}, Values: ids}).Find(&rows).Error
+		if err != nil {
+			db.AddError(fmt.Errorf("audit: failed to read rows after change: %w", err))
+			return
+		}
+		for _, row := range rows {
+			after[row[pk]] = row
+		}
+	}
+
+	events := make([]models.AuditEvent, 0, len(before))
+	for _, old := range before {
+		id := toUint(old[pk])
+		if action == models.AuditDelete {
+			events = append(events, newAuditEvent(db, action, id, nil))
+			continue
+		}
+
+		diff := diffRows(stmt.Schema, old, after[old[pk]])
+		if len(diff) > 0 {
+			events = append(events, newAuditEvent(db, action, id, diff))
+		}
+	}
+
+	saveAuditEvents(db, events)
+}
+
+// diffRows returns changed columns of a row, a row missing after update is left out
+func diffRows(s *schema.Schema, before, after map[string]any) map[string]models.Change {
+	if after == nil {
+		return nil
+	}
+
+	diff := make(map[string]models.Change)
+	for column, to := range after {
+		from := before[column]
+		if reflect.DeepEqual(from, to) {
+			continue
+		}
+		if f := s.LookUpField(column); f != nil && f.Tag.Get("audit") == "redact" {
+			from, to = redacted, redacted
+		}
+		diff[column] = models.Change{From: from, To: to}
+	}
+
+	return diff
+}
+
+func newAuditEvent(db *gorm.DB, action string, entityID uint, diff map[string]models.Change) models.AuditEvent {
+	meta := models.AuditMetaFromContext(db.Statement.Context)
+
+	return models.AuditEvent{
+		ActorID:   meta.ActorID,
+		RequestID: meta.RequestID,
+		IP:        meta.IP,
+		Action:    action,
+		Entity:    db.Statement.Table,
+		EntityID:  entityID,
+		Diff:      diff,
+	}
+}
+
+// saveAuditEvents writes events in the transaction of the change, failure rolls the change back
+func saveAuditEvents(db *gorm.DB, events []models.AuditEvent) {
+	if len(events) == 0 {
+		return
+	}
+	if err := db.Session(&gorm.Session{NewDB: true}).Create(&events).Error; err != nil {
+		db.AddError(fmt.Errorf("audit: failed to save events: %w", err))
+	}
+}
+
+func auditValue(f *schema.Field, v any) any {
+	if f.Tag.Get("audit") == "redact" {
+		return redacted
+	}
+	if valuer, ok := v.(driver.Valuer); ok {
+		if value, err := valuer.Value(); err == nil {
+			return value
+		}
+	}
+
+	return v
+}
+
+func primaryKey(stmt *gorm.Statement, row reflect.Value) uint {
+	pk := stmt.Schema.PrioritizedPrimaryField
+	if pk == nil {
+		return 0
+	}
+	id, _ := pk.ValueOf(stmt.Context, row)
+
+	return toUint(id)
+}
+
+// eachRow calls fn for a struct or every struct of a slice
+func eachRow(v reflect.Value, fn func(row reflect.Value)) {
+	switch reflect.Indirect(v).Kind() {
+	case reflect.Struct:
+		fn(reflect.Indirect(v))
+	case reflect.Slice, reflect.Array:
+		v = reflect.Indirect(v)
+		for i := 0; i < v.Len(); i++ {
+			fn(reflect.Indirect(v.Index(i)))
+		}
+	}
+}
+
+func toUint(v any) uint {
+	rv := reflect.ValueOf(v)
+	switch rv.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return uint(rv.Int())
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return uint(rv.Uint())
+	}
+
+	return 0
+}
+
+// migrateAuditActor replaces emails of actors in events recorded before actors were kept by id. Events
+// of accounts deleted since then are left without actor, as their emails no longer match a user.
+func migrateAuditActor(db *gorm.DB) error {
+	if !db.Migrator().HasTable(&models.AuditEvent{}) || !db.Migrator().HasColumn(&models.AuditEvent{}, "actor") {
+		return nil
+	}
+
+	return db.Transaction(func(tx *gorm.DB) error {
+		// the trigger is created again by protectAuditEvents
+		if err := tx.Exec(`DROP TRIGGER IF EXISTS audit_events_append_only ON audit_events`).Error; err != nil {
+			return err
+		}
+		if err := tx.Exec(`ALTER TABLE audit_events ADD COLUMN IF NOT EXISTS actor_id bigint NOT NULL DEFAULT 0`).Error; err != nil {
+			return err
+		}
+		err := tx.Exec(`UPDATE audit_events e SET actor_id = u.id FROM users u WHERE u.email = e.actor`).Error
+		if err != nil {
+			return err
+		}
+
+		return tx.Exec(`ALTER TABLE audit_events DROP COLUMN actor`).Error
+	})
+}
+
+// protectAuditEvents makes audit_events append-only for everyone, including the application itself
+func protectAuditEvents(db *gorm.DB) error {
+	err := db.Exec(`
+		CREATE OR REPLACE FUNCTION audit_events_append_only() RETURNS trigger AS $$
+		BEGIN
+			RAISE EXCEPTION 'audit_events is append-only';
+		END;
+		$$ LANGUAGE plpgsql;
+	`).Error
+	if err != nil {
+		return err
+	}
+
+	if err = db.Exec(`DROP TRIGGER IF EXISTS audit_events_append_only ON audit_events;`).Error; err != nil {
+		return err
+	}
+
+	return db.Exec(`
+		CREATE TRIGGER audit_events_append_only BEFORE UPDATE OR DELETE ON audit_events
+		FOR EACH ROW EXECUTE FUNCTION audit_events_append_only();
+	`).Error
+}
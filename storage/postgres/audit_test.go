@@ -0,0 +1,62 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"ChadProgress/internal/models"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm/schema"
+)
+
+func TestDiffRows(t *testing.T) {
+	s, err := schema.Parse(&models.User{}, &sync.Map{}, schema.NamingStrategy{})
+	require.NoError(t, err)
+
+	before := map[string]any{"id": int64(7), "email": "old@example.com", "name": "Bob", "status": "active"}
+	after := map[string]any{"id": int64(7), "email": "new@example.com", "name": "Bob", "status": "disabled"}
+
+	assert.Equal(t, map[string]models.Change{
+		"email":  {From: redacted, To: redacted},
+		"status": {From: "active", To: "disabled"},
+	}, diffRows(s, before, after))
+	assert.Nil(t, diffRows(s, before, nil))
+}
+
+func TestAuditOutlivesErasedEmail(t *testing.T) {
+	s := newTestStorage(t)
+	email := fmt.Sprintf("audit-erase-%d@example.com", time.Now().UnixNano())
+
+	user := &models.User{Email: email, Name: "Audit", Role: models.RoleClient}
+	_, err := s.SaveUser(context.Background(), user)
+	require.NoError(t, err)
+
+	ctx := context.WithValue(context.Background(), models.ContextUserKey, email)
+	ctx = context.WithValue(ctx, models.ContextUserIDKey, user.ID)
+	require.NoError(t, s.DB.WithContext(ctx).Model(user).Update("units", "imperial").Error)
+
+	var actorID uint
+	err = s.DB.Model(&models.AuditEvent{}).Select("actor_id").
+		Where("entity = ? AND entity_id = ? AND action = ?", "users", user.ID, models.AuditUpdate).
+		Order("id DESC").Limit(1).Scan(&actorID).Error
+	require.NoError(t, err)
+	assert.Equal(t, user.ID, actorID)
+
+	policy := models.RetentionPolicy{
+		Profile:       models.RetentionAnonymize,
+		Metrics:       models.RetentionAnonymize,
+		SharedRecords: models.RetentionAnonymize,
+	}
+	receipt := &models.DeletionReceipt{ID: fmt.Sprintf("audit-%d", user.ID), EmailHash: "test", DeletedAt: time.Now()}
+	require.NoError(t, s.EraseAccount(ctx, user.ID, policy, receipt))
+
+	var found int64
+	err = s.DB.Model(&models.AuditEvent{}).Where("audit_events::text LIKE ?", "%"+escapeLike(email)+"%").Count(&found).Error
+	require.NoError(t, err)
+	assert.Zero(t, found, "erased email is found in audit events")
+}
@@ -0,0 +1,333 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"ChadProgress/internal/models"
+	"ChadProgress/storage"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+func (s *Storage) SaveCredential(cred *models.Credential) error {
+	const op = "postgres.SaveCredential"
+	if err := s.DB.Create(cred).Error; err != nil {
+		return fmt.Errorf("%s: %w", op, s.classifyError(err, cred))
+	}
+
+	return nil
+}
+
+func (s *Storage) GetCredential(login string) (*models.Credential, error) {
+	const op = "postgres.GetCredential"
+	var cred models.Credential
+	if err := s.DB.First(&cred, "login = ?", login).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, fmt.Errorf("%s: %w", op, storage.ErrRecordNotFound)
+		}
+
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return &cred, nil
+}
+
+func (s *Storage) DeleteCredential(login string) error {
+	const op = "postgres.DeleteCredential"
+	res := s.DB.Where("login = ?", login).Delete(&models.Credential{})
+	if err := res.Error; err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	if res.RowsAffected == 0 {
+		return fmt.Errorf("%s: %w", op, storage.ErrRecordNotFound)
+	}
+
+	return nil
+}
+
+// UpdateCredential replaces password hash of login
+func (s *Storage) UpdateCredential(login, passwordHash string) error {
+	const op = "postgres.UpdateCredential"
+	res := s.DB.Model(&models.Credential{}).
+		Where("login = ?", login).
+		Update("password_hash", passwordHash)
+	if err := res.Error; err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	if res.RowsAffected == 0 {
+		return fmt.Errorf("%s: %w", op, storage.ErrRecordNotFound)
+	}
+
+	return nil
+}
+
+// ChangeCredentialLogin renames credentials of login
+func (s *Storage) ChangeCredentialLogin(login, newLogin string) error {
+	const op = "postgres.ChangeCredentialLogin"
+	res := s.DB.Model(&models.Credential{}).
+		Where("login = ?", login).
+		Update("login", newLogin)
+	if err := res.Error; err != nil {
+		return fmt.Errorf("%s: %w", op, s.classifyError(err, &models.Credential{Login: newLogin}))
+	}
+	if res.RowsAffected == 0 {
+		return fmt.Errorf("%s: %w", op, storage.ErrRecordNotFound)
+	}
+
+	return nil
+}
+
+func (s *Storage) SaveRefreshToken(token *models.RefreshToken) error {
+	const op = "postgres.SaveRefreshToken"
+	if err := s.DB.Create(token).Error; err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	return nil
+}
+
+func (s *Storage) GetRefreshToken(hash string) (*models.RefreshToken, error) {
+	const op = "postgres.GetRefreshToken"
+	var token models.RefreshToken
+	if err := s.DB.First(&token, "token_hash = ?", hash).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, fmt.Errorf("%s: %w", op, storage.ErrRecordNotFound)
+		}
+
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return &token, nil
+}
+
+// RevokeRefreshToken marks token as used. Token which is already revoked is reported as not found,
+// so that two concurrent refreshes with the same token can not both succeed.
+func (s *Storage) RevokeRefreshToken(hash string) error {
+	const op = "postgres.RevokeRefreshToken"
+	res := s.DB.Model(&models.RefreshToken{}).
+		Where("token_hash = ? AND revoked_at IS NULL", hash).
+		Update("revoked_at", time.Now())
+	if err := res.Error; err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	if res.RowsAffected == 0 {
+		return fmt.Errorf("%s: %w", op, storage.ErrRecordNotFound)
+	}
+
+	return nil
+}
+
+func (s *Storage) RevokeRefreshTokens(login string) error {
+	const op = "postgres.RevokeRefreshTokens"
+	err := s.DB.Model(&models.RefreshToken{}).
+		Where("login = ? AND revoked_at IS NULL", login).
+		Update("revoked_at", time.Now()).Error
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	return nil
+}
+
+// SaveRevokedToken stores revoked access token and forgets tokens which have expired anyway
+func (s *Storage) SaveRevokedToken(token *models.RevokedToken) error {
+	const op = "postgres.SaveRevokedToken"
+	if err := s.DB.Where("expires_at < ?", time.Now()).Delete(&models.RevokedToken{}).Error; err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	if err := s.DB.Save(token).Error; err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	return nil
+}
+
+func (s *Storage) IsTokenRevoked(tokenID string) (bool, error) {
+	const op = "postgres.IsTokenRevoked"
+	var count int64
+	if err := s.DB.Model(&models.RevokedToken{}).Where("token_id = ?", tokenID).Count(&count).Error; err != nil {
+		return false, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return count > 0, nil
+}
+
+func (s *Storage) SaveSession(session *models.Session) error {
+	const op = "postgres.SaveSession"
+	if err := s.DB.Create(session).Error; err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	return nil
+}
+
+func (s *Storage) GetSessionByAccessToken(hash string) (*models.Session, error) {
+	const op = "postgres.GetSessionByAccessToken"
+	var session models.Session
+	if err := s.DB.First(&session, "access_token_hash = ?", hash).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, fmt.Errorf("%s: %w", op, storage.ErrRecordNotFound)
+		}
+
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return &session, nil
+}
+
+func (s *Storage) GetSessionByRefreshToken(hash string) (*models.Session, error) {
+	const op = "postgres.GetSessionByRefreshToken"
+	var session models.Session
+	if err := s.DB.First(&session, "refresh_token_hash = ?", hash).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, fmt.Errorf("%s: %w", op, storage.ErrRecordNotFound)
+		}
+
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return &session, nil
+}
+
+func (s *Storage) GetSessions(login string) ([]models.Session, error) {
+	const op = "postgres.GetSessions"
+	var sessions []models.Session
+	err := s.DB.Where("login = ? AND revoked_at IS NULL", login).Order("last_seen_at DESC").Find(&sessions).Error
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return sessions, nil
+}
+
+// UpdateSessionTokens stores tokens issued on refresh, it fails if session was revoked meanwhile
+func (s *Storage) UpdateSessionTokens(id, accessHash, refreshHash string, client models.ClientInfo) error {
+	const op = "postgres.UpdateSessionTokens"
+	res := s.DB.Model(&models.Session{}).
+		Where("id = ? AND revoked_at IS NULL", id).
+		Updates(map[string]any{
+			"access_token_hash":  accessHash,
+			"refresh_token_hash": refreshHash,
+			"user_agent":         client.UserAgent,
+			"ip":                 client.IP,
+			"last_seen_at":       time.Now(),
+		})
+	if err := res.Error; err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	if res.RowsAffected == 0 {
+		return fmt.Errorf("%s: %w", op, storage.ErrRecordNotFound)
+	}
+
+	return nil
+}
+
+func (s *Storage) TouchSession(id, ip string, seenAt time.Time) error {
+	const op = "postgres.TouchSession"
+	err := s.DB.Model(&models.Session{}).
+		Where("id = ?", id).
+		Updates(map[string]any{"ip": ip, "last_seen_at": seenAt}).Error
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	return nil
+}
+
+// RevokeSessions revokes active sessions of login. If ids are given only these sessions are revoked,
+// sessions listed in except are kept. Number of revoked sessions is returned.
+func (s *Storage) RevokeSessions(login string, ids []string, except []string) (int64, error) {
+	const op = "postgres.RevokeSessions"
+	q := s.DB.Model(&models.Session{}).Where("login = ? AND revoked_at IS NULL", login)
+	if len(ids) > 0 {
+		q = q.Where("id IN ?", ids)
+	}
+	if len(except) > 0 {
+		q = q.Where("id NOT IN ?", except)
+	}
+
+	res := q.Update("revoked_at", time.Now())
+	if err := res.Error; err != nil {
+		return 0, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return res.RowsAffected, nil
+}
+
+func (s *Storage) SaveEmailToken(token *models.EmailToken) error {
+	const op = "postgres.SaveEmailToken"
+	if err := s.DB.Create(token).Error; err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	return nil
+}
+
+// GetEmailToken returns unused and unexpired token without using it, ErrRecordNotFound is returned
+// for unknown, used and expired tokens
+func (s *Storage) GetEmailToken(hash, purpose string, now time.Time) (*models.EmailToken, error) {
+	const op = "postgres.GetEmailToken"
+	var token models.EmailToken
+	err := s.DB.Where("token_hash = ? AND purpose = ? AND used_at IS NULL AND expires_at > ?", hash, purpose, now).
+		First(&token).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, fmt.Errorf("%s: %w", op, storage.ErrRecordNotFound)
+		}
+
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return &token, nil
+}
+
+// ConsumeEmailToken marks unused and unexpired token as used and returns it. Token can be consumed only once,
+// ErrRecordNotFound is returned for unknown, used and expired tokens.
+func (s *Storage) ConsumeEmailToken(hash, purpose string, now time.Time) (*models.EmailToken, error) {
+	const op = "postgres.ConsumeEmailToken"
+	var tokens []models.EmailToken
+	res := s.DB.Model(&tokens).
+		Clauses(clause.Returning{}).
+		Where("token_hash = ? AND purpose = ? AND used_at IS NULL AND expires_at > ?", hash, purpose, now).
+		Update("used_at", now)
+	if err := res.Error; err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	if len(tokens) == 0 {
+		return nil, fmt.Errorf("%s: %w", op, storage.ErrRecordNotFound)
+	}
+
+	return &tokens[0], nil
+}
+
+// InvalidateEmailTokens marks all unused tokens of login with given purpose as used
+func (s *Storage) InvalidateEmailTokens(login, purpose string) error {
+	const op = "postgres.InvalidateEmailTokens"
+	err := s.DB.Model(&models.EmailToken{}).
+		Where("login = ? AND purpose = ? AND used_at IS NULL", login, purpose).
+		Update("used_at", time.Now()).Error
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	return nil
+}
+
+func (s *Storage) MarkEmailVerified(ctx context.Context, email string, at time.Time) error {
+	const op = "postgres.MarkEmailVerified"
+	res := s.DB.WithContext(ctx).Model(&models.User{}).
+		Where("email = ?", email).
+		Update("email_verified_at", gorm.Expr("COALESCE(email_verified_at, ?)", at))
+	if err := res.Error; err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	if res.RowsAffected == 0 {
+		return fmt.Errorf("%s: %w", op, storage.ErrRecordNotFound)
+	}
+
+	return nil
+}
@@ -0,0 +1,80 @@
+package postgres
+
+import (
+	"fmt"
+	"log/slog"
+
+	"gorm.io/gorm"
+)
+
+// constraint is a domain rule enforced by database which gorm tags can not declare: gorm creates foreign
+// keys only for relations declared on the referencing model and validates existing rows right away.
+type constraint struct {
+	Name  string
+	Table string
+	// Column is reported as the offending field of storage.FieldError
+	Column     string
+	Definition string
+}
+
+var constraints = []constraint{
+	{"fk_trainers_user", "trainers", "user_id", "FOREIGN KEY (user_id) REFERENCES users (id)"},
+	{"fk_clients_user", "clients", "user_id", "FOREIGN KEY (user_id) REFERENCES users (id)"},
+	{"fk_clients_trainer", "clients", "trainer_id", "FOREIGN KEY (trainer_id) REFERENCES trainers (id)"},
+	{"fk_training_plans_trainer", "training_plans", "trainer_id", "FOREIGN KEY (trainer_id) REFERENCES trainers (id)"},
+	{"fk_training_plans_client", "training_plans", "client_id", "FOREIGN KEY (client_id) REFERENCES clients (id)"},
+	{"fk_progress_reports_trainer", "progress_reports", "trainer_id", "FOREIGN KEY (trainer_id) REFERENCES trainers (id)"},
+	{"fk_progress_reports_client", "progress_reports", "client_id", "FOREIGN KEY (client_id) REFERENCES clients (id)"},
+	{"fk_metrics_client", "metrics", "client_id", "FOREIGN KEY (client_id) REFERENCES clients (id)"},
+	{"fk_metric_rollups_client", "metric_rollups", "client_id", "FOREIGN KEY (client_id) REFERENCES clients (id)"},
+	{"chk_users_units", "users", "units", "CHECK (units IN ('metric', 'imperial'))"},
+	{"chk_clients_height", "clients", "height", "CHECK (height >= 0)"},
+	{"chk_clients_weight", "clients", "weight", "CHECK (weight >= 0)"},
+	{"chk_clients_body_fat", "clients", "body_fat", "CHECK (body_fat >= 0)"},
+	{"chk_clients_goals", "clients", "goals", "CHECK (jsonb_typeof(goals) = 'object')"},
+	// ranges of measurements are kept in measurement catalog and checked by service
+	{"chk_metrics_measurements", "metrics", "measurements", "CHECK (jsonb_typeof(measurements) = 'object')"},
+}
+
+// constraintColumn returns column of a constraint declared in constraints
+func constraintColumn(name string) (string, bool) {
+	for _, c := range constraints {
+		if c.Name == name {
+			return c.Column, true
+		}
+	}
+
+	return "", false
+}
+
+// addConstraints adds missing constraints. They are added NOT VALID, so they apply to new rows at once
+// and rows saved before them do not stop the start. Then they are validated: a constraint violated by
+// existing rows is left not valid and reported, it is validated again on the next start.
+func addConstraints(db *gorm.DB, log *slog.Logger) error {
+	for _, c := range constraints {
+		var validated []bool
+		err := db.Raw(`SELECT convalidated FROM pg_constraint WHERE conname = ? AND conrelid = ?::regclass`, c.Name, c.Table).
+			Scan(&validated).Error
+		if err != nil {
+			return err
+		}
+
+		if len(validated) == 0 {
+			err = db.Exec(fmt.Sprintf(`ALTER TABLE %q ADD CONSTRAINT %q %s NOT VALID`, c.Table, c.Name, c.Definition)).Error
+			if err != nil {
+				return fmt.Errorf("failed to add %s: %w", c.Name, err)
+			}
+		} else if validated[0] {
+			continue
+		}
+
+		if err = db.Exec(fmt.Sprintf(`ALTER TABLE %q VALIDATE CONSTRAINT %q`, c.Table, c.Name)).Error; err != nil {
+			log.Warn("existing rows violate constraint, it applies to new rows only",
+				slog.String("constraint", c.Name),
+				slog.String("error", err.Error()),
+			)
+		}
+	}
+
+	return nil
+}
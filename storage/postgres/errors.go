@@ -0,0 +1,148 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"ChadProgress/storage"
+
+	"github.com/jackc/pgx/v5/pgconn"
+	"gorm.io/gorm"
+	"gorm.io/gorm/schema"
+)
+
+// SQLSTATE codes, see https://www.postgresql.org/docs/current/errcodes-appendix.html
+const (
+	codeStringTooLong       = "22001"
+	codeInvalidText         = "22P02"
+	codeForeignKeyViolation = "23503"
+	codeUniqueViolation     = "23505"
+	codeCheckViolation      = "23514"
+)
+
+var (
+	// keyDetailRe finds the first column in details of unique and foreign key violations,
+	// e.g. Key (email)=(bob@example.com) already exists.
+	keyDetailRe = regexp.MustCompile(`^Key \(([^,)]+)`)
+	enumRe      = regexp.MustCompile(`invalid input value for enum ([^:]+):`)
+	varcharRe   = regexp.MustCompile(`(?i)^(?:varchar|character varying)\((\d+)\)$`)
+)
+
+// classifyError converts constraint violations to storage errors naming the column. Postgres does not
+// name it for too long strings and invalid enum values, then it is looked up in schema of model by
+// its values. Other errors are returned as is.
+func (s *Storage) classifyError(err error, model any) error {
+	var pgErr *pgconn.PgError
+	if !errors.As(err, &pgErr) {
+		return err
+	}
+
+	var kind error
+	switch pgErr.Code {
+	case codeUniqueViolation:
+		kind = storage.ErrDuplicateKey
+	case codeForeignKeyViolation:
+		kind = storage.ErrForeignKeyViolation
+	case codeCheckViolation:
+		kind = storage.ErrCheckViolation
+	case codeStringTooLong:
+		kind = storage.ErrFieldIsTooLong
+	case codeInvalidText:
+		// the same code is used for malformed numbers and uuids, only enums are classified
+		if !enumRe.MatchString(pgErr.Message) {
+			return err
+		}
+		kind = storage.ErrInvalidEnumValue
+	default:
+		return err
+	}
+
+	return &storage.FieldError{
+		Err:        kind,
+		Field:      s.errorField(pgErr, model),
+		Constraint: pgErr.ConstraintName,
+	}
+}
+
+func (s *Storage) errorField(pgErr *pgconn.PgError, model any) string {
+	if pgErr.ColumnName != "" {
+		return pgErr.ColumnName
+	}
+	if column, ok := constraintColumn(pgErr.ConstraintName); ok {
+		return column
+	}
+	if m := keyDetailRe.FindStringSubmatch(pgErr.Detail); m != nil {
+		return strings.Trim(m[1], `"`)
+	}
+	if model == nil {
+		return ""
+	}
+
+	stmt := &gorm.Statement{DB: s.DB}
+	if err := stmt.Parse(model); err != nil {
+		return ""
+	}
+
+	switch pgErr.Code {
+	case codeCheckViolation:
+		if chk, ok := stmt.Schema.ParseCheckConstraints()[pgErr.ConstraintName]; ok && chk.Field != nil {
+			return chk.Field.DBName
+		}
+	case codeUniqueViolation:
+		if idx, ok := stmt.Schema.ParseIndexes()[pgErr.ConstraintName]; ok && len(idx.Fields) > 0 {
+			return idx.Fields[0].DBName
+		}
+	case codeStringTooLong:
+		return tooLongField(stmt, model)
+	case codeInvalidText:
+		if m := enumRe.FindStringSubmatch(pgErr.Message); m != nil {
+			return enumField(stmt.Schema, strings.Trim(m[1], `"`))
+		}
+	}
+
+	return ""
+}
+
+// tooLongField finds string field of model whose value exceeds size of its column
+func tooLongField(stmt *gorm.Statement, model any) string {
+	row := reflect.Indirect(reflect.ValueOf(model))
+	if row.Kind() != reflect.Struct {
+		return ""
+	}
+
+	for _, f := range stmt.Schema.Fields {
+		if f.DBName == "" || f.DataType != schema.String && !varcharRe.MatchString(string(f.DataType)) {
+			continue
+		}
+		size := f.Size
+		if m := varcharRe.FindStringSubmatch(string(f.DataType)); m != nil {
+			size, _ = strconv.Atoi(m[1])
+		}
+		if size <= 0 {
+			continue
+		}
+
+		v, _ := f.ValueOf(context.Background(), row)
+		if str, ok := v.(string); ok && utf8.RuneCountInString(str) > size {
+			return f.DBName
+		}
+	}
+
+	return ""
+}
+
+// enumField finds column of the enum type
+func enumField(s *schema.Schema, enum string) string {
+	for _, f := range s.Fields {
+		if strings.EqualFold(string(f.DataType), enum) {
+			return f.DBName
+		}
+	}
+
+	return ""
+}
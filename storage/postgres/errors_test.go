@@ -0,0 +1,105 @@
+package postgres
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	"ChadProgress/internal/models"
+	"ChadProgress/storage"
+
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+)
+
+// newOfflineStorage returns storage which only parses schemas, it never connects to database
+func newOfflineStorage(t *testing.T) *Storage {
+	t.Helper()
+	db, err := gorm.Open(postgres.Open("host=localhost"), &gorm.Config{DisableAutomaticPing: true})
+	require.NoError(t, err)
+
+	return &Storage{DB: db}
+}
+
+func TestClassifyError(t *testing.T) {
+	s := newOfflineStorage(t)
+
+	tests := []struct {
+		name  string
+		err   *pgconn.PgError
+		model any
+		kind  error
+		field string
+	}{
+		{
+			name: "Unique violation",
+			err: &pgconn.PgError{Code: codeUniqueViolation, ConstraintName: "uni_users_email",
+				Detail: "Key (email)=(bob@example.com) already exists."},
+			model: &models.User{},
+			kind:  storage.ErrDuplicateKey,
+			field: "email",
+		},
+		{
+			name: "Foreign key violation",
+			err: &pgconn.PgError{Code: codeForeignKeyViolation, ConstraintName: "fk_clients_training_plans",
+				Detail: `Key (client_id)=(42) is not present in table "clients".`},
+			model: &models.TrainingPlan{},
+			kind:  storage.ErrForeignKeyViolation,
+			field: "client_id",
+		},
+		{
+			name:  "Too long string is found by value",
+			err:   &pgconn.PgError{Code: codeStringTooLong, Message: "value too long for type character varying(150)"},
+			model: &models.Trainer{Qualifications: strings.Repeat("a", 151), Experience: "ok"},
+			kind:  storage.ErrFieldIsTooLong,
+			field: "qualifications",
+		},
+		{
+			name:  "Invalid enum value",
+			err:   &pgconn.PgError{Code: codeInvalidText, Message: `invalid input value for enum status: "SLEEPING"`},
+			model: &models.Trainer{},
+			kind:  storage.ErrInvalidEnumValue,
+			field: "status",
+		},
+		{
+			name:  "Constraint added by storage",
+			err:   &pgconn.PgError{Code: codeCheckViolation, ConstraintName: "chk_clients_body_fat"},
+			model: &models.Client{},
+			kind:  storage.ErrCheckViolation,
+			field: "body_fat",
+		},
+		{
+			name:  "Column named by postgres",
+			err:   &pgconn.PgError{Code: codeCheckViolation, ColumnName: "weight", ConstraintName: "chk_clients_weight"},
+			model: &models.Client{},
+			kind:  storage.ErrCheckViolation,
+			field: "weight",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := s.classifyError(fmt.Errorf("wrapped: %w", tt.err), tt.model)
+			assert.ErrorIs(t, err, tt.kind)
+
+			var fieldErr *storage.FieldError
+			require.True(t, errors.As(err, &fieldErr))
+			assert.Equal(t, tt.field, fieldErr.Field)
+			assert.Equal(t, tt.err.ConstraintName, fieldErr.Constraint)
+		})
+	}
+}
+
+func TestClassifyErrorKeepsOtherErrors(t *testing.T) {
+	s := newOfflineStorage(t)
+
+	malformed := &pgconn.PgError{Code: codeInvalidText, Message: `invalid input syntax for type bigint: "x"`}
+	assert.Same(t, error(malformed), s.classifyError(malformed, &models.User{}))
+
+	other := errors.New("connection reset")
+	assert.Same(t, other, s.classifyError(other, &models.User{}))
+}
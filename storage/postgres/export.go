@@ -0,0 +1,175 @@
+package postgres
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"ChadProgress/internal/models"
+	"ChadProgress/storage"
+
+	"gorm.io/gorm"
+)
+
+// GetPersonalData collects everything stored about active user. Data is read in one read only
+// transaction, so that the export is a consistent snapshot.
+func (s *Storage) GetPersonalData(email string) (*models.PersonalData, error) {
+	const op = "postgres.GetPersonalData"
+	var data models.PersonalData
+	err := s.DB.Transaction(func(tx *gorm.DB) error {
+		err := tx.Where("email = ? AND status = ?", email, models.UserStatusActive).First(&data.User).Error
+		if err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return storage.ErrRecordNotFound
+			}
+
+			return err
+		}
+
+		var clients []models.Client
+		if err = tx.Where("user_id = ?", data.User.ID).Limit(1).Find(&clients).Error; err != nil {
+			return err
+		}
+		var trainers []models.Trainer
+		if err = tx.Where("user_id = ?", data.User.ID).Limit(1).Find(&trainers).Error; err != nil {
+			return err
+		}
+
+		// plans and reports are shared by trainer and client, each of them gets the ones they take part in
+		var column string
+		var profileID uint
+		switch {
+		case len(clients) > 0:
+			data.Client = &clients[0]
+			column, profileID = "client_id", data.Client.ID
+			if err = tx.Where("client_id = ?", profileID).Order("id").Find(&data.Metrics).Error; err != nil {
+				return err
+			}
+		case len(trainers) > 0:
+			data.Trainer = &trainers[0]
+			column, profileID = "trainer_id", data.Trainer.ID
+		}
+		if column != "" {
+			if err = tx.Where(column+" = ?", profileID).Order("id").Find(&data.TrainingPlans).Error; err != nil {
+				return err
+			}
+			if err = tx.Where(column+" = ?", profileID).Order("id").Find(&data.ProgressReports).Error; err != nil {
+				return err
+			}
+		}
+
+		return tx.Where("login = ?", email).Order("created_at").Find(&data.Sessions).Error
+	}, &sql.TxOptions{Isolation: sql.LevelRepeatableRead, ReadOnly: true})
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return &data, nil
+}
+
+func (s *Storage) SaveDataExport(export *models.DataExport) error {
+	const op = "postgres.SaveDataExport"
+	if err := s.DB.Create(export).Error; err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	return nil
+}
+
+// UpdateDataExport saves status, file and expiration of export
+func (s *Storage) UpdateDataExport(export *models.DataExport) error {
+	const op = "postgres.UpdateDataExport"
+	res := s.DB.Model(&models.DataExport{}).
+		Where("id = ?", export.ID).
+		Updates(map[string]any{
+			"status":     export.Status,
+			"path":       export.Path,
+			"size":       export.Size,
+			"ready_at":   export.ReadyAt,
+			"expires_at": export.ExpiresAt,
+		})
+	if err := res.Error; err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	if res.RowsAffected == 0 {
+		return fmt.Errorf("%s: %w", op, storage.ErrRecordNotFound)
+	}
+
+	return nil
+}
+
+// GetDataExport returns export of login, exports of other users are not found
+func (s *Storage) GetDataExport(login, id string) (*models.DataExport, error) {
+	const op = "postgres.GetDataExport"
+	var export models.DataExport
+	if err := s.DB.First(&export, "id = ? AND login = ?", id, login).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, fmt.Errorf("%s: %w", op, storage.ErrRecordNotFound)
+		}
+
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return &export, nil
+}
+
+func (s *Storage) GetDataExportByToken(hash string) (*models.DataExport, error) {
+	const op = "postgres.GetDataExportByToken"
+	var export models.DataExport
+	if err := s.DB.First(&export, "token_hash = ?", hash).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, fmt.Errorf("%s: %w", op, storage.ErrRecordNotFound)
+		}
+
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return &export, nil
+}
+
+// HasPendingDataExport reports whether archive of login is being built
+func (s *Storage) HasPendingDataExport(login string) (bool, error) {
+	const op = "postgres.HasPendingDataExport"
+	var count int64
+	err := s.DB.Model(&models.DataExport{}).
+		Where("login = ? AND status = ? AND expires_at > ?", login, models.ExportStatusPending, time.Now()).
+		Count(&count).Error
+	if err != nil {
+		return false, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return count > 0, nil
+}
+
+func (s *Storage) GetExpiredDataExports(now time.Time) ([]models.DataExport, error) {
+	const op = "postgres.GetExpiredDataExports"
+	var exports []models.DataExport
+	if err := s.DB.Where("expires_at <= ?", now).Find(&exports).Error; err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return exports, nil
+}
+
+func (s *Storage) DeleteDataExport(id string) error {
+	const op = "postgres.DeleteDataExport"
+	if err := s.DB.Where("id = ?", id).Delete(&models.DataExport{}).Error; err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	return nil
+}
+
+// FailPendingDataExports marks exports interrupted by restart as failed
+func (s *Storage) FailPendingDataExports(createdBefore time.Time) (int64, error) {
+	const op = "postgres.FailPendingDataExports"
+	res := s.DB.Model(&models.DataExport{}).
+		Where("status = ? AND created_at < ?", models.ExportStatusPending, createdBefore).
+		Update("status", models.ExportStatusFailed)
+	if err := res.Error; err != nil {
+		return 0, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return res.RowsAffected, nil
+}
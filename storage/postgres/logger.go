@@ -0,0 +1,111 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"regexp"
+	"runtime"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+const pkgPath = "ChadProgress/storage/postgres."
+
+// closureRe matches suffix of closures, e.g. .func1 or .func2.1
+var closureRe = regexp.MustCompile(`(\.func\d+)+(\.\d+)*$`)
+
+// gormLogger writes gorm logs to slog. Failed queries are logged with error level and queries slower
+// than slowThreshold with warning level, both with op of storage method that made them. Values of
+// queries are never logged, they hold personal data.
+type gormLogger struct {
+	log           *slog.Logger
+	level         logger.LogLevel
+	slowThreshold time.Duration
+}
+
+func newGormLogger(log *slog.Logger, slowThreshold time.Duration) *gormLogger {
+	return &gormLogger{
+		log:           log,
+		level:         logger.Warn,
+		slowThreshold: slowThreshold,
+	}
+}
+
+func (l *gormLogger) LogMode(level logger.LogLevel) logger.Interface {
+	c := *l
+	c.level = level
+
+	return &c
+}
+
+func (l *gormLogger) Info(ctx context.Context, msg string, args ...any) {
+	if l.level >= logger.Info {
+		l.log.InfoContext(ctx, fmt.Sprintf(msg, args...))
+	}
+}
+
+func (l *gormLogger) Warn(ctx context.Context, msg string, args ...any) {
+	if l.level >= logger.Warn {
+		l.log.WarnContext(ctx, fmt.Sprintf(msg, args...))
+	}
+}
+
+func (l *gormLogger) Error(ctx context.Context, msg string, args ...any) {
+	if l.level >= logger.Error {
+		l.log.ErrorContext(ctx, fmt.Sprintf(msg, args...))
+	}
+}
+
+func (l *gormLogger) Trace(ctx context.Context, begin time.Time, fc func() (sql string, rowsAffected int64), err error) {
+	if l.level <= logger.Silent {
+		return
+	}
+
+	elapsed := time.Since(begin)
+	failed := err != nil && !errors.Is(err, gorm.ErrRecordNotFound)
+	slow := l.slowThreshold > 0 && elapsed > l.slowThreshold
+	if !(failed && l.level >= logger.Error) && !(slow && l.level >= logger.Warn) {
+		return
+	}
+
+	sql, rows := fc()
+	attrs := []any{
+		slog.String("op", callerOp()),
+		slog.Duration("elapsed", elapsed),
+		slog.Int64("rows", rows),
+		slog.String("sql", sql),
+	}
+	if failed {
+		l.log.ErrorContext(ctx, "query failed", append(attrs, slog.String("error", err.Error()))...)
+		return
+	}
+	l.log.WarnContext(ctx, "slow query", append(attrs, slog.Duration("threshold", l.slowThreshold))...)
+}
+
+// ParamsFilter leaves placeholders in logged queries instead of values
+func (l *gormLogger) ParamsFilter(_ context.Context, sql string, _ ...any) (string, []any) {
+	return sql, nil
+}
+
+// callerOp names storage method which made the query the way ops are named in this package,
+// e.g. postgres.GetMetrics
+func callerOp() string {
+	pcs := make([]uintptr, 32)
+	n := runtime.Callers(3, pcs)
+	frames := runtime.CallersFrames(pcs[:n])
+	for {
+		frame, more := frames.Next()
+		if name, ok := strings.CutPrefix(frame.Function, pkgPath); ok && !strings.HasPrefix(name, "(*gormLogger)") {
+			name = strings.TrimPrefix(name, "(*Storage).")
+			return "postgres." + closureRe.ReplaceAllString(name, "")
+		}
+		if !more {
+			return "unknown"
+		}
+	}
+}
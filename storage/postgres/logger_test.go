@@ -0,0 +1,97 @@
+package postgres
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+func newCapturingLogger(buf *bytes.Buffer) *slog.Logger {
+	return slog.New(slog.NewJSONHandler(buf, &slog.HandlerOptions{Level: slog.LevelDebug}))
+}
+
+func TestGormLoggerSlowQuery(t *testing.T) {
+	var buf bytes.Buffer
+	s := newOfflineStorage(t)
+	db := s.DB.Session(&gorm.Session{DryRun: true, Logger: newGormLogger(newCapturingLogger(&buf), time.Nanosecond)})
+	s = &Storage{DB: db}
+
+	_, err := s.GetMetrics(42, time.Time{}, time.Time{})
+	require.NoError(t, err)
+
+	var entry map[string]any
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &entry))
+	assert.Equal(t, "slow query", entry["msg"])
+	assert.Equal(t, "postgres.GetMetrics", entry["op"])
+	assert.Contains(t, entry["sql"], "client_id = $1")
+	assert.NotContains(t, entry["sql"], "42")
+}
+
+func TestGormLoggerErrors(t *testing.T) {
+	ctx := context.Background()
+	sql := func() (string, int64) { return "SELECT 1", 0 }
+
+	t.Run("Failed query", func(t *testing.T) {
+		var buf bytes.Buffer
+		l := newGormLogger(newCapturingLogger(&buf), time.Hour)
+		l.Trace(ctx, time.Now(), sql, errors.New("connection reset"))
+
+		assert.Contains(t, buf.String(), `"msg":"query failed"`)
+		assert.Contains(t, buf.String(), `"error":"connection reset"`)
+	})
+
+	t.Run("Not found is not an error", func(t *testing.T) {
+		var buf bytes.Buffer
+		l := newGormLogger(newCapturingLogger(&buf), time.Hour)
+		l.Trace(ctx, time.Now(), sql, gorm.ErrRecordNotFound)
+
+		assert.Empty(t, buf.String())
+	})
+
+	t.Run("Silent", func(t *testing.T) {
+		var buf bytes.Buffer
+		l := newGormLogger(newCapturingLogger(&buf), time.Nanosecond).LogMode(logger.Silent)
+		l.Trace(ctx, time.Now().Add(-time.Second), sql, errors.New("connection reset"))
+
+		assert.Empty(t, buf.String())
+	})
+}
+
+func TestReaderSpreadsQueriesBetweenReplicas(t *testing.T) {
+	primary := newOfflineStorage(t)
+	first, second := newOfflineStorage(t).DB, newOfflineStorage(t).DB
+
+	assert.Same(t, primary.DB, primary.reader(), "primary serves reads without replicas")
+
+	s := &Storage{DB: primary.DB, replicas: []*gorm.DB{first, second}, next: &atomic.Uint32{}}
+	got := []*gorm.DB{s.reader(), s.reader(), s.reader()}
+	assert.Same(t, second, got[0])
+	assert.Same(t, first, got[1])
+	assert.Same(t, second, got[2])
+}
+
+func TestListQueriesUseReplicas(t *testing.T) {
+	// dry run replica answers without connecting, the offline primary fails every query
+	primary := newOfflineStorage(t).DB.Session(&gorm.Session{Logger: logger.Discard})
+	replica := newOfflineStorage(t).DB.Session(&gorm.Session{DryRun: true})
+	s := &Storage{DB: primary, replicas: []*gorm.DB{replica}, next: &atomic.Uint32{}}
+
+	_, err := s.GetTrainersClients(1)
+	assert.NoError(t, err)
+	_, err = s.GetProgressReport(1, 2)
+	assert.NoError(t, err)
+	_, err = s.GetPlan(1, 2)
+	assert.NoError(t, err)
+	_, err = s.GetMetrics(2, time.Time{}, time.Time{})
+	assert.NoError(t, err)
+}
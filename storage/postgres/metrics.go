@@ -0,0 +1,600 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"log/slog"
+	"sort"
+	"strings"
+	"time"
+
+	"ChadProgress/internal/models"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// Metrics are range partitioned by month of measured_at (UTC), partitions are named metrics_yYYYYmMM.
+// Rows of months without partition go to metrics_default, EnsureMetricPartitions moves them out.
+// gorm can not migrate partitioned tables, so metrics are created here instead of autoMigrate.
+const (
+	metricsTable       = "metrics"
+	metricsLegacyTable = "metrics_legacy"
+	metricsColumns     = "id, client_id, measurements, measured_at"
+)
+
+// insertBatchSize keeps statements adding many metrics or rollups under the limit of bind parameters
+const insertBatchSize = 1000
+
+// legacyMeasurements builds measurements of metrics saved before measurement catalog from their columns.
+// Zero meant the value was not given.
+const legacyMeasurements = `jsonb_strip_nulls(jsonb_build_object(
+	'height', nullif(height, 0), 'weight', nullif(weight, 0),
+	'body_fat', nullif(body_fat, 0), 'bmi', nullif(bmi, 0)))`
+
+// createMetricsTable creates partitioned metrics table. Table created by gorm before metrics were
+// partitioned is converted: its rows are copied to partitions covering them. Rows of removed clients or
+// without time of measurement can not be copied, they are left in metrics_legacy and reported.
+func createMetricsTable(db *gorm.DB, log *slog.Logger) error {
+	var kind []string
+	err := db.Raw(`SELECT relkind::text FROM pg_class WHERE oid = to_regclass(?)`, metricsTable).Scan(&kind).Error
+	if err != nil {
+		return err
+	}
+
+	switch {
+	case len(kind) == 0:
+		return db.Transaction(func(tx *gorm.DB) error {
+			if err := tx.Exec(`CREATE SEQUENCE IF NOT EXISTS metrics_id_seq`).Error; err != nil {
+				return err
+			}
+			if err := createPartitionedMetrics(tx); err != nil {
+				return err
+			}
+			now := time.Now()
+			if _, err := ensurePartitions(tx, now, now.AddDate(0, 1, 0)); err != nil {
+				return err
+			}
+
+			return tx.Exec(`ALTER SEQUENCE metrics_id_seq OWNED BY metrics.id`).Error
+		})
+	case kind[0] == "p":
+		return migrateMeasurements(db)
+	}
+
+	var left int64
+	err = db.Transaction(func(tx *gorm.DB) error {
+		for _, stmt := range []string{
+			`ALTER SEQUENCE metrics_id_seq OWNED BY NONE`,
+			`ALTER TABLE metrics RENAME TO metrics_legacy`,
+			`ALTER TABLE metrics_legacy RENAME CONSTRAINT metrics_pkey TO metrics_legacy_pkey`,
+			`DROP INDEX IF EXISTS idx_metrics_client_measured`,
+		} {
+			if err := tx.Exec(stmt).Error; err != nil {
+				return err
+			}
+		}
+		if err := createPartitionedMetrics(tx); err != nil {
+			return err
+		}
+
+		var bounds struct{ First, Last *time.Time }
+		err := tx.Raw(`SELECT min(measured_at) AS first, max(measured_at) AS last FROM metrics_legacy`).Scan(&bounds).Error
+		if err != nil {
+			return err
+		}
+		now := time.Now()
+		from, to := now, now.AddDate(0, 1, 0)
+		if bounds.First != nil && bounds.First.Before(from) {
+			from = *bounds.First
+		}
+		if bounds.Last != nil && bounds.Last.After(to) {
+			to = *bounds.Last
+		}
+		if _, err = ensurePartitions(tx, from, to); err != nil {
+			return err
+		}
+
+		err = tx.Exec(`
+			INSERT INTO metrics (` + metricsColumns + `)
+			SELECT id, client_id, ` + legacyMeasurements + `, measured_at FROM metrics_legacy l
+			WHERE measured_at IS NOT NULL
+				AND EXISTS (SELECT 1 FROM clients c WHERE c.id = l.client_id)`).Error
+		if err != nil {
+			return err
+		}
+		err = tx.Exec(`DELETE FROM metrics_legacy WHERE id IN (SELECT id FROM metrics)`).Error
+		if err != nil {
+			return err
+		}
+		if err = tx.Raw(`SELECT count(*) FROM metrics_legacy`).Scan(&left).Error; err != nil {
+			return err
+		}
+		if left == 0 {
+			if err = tx.Exec(`DROP TABLE metrics_legacy`).Error; err != nil {
+				return err
+			}
+		}
+
+		return tx.Exec(`ALTER SEQUENCE metrics_id_seq OWNED BY metrics.id`).Error
+	})
+	if err != nil {
+		return fmt.Errorf("failed to partition metrics: %w", err)
+	}
+	if left > 0 {
+		log.Warn("metrics violating constraints are left in "+metricsLegacyTable, slog.Int64("rows", left))
+	}
+
+	return nil
+}
+
+// createPartitionedMetrics creates metrics table with its default partition. Constraints of metrics are
+// declared with the table: they can not be added to a partitioned table as not valid.
+func createPartitionedMetrics(tx *gorm.DB) error {
+	defs := []string{
+		`id bigint NOT NULL DEFAULT nextval('metrics_id_seq')`,
+		`client_id bigint NOT NULL`,
+		`measurements jsonb NOT NULL DEFAULT '{}'`,
+		`measured_at timestamptz NOT NULL`,
+		// partition key must be a part of primary key
+		`PRIMARY KEY (id, measured_at)`,
+	}
+	for _, c := range constraints {
+		if c.Table == metricsTable {
+			defs = append(defs, fmt.Sprintf("CONSTRAINT %q %s", c.Name, c.Definition))
+		}
+	}
+
+	for _, stmt := range []string{
+		fmt.Sprintf("CREATE TABLE metrics (\n\t%s\n) PARTITION BY RANGE (measured_at)", strings.Join(defs, ",\n\t")),
+		`CREATE INDEX idx_metrics_client_measured ON metrics (client_id, measured_at)`,
+		`CREATE TABLE metrics_default PARTITION OF metrics DEFAULT`,
+	} {
+		if err := tx.Exec(stmt).Error; err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// migrateMeasurements moves height, weight, body fat and BMI columns of metrics partitioned before
+// measurement catalog was introduced to measurements
+func migrateMeasurements(db *gorm.DB) error {
+	var legacy bool
+	err := db.Raw(`
+		SELECT EXISTS (
+			SELECT 1 FROM information_schema.columns
+			WHERE table_schema = current_schema() AND table_name = ? AND column_name = 'height'
+		)`, metricsTable).Scan(&legacy).Error
+	if err != nil || !legacy {
+		return err
+	}
+
+	var check string
+	for _, c := range constraints {
+		if c.Name == "chk_metrics_measurements" {
+			check = c.Definition
+		}
+	}
+
+	return db.Transaction(func(tx *gorm.DB) error {
+		for _, stmt := range []string{
+			`ALTER TABLE metrics ADD COLUMN measurements jsonb NOT NULL DEFAULT '{}'`,
+			`UPDATE metrics SET measurements = ` + legacyMeasurements,
+			// checks of the columns are dropped with them
+			`ALTER TABLE metrics DROP COLUMN height, DROP COLUMN weight, DROP COLUMN body_fat, DROP COLUMN bmi`,
+			`ALTER TABLE metrics ADD CONSTRAINT chk_metrics_measurements ` + check,
+		} {
+			if err := tx.Exec(stmt).Error; err != nil {
+				return fmt.Errorf("failed to migrate measurements: %w", err)
+			}
+		}
+
+		return nil
+	})
+}
+
+// EnsureMetricPartitions creates partitions for every month from from to to and for months which rows
+// were saved to the default partition. It returns number of created partitions.
+func (s *Storage) EnsureMetricPartitions(ctx context.Context, from, to time.Time) (int, error) {
+	const op = "postgres.EnsureMetricPartitions"
+	var created int
+	err := s.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var err error
+		created, err = ensurePartitions(tx, from, to)
+		return err
+	})
+	if err != nil {
+		return 0, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return created, nil
+}
+
+func ensurePartitions(tx *gorm.DB, from, to time.Time) (int, error) {
+	var existing []string
+	err := tx.Raw(`
+		SELECT c.relname FROM pg_inherits i
+		JOIN pg_class c ON c.oid = i.inhrelid
+		WHERE i.inhparent = ?::regclass`, metricsTable).
+		Scan(&existing).Error
+	if err != nil {
+		return 0, err
+	}
+	exists := make(map[string]bool, len(existing))
+	for _, name := range existing {
+		exists[name] = true
+	}
+
+	var stray []struct{ Month time.Time }
+	err = tx.Raw(`SELECT DISTINCT date_trunc('month', measured_at AT TIME ZONE 'UTC') AS month FROM metrics_default`).
+		Scan(&stray).Error
+	if err != nil {
+		return 0, err
+	}
+
+	months := monthsBetween(from, to)
+	for _, m := range stray {
+		months = append(months, m.Month)
+	}
+	created := 0
+	for _, month := range months {
+		month = monthStart(month)
+		name := partitionName(month)
+		if exists[name] {
+			continue
+		}
+		if err = createPartition(tx, name, month); err != nil {
+			return 0, fmt.Errorf("failed to create %s: %w", name, err)
+		}
+		exists[name] = true
+		created++
+	}
+
+	return created, nil
+}
+
+// createPartition creates partition of the month. Postgres refuses to create a partition while the
+// default partition holds its rows, so they are moved to the new partition.
+func createPartition(tx *gorm.DB, name string, month time.Time) error {
+	// DDL takes no parameters, bounds are formatted from time values
+	from, to := month.Format(time.RFC3339), month.AddDate(0, 1, 0).Format(time.RFC3339)
+	inMonth := fmt.Sprintf("measured_at >= '%s' AND measured_at < '%s'", from, to)
+
+	for _, stmt := range []string{
+		`DROP TABLE IF EXISTS metrics_moved`,
+		`CREATE TEMP TABLE metrics_moved ON COMMIT DROP AS SELECT * FROM metrics_default WHERE ` + inMonth,
+		`DELETE FROM metrics_default WHERE ` + inMonth,
+		fmt.Sprintf(`CREATE TABLE %q PARTITION OF metrics FOR VALUES FROM ('%s') TO ('%s')`, name, from, to),
+		`INSERT INTO metrics SELECT * FROM metrics_moved`,
+		`DROP TABLE metrics_moved`,
+	} {
+		if err := tx.Exec(stmt).Error; err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func partitionName(month time.Time) string {
+	return fmt.Sprintf("metrics_y%04dm%02d", month.Year(), int(month.Month()))
+}
+
+// monthStart returns the first moment of month of t in UTC
+func monthStart(t time.Time) time.Time {
+	t = t.UTC()
+	return time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, time.UTC)
+}
+
+// monthsBetween returns starts of months from month of from to month of to inclusive
+func monthsBetween(from, to time.Time) []time.Time {
+	var months []time.Time
+	for m, last := monthStart(from), monthStart(to); !m.After(last); m = m.AddDate(0, 1, 0) {
+		months = append(months, m)
+	}
+
+	return months
+}
+
+// rollupsOf aggregates values of metrics to rollups of every period in time zones of clients, UTC for
+// clients missing in locs. Values of the same type and period are merged, as one upsert can not update
+// a row twice.
+func rollupsOf(metrics []models.Metric, locs map[uint]*time.Location) []models.MetricRollup {
+	type key struct {
+		clientID uint
+		kind     string
+		period   string
+		start    time.Time
+	}
+	index := make(map[key]int)
+	var rollups []models.MetricRollup
+	for _, m := range metrics {
+		loc := locs[m.ClientID]
+		if loc == nil {
+			loc = time.UTC
+		}
+		for _, kind := range sortedTypes(m.Measurements) {
+			v := m.Measurements[kind]
+			for _, period := range []string{models.PeriodDay, models.PeriodWeek} {
+				k := key{m.ClientID, kind, period, models.PeriodStart(period, m.MeasuredAt, loc)}
+				i, ok := index[k]
+				if !ok {
+					index[k] = len(rollups)
+					rollups = append(rollups, models.MetricRollup{
+						ClientID:    m.ClientID,
+						Type:        kind,
+						Period:      period,
+						PeriodStart: k.start,
+						ValueMin:    v,
+						ValueMax:    v,
+					})
+					i = len(rollups) - 1
+				}
+
+				r := &rollups[i]
+				r.Count++
+				r.ValueSum += v
+				r.ValueMin = min(r.ValueMin, v)
+				r.ValueMax = max(r.ValueMax, v)
+			}
+		}
+	}
+
+	return rollups
+}
+
+// sortedTypes returns measurement types of values in stable order
+func sortedTypes(values models.MeasurementValues) []string {
+	types := make([]string, 0, len(values))
+	for kind := range values {
+		types = append(types, kind)
+	}
+	sort.Strings(types)
+
+	return types
+}
+
+// addToRollups adds metrics to rollups of their days and weeks, it must run in the transaction
+// which saved the metrics
+func addToRollups(tx *gorm.DB, metrics []models.Metric) error {
+	locs, err := clientLocations(tx, metrics)
+	if err != nil {
+		return err
+	}
+	rollups := rollupsOf(metrics, locs)
+	if len(rollups) == 0 {
+		return nil
+	}
+
+	sum := func(column string) clause.Expr {
+		return gorm.Expr(fmt.Sprintf("metric_rollups.%[1]s + excluded.%[1]s", column))
+	}
+
+	return tx.Clauses(clause.OnConflict{
+		Columns: []clause.Column{{Name: "client_id"}, {Name: "type"}, {Name: "period"}, {Name: "period_start"}},
+		DoUpdates: clause.Assignments(map[string]any{
+			"count":     sum("count"),
+			"value_sum": sum("value_sum"),
+			"value_min": gorm.Expr("LEAST(metric_rollups.value_min, excluded.value_min)"),
+			"value_max": gorm.Expr("GREATEST(metric_rollups.value_max, excluded.value_max)"),
+		}),
+	}).CreateInBatches(&rollups, insertBatchSize).Error
+}
+
+// clientLocations returns time zones of users of clients the metrics belong to
+func clientLocations(tx *gorm.DB, metrics []models.Metric) (map[uint]*time.Location, error) {
+	ids := make([]uint, 0, len(metrics))
+	for _, m := range metrics {
+		ids = append(ids, m.ClientID)
+	}
+	var rows []struct {
+		ID       uint
+		Timezone string
+	}
+	err := tx.Raw(`SELECT c.id, u.timezone FROM clients c JOIN users u ON u.id = c.user_id WHERE c.id IN ?`, ids).
+		Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+
+	locs := make(map[uint]*time.Location, len(rows))
+	for _, row := range rows {
+		locs[row.ID] = models.LoadLocation(row.Timezone)
+	}
+
+	return locs, nil
+}
+
+// dropOutdatedRollups drops rollups made before they were kept per measurement type, they are
+// computed again by backfillRollups
+func dropOutdatedRollups(db *gorm.DB) error {
+	if !db.Migrator().HasTable(&models.MetricRollup{}) || db.Migrator().HasColumn(&models.MetricRollup{}, "Type") {
+		return nil
+	}
+
+	return db.Migrator().DropTable(&models.MetricRollup{})
+}
+
+// backfillRollups computes rollups of metrics saved before rollups were introduced
+func backfillRollups(db *gorm.DB) error {
+	var exists bool
+	if err := db.Raw(`SELECT EXISTS (SELECT 1 FROM metric_rollups)`).Scan(&exists).Error; err != nil {
+		return err
+	}
+	if exists {
+		return nil
+	}
+
+	return db.Transaction(func(tx *gorm.DB) error {
+		return computeRollups(tx, nil)
+	})
+}
+
+// computeRollups computes rollups of clients from their metrics, of every client when clientID is nil.
+// Existing rollups of the clients must be removed first.
+func computeRollups(tx *gorm.DB, clientID *uint) error {
+	// date_trunc starts weeks on Monday, the same as models.PeriodStart
+	query := `
+		INSERT INTO metric_rollups (client_id, type, period, period_start, count, value_sum, value_min, value_max)
+		SELECT m.client_id, v.key, @period, date_trunc(@period, m.measured_at AT TIME ZONE u.timezone)::date,
+			count(*), sum(v.value::numeric), min(v.value::numeric), max(v.value::numeric)
+		FROM metrics m
+		JOIN clients c ON c.id = m.client_id
+		JOIN users u ON u.id = c.user_id
+		CROSS JOIN jsonb_each_text(m.measurements) v
+		WHERE @client::bigint IS NULL OR m.client_id = @client
+		GROUP BY 1, 2, 4`
+
+	for _, period := range []string{models.PeriodDay, models.PeriodWeek} {
+		err := tx.Exec(query, sql.Named("period", period), sql.Named("client", clientID)).Error
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// RebuildMetricRollups computes rollups of client again, so that they are grouped by days of the
+// current time zone of the client
+func (s *Storage) RebuildMetricRollups(ctx context.Context, clientID uint) error {
+	const op = "postgres.RebuildMetricRollups"
+	err := s.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("client_id = ?", clientID).Delete(&models.MetricRollup{}).Error; err != nil {
+			return err
+		}
+
+		return computeRollups(tx, &clientID)
+	})
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	return nil
+}
+
+func (s *Storage) AddMetrics(ctx context.Context, metric *models.Metric) error {
+	const op = "postgres.AddMetrics"
+	err := s.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(metric).Error; err != nil {
+			return s.classifyError(err, metric)
+		}
+
+		return addToRollups(tx, []models.Metric{*metric})
+	})
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	return nil
+}
+
+// ImportMetrics adds many metrics in batches within one transaction, none is added if any fails
+func (s *Storage) ImportMetrics(ctx context.Context, metrics []models.Metric) error {
+	const op = "postgres.ImportMetrics"
+	if len(metrics) == 0 {
+		return nil
+	}
+
+	err := s.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.CreateInBatches(&metrics, insertBatchSize).Error; err != nil {
+			return s.classifyError(err, &models.Metric{})
+		}
+
+		return addToRollups(tx, metrics)
+	})
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	return nil
+}
+
+// GetMetrics returns metrics of client measured within [from, to) ordered by time of measurement,
+// zero from or to leaves the range open
+func (s *Storage) GetMetrics(clientID uint, from, to time.Time) ([]models.Metric, error) {
+	const op = "postgres.GetMetrics"
+	var metrics []models.Metric
+	res := s.reader().Where("client_id = ?", clientID).
+		Scopes(inRange("measured_at", from, to, false)).
+		Order("measured_at").
+		Find(&metrics)
+	if res.Error != nil {
+		return []models.Metric{}, fmt.Errorf("%s: %w", op, res.Error)
+	}
+
+	return metrics, nil
+}
+
+// GetMetricRollups returns rollups of every measurement type of client of period starting within [from, to) ordered by start,
+// zero from or to leaves the range open
+func (s *Storage) GetMetricRollups(clientID uint, period string, from, to time.Time) ([]models.MetricRollup, error) {
+	const op = "postgres.GetMetricRollups"
+	var rollups []models.MetricRollup
+	res := s.reader().Where("client_id = ? AND period = ?", clientID, period).
+		Scopes(inRange("period_start", from, to, true)).
+		Order("period_start, type").
+		Find(&rollups)
+	if res.Error != nil {
+		return []models.MetricRollup{}, fmt.Errorf("%s: %w", op, res.Error)
+	}
+
+	return rollups, nil
+}
+
+// inRange limits column to [from, to). Date columns are compared with UTC dates, comparing them with
+// timestamps would depend on time zone of the session.
+func inRange(column string, from, to time.Time, date bool) func(*gorm.DB) *gorm.DB {
+	value := func(t time.Time) any {
+		if date {
+			return t.UTC().Format(time.DateOnly)
+		}
+		return t
+	}
+
+	return func(db *gorm.DB) *gorm.DB {
+		if !from.IsZero() {
+			db = db.Where(column+" >= ?", value(from))
+		}
+		if !to.IsZero() {
+			db = db.Where(column+" < ?", value(to))
+		}
+		return db
+	}
+}
+
+// seedMeasurementTypes adds default measurement types missing in catalog
+func seedMeasurementTypes(db *gorm.DB) error {
+	types := append([]models.MeasurementType(nil), models.DefaultMeasurementTypes...)
+
+	return db.Clauses(clause.OnConflict{DoNothing: true}).Create(&types).Error
+}
+
+// GetMeasurementTypes returns measurement catalog ordered by code
+func (s *Storage) GetMeasurementTypes() ([]models.MeasurementType, error) {
+	const op = "postgres.GetMeasurementTypes"
+	var types []models.MeasurementType
+	if err := s.DB.Order("code").Find(&types).Error; err != nil {
+		return []models.MeasurementType{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return types, nil
+}
+
+// SaveMeasurementType adds measurement type to catalog or replaces unit, name and range of existing one.
+// Metrics saved before the change keep their values.
+func (s *Storage) SaveMeasurementType(ctx context.Context, t *models.MeasurementType) error {
+	const op = "postgres.SaveMeasurementType"
+	err := s.DB.WithContext(ctx).Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "code"}},
+		UpdateAll: true,
+	}).Create(t).Error
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, s.classifyError(err, t))
+	}
+
+	return nil
+}
@@ -0,0 +1,236 @@
+package postgres
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"ChadProgress/internal/models"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+)
+
+func TestRollupsOf(t *testing.T) {
+	monday := time.Date(2026, time.October, 12, 0, 0, 0, 0, time.UTC)
+	// Sunday evening in UTC+3 is still Sunday in UTC, it belongs to the week of monday
+	sunday := time.Date(2026, time.October, 18, 21, 0, 0, 0, time.FixedZone("MSK", 3*60*60))
+
+	rollups := rollupsOf([]models.Metric{
+		{ClientID: 1, Measurements: models.MeasurementValues{"weight": 80, "body_fat": 20}, MeasuredAt: monday.Add(8 * time.Hour)},
+		{ClientID: 1, Measurements: models.MeasurementValues{"weight": 78}, MeasuredAt: monday.Add(20 * time.Hour)},
+		{ClientID: 1, Measurements: models.MeasurementValues{"weight": 79}, MeasuredAt: sunday},
+		// Sunday night in UTC is Monday morning for client in UTC+3
+		{ClientID: 2, Measurements: models.MeasurementValues{"waist": 60}, MeasuredAt: monday.Add(-2 * time.Hour)},
+	}, map[uint]*time.Location{2: time.FixedZone("MSK", 3*60*60)})
+
+	sunDay := monday.AddDate(0, 0, 6)
+	assert.Equal(t, []models.MetricRollup{
+		{ClientID: 1, Type: "body_fat", Period: models.PeriodDay, PeriodStart: monday, Count: 1, ValueSum: 20, ValueMin: 20, ValueMax: 20},
+		{ClientID: 1, Type: "body_fat", Period: models.PeriodWeek, PeriodStart: monday, Count: 1, ValueSum: 20, ValueMin: 20, ValueMax: 20},
+		{ClientID: 1, Type: "weight", Period: models.PeriodDay, PeriodStart: monday, Count: 2, ValueSum: 158, ValueMin: 78, ValueMax: 80},
+		{ClientID: 1, Type: "weight", Period: models.PeriodWeek, PeriodStart: monday, Count: 3, ValueSum: 237, ValueMin: 78, ValueMax: 80},
+		{ClientID: 1, Type: "weight", Period: models.PeriodDay, PeriodStart: sunDay, Count: 1, ValueSum: 79, ValueMin: 79, ValueMax: 79},
+		{ClientID: 2, Type: "waist", Period: models.PeriodDay, PeriodStart: monday, Count: 1, ValueSum: 60, ValueMin: 60, ValueMax: 60},
+		{ClientID: 2, Type: "waist", Period: models.PeriodWeek, PeriodStart: monday, Count: 1, ValueSum: 60, ValueMin: 60, ValueMax: 60},
+	}, rollups)
+}
+
+func TestMonthsBetween(t *testing.T) {
+	from := time.Date(2026, time.November, 30, 23, 0, 0, 0, time.UTC)
+	to := time.Date(2027, time.February, 1, 0, 0, 0, 0, time.UTC)
+
+	var names []string
+	for _, month := range monthsBetween(from, to) {
+		names = append(names, partitionName(month))
+	}
+	assert.Equal(t, []string{"metrics_y2026m11", "metrics_y2026m12", "metrics_y2027m01", "metrics_y2027m02"}, names)
+	assert.Empty(t, monthsBetween(to, from))
+}
+
+// newTestClient saves client profile whose metrics are removed with it when the test ends
+func newTestClient(t testing.TB, s *Storage, email string) *models.Client {
+	t.Helper()
+	ctx := context.Background()
+
+	user := &models.User{Email: email, Name: "Metrics", Role: models.RoleClient, Status: models.UserStatusActive}
+	_, err := s.SaveUser(ctx, user)
+	require.NoError(t, err)
+	client := &models.Client{UserID: user.ID, TrainerID: dummyTrainerID}
+	require.NoError(t, s.SaveClient(ctx, client))
+
+	t.Cleanup(func() {
+		s.DB.Exec(`DELETE FROM metric_rollups WHERE client_id = ?`, client.ID)
+		s.DB.Exec(`DELETE FROM metrics WHERE client_id = ?`, client.ID)
+		s.DB.Exec(`DELETE FROM clients WHERE id = ?`, client.ID)
+		s.DB.Exec(`DELETE FROM users WHERE id = ?`, user.ID)
+	})
+
+	return client
+}
+
+func TestMetricPartitions(t *testing.T) {
+	s := newTestStorage(t)
+	ctx := context.Background()
+	client := newTestClient(t, s, "partitions@example.com")
+
+	// far ahead of partitions made in advance, the metric goes to the default partition
+	measuredAt := time.Date(2040, time.March, 10, 12, 0, 0, 0, time.UTC)
+	t.Cleanup(func() { s.DB.Exec(`DROP TABLE IF EXISTS metrics_y2040m03`) })
+	metric := &models.Metric{ClientID: client.ID, Measurements: models.MeasurementValues{"weight": 80}, MeasuredAt: measuredAt}
+	require.NoError(t, s.AddMetrics(ctx, metric))
+
+	partition := func() string {
+		var name string
+		require.NoError(t, s.DB.Raw(`SELECT tableoid::regclass::text FROM metrics WHERE id = ?`, metric.ID).Scan(&name).Error)
+		return name
+	}
+	assert.Equal(t, "metrics_default", partition())
+
+	now := time.Now()
+	created, err := s.EnsureMetricPartitions(ctx, now, now)
+	require.NoError(t, err)
+	assert.GreaterOrEqual(t, created, 1)
+	assert.Equal(t, "metrics_y2040m03", partition(), "metric is moved out of the default partition")
+
+	created, err = s.EnsureMetricPartitions(ctx, now, now)
+	require.NoError(t, err)
+	assert.Zero(t, created)
+
+	metrics, err := s.GetMetrics(client.ID, measuredAt.AddDate(0, 0, -1), measuredAt.AddDate(0, 0, 1))
+	require.NoError(t, err)
+	require.Len(t, metrics, 1)
+	assert.Equal(t, metric.ID, metrics[0].ID)
+	assert.Equal(t, models.MeasurementValues{"weight": 80}, metrics[0].Measurements)
+}
+
+func TestMetricRollups(t *testing.T) {
+	s := newTestStorage(t)
+	ctx := context.Background()
+	client := newTestClient(t, s, "rollups@example.com")
+
+	day := models.PeriodStart(models.PeriodDay, time.Now(), time.UTC)
+	for _, weight := range []float64{80, 78, 82} {
+		values := models.MeasurementValues{"weight": weight, "waist": 90}
+		require.NoError(t, s.AddMetrics(ctx, &models.Metric{ClientID: client.ID, Measurements: values, MeasuredAt: day.Add(time.Hour)}))
+	}
+
+	rollups, err := s.GetMetricRollups(client.ID, models.PeriodDay, day, day.AddDate(0, 0, 1))
+	require.NoError(t, err)
+	require.Len(t, rollups, 2, "a rollup per type")
+	assert.Equal(t, "waist", rollups[0].Type)
+	assert.Equal(t, float64(270), rollups[0].ValueSum)
+	assert.Equal(t, "weight", rollups[1].Type)
+	assert.Equal(t, int64(3), rollups[1].Count)
+	assert.Equal(t, float64(240), rollups[1].ValueSum)
+	assert.Equal(t, float64(78), rollups[1].ValueMin)
+	assert.Equal(t, float64(82), rollups[1].ValueMax)
+
+	week := models.PeriodStart(models.PeriodWeek, day, time.UTC)
+	rollups, err = s.GetMetricRollups(client.ID, models.PeriodWeek, week, week.AddDate(0, 0, 7))
+	require.NoError(t, err)
+	require.Len(t, rollups, 2)
+	assert.Equal(t, int64(3), rollups[1].Count)
+}
+
+func TestRebuildMetricRollups(t *testing.T) {
+	s := newTestStorage(t)
+	ctx := context.Background()
+	client := newTestClient(t, s, "timezone@example.com")
+
+	// 22:30 UTC is the next day in Moscow
+	measuredAt := models.PeriodStart(models.PeriodDay, time.Now(), time.UTC).Add(22*time.Hour + 30*time.Minute)
+	metric := &models.Metric{ClientID: client.ID, Measurements: models.MeasurementValues{"weight": 80}, MeasuredAt: measuredAt}
+	require.NoError(t, s.AddMetrics(ctx, metric))
+
+	dayRollup := func() time.Time {
+		rollups, err := s.GetMetricRollups(client.ID, models.PeriodDay, time.Time{}, time.Time{})
+		require.NoError(t, err)
+		require.Len(t, rollups, 1)
+		return rollups[0].PeriodStart
+	}
+	assert.Equal(t, models.PeriodStart(models.PeriodDay, measuredAt, time.UTC), dayRollup())
+
+	require.NoError(t, s.UpdateUserSettings(ctx, "timezone@example.com", models.UserSettings{Timezone: "Europe/Moscow"}))
+	require.NoError(t, s.RebuildMetricRollups(ctx, client.ID))
+	assert.Equal(t, models.PeriodStart(models.PeriodDay, measuredAt, time.UTC).AddDate(0, 0, 1), dayRollup())
+}
+
+func TestImportMetrics(t *testing.T) {
+	s := newTestStorage(t)
+	ctx := context.Background()
+	client := newTestClient(t, s, "import@example.com")
+
+	// more metrics than a batch, a weigh-in every day
+	start := models.PeriodStart(models.PeriodDay, time.Now(), time.UTC).AddDate(-3, 0, 0)
+	metrics := make([]models.Metric, 0, 3*365)
+	for i := range cap(metrics) {
+		metrics = append(metrics, models.Metric{ClientID: client.ID, Measurements: models.MeasurementValues{"weight": 80}, MeasuredAt: start.AddDate(0, 0, i).Add(8 * time.Hour)})
+	}
+	require.NoError(t, s.ImportMetrics(ctx, metrics))
+
+	saved, err := s.GetMetrics(client.ID, time.Time{}, time.Time{})
+	require.NoError(t, err)
+	assert.Len(t, saved, len(metrics))
+	rollups, err := s.GetMetricRollups(client.ID, models.PeriodDay, time.Time{}, time.Time{})
+	require.NoError(t, err)
+	assert.Len(t, rollups, len(metrics))
+
+	t.Run("Failure adds nothing", func(t *testing.T) {
+		broken := []models.Metric{
+			{ClientID: client.ID, Measurements: models.MeasurementValues{"weight": 81}, MeasuredAt: time.Now()},
+			{ClientID: 1 << 30, Measurements: models.MeasurementValues{"weight": 81}, MeasuredAt: time.Now()},
+		}
+		require.Error(t, s.ImportMetrics(ctx, broken))
+
+		after, err := s.GetMetrics(client.ID, time.Time{}, time.Time{})
+		require.NoError(t, err)
+		assert.Len(t, after, len(metrics))
+	})
+}
+
+// BenchmarkMetricsYear compares reading a year of hourly metrics with reading its rollups,
+// run with CP_TEST_DSN set: go test -run '^$' -bench MetricsYear ./storage/postgres
+func BenchmarkMetricsYear(b *testing.B) {
+	s := newTestStorage(b)
+	client := newTestClient(b, s, "benchmark@example.com")
+
+	to := models.PeriodStart(models.PeriodDay, time.Now(), time.UTC)
+	from := to.AddDate(-1, 0, 0)
+	_, err := s.EnsureMetricPartitions(context.Background(), from, to)
+	require.NoError(b, err)
+
+	var metrics []models.Metric
+	for t := from; t.Before(to); t = t.Add(time.Hour) {
+		metrics = append(metrics, models.Metric{
+			ClientID:     client.ID,
+			Measurements: models.MeasurementValues{"height": 180, "weight": 80, "body_fat": 20, "bmi": 24},
+			MeasuredAt:   t,
+		})
+	}
+	err = s.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.CreateInBatches(&metrics, 1000).Error; err != nil {
+			return err
+		}
+		return addToRollups(tx, metrics)
+	})
+	require.NoError(b, err)
+
+	b.Run("Raw", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			res, err := s.GetMetrics(client.ID, from, to)
+			require.NoError(b, err)
+			require.Len(b, res, len(metrics))
+		}
+	})
+	for _, period := range []string{models.PeriodDay, models.PeriodWeek} {
+		b.Run("Rollups/"+period, func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				res, err := s.GetMetricRollups(client.ID, period, from, to)
+				require.NoError(b, err)
+				require.NotEmpty(b, res)
+			}
+		})
+	}
+}
@@ -1,27 +1,43 @@
 package postgres
 
 import (
+	"context"
 	"errors"
 	"fmt"
-	"strings"
+	"log/slog"
+	"sync/atomic"
+	"time"
 
 	"ChadProgress/internal/models"
 	"ChadProgress/storage"
 
 	"gorm.io/driver/postgres"
 	"gorm.io/gorm"
-	"gorm.io/gorm/logger"
+	"gorm.io/gorm/clause"
 )
 
 type Storage struct {
 	DB *gorm.DB
+	// replicas serve list queries, see reader. Storage of a transaction has none.
+	replicas []*gorm.DB
+	next     *atomic.Uint32
 }
 
-func New(dsn string) (*Storage, error) {
+// Options configure connection pools of primary and every replica
+type Options struct {
+	MaxOpenConns    int
+	MaxIdleConns    int
+	ConnMaxLifetime time.Duration
+	ConnMaxIdleTime time.Duration
+	// SlowQueryThreshold is duration above which queries are logged, zero disables it
+	SlowQueryThreshold time.Duration
+	// ReplicaDSNs are read replicas, list queries are spread between them
+	ReplicaDSNs []string
+}
+
+func New(dsn string, log *slog.Logger, opts Options) (*Storage, error) {
 	const op = "postgres.New"
-	db, err := gorm.Open(postgres.Open(dsn), &gorm.Config{
-		Logger: logger.Default.LogMode(logger.Silent), // Отключаем логирование
-	})
+	db, err := open(dsn, log, opts)
 	if err != nil {
 		return nil, fmt.Errorf("%s: %w", op, err)
 	}
@@ -41,12 +57,85 @@ func New(dsn string) (*Storage, error) {
 		return nil, fmt.Errorf("%s: %w", op, err)
 	}
 
+	err = seedMeasurementTypes(db)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	err = createMetricsTable(db, log)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	err = addConstraints(db, log)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	err = backfillRollups(db)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	err = protectAuditEvents(db)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	err = registerAuditCallbacks(db)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
 	err = createDummyTrainer(db)
 	if err != nil {
 		return nil, fmt.Errorf("%s: %w", op, err)
 	}
 
-	return &Storage{DB: db}, nil
+	replicas := make([]*gorm.DB, 0, len(opts.ReplicaDSNs))
+	for _, replicaDSN := range opts.ReplicaDSNs {
+		replica, err := open(replicaDSN, log.With(slog.Bool("replica", true)), opts)
+		if err != nil {
+			return nil, fmt.Errorf("%s: failed to open replica: %w", op, err)
+		}
+		replicas = append(replicas, replica)
+	}
+
+	return &Storage{DB: db, replicas: replicas, next: &atomic.Uint32{}}, nil
+}
+
+func open(dsn string, log *slog.Logger, opts Options) (*gorm.DB, error) {
+	db, err := gorm.Open(postgres.Open(dsn), &gorm.Config{
+		Logger: newGormLogger(log, opts.SlowQueryThreshold),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	sqlDB, err := db.DB()
+	if err != nil {
+		return nil, err
+	}
+	sqlDB.SetMaxOpenConns(opts.MaxOpenConns)
+	if opts.MaxIdleConns > 0 {
+		// zero would close every connection after use
+		sqlDB.SetMaxIdleConns(opts.MaxIdleConns)
+	}
+	sqlDB.SetConnMaxLifetime(opts.ConnMaxLifetime)
+	sqlDB.SetConnMaxIdleTime(opts.ConnMaxIdleTime)
+
+	return db, nil
+}
+
+// reader returns connection for list queries: the next replica, or primary when there are no replicas.
+// Replicas lag behind primary, so list endpoints read from replicas, while reads made in the same request
+// as a write use s.DB. Storage of WithTx has no replicas, its reads always see writes of the transaction.
+func (s *Storage) reader() *gorm.DB {
+	if len(s.replicas) == 0 {
+		return s.DB
+	}
+
+	return s.replicas[int(s.next.Add(1))%len(s.replicas)]
 }
 
 func createRoleEnum(db *gorm.DB) error {
@@ -63,10 +152,11 @@ func createRoleEnum(db *gorm.DB) error {
 	}
 
 	if !exists {
-		return db.Exec(`CREATE TYPE role_enum AS ENUM ('trainer', 'client');`).Error
+		return db.Exec(`CREATE TYPE role_enum AS ENUM ('trainer', 'client', 'admin');`).Error
 	}
 
-	return nil
+	// databases created before admin role was introduced
+	return db.Exec(`ALTER TYPE role_enum ADD VALUE IF NOT EXISTS 'admin';`).Error
 }
 
 func createTrainerStatusEnum(db *gorm.DB) error {
@@ -89,20 +179,22 @@ func createTrainerStatusEnum(db *gorm.DB) error {
 	return nil
 }
 
-// createDummyTrainer required init function. generates first dummy trainer that every new client will link to by default
+// createDummyTrainer required init function. generates first dummy trainer that every new client will link to by default.
+// User and trainer are created in one transaction, so that failed start does not leave user without profile.
 func createDummyTrainer(db *gorm.DB) error {
-	var dummyTrainer models.Trainer
-	if err := db.Where("id = 1").First(&dummyTrainer).Error; err != nil {
-		if !errors.Is(err, gorm.ErrRecordNotFound) {
+	return db.Transaction(func(tx *gorm.DB) error {
+		var dummyTrainer models.Trainer
+		err := tx.Where("id = ?", dummyTrainerID).First(&dummyTrainer).Error
+		if err == nil || !errors.Is(err, gorm.ErrRecordNotFound) {
 			return err
 		}
 
-		user := models.User{
-			Email: "dummytrainer@mail.ru",
-			Name:  "Dummy Trainer",
-			Role:  "trainer",
-		}
-		if err = db.Create(&user).Error; err != nil {
+		// user may be left by a start which failed before transaction was used here
+		var user models.User
+		err = tx.Where(models.User{Email: "dummytrainer@mail.ru"}).
+			Attrs(models.User{Name: "Dummy Trainer", Role: models.RoleTrainer}).
+			FirstOrCreate(&user).Error
+		if err != nil {
 			return err
 		}
 
@@ -113,68 +205,115 @@ func createDummyTrainer(db *gorm.DB) error {
 			Achievements:   "I'm dummy!",
 			Status:         models.StatusActive,
 		}
-		if err = db.Create(&defaultTrainer).Error; err != nil {
-			return err
-		}
-	}
 
-	return nil
+		return tx.Create(&defaultTrainer).Error
+	})
 }
 
 func autoMigrate(db *gorm.DB) error {
-	return db.AutoMigrate(
+	// users registered before email verification was introduced are treated as verified
+	grandfatherEmails := db.Migrator().HasTable(&models.User{}) &&
+		!db.Migrator().HasColumn(&models.User{}, "EmailVerifiedAt")
+
+	if err := dropOutdatedRollups(db); err != nil {
+		return err
+	}
+	if err := migrateAuditActor(db); err != nil {
+		return err
+	}
+
+	err := db.AutoMigrate(
 		&models.User{},
 		&models.Trainer{},
 		&models.Client{},
 		&models.TrainingPlan{},
 		&models.ProgressReport{},
-		&models.Metric{},
+		&models.MetricRollup{},
+		&models.MeasurementType{},
+		&models.Credential{},
+		&models.RefreshToken{},
+		&models.RevokedToken{},
+		&models.Session{},
+		&models.EmailToken{},
+		&models.DeletionReceipt{},
+		&models.DataExport{},
+		&models.AuditEvent{},
 	)
+	if err != nil {
+		return err
+	}
+
+	if grandfatherEmails {
+		return db.Exec(`UPDATE users SET email_verified_at = registered_at WHERE email_verified_at IS NULL`).Error
+	}
+
+	return nil
 }
 
-func (s *Storage) SaveUser(user *models.User) (int64, error) {
+func (s *Storage) SaveUser(ctx context.Context, user *models.User) (int64, error) {
 	const op = "postgres.SaveUser"
-	result := s.DB.Create(user)
+	result := s.DB.WithContext(ctx).Create(user)
 	if err := result.Error; err != nil {
-		if isInvalidEnumError(err) {
-			return -1, fmt.Errorf("%s: %w", op, storage.ErrUserAlreadyExists)
-		} else if isTooLongFieldError(err) {
-			return -1, fmt.Errorf("%s: %w", op, storage.ErrFieldIsTooLong)
-		}
-
-		return -1, fmt.Errorf("%s: %w", op, result.Error)
+		return -1, fmt.Errorf("%s: %w", op, s.classifyError(err, user))
 	}
 
 	return int64(user.ID), nil
 }
 
-func (s *Storage) SaveClient(client *models.Client) error {
+// ActivateUser marks pending user as active once registration is confirmed by auth service
+func (s *Storage) ActivateUser(ctx context.Context, id uint) error {
+	const op = "postgres.ActivateUser"
+	res := s.DB.WithContext(ctx).Model(&models.User{}).
+		Where("id = ? AND status = ?", id, models.UserStatusPending).
+		Update("status", models.UserStatusActive)
+	if err := res.Error; err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	if res.RowsAffected == 0 {
+		return fmt.Errorf("%s: %w", op, storage.ErrRecordNotFound)
+	}
+
+	return nil
+}
+
+// DeletePendingUser removes user whose registration was not completed. Active users are never touched.
+func (s *Storage) DeletePendingUser(ctx context.Context, id uint) error {
+	const op = "postgres.DeletePendingUser"
+	res := s.DB.WithContext(ctx).Where("id = ? AND status = ?", id, models.UserStatusPending).Delete(&models.User{})
+	if err := res.Error; err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	return nil
+}
+
+// GetPendingUsers returns users stuck in registration since before the given moment
+func (s *Storage) GetPendingUsers(registeredBefore time.Time) ([]models.User, error) {
+	const op = "postgres.GetPendingUsers"
+	var users []models.User
+	res := s.DB.Where("status = ? AND registered_at < ?", models.UserStatusPending, registeredBefore).Find(&users)
+	if err := res.Error; err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return users, nil
+}
+
+func (s *Storage) SaveClient(ctx context.Context, client *models.Client) error {
 	const op = "postgres.SaveClient"
-	result := s.DB.Create(client)
+	result := s.DB.WithContext(ctx).Create(client)
 	if result.Error != nil {
-		if isDuplicateKeyError(result.Error) {
-			return fmt.Errorf("%s: %w", op, storage.ErrDuplicateKey)
-		} else if isTooLongFieldError(result.Error) {
-			return fmt.Errorf("%s: %w", op, storage.ErrFieldIsTooLong)
-		}
-
-		return fmt.Errorf("%s: %w", op, result.Error)
+		return fmt.Errorf("%s: %w", op, s.classifyError(result.Error, client))
 	}
 
 	return nil
 }
 
-func (s *Storage) SaveTrainer(trainer *models.Trainer) error {
+func (s *Storage) SaveTrainer(ctx context.Context, trainer *models.Trainer) error {
 	const op = "postgres.SaveTrainer"
-	result := s.DB.Create(trainer)
+	result := s.DB.WithContext(ctx).Create(trainer)
 	if result.Error != nil {
-		if isDuplicateKeyError(result.Error) {
-			return fmt.Errorf("%s: %w", op, storage.ErrDuplicateKey)
-		} else if isTooLongFieldError(result.Error) {
-			return fmt.Errorf("%s: %w", op, storage.ErrFieldIsTooLong)
-		}
-
-		return fmt.Errorf("%s: %w", op, result.Error)
+		return fmt.Errorf("%s: %w", op, s.classifyError(result.Error, trainer))
 	}
 
 	return nil
@@ -195,6 +334,22 @@ func (s *Storage) GetUserByEmail(email string) (*models.User, error) {
 	return &user, nil
 }
 
+// UpdateUserSettings saves nonempty settings of user
+func (s *Storage) UpdateUserSettings(ctx context.Context, email string, settings models.UserSettings) error {
+	const op = "postgres.UpdateUserSettings"
+	res := s.DB.WithContext(ctx).Model(&models.User{}).
+		Where("email = ?", email).
+		Updates(models.User{Units: settings.Units, Timezone: settings.Timezone})
+	if err := res.Error; err != nil {
+		return fmt.Errorf("%s: %w", op, s.classifyError(err, &models.User{}))
+	}
+	if res.RowsAffected == 0 {
+		return fmt.Errorf("%s: %w", op, storage.ErrRecordNotFound)
+	}
+
+	return nil
+}
+
 func (s *Storage) GetTrainerByID(id uint) (*models.Trainer, error) {
 	const op = "postgres.GetTrainerByID"
 	var trainer models.Trainer
@@ -210,6 +365,23 @@ func (s *Storage) GetTrainerByID(id uint) (*models.Trainer, error) {
 	return &trainer, nil
 }
 
+// LockTrainer reads trainer and keeps it from being changed until the end of transaction, so that checks
+// of its status stay valid. It must be called on storage passed by WithTx.
+func (s *Storage) LockTrainer(id uint) (*models.Trainer, error) {
+	const op = "postgres.LockTrainer"
+	var trainer models.Trainer
+	result := s.DB.Clauses(clause.Locking{Strength: "SHARE"}).First(&trainer, "id = ?", id)
+	if err := result.Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, fmt.Errorf("%s: %w", op, storage.ErrRecordNotFound)
+		}
+
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return &trainer, nil
+}
+
 func (s *Storage) GetTrainerByUserID(userID uint) (*models.Trainer, error) {
 	const op = "postgres.GetTrainerByUserID"
 	var trainer models.Trainer
@@ -255,61 +427,53 @@ func (s *Storage) GetClientByUserID(userID uint) (*models.Client, error) {
 	return &client, nil
 }
 
-func (s *Storage) UpdateTrainerID(clientID, trainerID uint) error {
-	// TODO: return more detailed error
-	return s.DB.Model(&models.Client{}).Where("id = ?", clientID).Update("trainer_id", trainerID).Error
-}
-
-func (s *Storage) GetTrainersClients(trainerID uint) ([]models.Client, error) {
-	var clients []models.Client
-	res := s.DB.Where("trainer_id = ?", trainerID).Find(&clients)
-	if res.Error != nil {
-		return []models.Client{}, res.Error
+func (s *Storage) UpdateTrainerID(ctx context.Context, clientID, trainerID uint) error {
+	const op = "postgres.UpdateTrainerID"
+	res := s.DB.WithContext(ctx).Model(&models.Client{}).Where("id = ?", clientID).Update("trainer_id", trainerID)
+	if err := res.Error; err != nil {
+		return fmt.Errorf("%s: %w", op, s.classifyError(err, &models.Client{}))
 	}
 
-	return clients, nil
+	return nil
 }
 
-func (s *Storage) CreatePlan(plan *models.TrainingPlan) error {
-	const op = "postgres.CreatePlan"
-	result := s.DB.Create(plan)
-	if result.Error != nil {
-		if isTooLongFieldError(result.Error) {
-			return fmt.Errorf("%s: %w", op, storage.ErrFieldIsTooLong)
-		}
-
-		return fmt.Errorf("%s: %w", op, result.Error)
+// UpdateClientGoals replaces goals of client
+func (s *Storage) UpdateClientGoals(ctx context.Context, clientID uint, goals models.MeasurementValues) error {
+	const op = "postgres.UpdateClientGoals"
+	res := s.DB.WithContext(ctx).Model(&models.Client{}).Where("id = ?", clientID).Update("goals", goals)
+	if err := res.Error; err != nil {
+		return fmt.Errorf("%s: %w", op, s.classifyError(err, &models.Client{}))
 	}
 
 	return nil
 }
 
-func (s *Storage) AddMetrics(metric *models.Metric) error {
-	const op = "postgres.AddMetrics"
-	result := s.DB.Create(metric)
-	if err := result.Error; err != nil {
-		return fmt.Errorf("%s: %w", op, result.Error)
+func (s *Storage) GetTrainersClients(trainerID uint) ([]models.Client, error) {
+	const op = "postgres.GetTrainersClients"
+	var clients []models.Client
+	res := s.reader().Where("trainer_id = ?", trainerID).Find(&clients)
+	if err := res.Error; err != nil {
+		return []models.Client{}, fmt.Errorf("%s: %w", op, err)
 	}
 
-	return nil
+	return clients, nil
 }
 
-func (s *Storage) GetMetrics(clientID uint) ([]models.Metric, error) {
-	const op = "postgres.GetMetrics"
-	var metrics []models.Metric
-	res := s.DB.Where("client_id = ?", clientID).Find(&metrics)
-	if res.Error != nil {
-		return []models.Metric{}, fmt.Errorf("%s: %w", op, res.Error)
+func (s *Storage) CreatePlan(ctx context.Context, plan *models.TrainingPlan) error {
+	const op = "postgres.CreatePlan"
+	result := s.DB.WithContext(ctx).Create(plan)
+	if result.Error != nil {
+		return fmt.Errorf("%s: %w", op, s.classifyError(result.Error, plan))
 	}
 
-	return metrics, nil
+	return nil
 }
 
-func (s *Storage) AddProgressReport(report *models.ProgressReport) error {
+func (s *Storage) AddProgressReport(ctx context.Context, report *models.ProgressReport) error {
 	const op = "postgres.AddProgressReport"
-	res := s.DB.Create(report)
+	res := s.DB.WithContext(ctx).Create(report)
 	if err := res.Error; err != nil {
-		return fmt.Errorf("%s: %w", op, res.Error)
+		return fmt.Errorf("%s: %w", op, s.classifyError(err, report))
 	}
 
 	return nil
@@ -318,7 +482,7 @@ func (s *Storage) AddProgressReport(report *models.ProgressReport) error {
 func (s *Storage) GetProgressReport(trainerID, clientID uint) ([]models.ProgressReport, error) {
 	const op = "postgres.GetProgressReport"
 	var reports []models.ProgressReport
-	res := s.DB.Where("trainer_id = ? AND client_id = ?", trainerID, clientID).Find(&reports)
+	res := s.reader().Where("trainer_id = ? AND client_id = ?", trainerID, clientID).Find(&reports)
 	if err := res.Error; err != nil {
 		return []models.ProgressReport{}, fmt.Errorf("%s: %w", op, res.Error)
 	}
@@ -329,22 +493,10 @@ func (s *Storage) GetProgressReport(trainerID, clientID uint) ([]models.Progress
 func (s *Storage) GetPlan(trainerID, clientID uint) ([]models.TrainingPlan, error) {
 	const op = "postgres.GetTrainingPlan"
 	var plans []models.TrainingPlan
-	res := s.DB.Where("trainer_id = ? AND client_id = ?", trainerID, clientID).Find(&plans)
+	res := s.reader().Where("trainer_id = ? AND client_id = ?", trainerID, clientID).Find(&plans)
 	if err := res.Error; err != nil {
 		return []models.TrainingPlan{}, fmt.Errorf("%s: %w", op, res.Error)
 	}
 
 	return plans, nil
 }
-
-func isInvalidEnumError(err error) bool {
-	return strings.Contains(err.Error(), "SQLSTATE 22P02")
-}
-
-func isTooLongFieldError(err error) bool {
-	return strings.Contains(err.Error(), "SQLSTATE 22001")
-}
-
-func isDuplicateKeyError(err error) bool {
-	return strings.Contains(err.Error(), "SQLSTATE 23505")
-}
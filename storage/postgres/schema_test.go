@@ -0,0 +1,113 @@
+package postgres
+
+import (
+	"context"
+	"testing"
+
+	"ChadProgress/internal/models"
+	"ChadProgress/storage"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestSchema inspects the live schema created by New
+func TestSchema(t *testing.T) {
+	s := newTestStorage(t)
+
+	t.Run("Constraints", func(t *testing.T) {
+		expected := map[string]string{
+			"fk_trainers_user":            "trainers",
+			"fk_clients_user":             "clients",
+			"fk_clients_trainer":          "clients",
+			"fk_training_plans_trainer":   "training_plans",
+			"fk_training_plans_client":    "training_plans",
+			"fk_progress_reports_trainer": "progress_reports",
+			"fk_progress_reports_client":  "progress_reports",
+			"fk_metrics_client":           "metrics",
+			"fk_metric_rollups_client":    "metric_rollups",
+			"chk_users_units":             "users",
+			"chk_clients_height":          "clients",
+			"chk_clients_weight":          "clients",
+			"chk_clients_body_fat":        "clients",
+			"chk_clients_goals":           "clients",
+			"chk_metrics_measurements":    "metrics",
+		}
+
+		var rows []struct {
+			Name      string
+			Table     string
+			Validated bool
+		}
+		err := s.DB.Raw(`
+			SELECT c.conname AS name, t.relname AS "table", c.convalidated AS validated
+			FROM pg_constraint c JOIN pg_class t ON t.oid = c.conrelid
+			WHERE c.contype IN ('f', 'c') AND NOT t.relispartition
+		`).Scan(&rows).Error
+		require.NoError(t, err)
+
+		found := make(map[string]string, len(rows))
+		for _, row := range rows {
+			found[row.Name] = row.Table
+			if _, ok := expected[row.Name]; ok {
+				assert.True(t, row.Validated, "%s is violated by existing rows", row.Name)
+			}
+		}
+		for name, table := range expected {
+			assert.Equal(t, table, found[name], "constraint %s", name)
+		}
+	})
+
+	t.Run("Indexes", func(t *testing.T) {
+		expected := map[string]string{
+			"idx_metrics_client_measured":         "(client_id, measured_at)",
+			"idx_clients_trainer_id":              "(trainer_id)",
+			"idx_training_plans_trainer_client":   "(trainer_id, client_id)",
+			"idx_training_plans_client_id":        "(client_id)",
+			"idx_progress_reports_trainer_client": "(trainer_id, client_id)",
+			"idx_progress_reports_client_id":      "(client_id)",
+			"idx_users_status_registered":         "(status, registered_at)",
+			"idx_email_tokens_login_purpose":      "(login, purpose)",
+			"idx_data_exports_status_created":     "(status, created_at)",
+			"idx_audit_entity":                    "(entity, entity_id)",
+		}
+
+		var rows []struct {
+			Name       string
+			Definition string
+		}
+		err := s.DB.Raw(`SELECT indexname AS name, indexdef AS definition FROM pg_indexes WHERE schemaname = current_schema()`).
+			Scan(&rows).Error
+		require.NoError(t, err)
+
+		found := make(map[string]string, len(rows))
+		for _, row := range rows {
+			found[row.Name] = row.Definition
+		}
+		for name, columns := range expected {
+			if assert.Contains(t, found, name) {
+				assert.Contains(t, found[name], columns, "index %s", name)
+			}
+		}
+	})
+
+	t.Run("Violations", func(t *testing.T) {
+		ctx := context.Background()
+
+		err := s.AddMetrics(ctx, &models.Metric{ClientID: 1 << 30, Measurements: models.MeasurementValues{models.MeasurementWeight: 80}})
+		assert.ErrorIs(t, err, storage.ErrForeignKeyViolation)
+		assertField(t, err, "client_id")
+
+		err = s.SaveClient(ctx, &models.Client{UserID: 1 << 30, TrainerID: dummyTrainerID, Weight: -1})
+		assert.ErrorIs(t, err, storage.ErrCheckViolation)
+		assertField(t, err, "weight")
+	})
+}
+
+func assertField(t *testing.T, err error, field string) {
+	t.Helper()
+	var fieldErr *storage.FieldError
+	if assert.ErrorAs(t, err, &fieldErr) {
+		assert.Equal(t, field, fieldErr.Field)
+	}
+}
@@ -0,0 +1,17 @@
+package postgres
+
+import (
+	"context"
+
+	"gorm.io/gorm"
+)
+
+// WithTx runs fn in a transaction. Storage passed to fn is bound to the transaction and to ctx, its
+// methods see changes made before in fn. The transaction is committed when fn returns nil and rolled
+// back when it returns error or panics. Error of fn is returned as is. Calls of methods which open
+// transactions themselves are nested with savepoints.
+func (s *Storage) WithTx(ctx context.Context, fn func(tx *Storage) error) error {
+	return s.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		return fn(&Storage{DB: tx})
+	})
+}
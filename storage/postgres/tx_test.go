@@ -0,0 +1,79 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"os"
+	"testing"
+
+	"ChadProgress/internal/models"
+	"ChadProgress/storage"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// testDSNEnv names database the storage tests run against. They change data, so it must not be
+// a database anyone uses.
+const testDSNEnv = "CP_TEST_DSN"
+
+func newTestStorage(t testing.TB) *Storage {
+	t.Helper()
+	dsn := os.Getenv(testDSNEnv)
+	if dsn == "" {
+		t.Skipf("%s is not set", testDSNEnv)
+	}
+
+	s, err := New(dsn, slog.New(slog.NewTextHandler(io.Discard, nil)), Options{})
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		if db, err := s.DB.DB(); err == nil {
+			_ = db.Close()
+		}
+	})
+
+	return s
+}
+
+func TestWithTxRollsBack(t *testing.T) {
+	s := newTestStorage(t)
+	ctx := context.Background()
+	failure := errors.New("second step failed")
+	email := "tx-rollback@example.com"
+
+	err := s.WithTx(ctx, func(tx *Storage) error {
+		if _, err := tx.SaveUser(ctx, &models.User{Email: email, Name: "Tx", Role: models.RoleClient}); err != nil {
+			return err
+		}
+
+		return failure
+	})
+	require.ErrorIs(t, err, failure)
+
+	_, err = s.GetUserByEmail(email)
+	assert.ErrorIs(t, err, storage.ErrRecordNotFound)
+}
+
+func TestWithTxCommits(t *testing.T) {
+	s := newTestStorage(t)
+	ctx := context.Background()
+	email := "tx-commit@example.com"
+
+	err := s.WithTx(ctx, func(tx *Storage) error {
+		user := &models.User{Email: email, Name: "Tx", Role: models.RoleClient, Status: models.UserStatusPending}
+		_, err := tx.SaveUser(ctx, user)
+		if err != nil {
+			return err
+		}
+		t.Cleanup(func() { _ = s.DeletePendingUser(ctx, user.ID) })
+
+		return nil
+	})
+	require.NoError(t, err)
+
+	user, err := s.GetUserByEmail(email)
+	require.NoError(t, err)
+	assert.Equal(t, email, user.Email)
+}
@@ -7,12 +7,35 @@ import (
 )
 
 var (
-	ErrUserAlreadyExists = errors.New("user already exists")
-	ErrRecordNotFound    = errors.New("user not found")
-	ErrFieldIsTooLong    = errors.New("field is too long")
-	ErrDuplicateKey      = errors.New("duplicate key value violates unique constraint")
+	ErrRecordNotFound      = errors.New("user not found")
+	ErrFieldIsTooLong      = errors.New("field is too long")
+	ErrDuplicateKey        = errors.New("duplicate key value violates unique constraint")
+	ErrForeignKeyViolation = errors.New("referenced record does not exist")
+	ErrInvalidEnumValue    = errors.New("invalid enum value")
+	ErrCheckViolation      = errors.New("value violates check constraint")
 )
 
+// FieldError is a constraint violation caused by a value of a particular column. It matches its
+// kind, one of the errors above, in errors.Is.
+type FieldError struct {
+	Err error
+	// Field is the column, empty when database did not tell which one it is
+	Field      string
+	Constraint string
+}
+
+func (e *FieldError) Error() string {
+	if e.Field == "" {
+		return e.Err.Error()
+	}
+
+	return e.Err.Error() + ": " + e.Field
+}
+
+func (e *FieldError) Unwrap() error {
+	return e.Err
+}
+
 type Storage interface {
 	SaveUser(user *models.User) (int64, error)
 	SaveClient(client *models.Client) error